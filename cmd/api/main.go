@@ -10,9 +10,12 @@ import (
 
 	"CLOAKBE/internal/config"
 	"CLOAKBE/internal/database"
+	"CLOAKBE/internal/domain"
 	"CLOAKBE/internal/handler"
 	"CLOAKBE/internal/middleware"
+	"CLOAKBE/internal/notify"
 	"CLOAKBE/internal/repository"
+	"CLOAKBE/internal/repository/memory"
 	"CLOAKBE/internal/usecase"
 
 	"github.com/gofiber/fiber/v2"
@@ -46,38 +49,188 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	// Initialize database
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	// Init repositories
+	var (
+		businessRepo domain.BusinessRepository
+		keyRepo      domain.BusinessKeyRepository
+		customerRepo domain.CustomerRepository
+		serviceRepo  domain.ServiceRepository
+		zoneRepo     domain.ZoneRepository
+		slotRepo     domain.SlotRepository
+		ticketRepo   domain.TicketRepository
+		syncRepo     domain.SyncEventRepository
+		waitlistRepo domain.WaitlistRepository
+		holdRepo     domain.SlotHoldRepository
+		itemTypeRepo domain.ItemTypeRepository
+		itemRepo     domain.TicketItemRepository
+		reissueRepo  domain.TicketReissueRepository
+		transferRepo domain.TicketTransferRepository
+		eventRepo    domain.TicketEventRepository
+		attemptRepo  domain.ScanAttemptRepository
+		alertRepo    domain.ScanAlertRepository
+		staffRepo    domain.StaffRepository
+		deviceRepo   domain.DeviceRepository
+		sessionRepo  domain.AuthSessionRepository
+		refreshRepo  domain.RefreshTokenRepository
+		codeRepo     domain.LoginCodeRepository
+		bizTokenRepo domain.BusinessTokenRepository
+		factorRepo   domain.TOTPFactorRepository
+		recoveryRepo domain.RecoveryCodeRepository
+		mfaRepo      domain.MFAChallengeRepository
+		limitRepo    domain.RateLimitRepository
+		lockoutRepo  domain.LoginLockoutRepository
+		txManager    domain.TxManager
+	)
 
-	db, err := database.New(ctx, cfg.DatabaseURL)
-	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+	switch cfg.Storage {
+	case "memory":
+		log.Println("Using in-memory storage, data will be lost on shutdown")
+		store := memory.NewStore()
+		businessRepo = memory.NewBusinessRepository(store)
+		keyRepo = memory.NewBusinessKeyRepository(store)
+		customerRepo = memory.NewCustomerRepository(store)
+		serviceRepo = memory.NewServiceRepository(store)
+		zoneRepo = memory.NewZoneRepository(store)
+		slotRepo = memory.NewSlotRepository(store)
+		ticketRepo = memory.NewTicketRepository(store)
+		syncRepo = memory.NewSyncEventRepository(store)
+		waitlistRepo = memory.NewWaitlistRepository(store)
+		holdRepo = memory.NewSlotHoldRepository(store)
+		itemTypeRepo = memory.NewItemTypeRepository(store)
+		itemRepo = memory.NewTicketItemRepository(store)
+		reissueRepo = memory.NewTicketReissueRepository(store)
+		transferRepo = memory.NewTicketTransferRepository(store)
+		eventRepo = memory.NewTicketEventRepository(store)
+		attemptRepo = memory.NewScanAttemptRepository(store)
+		alertRepo = memory.NewScanAlertRepository(store)
+		staffRepo = memory.NewStaffRepository(store)
+		deviceRepo = memory.NewDeviceRepository(store)
+		sessionRepo = memory.NewAuthSessionRepository(store)
+		refreshRepo = memory.NewRefreshTokenRepository(store)
+		codeRepo = memory.NewLoginCodeRepository(store)
+		bizTokenRepo = memory.NewBusinessTokenRepository(store)
+		factorRepo = memory.NewTOTPFactorRepository(store)
+		recoveryRepo = memory.NewRecoveryCodeRepository(store)
+		mfaRepo = memory.NewMFAChallengeRepository(store)
+		limitRepo = memory.NewRateLimitRepository(store)
+		lockoutRepo = memory.NewLoginLockoutRepository(store)
+		txManager = memory.NewTxManager(store)
+	default:
+		// Initialize database
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		db, err := database.New(ctx, cfg.DatabaseURL)
+		if err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+		defer db.Close()
+
+		businessRepo = repository.NewPostgresBusinessRepository(db)
+		keyRepo = repository.NewPostgresBusinessKeyRepository(db)
+		customerRepo = repository.NewPostgresCustomerRepository(db)
+		serviceRepo = repository.NewPostgresServiceRepository(db)
+		zoneRepo = repository.NewPostgresZoneRepository(db)
+		slotRepo = repository.NewPostgresSlotRepository(db)
+		ticketRepo = repository.NewPostgresTicketRepository(db)
+		syncRepo = repository.NewPostgresSyncEventRepository(db)
+		waitlistRepo = repository.NewPostgresWaitlistRepository(db)
+		holdRepo = repository.NewPostgresSlotHoldRepository(db)
+		itemTypeRepo = repository.NewPostgresItemTypeRepository(db)
+		itemRepo = repository.NewPostgresTicketItemRepository(db)
+		reissueRepo = repository.NewPostgresTicketReissueRepository(db)
+		transferRepo = repository.NewPostgresTicketTransferRepository(db)
+		eventRepo = repository.NewPostgresTicketEventRepository(db)
+		attemptRepo = repository.NewPostgresScanAttemptRepository(db)
+		alertRepo = repository.NewPostgresScanAlertRepository(db)
+		staffRepo = repository.NewPostgresStaffRepository(db)
+		deviceRepo = repository.NewPostgresDeviceRepository(db)
+		sessionRepo = repository.NewPostgresAuthSessionRepository(db)
+		refreshRepo = repository.NewPostgresRefreshTokenRepository(db)
+		codeRepo = repository.NewPostgresLoginCodeRepository(db)
+		bizTokenRepo = repository.NewPostgresBusinessTokenRepository(db)
+		factorRepo = repository.NewPostgresTOTPFactorRepository(db)
+		recoveryRepo = repository.NewPostgresRecoveryCodeRepository(db)
+		mfaRepo = repository.NewPostgresMFAChallengeRepository(db)
+		txManager = database.NewTxManager(db)
+
+		if cfg.RateLimitStorage == "memory" {
+			limitStore := memory.NewStore()
+			limitRepo = memory.NewRateLimitRepository(limitStore)
+			lockoutRepo = memory.NewLoginLockoutRepository(limitStore)
+		} else {
+			limitRepo = repository.NewPostgresRateLimitRepository(db)
+			lockoutRepo = repository.NewPostgresLoginLockoutRepository(db)
+		}
 	}
-	defer db.Close()
 
-	// Init repositories
-	businessRepo := repository.NewPostgresBusinessRepository(db)
-	customerRepo := repository.NewPostgresCustomerRepository(db)
-	serviceRepo := repository.NewPostgresServiceRepository(db)
-	slotRepo := repository.NewPostgresSlotRepository(db)
-	ticketRepo := repository.NewPostgresTicketRepository(db)
+	// Init notification sender
+	var sender notify.Sender = notify.NewLogSender()
+	if cfg.NotifySender == "file" {
+		sender = notify.NewFileSender(cfg.NotifyFile)
+	}
 
 	// Init usecases
-	authUsecase := usecase.NewAuthUsecase(businessRepo, customerRepo, cfg.JWTSecret)
-	ticketUsecase := usecase.NewTicketUsecase(ticketRepo, slotRepo, serviceRepo, businessRepo)
-	serviceUsecase := usecase.NewServiceUsecase(serviceRepo, slotRepo, businessRepo)
+	rateLimitUsecase := usecase.NewRateLimitUsecase(limitRepo, lockoutRepo, usecase.LockoutPolicy{
+		Threshold: cfg.LoginLockoutThreshold,
+		BaseDelay: cfg.LoginLockoutBaseDelay,
+		MaxDelay:  cfg.LoginLockoutMaxDelay,
+	})
+	authUsecase := usecase.NewAuthUsecase(businessRepo, keyRepo, sessionRepo, refreshRepo, factorRepo, mfaRepo, rateLimitUsecase, txManager, cfg.JWTSecret, cfg.AccessTokenTTL, cfg.RefreshTokenTTL, cfg.SessionCacheTTL, cfg.RequireVerifiedEmail)
+	accountUsecase := usecase.NewBusinessAccountUsecase(businessRepo, bizTokenRepo, authUsecase, sender, txManager, cfg.EmailVerificationTTL, cfg.PasswordResetTTL, cfg.BusinessVerifyEmailURL, cfg.BusinessResetPasswordURL)
+	mfaUsecase := usecase.NewMFAUsecase(factorRepo, recoveryRepo, mfaRepo, authUsecase, txManager)
+	customerAuthUsecase := usecase.NewCustomerAuthUsecase(codeRepo, customerRepo, authUsecase, sender, txManager, cfg.LoginCodeTTL, cfg.LoginCodeResendInterval, cfg.CustomerLoginLinkURL)
+	staffUsecase := usecase.NewStaffUsecase(staffRepo, authUsecase, cfg.StaffInviteTTL)
+	deviceUsecase := usecase.NewDeviceUsecase(deviceRepo, serviceRepo)
+	waitlistUsecase := usecase.NewWaitlistUsecase(waitlistRepo, serviceRepo, zoneRepo, slotRepo, txManager, cfg.WaitlistOfferWindow)
+	holdUsecase := usecase.NewHoldUsecase(holdRepo, serviceRepo, zoneRepo, slotRepo, waitlistUsecase, txManager, cfg.SlotHoldDuration)
+	itemUsecase := usecase.NewItemUsecase(itemTypeRepo, itemRepo, ticketRepo, serviceRepo, zoneRepo, slotRepo)
+	eventUsecase := usecase.NewTicketEventUsecase(eventRepo, ticketRepo, serviceRepo)
+	scanAuditUsecase := usecase.NewScanAuditUsecase(attemptRepo, alertRepo, txManager, usecase.ScanAlertPolicy{
+		BadSignatureThreshold: cfg.ScanAlertBadSignatures,
+		BadSignatureWindow:    cfg.ScanAlertBadSignatureWindow,
+		DuplicateWindow:       cfg.ScanAlertDuplicateWindow,
+	})
+	recoveryUsecase := usecase.NewRecoveryUsecase(ticketRepo, reissueRepo, serviceRepo, customerRepo, slotRepo, keyRepo, itemUsecase, eventUsecase, txManager)
+	transferUsecase := usecase.NewTransferUsecase(transferRepo, ticketRepo, serviceRepo, customerRepo, slotRepo, keyRepo, itemUsecase, eventUsecase, txManager)
+	ticketUsecase := usecase.NewTicketUsecase(ticketRepo, slotRepo, serviceRepo, zoneRepo, keyRepo, waitlistUsecase, holdUsecase, itemUsecase, recoveryUsecase, transferUsecase, eventUsecase, scanAuditUsecase, txManager)
+	serviceUsecase := usecase.NewServiceUsecase(serviceRepo, slotRepo, businessRepo, ticketRepo, zoneRepo, waitlistUsecase, holdUsecase, eventUsecase, txManager)
+	zoneUsecase := usecase.NewZoneUsecase(zoneRepo, serviceRepo, slotRepo, txManager)
+	keyUsecase := usecase.NewKeyUsecase(keyRepo, txManager, cfg.KeyGracePeriod)
+	syncUsecase := usecase.NewSyncUsecase(ticketUsecase, ticketRepo, serviceRepo, syncRepo, txManager)
+	expiryUsecase := usecase.NewExpiryUsecase(ticketRepo, slotRepo, serviceRepo, waitlistUsecase, holdUsecase, eventUsecase, txManager)
 
 	// Init handlers
-	authHandler := handler.NewAuthHandler(authUsecase)
+	authHandler := handler.NewAuthHandler(authUsecase, accountUsecase)
+	mfaHandler := handler.NewMFAHandler(mfaUsecase)
+	customerAuthHandler := handler.NewCustomerAuthHandler(customerAuthUsecase)
+	staffHandler := handler.NewStaffHandler(staffUsecase)
+	deviceHandler := handler.NewDeviceHandler(deviceUsecase)
 	ticketHandler := handler.NewTicketHandler(ticketUsecase)
 	serviceHandler := handler.NewServiceHandler(serviceUsecase)
+	zoneHandler := handler.NewZoneHandler(zoneUsecase)
+	waitlistHandler := handler.NewWaitlistHandler(waitlistUsecase)
+	holdHandler := handler.NewHoldHandler(holdUsecase, ticketUsecase)
+	itemHandler := handler.NewItemHandler(itemUsecase)
+	recoveryHandler := handler.NewRecoveryHandler(recoveryUsecase)
+	transferHandler := handler.NewTransferHandler(transferUsecase)
+	eventHandler := handler.NewTicketEventHandler(eventUsecase)
+	scanAuditHandler := handler.NewScanAuditHandler(scanAuditUsecase)
+	keyHandler := handler.NewKeyHandler(keyUsecase)
+	syncHandler := handler.NewSyncHandler(syncUsecase)
+	expiryHandler := handler.NewExpiryHandler(expiryUsecase)
 
 	// Setup Fiber app
 	app := fiber.New(fiber.Config{
 		AppName:      "CLOAK API v1.0",
 		BodyLimit:    1024 * 1024,
 		ErrorHandler: defaultErrorHandler,
+		// Behind a load balancer, c.IP() (and so per-IP rate limits) reads
+		// the client address from ProxyHeader on requests from its addresses
+		ProxyHeader:             cfg.ProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          cfg.TrustedProxies,
+		EnableIPValidation:      true,
 	})
 
 	// Global middleware
@@ -87,7 +240,7 @@ func main() {
 		AllowOrigins:     "*",
 		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS,PATCH",
 		AllowHeaders:     "Origin,Content-Type,Authorization,Accept",
-		ExposeHeaders:    "Content-Length",
+		ExposeHeaders:    "Content-Length,Retry-After,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset",
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
@@ -97,38 +250,172 @@ func main() {
 		return c.JSON(fiber.Map{"status": "ok"})
 	})
 
+	// Rate limit policies. Logins are limited per IP and per account on top
+	// of the lockouts bad passwords earn; other public routes per IP; the
+	// authenticated API per account, and scanning per account and business.
+	rateLimit := func(name string, limit config.RateLimit, keyFunc middleware.RateLimitKeyFunc) fiber.Handler {
+		if limit.Limit == 0 {
+			return func(c *fiber.Ctx) error { return c.Next() }
+		}
+		policy := usecase.RateLimitPolicy{Name: name, Limit: limit.Limit, Window: limit.Window}
+		return middleware.RateLimitMiddleware(rateLimitUsecase, policy, keyFunc)
+	}
+	loginPerIP := rateLimit("login-ip", cfg.RateLimitLoginIP, middleware.KeyByIP)
+	loginPerAccount := rateLimit("login-account", cfg.RateLimitLoginAccount, middleware.KeyByAccount)
+	publicPerIP := rateLimit("public-ip", cfg.RateLimitPublicIP, middleware.KeyByIP)
+	apiPerAccount := rateLimit("api-account", cfg.RateLimitAPIAccount, middleware.KeyByAccount)
+	scanPerAccount := rateLimit("scan-account", cfg.RateLimitScanAccount, middleware.KeyByAccount)
+	scanPerBusiness := rateLimit("scan-business", cfg.RateLimitScanBusiness, middleware.KeyByBusiness)
+
 	// Public routes
 	public := app.Group("/api/v1")
-	public.Post("/auth/business/register", authHandler.BusinessRegister)
-	public.Post("/auth/business/login", authHandler.BusinessLogin)
-	public.Post("/auth/customer/login", authHandler.CustomerLogin)
+	public.Post("/auth/business/register", publicPerIP, authHandler.BusinessRegister)
+	public.Post("/auth/business/login", loginPerIP, loginPerAccount, authHandler.BusinessLogin)
+	public.Post("/auth/business/verify-email", publicPerIP, authHandler.VerifyEmail)
+	public.Post("/auth/business/resend-verification", publicPerIP, authHandler.ResendVerification)
+	public.Post("/auth/business/forgot-password", publicPerIP, authHandler.ForgotPassword)
+	public.Post("/auth/business/reset-password", loginPerIP, authHandler.ResetPassword)
+	public.Post("/auth/mfa/verify", loginPerIP, mfaHandler.Verify)
+	public.Post("/auth/customer/login", loginPerIP, loginPerAccount, customerAuthHandler.RequestCode)
+	public.Post("/auth/customer/verify", loginPerIP, customerAuthHandler.VerifyCode)
+	public.Post("/auth/refresh", publicPerIP, authHandler.Refresh)
+	public.Post("/auth/staff/login", loginPerIP, loginPerAccount, staffHandler.StaffLogin)
+	public.Post("/auth/staff/accept", loginPerIP, staffHandler.AcceptInvite)
+	public.Get("/businesses/:id/keys", publicPerIP, keyHandler.ListPublicKeys)
 
-	// Protected routes (require JWT)
+	// Protected routes (require a JWT or a scanner device token)
 	protected := app.Group("/api/v1")
-	protected.Use(middleware.AuthMiddleware(cfg.JWTSecret))
+	protected.Use(middleware.AuthMiddleware(cfg.JWTSecret, authUsecase, deviceUsecase))
+	protected.Use(middleware.StaffMiddleware(staffUsecase))
+	protected.Use(apiPerAccount)
+
+	// Logout revokes the session of the access token presented
+	protected.Post("/auth/logout", middleware.RoleMiddleware("business", "customer"), authHandler.Logout)
+
+	// Two-factor enrollment, for the business account and each staff member
+	mfa := protected.Group("/auth/mfa")
+	mfa.Use(middleware.RoleMiddleware("business"))
+	mfa.Get("", mfaHandler.Status)
+	mfa.Post("/setup", mfaHandler.Setup)
+	mfa.Post("/confirm", mfaHandler.Confirm)
+	mfa.Post("/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+	mfa.Post("/disable", mfaHandler.Disable)
 
-	// Business routes (role: business)
-	business := protected.Group("/tickets")
-	business.Use(middleware.RoleMiddleware("business"))
-	business.Post("/checkin", ticketHandler.CheckIn)
-	business.Post("/scan", ticketHandler.Scan)
-	business.Post("/:id/release", ticketHandler.Release)
+	// Staff roles narrow business routes; the business account acts as owner
+	owners := middleware.StaffRoleMiddleware(domain.StaffRoleOwner)
+	managers := middleware.StaffRoleMiddleware(domain.StaffRoleOwner, domain.StaffRoleManager)
+
+	// Ticket routes. Roles are checked per route: a group-level middleware
+	// would apply to every /tickets path and lock customers out of check-in.
+	// Scanner devices may only use the routes of the actions they hold.
+	tickets := protected.Group("/tickets")
+	businessOnly := middleware.RoleMiddleware("business")
+	businessOrDevice := middleware.RoleMiddleware("business", "device")
+	tickets.Post("/checkin", middleware.RoleMiddleware("business", "customer", "device"), middleware.DeviceActionMiddleware(domain.DeviceActionCheckIn), scanPerAccount, scanPerBusiness, ticketHandler.CheckInByRole)
+	tickets.Post("/scan", businessOrDevice, middleware.DeviceActionMiddleware(domain.DeviceActionScan), scanPerAccount, scanPerBusiness, ticketHandler.Scan)
+	tickets.Post("/:id/release", businessOrDevice, middleware.DeviceActionMiddleware(domain.DeviceActionRelease), scanPerAccount, scanPerBusiness, ticketHandler.Release)
+	tickets.Get("/lookup", businessOnly, recoveryHandler.LookupTickets)
+	tickets.Get("/events", businessOnly, eventHandler.ListEvents)
+	tickets.Get("/:id/events", businessOnly, eventHandler.ListTicketEvents)
+	tickets.Post("/:id/reissue", businessOnly, managers, recoveryHandler.ReissueTicket)
+	tickets.Get("/:id/reissues", businessOnly, recoveryHandler.ListReissues)
+	tickets.Post("/:id/transfers", middleware.RoleMiddleware("customer"), transferHandler.StartTransfer)
+	tickets.Get("/:id/transfers", middleware.RoleMiddleware("business", "customer"), transferHandler.ListTransfers)
+	tickets.Post("/:id/items", businessOnly, itemHandler.AddItem)
+	tickets.Delete("/:id/items/:itemId", businessOnly, itemHandler.RemoveItem)
+	tickets.Post("/expiry/run", businessOnly, managers, expiryHandler.RunNow)
 
 	// Service routes (role: business)
 	services := protected.Group("/services")
 	services.Use(middleware.RoleMiddleware("business"))
-	services.Post("", serviceHandler.CreateService)
+	services.Post("", managers, serviceHandler.CreateService)
 	services.Get("", serviceHandler.ListServices)
 	services.Get("/:id", serviceHandler.GetService)
+	services.Put("/:id", managers, serviceHandler.UpdateService)
+	services.Delete("/:id", managers, serviceHandler.DeleteService)
+	services.Post("/:id/archive", managers, serviceHandler.ArchiveService)
+	services.Post("/:id/unarchive", managers, serviceHandler.UnarchiveService)
 	services.Get("/:id/stats", serviceHandler.GetServiceStats)
+	services.Get("/:id/zones", zoneHandler.ListZones)
+	services.Post("/:id/zones", managers, zoneHandler.CreateZone)
+	services.Put("/:id/zones/:zoneId", managers, zoneHandler.UpdateZone)
+	services.Get("/:id/waitlist", waitlistHandler.ListWaitlist)
+	services.Post("/:id/waitlist", waitlistHandler.AddToWaitlist)
+	services.Get("/:id/item-types", itemHandler.ListItemTypes)
+	services.Post("/:id/item-types", managers, itemHandler.CreateItemType)
+	services.Delete("/:id/item-types/:typeId", managers, itemHandler.DeleteItemType)
+
+	// QR signing key routes (role: business)
+	keys := protected.Group("/keys")
+	keys.Use(middleware.RoleMiddleware("business"))
+	keys.Get("", keyHandler.ListKeys)
+	keys.Post("/rotate", owners, keyHandler.RotateKey)
+
+	// Staff routes (owners invite and manage staff, managers see the roster)
+	staff := protected.Group("/staff")
+	staff.Use(middleware.RoleMiddleware("business"))
+	staff.Post("", owners, staffHandler.InviteStaff)
+	staff.Get("", managers, staffHandler.ListStaff)
+	staff.Put("/:id", owners, staffHandler.UpdateStaff)
+	staff.Delete("/:id", owners, staffHandler.DisableStaff)
+
+	// Scanner device routes (managers register and revoke devices)
+	devices := protected.Group("/devices")
+	devices.Use(middleware.RoleMiddleware("business"))
+	devices.Post("", managers, deviceHandler.RegisterDevice)
+	devices.Get("", deviceHandler.ListDevices)
+	devices.Get("/:id", deviceHandler.GetDevice)
+	devices.Put("/:id", managers, deviceHandler.UpdateDevice)
+	devices.Delete("/:id", managers, deviceHandler.RevokeDevice)
+
+	// Scan attempt and alert routes (managers acknowledge alerts)
+	scans := protected.Group("/scans")
+	scans.Use(middleware.RoleMiddleware("business"))
+	scans.Get("/attempts", scanAuditHandler.ListAttempts)
+	scans.Get("/alerts", scanAuditHandler.ListAlerts)
+	scans.Post("/alerts/:id/acknowledge", managers, scanAuditHandler.AcknowledgeAlert)
 
-	// Customer routes (role: customer)
-	customer := protected.Group("/tickets")
-	customer.Use(middleware.RoleMiddleware("customer"))
-	customer.Post("/checkin", ticketHandler.CustomerCheckIn)
+	// Offline scanner sync routes (role: business, or devices allowed to sync)
+	sync := protected.Group("/sync")
+	sync.Use(businessOrDevice, middleware.DeviceActionMiddleware(domain.DeviceActionSync))
+	sync.Get("/manifest", syncHandler.Manifest)
+	sync.Post("/events", syncHandler.Upload)
 
-	// Customer ticket list route
-	protected.Get("/customers/:id/tickets", ticketHandler.GetCustomerTickets)
+	// Waitlist routes (customers for their own entries, businesses for their services)
+	waitlist := protected.Group("/waitlist")
+	waitlist.Post("", middleware.RoleMiddleware("customer"), waitlistHandler.JoinWaitlist)
+	waitlist.Get("/:id", middleware.RoleMiddleware("business", "customer"), waitlistHandler.GetEntry)
+	waitlist.Delete("/:id", middleware.RoleMiddleware("business", "customer"), waitlistHandler.LeaveWaitlist)
+
+	// Slot hold routes (customers hold a slot, staff confirm it into a ticket)
+	holds := protected.Group("/holds")
+	holds.Post("", middleware.RoleMiddleware("customer"), holdHandler.CreateHold)
+	holds.Get("/:id", middleware.RoleMiddleware("business", "customer"), holdHandler.GetHold)
+	holds.Post("/:id/confirm", businessOnly, holdHandler.ConfirmHold)
+	holds.Delete("/:id", middleware.RoleMiddleware("business", "customer"), holdHandler.CancelHold)
+
+	// Customer ticket list routes (customers see their own tickets,
+	// businesses the tickets issued for their services)
+	protected.Get("/customers/:id/tickets", middleware.RoleMiddleware("business", "customer"), ticketHandler.GetCustomerTickets)
+	protected.Get("/me/tickets", middleware.RoleMiddleware("customer"), ticketHandler.GetMyTickets)
+
+	// Ticket transfer routes (the recipient accepts or declines, the sender
+	// may cancel while the transfer is pending)
+	protected.Get("/me/transfers", middleware.RoleMiddleware("customer"), transferHandler.ListIncoming)
+	transfers := protected.Group("/transfers")
+	transfers.Use(middleware.RoleMiddleware("customer"))
+	transfers.Post("/:id/accept", transferHandler.AcceptTransfer)
+	transfers.Delete("/:id", transferHandler.CancelTransfer)
+
+	// Background sweepers. Holds and waitlist offers lapse even when ticket
+	// expiry is disabled.
+	sweeperCtx, stopSweeper := context.WithCancel(context.Background())
+	defer stopSweeper()
+	if cfg.ExpirySweepInterval > 0 {
+		go runExpirySweeper(sweeperCtx, expiryUsecase, cfg.ExpirySweepInterval)
+	}
+	go runReservationSweeper(sweeperCtx, expiryUsecase, reservationSweepInterval)
+	go runRateLimitSweeper(sweeperCtx, rateLimitUsecase, rateLimitSweepInterval)
 
 	// Start server with graceful shutdown
 	go func() {
@@ -144,6 +431,7 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down server...")
+	stopSweeper()
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -154,6 +442,75 @@ func main() {
 	log.Println("Server exited")
 }
 
+// runExpirySweeper expires overdue tickets every interval until ctx is cancelled
+func runExpirySweeper(ctx context.Context, expiryUsecase *usecase.ExpiryUsecase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := expiryUsecase.Sweep(ctx, "", "")
+			if err != nil {
+				log.Printf("Expiry sweep failed: %v", err)
+				continue
+			}
+			if len(result.Expired) > 0 {
+				log.Printf("Expiry sweep expired %d tickets", len(result.Expired))
+			}
+		}
+	}
+}
+
+// reservationSweepInterval is how often unconfirmed slot holds and untaken
+// waitlist offers are closed
+const reservationSweepInterval = 15 * time.Second
+
+// runReservationSweeper closes lapsed holds and offers until ctx is cancelled
+func runReservationSweeper(ctx context.Context, expiryUsecase *usecase.ExpiryUsecase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := expiryUsecase.SweepReservations(ctx)
+			if err != nil {
+				log.Printf("Reservation sweep failed: %v", err)
+				continue
+			}
+			if result.ExpiredHolds > 0 || result.LapsedOffers > 0 {
+				log.Printf("Reservation sweep expired %d holds and lapsed %d waitlist offers", result.ExpiredHolds, result.LapsedOffers)
+			}
+		}
+	}
+}
+
+// rateLimitSweepInterval is how often finished rate limit windows and
+// forgotten lockouts are deleted
+const rateLimitSweepInterval = 10 * time.Minute
+
+// runRateLimitSweeper deletes stale rate limit state until ctx is cancelled
+func runRateLimitSweeper(ctx context.Context, rateLimitUsecase *usecase.RateLimitUsecase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := rateLimitUsecase.Sweep(ctx); err != nil {
+				log.Printf("Rate limit sweep failed: %v", err)
+			}
+		}
+	}
+}
+
 func defaultErrorHandler(c *fiber.Ctx, err error) error {
 	code := fiber.StatusInternalServerError
 	msg := "Internal Server Error"
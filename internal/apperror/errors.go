@@ -18,6 +18,7 @@ const (
 	CodeDatabaseError   ErrorCode = "DATABASE_ERROR"
 	CodeValidationError ErrorCode = "VALIDATION_ERROR"
 	CodeUnprocessable   ErrorCode = "UNPROCESSABLE_ENTITY"
+	CodeTooManyRequests ErrorCode = "TOO_MANY_REQUESTS"
 )
 
 // AppError is the application-specific error type
@@ -97,6 +98,17 @@ func NewValidationError(message string, details map[string]string) *AppError {
 	}
 }
 
+// NewTooManyRequests reports a throttled request; retryAfter is the number of
+// seconds until it may be retried
+func NewTooManyRequests(message string, retryAfter int64) *AppError {
+	return &AppError{
+		Code:       CodeTooManyRequests,
+		Message:    message,
+		StatusCode: 429,
+		Details:    map[string]interface{}{"retry_after": retryAfter},
+	}
+}
+
 func NewInternalServer(message string, err error) *AppError {
 	return &AppError{
 		Code:       CodeInternalServer,
@@ -31,3 +31,11 @@ func IsForbidden(err error) bool {
 	}
 	return false
 }
+
+// IsBadRequest checks if an error is a BadRequest error
+func IsBadRequest(err error) bool {
+	if appErr, ok := err.(*AppError); ok {
+		return appErr.Code == CodeBadRequest
+	}
+	return false
+}
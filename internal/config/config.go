@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -14,17 +16,91 @@ type Config struct {
 	ServerPort  string
 	Environment string
 
+	// Storage backend: "postgres" or "memory"
+	Storage string
+
 	// Database
 	DatabaseURL string
 
 	// JWT
 	JWTSecret string
+	// Lifetime of access tokens and of unused refresh tokens
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// How long a session revocation check is cached per instance
+	SessionCacheTTL time.Duration
 
 	// QR Signing
 	HMACSecret string
+	// How long a rotated-out signing key keeps verifying tickets
+	KeyGracePeriod time.Duration
 
 	// API
 	APITimeout time.Duration
+
+	// Background ticket expiry sweep interval (0 disables the sweeper)
+	ExpirySweepInterval time.Duration
+
+	// How long a released slot stays reserved for the head of the waitlist
+	WaitlistOfferWindow time.Duration
+
+	// How long a customer's slot hold waits for staff confirmation
+	SlotHoldDuration time.Duration
+
+	// How long a staff invite can be accepted
+	StaffInviteTTL time.Duration
+
+	// How long a customer login code is valid, and how often one can be sent
+	LoginCodeTTL            time.Duration
+	LoginCodeResendInterval time.Duration
+	// Page magic login links point to ("" sends codes only)
+	CustomerLoginLinkURL string
+
+	// Notification sender: "log" or "file"; the file sender appends to NotifyFile
+	NotifySender string
+	NotifyFile   string
+
+	// Business email verification and password reset token lifetimes
+	EmailVerificationTTL time.Duration
+	PasswordResetTTL     time.Duration
+	// Pages the verification and reset emails link to ("" sends bare tokens)
+	BusinessVerifyEmailURL   string
+	BusinessResetPasswordURL string
+	// Refuse business logins until the email address is verified
+	RequireVerifiedEmail bool
+
+	// Where rate limit counters live: "postgres" shares them between
+	// instances, "memory" keeps them per instance. Defaults to Storage.
+	RateLimitStorage string
+	// Bad passwords before an account is locked out (0 disables lockouts),
+	// and the first and longest lockout; each bad password doubles it
+	LoginLockoutThreshold int
+	LoginLockoutBaseDelay time.Duration
+	LoginLockoutMaxDelay  time.Duration
+	// Requests allowed per window by each rate limit policy, written like
+	// "20/1m" (a limit of 0 turns the policy off)
+	RateLimitLoginIP      RateLimit
+	RateLimitLoginAccount RateLimit
+	RateLimitPublicIP     RateLimit
+	RateLimitAPIAccount   RateLimit
+	RateLimitScanAccount  RateLimit
+	RateLimitScanBusiness RateLimit
+	// Header a load balancer puts the client IP in ("" uses the connection's
+	// address), honoured only on requests from TrustedProxies (IPs or CIDRs)
+	ProxyHeader    string
+	TrustedProxies []string
+	// Bad QR signatures from one scanner within the window that raise an
+	// alert (0 disables the rule), and how soon a valid scan of the same
+	// ticket on another scanner is flagged as a duplicate (0 disables it)
+	ScanAlertBadSignatures      int
+	ScanAlertBadSignatureWindow time.Duration
+	ScanAlertDuplicateWindow    time.Duration
+}
+
+// RateLimit allows Limit requests per Window
+type RateLimit struct {
+	Limit  int
+	Window time.Duration
 }
 
 // Load reads configuration from environment variables
@@ -45,14 +121,188 @@ func Load() (*Config, error) {
 	cfg := &Config{
 		ServerPort:  getEnv("PORT", "8080"),
 		Environment: getEnv("ENVIRONMENT", "development"),
+		Storage:     getEnv("STORAGE", "postgres"),
 		DatabaseURL: getEnv("DATABASE_URL", ""),
 		JWTSecret:   getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
 		HMACSecret:  getEnv("HMAC_SECRET", "your-hmac-secret-change-in-production"),
 		APITimeout:  30 * time.Second,
+
+		CustomerLoginLinkURL: getEnv("CUSTOMER_LOGIN_LINK_URL", ""),
+		NotifySender:         getEnv("NOTIFY_SENDER", "log"),
+		NotifyFile:           getEnv("NOTIFY_FILE", "notifications.jsonl"),
+
+		BusinessVerifyEmailURL:   getEnv("BUSINESS_VERIFY_EMAIL_URL", ""),
+		BusinessResetPasswordURL: getEnv("BUSINESS_RESET_PASSWORD_URL", ""),
+
+		ProxyHeader:    getEnv("PROXY_HEADER", ""),
+		TrustedProxies: getListEnv("TRUSTED_PROXIES"),
+	}
+	cfg.RateLimitStorage = getEnv("RATE_LIMIT_STORAGE", cfg.Storage)
+
+	accessTokenTTL, err := getDurationEnv("ACCESS_TOKEN_TTL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	cfg.AccessTokenTTL = accessTokenTTL
+
+	refreshTokenTTL, err := getDurationEnv("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	cfg.RefreshTokenTTL = refreshTokenTTL
+
+	sessionCacheTTL, err := getDurationEnv("SESSION_CACHE_TTL", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	cfg.SessionCacheTTL = sessionCacheTTL
+
+	keyGracePeriod, err := getDurationEnv("KEY_GRACE_PERIOD", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	cfg.KeyGracePeriod = keyGracePeriod
+
+	expirySweepInterval, err := getDurationEnv("EXPIRY_SWEEP_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ExpirySweepInterval = expirySweepInterval
+
+	waitlistOfferWindow, err := getDurationEnv("WAITLIST_OFFER_WINDOW", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	cfg.WaitlistOfferWindow = waitlistOfferWindow
+
+	slotHoldDuration, err := getDurationEnv("SLOT_HOLD_DURATION", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	cfg.SlotHoldDuration = slotHoldDuration
+
+	staffInviteTTL, err := getDurationEnv("STAFF_INVITE_TTL", 72*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	cfg.StaffInviteTTL = staffInviteTTL
+
+	loginCodeTTL, err := getDurationEnv("LOGIN_CODE_TTL", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	cfg.LoginCodeTTL = loginCodeTTL
+
+	loginCodeResendInterval, err := getDurationEnv("LOGIN_CODE_RESEND_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	cfg.LoginCodeResendInterval = loginCodeResendInterval
+
+	emailVerificationTTL, err := getDurationEnv("EMAIL_VERIFICATION_TTL", 48*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	cfg.EmailVerificationTTL = emailVerificationTTL
+
+	passwordResetTTL, err := getDurationEnv("PASSWORD_RESET_TTL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	cfg.PasswordResetTTL = passwordResetTTL
+
+	requireVerifiedEmail, err := getBoolEnv("REQUIRE_VERIFIED_EMAIL", false)
+	if err != nil {
+		return nil, err
+	}
+	cfg.RequireVerifiedEmail = requireVerifiedEmail
+
+	loginLockoutThreshold, err := getIntEnv("LOGIN_LOCKOUT_THRESHOLD", 5)
+	if err != nil {
+		return nil, err
+	}
+	cfg.LoginLockoutThreshold = loginLockoutThreshold
+
+	loginLockoutBaseDelay, err := getDurationEnv("LOGIN_LOCKOUT_BASE_DELAY", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	cfg.LoginLockoutBaseDelay = loginLockoutBaseDelay
+
+	loginLockoutMaxDelay, err := getDurationEnv("LOGIN_LOCKOUT_MAX_DELAY", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	cfg.LoginLockoutMaxDelay = loginLockoutMaxDelay
+
+	rateLimits := []struct {
+		key      string
+		target   *RateLimit
+		fallback RateLimit
+	}{
+		{"RATE_LIMIT_LOGIN_IP", &cfg.RateLimitLoginIP, RateLimit{20, time.Minute}},
+		{"RATE_LIMIT_LOGIN_ACCOUNT", &cfg.RateLimitLoginAccount, RateLimit{10, 15 * time.Minute}},
+		{"RATE_LIMIT_PUBLIC_IP", &cfg.RateLimitPublicIP, RateLimit{60, time.Minute}},
+		{"RATE_LIMIT_API_ACCOUNT", &cfg.RateLimitAPIAccount, RateLimit{600, time.Minute}},
+		{"RATE_LIMIT_SCAN_ACCOUNT", &cfg.RateLimitScanAccount, RateLimit{120, time.Minute}},
+		{"RATE_LIMIT_SCAN_BUSINESS", &cfg.RateLimitScanBusiness, RateLimit{1200, time.Minute}},
+	}
+	for _, rateLimit := range rateLimits {
+		limit, err := getRateLimitEnv(rateLimit.key, rateLimit.fallback)
+		if err != nil {
+			return nil, err
+		}
+		*rateLimit.target = limit
+	}
+
+	scanAlertBadSignatures, err := getIntEnv("SCAN_ALERT_BAD_SIGNATURES", 5)
+	if err != nil {
+		return nil, err
 	}
+	cfg.ScanAlertBadSignatures = scanAlertBadSignatures
+
+	scanAlertBadSignatureWindow, err := getDurationEnv("SCAN_ALERT_BAD_SIGNATURE_WINDOW", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ScanAlertBadSignatureWindow = scanAlertBadSignatureWindow
+
+	scanAlertDuplicateWindow, err := getDurationEnv("SCAN_ALERT_DUPLICATE_WINDOW", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ScanAlertDuplicateWindow = scanAlertDuplicateWindow
 
 	// Validate required fields
-	if cfg.DatabaseURL == "" {
+	if cfg.Storage != "postgres" && cfg.Storage != "memory" {
+		return nil, fmt.Errorf("STORAGE must be \"postgres\" or \"memory\", got %q", cfg.Storage)
+	}
+
+	if cfg.RateLimitStorage != "postgres" && cfg.RateLimitStorage != "memory" {
+		return nil, fmt.Errorf("RATE_LIMIT_STORAGE must be \"postgres\" or \"memory\", got %q", cfg.RateLimitStorage)
+	}
+
+	if cfg.Storage == "memory" && cfg.RateLimitStorage == "postgres" {
+		return nil, fmt.Errorf("RATE_LIMIT_STORAGE=postgres needs STORAGE=postgres")
+	}
+
+	if cfg.LoginLockoutThreshold < 0 {
+		return nil, fmt.Errorf("LOGIN_LOCKOUT_THRESHOLD must not be negative")
+	}
+
+	if cfg.ProxyHeader != "" && len(cfg.TrustedProxies) == 0 {
+		return nil, fmt.Errorf("PROXY_HEADER needs TRUSTED_PROXIES; list the load balancer addresses (0.0.0.0/0 trusts every client)")
+	}
+
+	if cfg.ScanAlertBadSignatures < 0 {
+		return nil, fmt.Errorf("SCAN_ALERT_BAD_SIGNATURES must not be negative")
+	}
+
+	if cfg.NotifySender != "log" && cfg.NotifySender != "file" {
+		return nil, fmt.Errorf("NOTIFY_SENDER must be \"log\" or \"file\", got %q", cfg.NotifySender)
+	}
+
+	if cfg.Storage == "postgres" && cfg.DatabaseURL == "" {
 		return nil, fmt.Errorf(
 			"DATABASE_URL environment variable is required\n" +
 				"Expected format: postgres://[user]:[password]@[host]:[port]/[database]?sslmode=[disable|require]\n" +
@@ -62,6 +312,9 @@ func Load() (*Config, error) {
 	}
 
 	if cfg.Environment == "production" {
+		if cfg.Storage == "memory" {
+			return nil, fmt.Errorf("STORAGE=memory is not allowed in production")
+		}
 		if cfg.JWTSecret == "your-secret-key-change-in-production" {
 			return nil, fmt.Errorf("JWT_SECRET must be set in production")
 		}
@@ -80,3 +333,85 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getDurationEnv parses a duration environment variable (e.g. "12h") or returns a default value
+func getDurationEnv(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a duration like \"24h\": %w", key, err)
+	}
+
+	return d, nil
+}
+
+// getBoolEnv parses a boolean environment variable (e.g. "true") or returns a default value
+func getBoolEnv(key string, defaultValue bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false: %w", key, err)
+	}
+
+	return b, nil
+}
+
+// getIntEnv parses an integer environment variable or returns a default value
+func getIntEnv(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a whole number: %w", key, err)
+	}
+
+	return n, nil
+}
+
+// getListEnv splits a comma-separated environment variable, dropping blanks
+func getListEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getRateLimitEnv parses a rate limit environment variable like "20/1m"
+// (20 requests a minute) or returns a default value
+func getRateLimitEnv(key string, defaultValue RateLimit) (RateLimit, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	limit, window, ok := strings.Cut(value, "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("%s must be requests per window like \"20/1m\"", key)
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(limit))
+	if err != nil || n < 0 {
+		return RateLimit{}, fmt.Errorf("%s must start with a whole number of requests", key)
+	}
+
+	d, err := time.ParseDuration(strings.TrimSpace(window))
+	if err != nil || d < time.Second {
+		return RateLimit{}, fmt.Errorf("%s must end with a window of at least a second like \"1m\"", key)
+	}
+
+	return RateLimit{Limit: n, Window: d}, nil
+}
@@ -9,12 +9,86 @@ import (
 const (
 	SlotStatusFree     = "free"
 	SlotStatusOccupied = "occupied"
+	SlotStatusReserved = "reserved" // Held for the waitlist entry it was offered to
+	SlotStatusHeld     = "held"     // Held for a customer until staff confirm or the hold expires
+)
+
+// Slot Hold Status Constants
+const (
+	HoldStatusActive    = "active"
+	HoldStatusConfirmed = "confirmed" // Turned into a ticket
+	HoldStatusCancelled = "cancelled"
+	HoldStatusExpired   = "expired"
 )
 
 // Ticket Status Constants
 const (
 	TicketStatusActive   = "active"
 	TicketStatusReleased = "released"
+	TicketStatusExpired  = "expired"
+)
+
+// Ticket Expiry Reason Constants
+const (
+	ExpiryReasonTTL         = "ttl"          // Older than the service's ticket TTL
+	ExpiryReasonClosingTime = "closing_time" // Service closing time has passed
+)
+
+// Waitlist Entry Status Constants
+const (
+	WaitlistStatusWaiting   = "waiting"   // Queued for a slot
+	WaitlistStatusOffered   = "offered"   // A released slot is reserved for the entry
+	WaitlistStatusFulfilled = "fulfilled" // Checked in on the reserved slot
+	WaitlistStatusCancelled = "cancelled" // Left the queue
+	WaitlistStatusLapsed    = "lapsed"    // Did not check in before the offer expired
+)
+
+// Business Key Status Constants
+const (
+	KeyStatusActive  = "active"
+	KeyStatusRetired = "retired"
+)
+
+// Business Key Algorithm Constants
+const (
+	KeyAlgorithmHMAC    = "hmac-sha256" // Signs v1 QR payloads
+	KeyAlgorithmEd25519 = "ed25519"     // Signs v2 QR payloads
+)
+
+// Sync Event Type Constants
+const (
+	SyncEventTypeScan    = "scan"
+	SyncEventTypeRelease = "release"
+)
+
+// Sync Event Result Constants
+const (
+	SyncResultApplied   = "applied"
+	SyncResultConflict  = "conflict"
+	SyncResultRejected  = "rejected"
+	SyncResultDuplicate = "duplicate"
+)
+
+// Staff Role Constants
+const (
+	StaffRoleOwner     = "owner"
+	StaffRoleManager   = "manager"
+	StaffRoleAttendant = "attendant"
+)
+
+// Staff Status Constants
+const (
+	StaffStatusInvited  = "invited"
+	StaffStatusActive   = "active"
+	StaffStatusDisabled = "disabled"
+)
+
+// Device Action Constants, the operations a scanner device token may perform
+const (
+	DeviceActionCheckIn = "checkin"
+	DeviceActionScan    = "scan"
+	DeviceActionRelease = "release"
+	DeviceActionSync    = "sync"
 )
 
 // NowTimestamp returns current time as Unix timestamp
@@ -24,61 +98,632 @@ func NowTimestamp() int64 {
 
 // Business represents a business entity (e.g., a nightclub, event venue)
 type Business struct {
+	ID              string
+	Name            string
+	Email           string
+	Password        string // bcrypt hash
+	Role            string // "business"
+	HMACKey         string // Original QR signing secret, kept in the keyring under the business ID
+	EmailVerifiedAt int64  // Unix timestamp the business proved it owns Email (0 = not verified)
+	CreatedAt       int64
+	UpdatedAt       int64
+}
+
+// Staff is a person working for a business, e.g. a bartender or door
+// attendant, with their own login. Staff are invited by email and set their
+// own password when they accept the invite.
+type Staff struct {
+	ID              string
+	BusinessID      string
+	Email           string // Lowercased
+	Name            string
+	Role            string // "owner", "manager" or "attendant"
+	Status          string // "invited", "active" or "disabled"
+	Password        string // bcrypt hash, empty until the invite is accepted
+	InviteTokenHash string // SHA-256 of the pending invite token (nullable)
+	InviteExpiresAt int64  // Unix timestamp when the pending invite lapses (nullable)
+	CreatedAt       int64
+	UpdatedAt       int64
+}
+
+// AuthSession is one login: the family of refresh tokens rotated from the
+// first one issued at login. Access tokens name their session, so revoking
+// it logs the holder out everywhere the family was used.
+type AuthSession struct {
+	ID         string
+	Subject    string // Business, customer or staff ID that logged in
+	UserID     string // Claims restored on refresh
+	Email      string
+	Role       string
+	BusinessID string // (nullable)
+	StaffID    string // (nullable)
+	StaffRole  string // (nullable)
+	RevokedAt  int64  // Unix timestamp when the session was revoked (nullable)
+	CreatedAt  int64
+	UpdatedAt  int64
+}
+
+// IsRevoked reports whether the session was revoked
+func (s *AuthSession) IsRevoked() bool {
+	return s.RevokedAt != 0
+}
+
+// RefreshToken is one member of a session's token family. Each refresh
+// rotates it out for a new one.
+type RefreshToken struct {
 	ID        string
-	Name      string
-	Email     string
-	Password  string // bcrypt hash
-	Role      string // "business"
-	HMACKey   string // Secret key for QR signing
+	SessionID string
+	TokenHash string // SHA-256 of the refresh token
+	ExpiresAt int64
+	RotatedAt int64 // Unix timestamp when it was exchanged (nullable)
 	CreatedAt int64
-	UpdatedAt int64
+}
+
+// Device is a scanner registered under a business, e.g. a door tablet. It
+// authenticates with a long-lived token limited to some services and
+// actions.
+type Device struct {
+	ID         string
+	BusinessID string
+	Name       string
+	TokenHash  string   // SHA-256 of the device token
+	ServiceIDs []string // Services the device may act on; empty = all services
+	Actions    []string // Device action constants
+	LastSeenAt int64    // Unix timestamp of the last authenticated request (nullable)
+	RevokedAt  int64    // Unix timestamp when the token was revoked (nullable)
+	CreatedAt  int64
+	UpdatedAt  int64
+}
+
+// IsRevoked reports whether the device's token was revoked
+func (d *Device) IsRevoked() bool {
+	return d.RevokedAt != 0
+}
+
+// BusinessKey is one entry of a business's QR signing keyring. Its ID is
+// the kid carried in QR payloads.
+type BusinessKey struct {
+	ID         string
+	BusinessID string
+	Algorithm  string // "hmac-sha256" or "ed25519"
+	Secret     string // HMAC secret or hex encoded Ed25519 private key seed
+	PublicKey  string // base64url encoded Ed25519 public key (empty for HMAC keys)
+	Status     string // "active" or "retired"
+	CreatedAt  int64
+	RetiredAt  int64 // Unix timestamp when the key was rotated out (nullable)
+	ExpiresAt  int64 // End of the grace period for a retired key (nullable)
+}
+
+// CanVerify reports whether tickets signed with the key are still accepted at now
+func (k *BusinessKey) CanVerify(now int64) bool {
+	return k.Status == KeyStatusActive || now < k.ExpiresAt
 }
 
 // Customer represents a customer entity
 type Customer struct {
+	ID              string
+	Email           string // Empty for customers who sign in by phone only
+	Phone           string
+	PhoneVerifiedAt int64 // Unix timestamp when a login code proved the phone (nullable)
+	CreatedAt       int64
+}
+
+// LoginCode is a one-time code, with a matching magic link, that proves a
+// customer owns an email address or phone number
+type LoginCode struct {
+	ID          string
+	Channel     string // "email" or "sms"
+	Destination string // Email address or phone number the code was sent to
+	Phone       string // Phone to store on a new customer signing in by email (nullable)
+	CodeHash    string // SHA-256 of the login ID and code
+	LinkHash    string // SHA-256 of the magic link token
+	Attempts    int    // Wrong codes entered so far
+	ExpiresAt   int64
+	ConsumedAt  int64 // Unix timestamp when used or superseded (nullable)
+	CreatedAt   int64
+}
+
+// Business token purposes
+const (
+	BusinessTokenVerifyEmail   = "verify_email"
+	BusinessTokenResetPassword = "reset_password"
+)
+
+// BusinessToken is a single-use token mailed to a business, proving it owns
+// its email address or letting it choose a new password
+type BusinessToken struct {
+	ID         string
+	BusinessID string
+	Purpose    string // verify_email or reset_password
+	TokenHash  string // SHA-256 of the token; the token itself is only mailed
+	ExpiresAt  int64
+	UsedAt     int64 // Unix timestamp when used or superseded (nullable)
+	CreatedAt  int64
+}
+
+// TOTPFactor is an authenticator app enrolled as a second login factor of a
+// business account or staff member
+type TOTPFactor struct {
+	SubjectID    string // Business or staff ID
+	Secret       string // Base32 shared secret
+	ConfirmedAt  int64  // Unix timestamp enrollment was confirmed (0 = pending, 2FA off)
+	LastUsedStep int64  // Last accepted TOTP time step, so each code works once
+	CreatedAt    int64
+	UpdatedAt    int64
+}
+
+// IsEnabled reports whether logins must pass the second factor
+func (f *TOTPFactor) IsEnabled() bool {
+	return f.ConfirmedAt > 0
+}
+
+// RecoveryCode is a one-time code that stands in for a TOTP code when the
+// authenticator app is lost
+type RecoveryCode struct {
 	ID        string
-	Email     string
-	Phone     string
+	SubjectID string
+	CodeHash  string // SHA-256 of the code
+	UsedAt    int64  // Unix timestamp when used (nullable)
 	CreatedAt int64
 }
 
+// MFAChallenge is a login that passed the password check and waits for a
+// TOTP or recovery code. It carries the claims the session will open with.
+type MFAChallenge struct {
+	ID         string
+	Subject    string // Business or staff ID that logged in
+	UserID     string
+	Email      string
+	Role       string
+	BusinessID string
+	StaffID    string
+	StaffRole  string
+	TokenHash  string // SHA-256 of the challenge token
+	Attempts   int    // Wrong codes entered so far
+	ExpiresAt  int64
+	UsedAt     int64 // Unix timestamp when completed (nullable)
+	CreatedAt  int64
+}
+
+// RateLimitCounter counts the requests made under a rate limit key in the
+// current fixed window
+type RateLimitCounter struct {
+	Key     string // Policy name and the IP, account or business it limits
+	Count   int
+	ResetAt int64 // Unix timestamp when the window ends and the count starts over
+}
+
+// LoginLockout tracks the bad passwords entered for an account. After too
+// many, logins are refused until LockedUntil.
+type LoginLockout struct {
+	Key           string // Login kind and normalized email
+	Failures      int    // Bad passwords since the last successful login
+	LockedUntil   int64  // Unix timestamp (nullable)
+	LastFailureAt int64
+}
+
 // Service represents a ticketing service (e.g., VIP table reservation, door entry)
 type Service struct {
 	ID         string
 	BusinessID string
 	Name       string
 	TotalSlots int
+	TicketTTL  int64 // Seconds after issue when active tickets expire (0 = never)
+	ClosesAt   int64 // Unix timestamp after which active tickets expire (0 = never)
+	ArchivedAt int64 // Unix timestamp the service was archived (0 = not archived)
 	CreatedAt  int64
 	UpdatedAt  int64
 }
 
+// IsArchived reports whether the service is closed to new check-ins
+func (s *Service) IsArchived() bool {
+	return s.ArchivedAt > 0
+}
+
+// IsClosed reports whether the service's closing time has passed by now
+func (s *Service) IsClosed(now int64) bool {
+	return s.ClosesAt > 0 && s.ClosesAt <= now
+}
+
+// ExpiryReason returns why a ticket issued at issuedAt has expired by now,
+// or "" if it has not
+func (s *Service) ExpiryReason(issuedAt, now int64) string {
+	if s.IsClosed(now) {
+		return ExpiryReasonClosingTime
+	}
+	if s.TicketTTL > 0 && issuedAt+s.TicketTTL <= now {
+		return ExpiryReasonTTL
+	}
+	return ""
+}
+
 // Slot represents a single slot/ticket in a service
 type Slot struct {
 	ID         string
 	ServiceID  string
+	ZoneID     string // "" when the service has no zones
 	SlotNumber int
 	Status     string // "free" or "occupied"
 	CreatedAt  int64
 	UpdatedAt  int64
 }
 
-// Ticket represents an issued ticket
-type Ticket struct {
+// Zone is an area of a service with its own capacity, e.g. a coat rack or a
+// VIP closet. Slot numbers stay unique across the whole service.
+type Zone struct {
+	ID        string
+	ServiceID string
+	Name      string
+	Capacity  int
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+// SlotCounts maps a slot status to the number of slots in it
+type SlotCounts map[string]int
+
+// Total returns the number of slots across all statuses
+func (c SlotCounts) Total() int {
+	total := 0
+	for _, n := range c {
+		total += n
+	}
+	return total
+}
+
+// ZoneSlotCount is the occupancy of one zone
+type ZoneSlotCount struct {
+	ZoneID string
+	Counts SlotCounts
+}
+
+// WaitlistEntry is a place in a service's queue. Entries joined by a
+// customer carry their CustomerID; entries added by staff for walk-ins carry
+// a name and phone instead.
+type WaitlistEntry struct {
+	ID             string
+	ServiceID      string
+	ZoneID         string // "" = any zone
+	CustomerID     string
+	Name           string
+	Phone          string
+	Status         string
+	Seq            int64 // Queue order, assigned on create
+	SlotID         string
+	SlotNumber     int
+	OfferedAt      int64
+	OfferExpiresAt int64
+	TicketID       string
+	CreatedAt      int64
+	UpdatedAt      int64
+}
+
+// IsOpen reports whether the entry is still waiting for or holding a slot
+func (e *WaitlistEntry) IsOpen() bool {
+	return e.Status == WaitlistStatusWaiting || e.Status == WaitlistStatusOffered
+}
+
+// SlotHold keeps a slot for a customer for a short time, e.g. while they
+// walk to the counter, until an attendant confirms it into a ticket
+type SlotHold struct {
 	ID         string
 	ServiceID  string
 	SlotID     string
 	SlotNumber int
-	CustomerID string // nullable for anonymous tickets
-	Status     string // "active" or "released"
-	HMACDigest string // Store the HMAC for audit trail
-	IssuedAt   int64  // Unix timestamp when ticket was created
-	ReleasedAt int64  // Unix timestamp when ticket was released (nullable)
+	ZoneID     string
+	CustomerID string
+	Status     string
+	ExpiresAt  int64
+	TicketID   string
 	CreatedAt  int64
 	UpdatedAt  int64
 }
 
+// Ticket represents an issued ticket
+type Ticket struct {
+	ID           string
+	ServiceID    string
+	SlotID       string
+	SlotNumber   int
+	CustomerID   string // nullable for anonymous tickets
+	Status       string // "active", "released" or "expired"
+	HMACDigest   string // Store the HMAC for audit trail
+	IssuedAt     int64  // Unix timestamp when ticket was created
+	ReleasedAt   int64  // Unix timestamp when ticket was released (nullable)
+	ExpiredAt    int64  // Unix timestamp when ticket was expired by the sweeper (nullable)
+	ExpiryReason string // "ttl" or "closing_time" (nullable)
+	QRGeneration int    // Times the QR code was replaced by a reissue or transfer
+	IssuedBy     string // Staff member who checked the ticket in (nullable)
+	ScannedBy    string // Staff member who last scanned the ticket (nullable)
+	ScannedAt    int64  // Unix timestamp of the last scan (nullable)
+	ReleasedBy   string // Staff member who released the ticket (nullable)
+	CreatedAt    int64
+	UpdatedAt    int64
+}
+
+// Identity Check Methods, recorded when a lost ticket is reissued
+const (
+	IdentityCheckIDDocument      = "id_document"      // Photo ID matching the customer
+	IdentityCheckAccount         = "account"          // Customer showed their signed-in account
+	IdentityCheckItemDescription = "item_description" // Customer described the stored items
+	IdentityCheckOther           = "other"            // Described in the note
+)
+
+// TicketReissue records a lost ticket being reissued with a new QR code.
+// The previous signature digest is revoked for good.
+type TicketReissue struct {
+	ID             string
+	TicketID       string
+	RevokedDigest  string
+	IdentityMethod string
+	IdentityNote   string
+	ReissuedBy     string // Staff member that performed the check, or the business without staff
+	CreatedAt      int64
+}
+
+// Ticket Transfer Status Constants
+const (
+	TransferStatusPending   = "pending"
+	TransferStatusAccepted  = "accepted"
+	TransferStatusDeclined  = "declined"  // By the recipient
+	TransferStatusCancelled = "cancelled" // By the sender
+)
+
+// TicketTransfer hands a customer's ticket to someone else, identified by
+// email or phone. Accepting it moves the ticket to the recipient and revokes
+// the sender's QR code.
+type TicketTransfer struct {
+	ID             string
+	TicketID       string
+	FromCustomerID string
+	ToEmail        string
+	ToPhone        string
+	ToCustomerID   string // Set when accepted
+	Status         string
+	RevokedDigest  string // Set when accepted
+	CreatedAt      int64
+	UpdatedAt      int64
+}
+
+// TicketSearch filters a business's tickets when looking up a lost ticket.
+// Zero values match everything.
+type TicketSearch struct {
+	BusinessID    string
+	ServiceID     string
+	CustomerEmail string
+	CustomerPhone string
+	SlotNumber    int
+	IssuedFrom    int64
+	IssuedTo      int64
+	Status        string
+	Limit         int
+}
+
+// ItemType is a kind of item a service accepts, e.g. "coat" or "bag".
+// Items of a type with a zone must be stored on a slot in that zone.
+type ItemType struct {
+	ID        string
+	ServiceID string
+	Name      string
+	ZoneID    string // "" = any zone
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+// TicketItem is one item handed in under a ticket
+type TicketItem struct {
+	ID          string
+	TicketID    string
+	Type        string
+	Description string
+	Count       int   // 0 = not counted
+	Seq         int64 // Insertion order, set by CreateBatch
+	CreatedAt   int64
+}
+
+// Ticket event types
+const (
+	TicketEventIssued   = "issued"
+	TicketEventScanned  = "scanned"
+	TicketEventReleased = "released"
+	TicketEventExpired  = "expired"
+	TicketEventReissued = "reissued" // New QR code after a lost ticket or a transfer
+	TicketEventVoided   = "voided"   // Cancelled with its service
+)
+
+// Ticket event actor types
+const (
+	ActorTypeBusiness = "business"
+	ActorTypeStaff    = "staff"
+	ActorTypeDevice   = "device"
+	ActorTypeCustomer = "customer"
+	ActorTypeSystem   = "system" // Background jobs such as the expiry sweep
+)
+
+// TicketEvent is an entry in a ticket's append-only history. It is written
+// in the same transaction as the change it records and outlives the ticket.
+type TicketEvent struct {
+	ID         string
+	Seq        int64 // Write order, assigned on create
+	TicketID   string
+	BusinessID string
+	ServiceID  string
+	Type       string
+	ActorType  string
+	ActorID    string // "" for the system
+	Metadata   map[string]interface{}
+	OccurredAt int64
+}
+
+// TicketEventFilter selects a business's ticket events. Zero values match
+// everything.
+type TicketEventFilter struct {
+	BusinessID string
+	ServiceID  string
+	Type       string
+	From       int64 // Occurred at or after, Unix seconds
+	To         int64 // Occurred at or before, Unix seconds
+	AfterSeq   int64 // Resume after this event
+	Limit      int
+}
+
+// Scan attempt outcomes
+const (
+	ScanOutcomeValid           = "valid"
+	ScanOutcomeAlreadyReleased = "already_released"
+	ScanOutcomeExpired         = "expired"
+	ScanOutcomeBadSignature    = "bad_signature"  // Forged, tampered or signed with an unknown key
+	ScanOutcomeWrongBusiness   = "wrong_business" // Signed for another business
+	ScanOutcomeWrongService    = "wrong_service"  // Outside the scanning device's services
+	ScanOutcomeUnknownTicket   = "unknown_ticket"
+	ScanOutcomeRevoked         = "revoked"   // Replaced by a reissue or transfer
+	ScanOutcomeMalformed       = "malformed" // Not a QR payload at all
+)
+
+// ScanAttempt records one QR code presented to a scanner, whatever came of
+// it. Attempts are never updated.
+type ScanAttempt struct {
+	ID          string
+	Seq         int64 // Write order, assigned on create
+	BusinessID  string
+	ServiceID   string // "" when the code could not be trusted
+	TicketID    string // "" when no ticket was matched
+	Outcome     string
+	QRDigest    string // "" for malformed payloads
+	DeviceID    string // "" unless a scanner device scanned
+	StaffID     string // "" for the business account
+	AttemptedAt int64
+}
+
+// ScanAttemptFilter selects a business's scan attempts. Zero values match
+// everything.
+type ScanAttemptFilter struct {
+	BusinessID string
+	Outcome    string
+	TicketID   string
+	DeviceID   string
+	StaffID    string
+	From       int64 // Attempted at or after, Unix seconds
+	To         int64 // Attempted at or before, Unix seconds
+	AfterSeq   int64 // Resume after this attempt
+	Limit      int
+}
+
+// Scan alert rules
+const (
+	ScanAlertReleasedRescanned = "released_ticket_rescanned"
+	ScanAlertRevokedCode       = "revoked_code_scanned"
+	ScanAlertDuplicateScan     = "duplicate_scan"      // One ticket valid on two scanners at once
+	ScanAlertBadSignatureBurst = "bad_signature_burst" // Many bad signatures from one scanner
+)
+
+// Scan alert statuses
+const (
+	ScanAlertStatusOpen         = "open"
+	ScanAlertStatusAcknowledged = "acknowledged"
+)
+
+// ScanAlert flags a suspicious scan attempt for the business to review
+type ScanAlert struct {
+	ID             string
+	BusinessID     string
+	Rule           string
+	AttemptID      string
+	TicketID       string // "" when no ticket was matched
+	DeviceID       string
+	StaffID        string
+	Details        map[string]interface{}
+	CreatedAt      int64
+	AcknowledgedAt int64  // nullable
+	AcknowledgedBy string // Business or staff member (nullable)
+}
+
+// Status reports whether the alert still needs review
+func (a *ScanAlert) Status() string {
+	if a.AcknowledgedAt != 0 {
+		return ScanAlertStatusAcknowledged
+	}
+	return ScanAlertStatusOpen
+}
+
+// ScanAlertFilter selects a business's scan alerts. Zero values match
+// everything.
+type ScanAlertFilter struct {
+	BusinessID string
+	Rule       string
+	Status     string // "open" or "acknowledged"
+	From       int64  // Created at or after, Unix seconds
+	To         int64  // Created at or before, Unix seconds
+	Limit      int
+}
+
+// SyncEvent is a scan or release recorded offline by a scanner device and
+// replayed by the server. Its ID is generated on the device so uploads are idempotent.
+type SyncEvent struct {
+	ID         string
+	BusinessID string
+	DeviceID   string
+	Type       string // "scan" or "release"
+	TicketID   string // may be empty or unknown for rejected events
+	OccurredAt int64  // Unix timestamp recorded by the device
+	ReceivedAt int64  // Unix timestamp when the server replayed the event
+	Result     string // "applied", "conflict" or "rejected"
+	Message    string
+}
+
 // Repository Interfaces
 
+// TxManager runs a unit of work atomically. Repository calls made with the
+// context passed to fn join the same transaction.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// StaffRepository defines staff persistence operations
+type StaffRepository interface {
+	Create(ctx context.Context, staff *Staff) error
+	FindByID(ctx context.Context, id string) (*Staff, error)
+	FindByInviteToken(ctx context.Context, tokenHash string) (*Staff, error)
+	FindByEmail(ctx context.Context, businessID, email string) (*Staff, error)
+	ListByEmail(ctx context.Context, email string) ([]Staff, error)
+	ListByBusinessID(ctx context.Context, businessID string) ([]Staff, error)
+	Update(ctx context.Context, staff *Staff) error
+	// AcceptInvite activates an invited staff member with the name and
+	// password given, failing with a conflict unless the invite with
+	// tokenHash is still open
+	AcceptInvite(ctx context.Context, staff *Staff, tokenHash string) error
+}
+
+// AuthSessionRepository defines login session persistence operations
+type AuthSessionRepository interface {
+	Create(ctx context.Context, session *AuthSession) error
+	FindByID(ctx context.Context, id string) (*AuthSession, error)
+	// Revoke revokes a session; revoking a revoked session is a no-op
+	Revoke(ctx context.Context, id string, revokedAt int64) error
+	// RevokeBySubject revokes every live session of a subject and returns
+	// their IDs
+	RevokeBySubject(ctx context.Context, subject string, revokedAt int64) ([]string, error)
+}
+
+// RefreshTokenRepository defines refresh token persistence operations
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *RefreshToken) error
+	FindByTokenHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	// MarkRotated records that a token was exchanged, failing with a
+	// conflict if it already was
+	MarkRotated(ctx context.Context, id string, rotatedAt int64) error
+}
+
+// DeviceRepository defines scanner device persistence operations
+type DeviceRepository interface {
+	Create(ctx context.Context, device *Device) error
+	FindByID(ctx context.Context, id string) (*Device, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (*Device, error)
+	ListByBusinessID(ctx context.Context, businessID string) ([]Device, error)
+	Update(ctx context.Context, device *Device) error
+	UpdateLastSeen(ctx context.Context, id string, lastSeenAt int64) error
+}
+
 // BusinessRepository defines business persistence operations
 type BusinessRepository interface {
 	Create(ctx context.Context, business *Business) error
@@ -87,18 +732,117 @@ type BusinessRepository interface {
 	Update(ctx context.Context, business *Business) error
 }
 
+// BusinessKeyRepository defines QR signing keyring persistence operations
+type BusinessKeyRepository interface {
+	Create(ctx context.Context, key *BusinessKey) error
+	FindByID(ctx context.Context, id string) (*BusinessKey, error)
+	FindActiveByBusinessID(ctx context.Context, businessID, algorithm string) (*BusinessKey, error)
+	ListByBusinessID(ctx context.Context, businessID string) ([]BusinessKey, error)
+	Retire(ctx context.Context, id string, retiredAt, expiresAt int64) error
+}
+
 // CustomerRepository defines customer persistence operations
 type CustomerRepository interface {
 	Create(ctx context.Context, customer *Customer) error
 	FindByID(ctx context.Context, id string) (*Customer, error)
 	FindByEmail(ctx context.Context, email string) (*Customer, error)
 	FindOrCreate(ctx context.Context, email, phone string) (*Customer, error)
+	FindByVerifiedPhone(ctx context.Context, phone string) (*Customer, error)
+}
+
+// BusinessTokenRepository defines business token persistence operations
+type BusinessTokenRepository interface {
+	Create(ctx context.Context, token *BusinessToken) error
+	FindByTokenHash(ctx context.Context, tokenHash string) (*BusinessToken, error)
+	// FindLatest returns the newest token of a purpose issued to a business
+	FindLatest(ctx context.Context, businessID, purpose string) (*BusinessToken, error)
+	// Use marks a token used, failing with a conflict if it already was
+	Use(ctx context.Context, id string, usedAt int64) error
+	// RetireByBusiness marks every unused token of a purpose used
+	RetireByBusiness(ctx context.Context, businessID, purpose string, usedAt int64) error
+}
+
+// TOTPFactorRepository defines TOTP factor persistence operations
+type TOTPFactorRepository interface {
+	// Save creates a subject's factor or replaces a pending one
+	Save(ctx context.Context, factor *TOTPFactor) error
+	FindBySubject(ctx context.Context, subjectID string) (*TOTPFactor, error)
+	Confirm(ctx context.Context, subjectID string, confirmedAt int64) error
+	// UseStep records an accepted time step, failing with a conflict if that
+	// step or a later one was already used
+	UseStep(ctx context.Context, subjectID string, step int64) error
+	Delete(ctx context.Context, subjectID string) error
+}
+
+// RecoveryCodeRepository defines recovery code persistence operations
+type RecoveryCodeRepository interface {
+	// Replace swaps a subject's recovery codes for a new set
+	Replace(ctx context.Context, subjectID string, codes []RecoveryCode) error
+	// Use marks an unused code of the subject used, failing with not found
+	// if there is none with that hash
+	Use(ctx context.Context, subjectID, codeHash string, usedAt int64) error
+	CountUnused(ctx context.Context, subjectID string) (int, error)
+}
+
+// MFAChallengeRepository defines login challenge persistence operations
+type MFAChallengeRepository interface {
+	Create(ctx context.Context, challenge *MFAChallenge) error
+	FindByTokenHash(ctx context.Context, tokenHash string) (*MFAChallenge, error)
+	// RecordAttempt counts a code check on an unused challenge and returns
+	// the new count, failing with a conflict once maxAttempts were made
+	RecordAttempt(ctx context.Context, id string, maxAttempts int) (int, error)
+	// Use marks a challenge completed, failing with a conflict if it already was
+	Use(ctx context.Context, id string, usedAt int64) error
+}
+
+// RateLimitRepository defines rate limit counter persistence operations
+type RateLimitRepository interface {
+	// Hit counts a request under key, starting a window of the given seconds
+	// when the key has none running, and returns the updated counter
+	Hit(ctx context.Context, key string, window int64, now int64) (*RateLimitCounter, error)
+	// DeleteExpired removes counters whose window ended before now
+	DeleteExpired(ctx context.Context, now int64) (int64, error)
+}
+
+// LoginLockoutRepository defines login lockout persistence operations
+type LoginLockoutRepository interface {
+	FindByKey(ctx context.Context, key string) (*LoginLockout, error)
+	// RecordFailure counts a bad password, starting over when the last one is
+	// older than resetBefore, and returns the updated lockout
+	RecordFailure(ctx context.Context, key string, now, resetBefore int64) (*LoginLockout, error)
+	Lock(ctx context.Context, key string, until int64) error
+	Delete(ctx context.Context, key string) error
+	// DeleteStale removes lockouts whose last failure is older than before
+	// and that are no longer locked
+	DeleteStale(ctx context.Context, before, now int64) (int64, error)
+}
+
+// LoginCodeRepository defines customer login code persistence operations
+type LoginCodeRepository interface {
+	Create(ctx context.Context, code *LoginCode) error
+	FindByID(ctx context.Context, id string) (*LoginCode, error)
+	FindByLinkHash(ctx context.Context, linkHash string) (*LoginCode, error)
+	// LockDestination holds a lock on a destination until the transaction
+	// ends, so code requests for it take turns
+	LockDestination(ctx context.Context, destination string) error
+	// ListSince lists the codes sent to a destination since a time, newest first
+	ListSince(ctx context.Context, destination string, since int64) ([]LoginCode, error)
+	// RecordAttempt counts a verification attempt on an unused code and
+	// returns the new count, failing with a conflict once maxAttempts were made
+	RecordAttempt(ctx context.Context, id string, maxAttempts int) (int, error)
+	// Consume marks a code used, failing with a conflict if it already was or
+	// more than maxAttempts were made
+	Consume(ctx context.Context, id string, consumedAt int64, maxAttempts int) error
+	// ConsumeByDestination retires every unused code of a destination
+	ConsumeByDestination(ctx context.Context, destination string, consumedAt int64) error
 }
 
 // ServiceRepository defines service persistence operations
 type ServiceRepository interface {
 	Create(ctx context.Context, service *Service) error
 	FindByID(ctx context.Context, id string) (*Service, error)
+	LockByID(ctx context.Context, id string) (*Service, error)
+	ShareLockByID(ctx context.Context, id string) (*Service, error)
 	ListByBusinessID(ctx context.Context, businessID string) ([]Service, error)
 	Update(ctx context.Context, service *Service) error
 	Delete(ctx context.Context, id string) error
@@ -110,9 +854,58 @@ type SlotRepository interface {
 	CreateBatch(ctx context.Context, slots []Slot) error
 	FindByID(ctx context.Context, id string) (*Slot, error)
 	ListByServiceID(ctx context.Context, serviceID string) ([]Slot, error)
-	ClaimNextFreeSlot(ctx context.Context, serviceID string) (*Slot, error)
+	ClaimNextFreeSlot(ctx context.Context, serviceID, zoneID string) (*Slot, error)
+	ClaimSlot(ctx context.Context, serviceID string, slotNumber int) (*Slot, error)
+	DeleteTrailing(ctx context.Context, serviceID string, keep int) error
+	DeleteZoneSlots(ctx context.Context, zoneID string, keep int) error
 	UpdateStatus(ctx context.Context, id string, status string) error
-	CountSlotsByStatus(ctx context.Context, serviceID string) (total, occupied int, err error)
+	CountSlotsByStatus(ctx context.Context, serviceID string) (SlotCounts, error)
+	CountSlotsByZone(ctx context.Context, serviceID string) ([]ZoneSlotCount, error)
+}
+
+// ItemTypeRepository defines item type persistence operations
+type ItemTypeRepository interface {
+	Create(ctx context.Context, itemType *ItemType) error
+	FindByID(ctx context.Context, id string) (*ItemType, error)
+	FindByName(ctx context.Context, serviceID, name string) (*ItemType, error)
+	ListByServiceID(ctx context.Context, serviceID string) ([]ItemType, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// TicketItemRepository defines ticket item persistence operations
+type TicketItemRepository interface {
+	CreateBatch(ctx context.Context, items []TicketItem) error
+	FindByID(ctx context.Context, id string) (*TicketItem, error)
+	ListByTicketID(ctx context.Context, ticketID string) ([]TicketItem, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// WaitlistRepository defines waitlist persistence operations
+type WaitlistRepository interface {
+	Create(ctx context.Context, entry *WaitlistEntry) error
+	FindByID(ctx context.Context, id string) (*WaitlistEntry, error)
+	FindOpenByCustomer(ctx context.Context, serviceID, customerID string) (*WaitlistEntry, error)
+	ListOpenByServiceID(ctx context.Context, serviceID string) ([]WaitlistEntry, error)
+	LockNextWaiting(ctx context.Context, serviceID, zoneID string) (*WaitlistEntry, error)
+	ListExpiredOffers(ctx context.Context, now int64, limit int) ([]WaitlistEntry, error)
+	Update(ctx context.Context, entry *WaitlistEntry, fromStatus string) error
+}
+
+// SlotHoldRepository defines slot hold persistence operations
+type SlotHoldRepository interface {
+	Create(ctx context.Context, hold *SlotHold) error
+	FindByID(ctx context.Context, id string) (*SlotHold, error)
+	ListActiveByServiceID(ctx context.Context, serviceID string) ([]SlotHold, error)
+	ListExpired(ctx context.Context, now int64, limit int) ([]SlotHold, error)
+	Update(ctx context.Context, hold *SlotHold, fromStatus string) error
+}
+
+// ZoneRepository defines zone persistence operations
+type ZoneRepository interface {
+	Create(ctx context.Context, zone *Zone) error
+	FindByID(ctx context.Context, id string) (*Zone, error)
+	ListByServiceID(ctx context.Context, serviceID string) ([]Zone, error)
+	Update(ctx context.Context, zone *Zone) error
 }
 
 // TicketRepository defines ticket persistence operations
@@ -120,7 +913,78 @@ type TicketRepository interface {
 	Create(ctx context.Context, ticket *Ticket) error
 	FindByID(ctx context.Context, id string) (*Ticket, error)
 	FindByHMAC(ctx context.Context, hmacDigest string) (*Ticket, error)
-	ListByCustomerID(ctx context.Context, customerID string) ([]Ticket, error)
+	ListByCustomerID(ctx context.Context, customerID, businessID string) ([]Ticket, error)
 	ListActiveByServiceID(ctx context.Context, serviceID string) ([]Ticket, error)
+	CountByServiceID(ctx context.Context, serviceID string) (int, error)
+	ListExpirable(ctx context.Context, businessID string, now int64, limit int) ([]Ticket, error)
+	Search(ctx context.Context, search TicketSearch) ([]Ticket, error)
 	UpdateStatus(ctx context.Context, id string, status string) error
+	MarkScanned(ctx context.Context, id, staffID string) error
+	MarkReleased(ctx context.Context, id, staffID string) error
+	UpdateDigest(ctx context.Context, id, fromDigest, toDigest string, generation int) error
+	UpdateCustomer(ctx context.Context, id, fromCustomerID, toCustomerID string) error
+	Expire(ctx context.Context, id, reason string) error
+}
+
+// TicketTransferRepository defines ticket transfer persistence operations
+type TicketTransferRepository interface {
+	Create(ctx context.Context, transfer *TicketTransfer) error
+	FindByID(ctx context.Context, id string) (*TicketTransfer, error)
+	FindByRevokedDigest(ctx context.Context, digest string) (*TicketTransfer, error)
+	ListByTicketID(ctx context.Context, ticketID string) ([]TicketTransfer, error)
+	ListPendingForRecipient(ctx context.Context, email, phone string) ([]TicketTransfer, error)
+	Update(ctx context.Context, transfer *TicketTransfer, fromStatus string) error
+}
+
+// TicketReissueRepository defines ticket reissue persistence operations
+type TicketReissueRepository interface {
+	Create(ctx context.Context, reissue *TicketReissue) error
+	FindByRevokedDigest(ctx context.Context, digest string) (*TicketReissue, error)
+	ListByTicketID(ctx context.Context, ticketID string) ([]TicketReissue, error)
+}
+
+// TicketEventRepository defines ticket event persistence operations. Events
+// are never updated.
+type TicketEventRepository interface {
+	// Create appends an event and sets its Seq
+	Create(ctx context.Context, event *TicketEvent) error
+	// ListByTicketID lists a business's events of a ticket in the order they
+	// were written
+	ListByTicketID(ctx context.Context, ticketID, businessID string) ([]TicketEvent, error)
+	// List lists events matching filter in the order they were written
+	List(ctx context.Context, filter TicketEventFilter) ([]TicketEvent, error)
+}
+
+// ScanAttemptRepository defines scan attempt persistence operations.
+// Attempts are never updated.
+type ScanAttemptRepository interface {
+	// Create appends an attempt and sets its Seq
+	Create(ctx context.Context, attempt *ScanAttempt) error
+	// List lists attempts matching filter in the order they were written
+	List(ctx context.Context, filter ScanAttemptFilter) ([]ScanAttempt, error)
+	// CountByScanner counts a business's attempts with outcome made since a
+	// time by exactly this device and staff member ("" for none)
+	CountByScanner(ctx context.Context, businessID, deviceID, staffID, outcome string, since int64) (int, error)
+	// FindLatestByOtherScanner finds a ticket's latest attempt with outcome
+	// made since a time by any scanner other than this device and staff member
+	FindLatestByOtherScanner(ctx context.Context, businessID, ticketID, deviceID, staffID, outcome string, since int64) (*ScanAttempt, error)
+}
+
+// ScanAlertRepository defines scan alert persistence operations
+type ScanAlertRepository interface {
+	Create(ctx context.Context, alert *ScanAlert) error
+	FindByID(ctx context.Context, id string) (*ScanAlert, error)
+	// List lists alerts matching filter, newest first
+	List(ctx context.Context, filter ScanAlertFilter) ([]ScanAlert, error)
+	// Acknowledge closes an open alert; it fails with a conflict when the
+	// alert was already acknowledged
+	Acknowledge(ctx context.Context, id, acknowledgedBy string, acknowledgedAt int64) error
+}
+
+// SyncEventRepository defines persistence operations for replayed device events
+type SyncEventRepository interface {
+	Create(ctx context.Context, event *SyncEvent) error
+	// FindByID finds a business's event by its device-generated ID
+	FindByID(ctx context.Context, businessID, id string) (*SyncEvent, error)
+	FindAppliedReleaseByTicketID(ctx context.Context, ticketID string) (*SyncEvent, error)
 }
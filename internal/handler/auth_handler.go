@@ -11,12 +11,13 @@ import (
 
 // AuthHandler handles authentication operations
 type AuthHandler struct {
-	authUsecase *usecase.AuthUsecase
+	authUsecase    *usecase.AuthUsecase
+	accountUsecase *usecase.BusinessAccountUsecase
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(authUsecase *usecase.AuthUsecase) *AuthHandler {
-	return &AuthHandler{authUsecase}
+func NewAuthHandler(authUsecase *usecase.AuthUsecase, accountUsecase *usecase.BusinessAccountUsecase) *AuthHandler {
+	return &AuthHandler{authUsecase, accountUsecase}
 }
 
 // BusinessRegister handles POST /auth/business/register
@@ -34,6 +35,11 @@ func (h *AuthHandler) BusinessRegister(c *fiber.Ctx) error {
 		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
 	}
 
+	// The account exists either way; the business can ask for another link
+	if err := h.accountUsecase.SendVerification(c.Context(), result.UserID); err != nil {
+		log.Printf("BusinessRegister: verification email not sent: %v", err)
+	}
+
 	return c.Status(201).JSON(result)
 }
 
@@ -48,21 +54,22 @@ func (h *AuthHandler) BusinessLogin(c *fiber.Ctx) error {
 	result, err := h.authUsecase.BusinessLogin(c.Context(), req)
 	if err != nil {
 		appErr := apperror.From(err)
+		setRetryAfter(c, appErr)
 		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
 	}
 
 	return c.Status(200).JSON(result)
 }
 
-// CustomerLogin handles POST /auth/customer/login
-func (h *AuthHandler) CustomerLogin(c *fiber.Ctx) error {
-	var req usecase.CustomerLoginRequest
+// Refresh handles POST /auth/refresh - Exchanges a refresh token for new tokens
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	var req usecase.RefreshRequest
 	if err := c.BodyParser(&req); err != nil {
 		appErr := apperror.NewBadRequest("invalid request body")
 		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
 	}
 
-	result, err := h.authUsecase.CustomerLogin(c.Context(), req)
+	result, err := h.authUsecase.Refresh(c.Context(), req)
 	if err != nil {
 		appErr := apperror.From(err)
 		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
@@ -70,3 +77,88 @@ func (h *AuthHandler) CustomerLogin(c *fiber.Ctx) error {
 
 	return c.Status(200).JSON(result)
 }
+
+// Logout handles POST /auth/logout - Revokes the caller's session, or all of them
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	var req usecase.LogoutRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			appErr := apperror.NewBadRequest("invalid request body")
+			return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+		}
+	}
+
+	req.SessionID, _ = c.Locals("session_id").(string)
+
+	if err := h.authUsecase.Logout(c.Context(), req); err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.SendStatus(204)
+}
+
+// VerifyEmail handles POST /auth/business/verify-email - Redeems an email verification token
+func (h *AuthHandler) VerifyEmail(c *fiber.Ctx) error {
+	var req usecase.VerifyEmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	if err := h.accountUsecase.VerifyEmail(c.Context(), req); err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.SendStatus(204)
+}
+
+// ResendVerification handles POST /auth/business/resend-verification
+func (h *AuthHandler) ResendVerification(c *fiber.Ctx) error {
+	var req usecase.BusinessEmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	if err := h.accountUsecase.ResendVerification(c.Context(), req); err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.SendStatus(202)
+}
+
+// ForgotPassword handles POST /auth/business/forgot-password - Emails a password reset link
+func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req usecase.BusinessEmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	if err := h.accountUsecase.RequestPasswordReset(c.Context(), req); err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.SendStatus(202)
+}
+
+// ResetPassword handles POST /auth/business/reset-password - Sets a new
+// password and signs the business out everywhere
+func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
+	var req usecase.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	if err := h.accountUsecase.ResetPassword(c.Context(), req); err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.SendStatus(204)
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CustomerAuthHandler handles passwordless customer logins
+type CustomerAuthHandler struct {
+	customerAuthUsecase *usecase.CustomerAuthUsecase
+}
+
+// NewCustomerAuthHandler creates a new customer auth handler
+func NewCustomerAuthHandler(customerAuthUsecase *usecase.CustomerAuthUsecase) *CustomerAuthHandler {
+	return &CustomerAuthHandler{customerAuthUsecase}
+}
+
+// RequestCode handles POST /auth/customer/login - Sends a login code to the
+// customer's email or phone
+func (h *CustomerAuthHandler) RequestCode(c *fiber.Ctx) error {
+	var req usecase.CustomerLoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	result, err := h.customerAuthUsecase.RequestCode(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		setRetryAfter(c, appErr)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(202).JSON(result)
+}
+
+// VerifyCode handles POST /auth/customer/verify - Exchanges a login code or
+// magic link token for tokens
+func (h *CustomerAuthHandler) VerifyCode(c *fiber.Ctx) error {
+	var req usecase.VerifyLoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	result, err := h.customerAuthUsecase.VerifyCode(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
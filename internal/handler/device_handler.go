@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DeviceHandler handles scanner device registration and revocation
+type DeviceHandler struct {
+	deviceUsecase *usecase.DeviceUsecase
+}
+
+// NewDeviceHandler creates a new device handler
+func NewDeviceHandler(deviceUsecase *usecase.DeviceUsecase) *DeviceHandler {
+	return &DeviceHandler{deviceUsecase}
+}
+
+// RegisterDevice handles POST /devices - Registers a scanner and returns its token once
+func (h *DeviceHandler) RegisterDevice(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+
+	var req usecase.RegisterDeviceRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	req.BusinessID = businessID
+
+	result, err := h.deviceUsecase.RegisterDevice(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(201).JSON(result)
+}
+
+// ListDevices handles GET /devices
+func (h *DeviceHandler) ListDevices(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+
+	result, err := h.deviceUsecase.ListDevices(c.Context(), businessID)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// GetDevice handles GET /devices/:id - Includes when the device was last seen
+func (h *DeviceHandler) GetDevice(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	deviceID := c.Params("id")
+
+	if deviceID == "" {
+		appErr := apperror.NewBadRequest("invalid device ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	result, err := h.deviceUsecase.GetDevice(c.Context(), deviceID, businessID)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// UpdateDevice handles PUT /devices/:id - Renames a device or changes its scope
+func (h *DeviceHandler) UpdateDevice(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	deviceID := c.Params("id")
+
+	if deviceID == "" {
+		appErr := apperror.NewBadRequest("invalid device ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	var req usecase.UpdateDeviceRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	req.DeviceID = deviceID
+	req.BusinessID = businessID
+
+	result, err := h.deviceUsecase.UpdateDevice(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// RevokeDevice handles DELETE /devices/:id - Revokes the device's token
+func (h *DeviceHandler) RevokeDevice(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	deviceID := c.Params("id")
+
+	if deviceID == "" {
+		appErr := apperror.NewBadRequest("invalid device ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	if err := h.deviceUsecase.RevokeDevice(c.Context(), deviceID, businessID); err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.SendStatus(204)
+}
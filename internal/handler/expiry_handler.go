@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ExpiryHandler handles ticket expiry operations
+type ExpiryHandler struct {
+	expiryUsecase *usecase.ExpiryUsecase
+}
+
+// NewExpiryHandler creates a new expiry handler
+func NewExpiryHandler(expiryUsecase *usecase.ExpiryUsecase) *ExpiryHandler {
+	return &ExpiryHandler{expiryUsecase}
+}
+
+// RunNow handles POST /tickets/expiry/run - Expire the business's overdue tickets immediately
+func (h *ExpiryHandler) RunNow(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	staffID, _ := c.Locals("staff_id").(string)
+
+	result, err := h.expiryUsecase.Sweep(c.Context(), businessID, staffID)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
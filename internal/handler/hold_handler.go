@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HoldHandler handles temporary slot holds
+type HoldHandler struct {
+	holdUsecase   *usecase.HoldUsecase
+	ticketUsecase *usecase.TicketUsecase
+}
+
+// NewHoldHandler creates a new hold handler
+func NewHoldHandler(holdUsecase *usecase.HoldUsecase, ticketUsecase *usecase.TicketUsecase) *HoldHandler {
+	return &HoldHandler{holdUsecase, ticketUsecase}
+}
+
+// CreateHold handles POST /holds - Customer holds a slot on the way to the counter
+func (h *HoldHandler) CreateHold(c *fiber.Ctx) error {
+	customerID := c.Locals("user_id").(string)
+
+	var req usecase.CreateHoldRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	req.CustomerID = customerID
+
+	result, err := h.holdUsecase.CreateHold(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(201).JSON(result)
+}
+
+// GetHold handles GET /holds/:id
+func (h *HoldHandler) GetHold(c *fiber.Ctx) error {
+	holdID := c.Params("id")
+
+	if holdID == "" {
+		appErr := apperror.NewBadRequest("invalid slot hold ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	result, err := h.holdUsecase.GetHold(c.Context(), holdID, requestActor(c))
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// ConfirmHold handles POST /holds/:id/confirm - Attendant issues the ticket
+func (h *HoldHandler) ConfirmHold(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	holdID := c.Params("id")
+
+	if holdID == "" {
+		appErr := apperror.NewBadRequest("invalid slot hold ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	result, err := h.ticketUsecase.ConfirmHold(c.Context(), holdID, businessID, requestStaffID(c))
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(201).JSON(result)
+}
+
+// CancelHold handles DELETE /holds/:id
+func (h *HoldHandler) CancelHold(c *fiber.Ctx) error {
+	holdID := c.Params("id")
+
+	if holdID == "" {
+		appErr := apperror.NewBadRequest("invalid slot hold ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	if err := h.holdUsecase.CancelHold(c.Context(), holdID, requestActor(c)); err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.SendStatus(204)
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ItemHandler handles ticket items and the item types of a service
+type ItemHandler struct {
+	itemUsecase *usecase.ItemUsecase
+}
+
+// NewItemHandler creates a new item handler
+func NewItemHandler(itemUsecase *usecase.ItemUsecase) *ItemHandler {
+	return &ItemHandler{itemUsecase}
+}
+
+// ListItemTypes handles GET /services/:id/item-types
+func (h *ItemHandler) ListItemTypes(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	serviceID := c.Params("id")
+
+	if serviceID == "" {
+		appErr := apperror.NewBadRequest("invalid service ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	result, err := h.itemUsecase.ListItemTypes(c.Context(), serviceID, businessID)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// CreateItemType handles POST /services/:id/item-types
+func (h *ItemHandler) CreateItemType(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	serviceID := c.Params("id")
+
+	if serviceID == "" {
+		appErr := apperror.NewBadRequest("invalid service ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	var req usecase.CreateItemTypeRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	req.ServiceID = serviceID
+	req.BusinessID = businessID
+
+	result, err := h.itemUsecase.CreateItemType(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(201).JSON(result)
+}
+
+// DeleteItemType handles DELETE /services/:id/item-types/:typeId
+func (h *ItemHandler) DeleteItemType(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	serviceID := c.Params("id")
+	typeID := c.Params("typeId")
+
+	if serviceID == "" || typeID == "" {
+		appErr := apperror.NewBadRequest("invalid service or item type ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	if err := h.itemUsecase.DeleteItemType(c.Context(), serviceID, typeID, businessID); err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.SendStatus(204)
+}
+
+// AddItem handles POST /tickets/:id/items - Staff add an item to an active ticket
+func (h *ItemHandler) AddItem(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	ticketID := c.Params("id")
+
+	if ticketID == "" {
+		appErr := apperror.NewBadRequest("invalid ticket ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	var req usecase.AddTicketItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	req.TicketID = ticketID
+	req.BusinessID = businessID
+
+	result, err := h.itemUsecase.AddItem(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(201).JSON(result)
+}
+
+// RemoveItem handles DELETE /tickets/:id/items/:itemId
+func (h *ItemHandler) RemoveItem(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	ticketID := c.Params("id")
+	itemID := c.Params("itemId")
+
+	if ticketID == "" || itemID == "" {
+		appErr := apperror.NewBadRequest("invalid ticket or item ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	if err := h.itemUsecase.RemoveItem(c.Context(), ticketID, itemID, businessID); err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.SendStatus(204)
+}
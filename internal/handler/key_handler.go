@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// KeyHandler handles QR signing key operations
+type KeyHandler struct {
+	keyUsecase *usecase.KeyUsecase
+}
+
+// NewKeyHandler creates a new key handler
+func NewKeyHandler(keyUsecase *usecase.KeyUsecase) *KeyHandler {
+	return &KeyHandler{keyUsecase}
+}
+
+// ListKeys handles GET /keys
+func (h *KeyHandler) ListKeys(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+
+	result, err := h.keyUsecase.ListKeys(c.Context(), businessID)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"keys": result,
+	})
+}
+
+// RotateKey handles POST /keys/rotate
+func (h *KeyHandler) RotateKey(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+
+	var req usecase.RotateKeyRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			appErr := apperror.NewBadRequest("invalid request body")
+			return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+		}
+	}
+
+	req.BusinessID = businessID
+
+	result, err := h.keyUsecase.RotateKey(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"keys": result,
+	})
+}
+
+// ListPublicKeys handles GET /businesses/:id/keys - Public Ed25519 keys for offline QR verification
+func (h *KeyHandler) ListPublicKeys(c *fiber.Ctx) error {
+	businessID := c.Params("id")
+	if businessID == "" {
+		appErr := apperror.NewBadRequest("invalid business ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	result, err := h.keyUsecase.ListPublicKeys(c.Context(), businessID)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"keys": result,
+	})
+}
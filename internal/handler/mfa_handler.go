@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MFAHandler handles TOTP two-factor enrollment and second-step logins
+type MFAHandler struct {
+	mfaUsecase *usecase.MFAUsecase
+}
+
+// NewMFAHandler creates a new MFA handler
+func NewMFAHandler(mfaUsecase *usecase.MFAUsecase) *MFAHandler {
+	return &MFAHandler{mfaUsecase}
+}
+
+// Verify handles POST /auth/mfa/verify - Exchanges a login challenge and a
+// TOTP or recovery code for tokens
+func (h *MFAHandler) Verify(c *fiber.Ctx) error {
+	var req usecase.VerifyMFARequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	result, err := h.mfaUsecase.VerifyChallenge(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// Status handles GET /auth/mfa - Reports whether the caller has 2FA on
+func (h *MFAHandler) Status(c *fiber.Ctx) error {
+	result, err := h.mfaUsecase.Status(c.Context(), mfaSubject(c))
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// Setup handles POST /auth/mfa/setup - Generates a TOTP secret for the caller
+func (h *MFAHandler) Setup(c *fiber.Ctx) error {
+	account := c.Locals("email").(string)
+
+	result, err := h.mfaUsecase.Setup(c.Context(), mfaSubject(c), account)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(201).JSON(result)
+}
+
+// Confirm handles POST /auth/mfa/confirm - Turns 2FA on with a first code
+// and returns recovery codes
+func (h *MFAHandler) Confirm(c *fiber.Ctx) error {
+	var req usecase.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	req.SubjectID = mfaSubject(c)
+
+	result, err := h.mfaUsecase.Confirm(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// RegenerateRecoveryCodes handles POST /auth/mfa/recovery-codes - Replaces
+// the caller's recovery codes
+func (h *MFAHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	var req usecase.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	req.SubjectID = mfaSubject(c)
+
+	result, err := h.mfaUsecase.RegenerateRecoveryCodes(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// Disable handles POST /auth/mfa/disable - Turns 2FA off
+func (h *MFAHandler) Disable(c *fiber.Ctx) error {
+	var req usecase.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	req.SubjectID = mfaSubject(c)
+
+	if err := h.mfaUsecase.Disable(c.Context(), req); err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.SendStatus(204)
+}
+
+// mfaSubject returns whose second factor a request manages: the staff
+// member signed in, or else the business account
+func mfaSubject(c *fiber.Ctx) string {
+	if staffID, _ := c.Locals("staff_id").(string); staffID != "" {
+		return staffID
+	}
+	return c.Locals("user_id").(string)
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RecoveryHandler handles lost-ticket lookup and reissue
+type RecoveryHandler struct {
+	recoveryUsecase *usecase.RecoveryUsecase
+}
+
+// NewRecoveryHandler creates a new recovery handler
+func NewRecoveryHandler(recoveryUsecase *usecase.RecoveryUsecase) *RecoveryHandler {
+	return &RecoveryHandler{recoveryUsecase}
+}
+
+// LookupTickets handles GET /tickets/lookup?email=&phone=&slot_number=&service_id=&from=&to=&status=
+func (h *RecoveryHandler) LookupTickets(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+
+	req := usecase.LookupTicketsRequest{
+		Email:      c.Query("email"),
+		Phone:      c.Query("phone"),
+		SlotNumber: c.QueryInt("slot_number", 0),
+		ServiceID:  c.Query("service_id"),
+		From:       int64(c.QueryInt("from", 0)),
+		To:         int64(c.QueryInt("to", 0)),
+		Status:     c.Query("status"),
+		BusinessID: businessID,
+	}
+
+	result, err := h.recoveryUsecase.LookupTickets(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"tickets": result,
+	})
+}
+
+// ReissueTicket handles POST /tickets/:id/reissue - New QR code for a lost ticket
+func (h *RecoveryHandler) ReissueTicket(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	ticketID := c.Params("id")
+
+	if ticketID == "" {
+		appErr := apperror.NewBadRequest("invalid ticket ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	var req usecase.ReissueTicketRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	req.TicketID = ticketID
+	req.BusinessID = businessID
+	req.StaffID = requestStaffID(c)
+
+	result, err := h.recoveryUsecase.ReissueTicket(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// ListReissues handles GET /tickets/:id/reissues
+func (h *RecoveryHandler) ListReissues(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	ticketID := c.Params("id")
+
+	if ticketID == "" {
+		appErr := apperror.NewBadRequest("invalid ticket ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	result, err := h.recoveryUsecase.ListReissues(c.Context(), ticketID, businessID)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
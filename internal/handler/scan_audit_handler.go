@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ScanAuditHandler serves recorded scan attempts and the alerts raised on them
+type ScanAuditHandler struct {
+	scanUsecase *usecase.ScanAuditUsecase
+}
+
+// NewScanAuditHandler creates a new scan audit handler
+func NewScanAuditHandler(scanUsecase *usecase.ScanAuditUsecase) *ScanAuditHandler {
+	return &ScanAuditHandler{scanUsecase}
+}
+
+// ListAttempts handles GET /scans/attempts?outcome=&ticket_id=&device_id=&staff_id=&from=&to=&after=&limit=
+func (h *ScanAuditHandler) ListAttempts(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+
+	req := usecase.ListScanAttemptsRequest{
+		Outcome:    c.Query("outcome"),
+		TicketID:   c.Query("ticket_id"),
+		DeviceID:   c.Query("device_id"),
+		StaffID:    c.Query("staff_id"),
+		From:       int64(c.QueryInt("from", 0)),
+		To:         int64(c.QueryInt("to", 0)),
+		After:      int64(c.QueryInt("after", 0)),
+		Limit:      c.QueryInt("limit", 0),
+		BusinessID: businessID,
+	}
+
+	result, err := h.scanUsecase.ListAttempts(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// ListAlerts handles GET /scans/alerts?status=&rule=&from=&to=&limit=
+func (h *ScanAuditHandler) ListAlerts(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+
+	req := usecase.ListScanAlertsRequest{
+		Rule:       c.Query("rule"),
+		Status:     c.Query("status"),
+		From:       int64(c.QueryInt("from", 0)),
+		To:         int64(c.QueryInt("to", 0)),
+		Limit:      c.QueryInt("limit", 0),
+		BusinessID: businessID,
+	}
+
+	result, err := h.scanUsecase.ListAlerts(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"alerts": result,
+	})
+}
+
+// AcknowledgeAlert handles POST /scans/alerts/:id/acknowledge
+func (h *ScanAuditHandler) AcknowledgeAlert(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	alertID := c.Params("id")
+
+	if alertID == "" {
+		appErr := apperror.NewBadRequest("invalid alert ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	acknowledgedBy := requestStaffID(c)
+	if acknowledgedBy == "" {
+		acknowledgedBy = businessID
+	}
+
+	result, err := h.scanUsecase.AcknowledgeAlert(c.Context(), alertID, businessID, acknowledgedBy)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"context"
+
 	"CLOAKBE/internal/apperror"
 	"CLOAKBE/internal/usecase"
 
@@ -57,11 +59,95 @@ func (h *ServiceHandler) GetService(c *fiber.Ctx) error {
 	return c.Status(200).JSON(result)
 }
 
+// UpdateService handles PUT /services/:id
+func (h *ServiceHandler) UpdateService(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	serviceID := c.Params("id")
+
+	if serviceID == "" {
+		appErr := apperror.NewBadRequest("invalid service ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	var req usecase.UpdateServiceRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	req.ServiceID = serviceID
+	req.BusinessID = businessID
+
+	result, err := h.serviceUsecase.UpdateService(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// DeleteService handles DELETE /services/:id?force=true
+func (h *ServiceHandler) DeleteService(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	serviceID := c.Params("id")
+
+	if serviceID == "" {
+		appErr := apperror.NewBadRequest("invalid service ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	force := c.QueryBool("force", false)
+
+	archived, err := h.serviceUsecase.DeleteService(c.Context(), serviceID, businessID, requestStaffID(c), force)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	// Services with ticket history are archived rather than deleted
+	if archived != nil {
+		return c.Status(200).JSON(archived)
+	}
+
+	return c.SendStatus(204)
+}
+
+// ArchiveService handles POST /services/:id/archive
+func (h *ServiceHandler) ArchiveService(c *fiber.Ctx) error {
+	return h.setArchived(c, h.serviceUsecase.ArchiveService)
+}
+
+// UnarchiveService handles POST /services/:id/unarchive
+func (h *ServiceHandler) UnarchiveService(c *fiber.Ctx) error {
+	return h.setArchived(c, h.serviceUsecase.UnarchiveService)
+}
+
+func (h *ServiceHandler) setArchived(c *fiber.Ctx, apply func(ctx context.Context, serviceID, businessID string) (*usecase.ServiceResponse, error)) error {
+	businessID := c.Locals("user_id").(string)
+	serviceID := c.Params("id")
+
+	if serviceID == "" {
+		appErr := apperror.NewBadRequest("invalid service ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	result, err := apply(c.Context(), serviceID, businessID)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
 // ListServices handles GET /services
 func (h *ServiceHandler) ListServices(c *fiber.Ctx) error {
 	businessID := c.Locals("user_id").(string)
 
-	result, err := h.serviceUsecase.ListServices(c.Context(), businessID)
+	includeArchived := c.QueryBool("include_archived", false)
+
+	result, err := h.serviceUsecase.ListServices(c.Context(), businessID, includeArchived)
 	if err != nil {
 		appErr := apperror.From(err)
 		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
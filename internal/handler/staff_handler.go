@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// StaffHandler handles staff accounts and staff logins
+type StaffHandler struct {
+	staffUsecase *usecase.StaffUsecase
+}
+
+// NewStaffHandler creates a new staff handler
+func NewStaffHandler(staffUsecase *usecase.StaffUsecase) *StaffHandler {
+	return &StaffHandler{staffUsecase}
+}
+
+// InviteStaff handles POST /staff - Owner invites a staff member by email
+func (h *StaffHandler) InviteStaff(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+
+	var req usecase.InviteStaffRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	req.BusinessID = businessID
+
+	result, err := h.staffUsecase.InviteStaff(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(201).JSON(result)
+}
+
+// ListStaff handles GET /staff
+func (h *StaffHandler) ListStaff(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+
+	result, err := h.staffUsecase.ListStaff(c.Context(), businessID)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// UpdateStaff handles PUT /staff/:id - Owner changes a staff member's role
+func (h *StaffHandler) UpdateStaff(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	staffID := c.Params("id")
+
+	if staffID == "" {
+		appErr := apperror.NewBadRequest("invalid staff ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	var req usecase.UpdateStaffRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	req.StaffID = staffID
+	req.BusinessID = businessID
+	req.ActorID = requestStaffID(c)
+
+	result, err := h.staffUsecase.UpdateStaff(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// DisableStaff handles DELETE /staff/:id - Owner takes away a staff member's access
+func (h *StaffHandler) DisableStaff(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	staffID := c.Params("id")
+
+	if staffID == "" {
+		appErr := apperror.NewBadRequest("invalid staff ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	if err := h.staffUsecase.DisableStaff(c.Context(), staffID, businessID, requestStaffID(c)); err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.SendStatus(204)
+}
+
+// AcceptInvite handles POST /auth/staff/accept - Staff member sets their password
+func (h *StaffHandler) AcceptInvite(c *fiber.Ctx) error {
+	var req usecase.AcceptInviteRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	result, err := h.staffUsecase.AcceptInvite(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// StaffLogin handles POST /auth/staff/login
+func (h *StaffHandler) StaffLogin(c *fiber.Ctx) error {
+	var req usecase.StaffLoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	result, err := h.staffUsecase.StaffLogin(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		setRetryAfter(c, appErr)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
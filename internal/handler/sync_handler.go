@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"strings"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SyncHandler handles offline scanner sync operations
+type SyncHandler struct {
+	syncUsecase *usecase.SyncUsecase
+}
+
+// NewSyncHandler creates a new sync handler
+func NewSyncHandler(syncUsecase *usecase.SyncUsecase) *SyncHandler {
+	return &SyncHandler{syncUsecase}
+}
+
+// Manifest handles GET /sync/manifest?service_ids=a,b - Active tickets for offline devices
+func (h *SyncHandler) Manifest(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+
+	req := usecase.ManifestRequest{BusinessID: businessID, Device: requestDevice(c)}
+	for _, serviceID := range strings.Split(c.Query("service_ids"), ",") {
+		if serviceID = strings.TrimSpace(serviceID); serviceID != "" {
+			req.ServiceIDs = append(req.ServiceIDs, serviceID)
+		}
+	}
+
+	result, err := h.syncUsecase.Manifest(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// Upload handles POST /sync/events - Replay events recorded offline by a device
+func (h *SyncHandler) Upload(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+
+	var req usecase.SyncUploadRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	req.BusinessID = businessID
+	req.StaffID = requestStaffID(c)
+	req.Device = requestDevice(c)
+
+	result, err := h.syncUsecase.Upload(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TicketEventHandler serves the ticket event log
+type TicketEventHandler struct {
+	eventUsecase *usecase.TicketEventUsecase
+}
+
+// NewTicketEventHandler creates a new ticket event handler
+func NewTicketEventHandler(eventUsecase *usecase.TicketEventUsecase) *TicketEventHandler {
+	return &TicketEventHandler{eventUsecase}
+}
+
+// ListTicketEvents handles GET /tickets/:id/events
+func (h *TicketEventHandler) ListTicketEvents(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	ticketID := c.Params("id")
+
+	if ticketID == "" {
+		appErr := apperror.NewBadRequest("invalid ticket ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	result, err := h.eventUsecase.ListTicketEvents(c.Context(), ticketID, businessID)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// ListEvents handles GET /tickets/events?service_id=&type=&from=&to=&after=&limit=
+func (h *TicketEventHandler) ListEvents(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+
+	req := usecase.ListEventsRequest{
+		ServiceID:  c.Query("service_id"),
+		Type:       c.Query("type"),
+		From:       int64(c.QueryInt("from", 0)),
+		To:         int64(c.QueryInt("to", 0)),
+		After:      int64(c.QueryInt("after", 0)),
+		Limit:      c.QueryInt("limit", 0),
+		BusinessID: businessID,
+	}
+
+	result, err := h.eventUsecase.ListEvents(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"strconv"
+
 	"CLOAKBE/internal/apperror"
 	"CLOAKBE/internal/usecase"
 
@@ -28,6 +30,8 @@ func (h *TicketHandler) CheckIn(c *fiber.Ctx) error {
 	}
 
 	req.BusinessID = businessID
+	req.StaffID = requestStaffID(c)
+	req.Device = requestDevice(c)
 
 	result, err := h.ticketUsecase.CheckIn(c.Context(), req)
 	if err != nil {
@@ -38,29 +42,42 @@ func (h *TicketHandler) CheckIn(c *fiber.Ctx) error {
 	return c.Status(200).JSON(result)
 }
 
+// CheckInByRole handles POST /tickets/checkin, which businesses and customers share
+func (h *TicketHandler) CheckInByRole(c *fiber.Ctx) error {
+	if c.Locals("role") == "customer" {
+		return h.CustomerCheckIn(c)
+	}
+	return h.CheckIn(c)
+}
+
 // CustomerCheckIn handles POST /tickets/checkin - Customer checks in to an event
 func (h *TicketHandler) CustomerCheckIn(c *fiber.Ctx) error {
-	_ = c.Locals("user_id").(string) // customerID - for future use (tracking which customer checked in)
+	customerID := c.Locals("user_id").(string)
 
-	var req struct {
-		ServiceID string `json:"service_id"`
-	}
+	var req usecase.CustomerCheckInRequest
 	if err := c.BodyParser(&req); err != nil {
 		appErr := apperror.NewBadRequest("invalid request body")
 		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
 	}
 
+	req.CustomerID = customerID
+
 	if req.ServiceID == "" {
 		appErr := apperror.NewBadRequest("service_id is required")
 		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
 	}
 
-	result, err := h.ticketUsecase.CustomerCheckIn(c.Context(), req.ServiceID)
+	result, err := h.ticketUsecase.CustomerCheckIn(c.Context(), req)
 	if err != nil {
 		appErr := apperror.From(err)
 		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
 	}
 
+	// Queued rather than checked in
+	if result.TicketID == "" {
+		return c.Status(202).JSON(result)
+	}
+
 	return c.Status(200).JSON(result)
 }
 
@@ -75,6 +92,8 @@ func (h *TicketHandler) Scan(c *fiber.Ctx) error {
 	}
 
 	req.BusinessID = businessID
+	req.StaffID = requestStaffID(c)
+	req.Device = requestDevice(c)
 
 	result, err := h.ticketUsecase.Scan(c.Context(), req)
 	if err != nil {
@@ -95,7 +114,7 @@ func (h *TicketHandler) Release(c *fiber.Ctx) error {
 		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
 	}
 
-	if err := h.ticketUsecase.Release(c.Context(), ticketID, businessID); err != nil {
+	if err := h.ticketUsecase.Release(c.Context(), ticketID, businessID, requestStaffID(c), requestDevice(c)); err != nil {
 		appErr := apperror.From(err)
 		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
 	}
@@ -111,7 +130,22 @@ func (h *TicketHandler) GetCustomerTickets(c *fiber.Ctx) error {
 		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
 	}
 
-	tickets, err := h.ticketUsecase.GetCustomerTickets(c.Context(), customerID)
+	tickets, err := h.ticketUsecase.GetCustomerTickets(c.Context(), customerID, requestActor(c))
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"tickets": tickets,
+	})
+}
+
+// GetMyTickets handles GET /me/tickets - The calling customer's tickets
+func (h *TicketHandler) GetMyTickets(c *fiber.Ctx) error {
+	actor := requestActor(c)
+
+	tickets, err := h.ticketUsecase.GetCustomerTickets(c.Context(), actor.UserID, actor)
 	if err != nil {
 		appErr := apperror.From(err)
 		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
@@ -129,3 +163,34 @@ func errorResponse(err *apperror.AppError) fiber.Map {
 		"details": err.Details,
 	}
 }
+
+// setRetryAfter copies the retry_after detail of a throttled request into
+// the Retry-After header
+func setRetryAfter(c *fiber.Ctx, err *apperror.AppError) {
+	if retryAfter, ok := err.Details["retry_after"].(int64); ok {
+		c.Set(fiber.HeaderRetryAfter, strconv.FormatInt(retryAfter, 10))
+	}
+}
+
+// requestActor identifies the authenticated caller for usecases that serve
+// both businesses and customers
+func requestActor(c *fiber.Ctx) usecase.Actor {
+	return usecase.Actor{
+		UserID: c.Locals("user_id").(string),
+		Role:   c.Locals("role").(string),
+	}
+}
+
+// requestStaffID returns the staff member behind a business request, or ""
+// for the business account itself
+func requestStaffID(c *fiber.Ctx) string {
+	staffID, _ := c.Locals("staff_id").(string)
+	return staffID
+}
+
+// requestDevice returns the scope of the scanner device behind a request, or
+// nil when the caller is not a device
+func requestDevice(c *fiber.Ctx) *usecase.DeviceScope {
+	device, _ := c.Locals("device_scope").(*usecase.DeviceScope)
+	return device
+}
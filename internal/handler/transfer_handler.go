@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TransferHandler handles customer-to-customer ticket transfers
+type TransferHandler struct {
+	transferUsecase *usecase.TransferUsecase
+}
+
+// NewTransferHandler creates a new transfer handler
+func NewTransferHandler(transferUsecase *usecase.TransferUsecase) *TransferHandler {
+	return &TransferHandler{transferUsecase}
+}
+
+// StartTransfer handles POST /tickets/:id/transfers - Owner offers the ticket to someone else
+func (h *TransferHandler) StartTransfer(c *fiber.Ctx) error {
+	customerID := c.Locals("user_id").(string)
+	ticketID := c.Params("id")
+
+	if ticketID == "" {
+		appErr := apperror.NewBadRequest("invalid ticket ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	var req usecase.StartTransferRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	req.TicketID = ticketID
+	req.CustomerID = customerID
+
+	result, err := h.transferUsecase.StartTransfer(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(201).JSON(result)
+}
+
+// ListTransfers handles GET /tickets/:id/transfers - Transfer history of a ticket
+func (h *TransferHandler) ListTransfers(c *fiber.Ctx) error {
+	ticketID := c.Params("id")
+
+	if ticketID == "" {
+		appErr := apperror.NewBadRequest("invalid ticket ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	result, err := h.transferUsecase.ListTransfers(c.Context(), ticketID, requestActor(c))
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// ListIncoming handles GET /me/transfers - Pending transfers addressed to the customer
+func (h *TransferHandler) ListIncoming(c *fiber.Ctx) error {
+	customerID := c.Locals("user_id").(string)
+
+	result, err := h.transferUsecase.ListIncoming(c.Context(), customerID)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// AcceptTransfer handles POST /transfers/:id/accept - Recipient takes over the ticket
+func (h *TransferHandler) AcceptTransfer(c *fiber.Ctx) error {
+	customerID := c.Locals("user_id").(string)
+	transferID := c.Params("id")
+
+	if transferID == "" {
+		appErr := apperror.NewBadRequest("invalid ticket transfer ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	result, err := h.transferUsecase.AcceptTransfer(c.Context(), transferID, customerID)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// CancelTransfer handles DELETE /transfers/:id - Sender cancels or recipient declines
+func (h *TransferHandler) CancelTransfer(c *fiber.Ctx) error {
+	customerID := c.Locals("user_id").(string)
+	transferID := c.Params("id")
+
+	if transferID == "" {
+		appErr := apperror.NewBadRequest("invalid ticket transfer ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	if err := h.transferUsecase.CancelTransfer(c.Context(), transferID, customerID); err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.SendStatus(204)
+}
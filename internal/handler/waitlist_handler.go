@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WaitlistHandler handles service waitlist operations
+type WaitlistHandler struct {
+	waitlistUsecase *usecase.WaitlistUsecase
+}
+
+// NewWaitlistHandler creates a new waitlist handler
+func NewWaitlistHandler(waitlistUsecase *usecase.WaitlistUsecase) *WaitlistHandler {
+	return &WaitlistHandler{waitlistUsecase}
+}
+
+// JoinWaitlist handles POST /waitlist - Customer queues for a full service
+func (h *WaitlistHandler) JoinWaitlist(c *fiber.Ctx) error {
+	customerID := c.Locals("user_id").(string)
+
+	var req usecase.JoinWaitlistRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	req.CustomerID = customerID
+	req.BusinessID = ""
+	req.Name = ""
+	req.Phone = ""
+
+	result, err := h.waitlistUsecase.Join(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(201).JSON(result)
+}
+
+// AddToWaitlist handles POST /services/:id/waitlist - Staff queue a walk-in or customer
+func (h *WaitlistHandler) AddToWaitlist(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	serviceID := c.Params("id")
+
+	if serviceID == "" {
+		appErr := apperror.NewBadRequest("invalid service ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	var req usecase.JoinWaitlistRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	req.ServiceID = serviceID
+	req.BusinessID = businessID
+
+	result, err := h.waitlistUsecase.Join(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(201).JSON(result)
+}
+
+// ListWaitlist handles GET /services/:id/waitlist
+func (h *WaitlistHandler) ListWaitlist(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	serviceID := c.Params("id")
+
+	if serviceID == "" {
+		appErr := apperror.NewBadRequest("invalid service ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	result, err := h.waitlistUsecase.ListWaitlist(c.Context(), serviceID, businessID)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// GetEntry handles GET /waitlist/:id - Queue position and any reserved slot
+func (h *WaitlistHandler) GetEntry(c *fiber.Ctx) error {
+	entryID := c.Params("id")
+
+	if entryID == "" {
+		appErr := apperror.NewBadRequest("invalid waitlist entry ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	result, err := h.waitlistUsecase.GetEntry(c.Context(), entryID, requestActor(c))
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// LeaveWaitlist handles DELETE /waitlist/:id
+func (h *WaitlistHandler) LeaveWaitlist(c *fiber.Ctx) error {
+	entryID := c.Params("id")
+
+	if entryID == "" {
+		appErr := apperror.NewBadRequest("invalid waitlist entry ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	if err := h.waitlistUsecase.Leave(c.Context(), entryID, requestActor(c)); err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.SendStatus(204)
+}
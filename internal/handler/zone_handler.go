@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ZoneHandler handles service zone operations
+type ZoneHandler struct {
+	zoneUsecase *usecase.ZoneUsecase
+}
+
+// NewZoneHandler creates a new zone handler
+func NewZoneHandler(zoneUsecase *usecase.ZoneUsecase) *ZoneHandler {
+	return &ZoneHandler{zoneUsecase}
+}
+
+// ListZones handles GET /services/:id/zones
+func (h *ZoneHandler) ListZones(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	serviceID := c.Params("id")
+
+	if serviceID == "" {
+		appErr := apperror.NewBadRequest("invalid service ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	result, err := h.zoneUsecase.ListZones(c.Context(), serviceID, businessID)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
+
+// CreateZone handles POST /services/:id/zones
+func (h *ZoneHandler) CreateZone(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	serviceID := c.Params("id")
+
+	if serviceID == "" {
+		appErr := apperror.NewBadRequest("invalid service ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	var req usecase.CreateZoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	req.ServiceID = serviceID
+	req.BusinessID = businessID
+
+	result, err := h.zoneUsecase.CreateZone(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(201).JSON(result)
+}
+
+// UpdateZone handles PUT /services/:id/zones/:zoneId
+func (h *ZoneHandler) UpdateZone(c *fiber.Ctx) error {
+	businessID := c.Locals("user_id").(string)
+	serviceID := c.Params("id")
+	zoneID := c.Params("zoneId")
+
+	if serviceID == "" || zoneID == "" {
+		appErr := apperror.NewBadRequest("invalid service or zone ID")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	var req usecase.UpdateZoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		appErr := apperror.NewBadRequest("invalid request body")
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	req.ZoneID = zoneID
+	req.ServiceID = serviceID
+	req.BusinessID = businessID
+
+	result, err := h.zoneUsecase.UpdateZone(c.Context(), req)
+	if err != nil {
+		appErr := apperror.From(err)
+		return c.Status(appErr.StatusCode).JSON(errorResponse(appErr))
+	}
+
+	return c.Status(200).JSON(result)
+}
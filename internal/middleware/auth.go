@@ -10,8 +10,10 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// AuthMiddleware validates JWT tokens
-func AuthMiddleware(jwtSecret string) fiber.Handler {
+// AuthMiddleware validates JWT tokens and scanner device tokens. JWTs are
+// rejected once their session is revoked. Devices act for their business
+// with the role "device", limited to their scope.
+func AuthMiddleware(jwtSecret string, auth *usecase.AuthUsecase, devices *usecase.DeviceUsecase) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
@@ -34,6 +36,31 @@ func AuthMiddleware(jwtSecret string) fiber.Handler {
 
 		tokenString := parts[1]
 
+		if strings.HasPrefix(tokenString, usecase.DeviceTokenPrefix) {
+			device, err := devices.Authenticate(c.Context(), tokenString)
+			if err != nil {
+				appErr := apperror.From(err)
+				return c.Status(appErr.StatusCode).JSON(fiber.Map{
+					"code":    appErr.Code,
+					"message": appErr.Message,
+				})
+			}
+
+			c.Locals("user_id", device.BusinessID)
+			c.Locals("email", "")
+			c.Locals("role", "device")
+			c.Locals("business_id", device.BusinessID)
+			c.Locals("staff_id", "")
+			c.Locals("staff_role", "")
+			c.Locals("device_id", device.ID)
+			c.Locals("device_scope", &usecase.DeviceScope{
+				DeviceID:   device.ID,
+				ServiceIDs: device.ServiceIDs,
+				Actions:    device.Actions,
+			})
+			return c.Next()
+		}
+
 		// Parse and validate token
 		token, err := jwt.ParseWithClaims(tokenString, &usecase.CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
 			return []byte(jwtSecret), nil
@@ -49,9 +76,22 @@ func AuthMiddleware(jwtSecret string) fiber.Handler {
 
 		// Extract claims and set in context
 		if claims, ok := token.Claims.(*usecase.CustomClaims); ok {
+			// Reject tokens of sessions that were logged out or revoked
+			if err := auth.CheckSession(c.Context(), claims.SessionID); err != nil {
+				appErr := apperror.From(err)
+				return c.Status(appErr.StatusCode).JSON(fiber.Map{
+					"code":    appErr.Code,
+					"message": appErr.Message,
+				})
+			}
+
 			c.Locals("user_id", claims.UserID)
 			c.Locals("email", claims.Email)
 			c.Locals("role", claims.Role)
+			c.Locals("business_id", claims.BusinessID)
+			c.Locals("staff_id", claims.StaffID)
+			c.Locals("staff_role", claims.StaffRole)
+			c.Locals("session_id", claims.SessionID)
 		} else {
 			appErr := apperror.NewUnauthorized("Invalid token claims")
 			return c.Status(appErr.StatusCode).JSON(fiber.Map{
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DeviceActionMiddleware limits scanner device tokens to the actions they
+// were registered for. Other callers pass through untouched and are left to
+// RoleMiddleware.
+func DeviceActionMiddleware(action string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		scope, _ := c.Locals("device_scope").(*usecase.DeviceScope)
+		if scope.Allows(action) {
+			return c.Next()
+		}
+
+		appErr := apperror.NewForbidden("device is not allowed to " + action + " tickets")
+		return c.Status(appErr.StatusCode).JSON(fiber.Map{
+			"code":    appErr.Code,
+			"message": appErr.Message,
+		})
+	}
+}
+
+// GetDeviceIDFromContext is a helper function to get the scanner device ID
+// from Fiber context, "" when the caller is not a device
+func GetDeviceIDFromContext(c *fiber.Ctx) string {
+	deviceID := c.Locals("device_id")
+	if deviceID == nil {
+		return ""
+	}
+	return deviceID.(string)
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RateLimitKeyFunc picks what a policy counts requests by. Returning ""
+// exempts the request from the policy.
+type RateLimitKeyFunc func(c *fiber.Ctx) string
+
+// KeyByIP counts requests per client IP
+func KeyByIP(c *fiber.Ctx) string {
+	return c.IP()
+}
+
+// KeyByBusiness counts requests per business, whether they come from the
+// business account, its staff or its devices. It must run after
+// AuthMiddleware; customers are exempt.
+func KeyByBusiness(c *fiber.Ctx) string {
+	businessID, _ := c.Locals("business_id").(string)
+	return businessID
+}
+
+// KeyByAccount counts requests per device, staff member or user once
+// authenticated, and per email or phone in the body of public login
+// requests
+func KeyByAccount(c *fiber.Ctx) string {
+	if deviceID, _ := c.Locals("device_id").(string); deviceID != "" {
+		return "device:" + deviceID
+	}
+	if staffID, _ := c.Locals("staff_id").(string); staffID != "" {
+		return "staff:" + staffID
+	}
+	if userID, _ := c.Locals("user_id").(string); userID != "" {
+		return "user:" + userID
+	}
+
+	var body struct {
+		Email string `json:"email"`
+		Phone string `json:"phone"`
+	}
+	if err := json.Unmarshal(c.Body(), &body); err != nil {
+		return ""
+	}
+	if email := strings.ToLower(strings.TrimSpace(body.Email)); email != "" {
+		return "email:" + email
+	}
+	if phone := strings.TrimSpace(body.Phone); phone != "" {
+		return "phone:" + phone
+	}
+	return ""
+}
+
+// RateLimitMiddleware counts requests under a policy and rejects those over
+// the limit with 429. Responses carry RateLimit-Limit, RateLimit-Remaining
+// and RateLimit-Reset headers for the tightest policy applied; throttled
+// ones also carry Retry-After.
+func RateLimitMiddleware(limiter *usecase.RateLimitUsecase, policy usecase.RateLimitPolicy, keyFunc RateLimitKeyFunc) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := keyFunc(c)
+		if key == "" {
+			return c.Next()
+		}
+
+		result, err := limiter.Allow(c.Context(), policy, key)
+		if err != nil {
+			appErr := apperror.From(err)
+			return c.Status(appErr.StatusCode).JSON(fiber.Map{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+		}
+
+		setRateLimitHeaders(c, result)
+
+		if !result.Allowed {
+			appErr := apperror.NewTooManyRequests("rate limit exceeded; slow down", result.Reset)
+			c.Set(fiber.HeaderRetryAfter, strconv.FormatInt(result.Reset, 10))
+			return c.Status(appErr.StatusCode).JSON(fiber.Map{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+				"details": appErr.Details,
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// setRateLimitHeaders reports a policy's counter unless an earlier policy
+// on the route left fewer requests
+func setRateLimitHeaders(c *fiber.Ctx, result *usecase.RateLimitResult) {
+	if set := c.GetRespHeader("RateLimit-Remaining"); set != "" {
+		if remaining, err := strconv.Atoi(set); err == nil && remaining < result.Remaining {
+			return
+		}
+	}
+
+	c.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Set("RateLimit-Reset", strconv.FormatInt(result.Reset, 10))
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// StaffMiddleware rejects staff tokens of disabled staff and refreshes the
+// staff role from storage, so access changes apply before tokens expire.
+// It must run after AuthMiddleware.
+func StaffMiddleware(staffUsecase *usecase.StaffUsecase) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		staffID := GetStaffIDFromContext(c)
+		if staffID == "" {
+			return c.Next()
+		}
+
+		role, err := staffUsecase.ActiveStaffRole(c.Context(), staffID, c.Locals("business_id").(string))
+		if err != nil {
+			appErr := apperror.From(err)
+			return c.Status(appErr.StatusCode).JSON(fiber.Map{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+		}
+
+		c.Locals("staff_role", role)
+		return c.Next()
+	}
+}
+
+// StaffRoleMiddleware limits business routes to staff with one of the
+// allowed roles. The business account itself acts as owner; customers pass
+// through untouched and are left to RoleMiddleware.
+func StaffRoleMiddleware(allowedRoles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if GetStaffIDFromContext(c) == "" {
+			return c.Next()
+		}
+
+		role := c.Locals("staff_role").(string)
+		for _, allowed := range allowedRoles {
+			if role == allowed {
+				return c.Next()
+			}
+		}
+
+		appErr := apperror.NewForbidden("insufficient staff permissions")
+		return c.Status(appErr.StatusCode).JSON(fiber.Map{
+			"code":    appErr.Code,
+			"message": appErr.Message,
+		})
+	}
+}
+
+// GetStaffIDFromContext is a helper function to get the staff ID from Fiber
+// context, "" when the caller is not a staff member
+func GetStaffIDFromContext(c *fiber.Ctx) string {
+	staffID := c.Locals("staff_id")
+	if staffID == nil {
+		return ""
+	}
+	return staffID.(string)
+}
@@ -0,0 +1,85 @@
+// Package notify delivers one-time codes and links to customers and
+// businesses. Production deployments plug in an email or SMS gateway; the
+// log and file senders are meant for local development.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Delivery channels
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+)
+
+// Message is one notification to one recipient
+type Message struct {
+	Channel string `json:"channel"` // "email" or "sms"
+	To      string `json:"to"`      // Email address or phone number
+	Subject string `json:"subject,omitempty"`
+	Body    string `json:"body"`
+}
+
+// Sender delivers messages
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogSender writes messages to the standard logger instead of sending them
+type LogSender struct{}
+
+// NewLogSender creates a sender that logs messages
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+// Send logs the message
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	log.Printf("notify: %s to %s: %s %s", msg.Channel, msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileSender appends messages to a file as JSON lines, so local tools and
+// scripts can pick up codes and links
+type FileSender struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileSender creates a sender that appends messages to path
+func NewFileSender(path string) *FileSender {
+	return &FileSender{path: path}
+}
+
+// Send appends the message to the file
+func (s *FileSender) Send(ctx context.Context, msg Message) error {
+	line, err := json.Marshal(struct {
+		Message
+		SentAt int64 `json:"sent_at"`
+	}{msg, time.Now().Unix()})
+	if err != nil {
+		return fmt.Errorf("encode message: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", s.path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write %s: %w", s.path, err)
+	}
+
+	return nil
+}
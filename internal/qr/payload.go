@@ -1,9 +1,12 @@
 package qr
 
 import (
+	"crypto/ed25519"
 	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -11,32 +14,60 @@ import (
 	"github.com/google/uuid"
 )
 
+// Payload versions
+const (
+	VersionHMAC    = 1 // Signed with the business's shared HMAC secret
+	VersionEd25519 = 2 // Signed with the business's Ed25519 key, verifiable offline
+)
+
 // Payload represents the QR code data that gets signed and encoded
 type Payload struct {
-	Version    int    `json:"v"`   // Version 1
+	Version    int    `json:"v"`   // VersionHMAC or VersionEd25519
 	TicketID   string `json:"tid"` // Ticket UUID
 	ServiceID  string `json:"sid"` // Service UUID
 	BusinessID string `json:"bid"` // Business UUID
 	SlotNumber int    `json:"slot"`
-	IssuedAt   int64  `json:"iat"` // Unix timestamp
-	HMAC       string `json:"hmac"`
+	IssuedAt   int64  `json:"iat"`            // Unix timestamp
+	KeyID      string `json:"kid,omitempty"`  // Signing key ID (empty for tickets issued before key rotation)
+	Generation int    `json:"gen,omitempty"`  // Times the ticket's code was reissued (0 for the original)
+	HMAC       string `json:"hmac,omitempty"` // Version 1 signature (hex)
+	Signature  string `json:"sig,omitempty"`  // Version 2 signature (base64url)
 }
 
-// New creates a new QR payload with current timestamp
-func New(ticketID, serviceID, businessID string, slotNumber int) *Payload {
+// New creates a new version 1 QR payload with current timestamp, to be signed with key keyID
+func New(ticketID, serviceID, businessID string, slotNumber int, keyID string) *Payload {
 	return &Payload{
-		Version:    1,
+		Version:    VersionHMAC,
 		TicketID:   ticketID,
 		ServiceID:  serviceID,
 		BusinessID: businessID,
 		SlotNumber: slotNumber,
 		IssuedAt:   time.Now().Unix(),
+		KeyID:      keyID,
 	}
 }
 
-// canonicalString creates the string to sign over
+// NewV2 creates a new version 2 QR payload with current timestamp, to be signed with Ed25519 key keyID
+func NewV2(ticketID, serviceID, businessID string, slotNumber int, keyID string) *Payload {
+	p := New(ticketID, serviceID, businessID, slotNumber, keyID)
+	p.Version = VersionEd25519
+	return p
+}
+
+// Digest returns the signature that identifies the ticket, whatever the version
+func (p *Payload) Digest() string {
+	if p.Version == VersionEd25519 {
+		return p.Signature
+	}
+	return p.HMAC
+}
+
+// canonicalString creates the string to sign over:
+// v=<v>&tid=<tid>&sid=<sid>&bid=<bid>&slot=<slot>&iat=<iat>[&kid=<kid>][&gen=<gen>]
+// Offline scanners rebuild it to verify v2 signatures. The kid and gen are
+// only appended when set so that older signatures still verify.
 func (p *Payload) canonicalString() string {
-	return fmt.Sprintf("v=%d&tid=%s&sid=%s&bid=%s&slot=%d&iat=%d",
+	canonical := fmt.Sprintf("v=%d&tid=%s&sid=%s&bid=%s&slot=%d&iat=%d",
 		p.Version,
 		p.TicketID,
 		p.ServiceID,
@@ -44,6 +75,13 @@ func (p *Payload) canonicalString() string {
 		p.SlotNumber,
 		p.IssuedAt,
 	)
+	if p.KeyID != "" {
+		canonical += "&kid=" + p.KeyID
+	}
+	if p.Generation > 0 {
+		canonical += fmt.Sprintf("&gen=%d", p.Generation)
+	}
+	return canonical
 }
 
 // Sign computes HMAC over the payload
@@ -81,6 +119,55 @@ func (p *Payload) Verify(secret string) error {
 	return nil
 }
 
+// GenerateEd25519Key creates a new key pair, returning the hex encoded
+// private key seed and the base64url encoded public key
+func GenerateEd25519Key() (secret, publicKey string, err error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(priv.Seed()), base64.RawURLEncoding.EncodeToString(pub), nil
+}
+
+// SignEd25519 signs a version 2 payload with a hex encoded private key seed
+func (p *Payload) SignEd25519(secret string) error {
+	if p.Version != VersionEd25519 {
+		return fmt.Errorf("payload version %d cannot be signed with ed25519", p.Version)
+	}
+
+	seed, err := hex.DecodeString(secret)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return fmt.Errorf("invalid ed25519 private key")
+	}
+
+	sig := ed25519.Sign(ed25519.NewKeyFromSeed(seed), []byte(p.canonicalString()))
+	p.Signature = base64.RawURLEncoding.EncodeToString(sig)
+	return nil
+}
+
+// VerifyEd25519 checks a version 2 signature against a base64url encoded public key
+func (p *Payload) VerifyEd25519(publicKey string) error {
+	if p.Signature == "" {
+		return fmt.Errorf("payload not signed")
+	}
+
+	pub, err := base64.RawURLEncoding.DecodeString(publicKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid ed25519 public key")
+	}
+
+	sig, err := base64.RawURLEncoding.Strict().DecodeString(p.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding")
+	}
+
+	if !ed25519.Verify(ed25519.PublicKey(pub), []byte(p.canonicalString()), sig) {
+		return fmt.Errorf("invalid ed25519 signature")
+	}
+
+	return nil
+}
+
 // Encode converts payload to base64 JSON string (for QR code)
 func (p *Payload) Encode() (string, error) {
 	data, err := json.Marshal(p)
@@ -107,5 +194,18 @@ func Decode(encoded string) (*Payload, error) {
 		return nil, fmt.Errorf("invalid ticket ID format: %w", err)
 	}
 
+	switch payload.Version {
+	case VersionHMAC:
+		if payload.HMAC == "" {
+			return nil, fmt.Errorf("version 1 payload is missing its hmac")
+		}
+	case VersionEd25519:
+		if payload.Signature == "" || payload.KeyID == "" {
+			return nil, fmt.Errorf("version 2 payload is missing its signature or kid")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported payload version %d", payload.Version)
+	}
+
 	return &payload, nil
 }
@@ -0,0 +1,248 @@
+package qr
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const (
+	testTicketID   = "6f1c1f3e-2a5b-4c8e-9d0a-1b2c3d4e5f60"
+	testServiceID  = "0a9b8c7d-6e5f-4a3b-8c1d-2e3f4a5b6c7d"
+	testBusinessID = "11111111-2222-4333-8444-555555555555"
+	testHMACSecret = "business-secret"
+)
+
+// signedV1 returns an encoded version 1 payload signed with testHMACSecret
+func signedV1(t *testing.T) string {
+	t.Helper()
+
+	p := New(testTicketID, testServiceID, testBusinessID, 7, "key-1")
+	if err := p.Sign(testHMACSecret); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	encoded, err := p.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	return encoded
+}
+
+// signedV2 returns an encoded version 2 payload of the given generation and
+// the public key that verifies it
+func signedV2(t *testing.T, generation int) (string, string) {
+	t.Helper()
+
+	secret, publicKey, err := GenerateEd25519Key()
+	if err != nil {
+		t.Fatalf("GenerateEd25519Key: %v", err)
+	}
+
+	p := NewV2(testTicketID, testServiceID, testBusinessID, 7, "key-2")
+	p.Generation = generation
+	if err := p.SignEd25519(secret); err != nil {
+		t.Fatalf("SignEd25519: %v", err)
+	}
+	encoded, err := p.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	return encoded, publicKey
+}
+
+// reencode decodes a payload's JSON, applies edit to its fields and encodes
+// it again, keeping the signature
+func reencode(t *testing.T, encoded string, edit func(fields map[string]any)) string {
+	t.Helper()
+
+	data, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	edit(fields)
+
+	data, err = json.Marshal(fields)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return base64.URLEncoding.EncodeToString(data)
+}
+
+// nonCanonical changes the unused low bits of the last character of an
+// unpadded base64url string, which a lenient decoder ignores
+func nonCanonical(s string) string {
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	last := strings.IndexByte(alphabet, s[len(s)-1])
+	return s[:len(s)-1] + string(alphabet[last^1])
+}
+
+func TestCanonicalString(t *testing.T) {
+	tests := []struct {
+		name       string
+		keyID      string
+		generation int
+		want       string
+	}{
+		{"without kid or gen", "", 0, "v=2&tid=t&sid=s&bid=b&slot=3&iat=100"},
+		{"with kid", "k", 0, "v=2&tid=t&sid=s&bid=b&slot=3&iat=100&kid=k"},
+		{"with kid and gen", "k", 2, "v=2&tid=t&sid=s&bid=b&slot=3&iat=100&kid=k&gen=2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Payload{Version: VersionEd25519, TicketID: "t", ServiceID: "s", BusinessID: "b", SlotNumber: 3, IssuedAt: 100, KeyID: tt.keyID, Generation: tt.generation}
+			if got := p.canonicalString(); got != tt.want {
+				t.Fatalf("canonicalString: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestV1RoundTrip(t *testing.T) {
+	p, err := Decode(signedV1(t))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if p.Version != VersionHMAC || p.SlotNumber != 7 || p.KeyID != "key-1" {
+		t.Fatalf("Decode: got version %d, slot %d, kid %q", p.Version, p.SlotNumber, p.KeyID)
+	}
+	if err := p.Verify(testHMACSecret); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if p.Digest() != p.HMAC {
+		t.Fatal("Digest: want the hmac of a version 1 payload")
+	}
+}
+
+func TestV1RejectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		edit   func(fields map[string]any)
+		secret string
+	}{
+		{"wrong secret", func(map[string]any) {}, "other-secret"},
+		{"flipped slot", func(f map[string]any) { f["slot"] = 8 }, testHMACSecret},
+		{"flipped service", func(f map[string]any) { f["sid"] = testBusinessID }, testHMACSecret},
+		{"flipped kid", func(f map[string]any) { f["kid"] = "key-9" }, testHMACSecret},
+		{"added gen", func(f map[string]any) { f["gen"] = 1 }, testHMACSecret},
+		{"flipped hmac", func(f map[string]any) { f["hmac"] = strings.Repeat("0", 64) }, testHMACSecret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Decode(reencode(t, signedV1(t), tt.edit))
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if err := p.Verify(tt.secret); err == nil {
+				t.Fatal("Verify: want an error")
+			}
+		})
+	}
+}
+
+func TestV2RoundTrip(t *testing.T) {
+	for _, generation := range []int{0, 3} {
+		encoded, publicKey := signedV2(t, generation)
+
+		p, err := Decode(encoded)
+		if err != nil {
+			t.Fatalf("Decode: %v", err)
+		}
+		if p.Version != VersionEd25519 || p.Generation != generation || p.KeyID != "key-2" {
+			t.Fatalf("Decode: got version %d, gen %d, kid %q", p.Version, p.Generation, p.KeyID)
+		}
+		if err := p.VerifyEd25519(publicKey); err != nil {
+			t.Fatalf("VerifyEd25519 (gen %d): %v", generation, err)
+		}
+		if p.Digest() != p.Signature {
+			t.Fatal("Digest: want the signature of a version 2 payload")
+		}
+	}
+}
+
+func TestV2RejectsTampering(t *testing.T) {
+	encoded, publicKey := signedV2(t, 1)
+	_, otherKey, err := GenerateEd25519Key()
+	if err != nil {
+		t.Fatalf("GenerateEd25519Key: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		edit      func(fields map[string]any)
+		publicKey string
+	}{
+		{"wrong key", func(map[string]any) {}, otherKey},
+		{"flipped slot", func(f map[string]any) { f["slot"] = 8 }, publicKey},
+		{"flipped ticket", func(f map[string]any) { f["tid"] = testServiceID }, publicKey},
+		{"flipped kid", func(f map[string]any) { f["kid"] = "key-9" }, publicKey},
+		{"flipped gen", func(f map[string]any) { f["gen"] = 2 }, publicKey},
+		{"dropped gen", func(f map[string]any) { delete(f, "gen") }, publicKey},
+		{"non-canonical signature", func(f map[string]any) { f["sig"] = nonCanonical(f["sig"].(string)) }, publicKey},
+		{"padded signature", func(f map[string]any) { f["sig"] = f["sig"].(string) + "==" }, publicKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Decode(reencode(t, encoded, tt.edit))
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if err := p.VerifyEd25519(tt.publicKey); err == nil {
+				t.Fatal("VerifyEd25519: want an error")
+			}
+		})
+	}
+}
+
+func TestDecodeRejectsMalformedPayloads(t *testing.T) {
+	v1 := signedV1(t)
+	v2, _ := signedV2(t, 0)
+
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"not base64", "not base64!"},
+		{"not json", base64.URLEncoding.EncodeToString([]byte("ticket"))},
+		{"unknown version", reencode(t, v1, func(f map[string]any) { f["v"] = 3 })},
+		{"missing version", reencode(t, v1, func(f map[string]any) { delete(f, "v") })},
+		{"v1 without hmac", reencode(t, v1, func(f map[string]any) { delete(f, "hmac") })},
+		{"v1 signature on a v2 payload", reencode(t, v1, func(f map[string]any) { f["v"] = 2 })},
+		{"v2 without signature", reencode(t, v2, func(f map[string]any) { delete(f, "sig") })},
+		{"v2 without kid", reencode(t, v2, func(f map[string]any) { delete(f, "kid") })},
+		{"invalid ticket id", reencode(t, v2, func(f map[string]any) { f["tid"] = "ticket-1" })},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.encoded); err == nil {
+				t.Fatal("Decode: want an error")
+			}
+		})
+	}
+}
+
+func TestSignRejectsMismatchedKeys(t *testing.T) {
+	secret, _, err := GenerateEd25519Key()
+	if err != nil {
+		t.Fatalf("GenerateEd25519Key: %v", err)
+	}
+
+	if err := New(testTicketID, testServiceID, testBusinessID, 1, "").SignEd25519(secret); err == nil {
+		t.Fatal("SignEd25519: want an error for a version 1 payload")
+	}
+	if err := NewV2(testTicketID, testServiceID, testBusinessID, 1, "k").SignEd25519("abcd"); err == nil {
+		t.Fatal("SignEd25519: want an error for a short seed")
+	}
+	if err := New(testTicketID, testServiceID, testBusinessID, 1, "").Sign(""); err == nil {
+		t.Fatal("Sign: want an error for an empty secret")
+	}
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/database"
+	"CLOAKBE/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// authSessionColumns is the select list matching scanAuthSession
+const authSessionColumns = `id, subject, user_id, COALESCE(email, ''), role, COALESCE(business_id, ''),
+		COALESCE(staff_id, ''), COALESCE(staff_role, ''), COALESCE(revoked_at, 0), created_at, updated_at`
+
+func scanAuthSession(row rowScanner, s *domain.AuthSession) error {
+	return row.Scan(
+		&s.ID, &s.Subject, &s.UserID, &s.Email, &s.Role, &s.BusinessID,
+		&s.StaffID, &s.StaffRole, &s.RevokedAt, &s.CreatedAt, &s.UpdatedAt,
+	)
+}
+
+// PostgresAuthSessionRepository implements AuthSessionRepository for PostgreSQL
+type PostgresAuthSessionRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresAuthSessionRepository creates a new auth session repository
+func NewPostgresAuthSessionRepository(db *database.Pool) *PostgresAuthSessionRepository {
+	return &PostgresAuthSessionRepository{db: db}
+}
+
+// Create inserts a new session
+func (r *PostgresAuthSessionRepository) Create(ctx context.Context, session *domain.AuthSession) error {
+	query := `
+		INSERT INTO auth_sessions (id, subject, user_id, email, role, business_id, staff_id, staff_role, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), $9, $10)
+	`
+
+	_, err := r.db.DB(ctx).Exec(ctx, query,
+		session.ID, session.Subject, session.UserID, session.Email, session.Role, session.BusinessID,
+		session.StaffID, session.StaffRole, session.CreatedAt, session.UpdatedAt,
+	)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to create session", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a session by ID
+func (r *PostgresAuthSessionRepository) FindByID(ctx context.Context, id string) (*domain.AuthSession, error) {
+	query := `SELECT ` + authSessionColumns + ` FROM auth_sessions WHERE id = $1`
+
+	s := &domain.AuthSession{}
+	if err := scanAuthSession(r.db.DB(ctx).QueryRow(ctx, query, id), s); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("session")
+		}
+		return nil, apperror.NewDatabaseError("failed to find session", err)
+	}
+
+	return s, nil
+}
+
+// Revoke revokes a session; revoking a revoked session is a no-op
+func (r *PostgresAuthSessionRepository) Revoke(ctx context.Context, id string, revokedAt int64) error {
+	query := `UPDATE auth_sessions SET revoked_at = $2, updated_at = $2 WHERE id = $1 AND revoked_at IS NULL`
+
+	if _, err := r.db.DB(ctx).Exec(ctx, query, id, revokedAt); err != nil {
+		return apperror.NewDatabaseError("failed to revoke session", err)
+	}
+
+	return nil
+}
+
+// RevokeBySubject revokes every live session of a subject and returns their IDs
+func (r *PostgresAuthSessionRepository) RevokeBySubject(ctx context.Context, subject string, revokedAt int64) ([]string, error) {
+	query := `
+		UPDATE auth_sessions SET revoked_at = $2, updated_at = $2
+		WHERE subject = $1 AND revoked_at IS NULL
+		RETURNING id
+	`
+
+	rows, err := r.db.DB(ctx).Query(ctx, query, subject, revokedAt)
+	if err != nil {
+		return nil, apperror.NewDatabaseError("failed to revoke sessions", err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, apperror.NewDatabaseError("failed to scan session", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDatabaseError("failed to iterate sessions", err)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/database"
+	"CLOAKBE/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgresBusinessKeyRepository implements BusinessKeyRepository for PostgreSQL
+type PostgresBusinessKeyRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresBusinessKeyRepository creates a new business key repository
+func NewPostgresBusinessKeyRepository(db *database.Pool) *PostgresBusinessKeyRepository {
+	return &PostgresBusinessKeyRepository{db: db}
+}
+
+// Create inserts a new signing key
+func (r *PostgresBusinessKeyRepository) Create(ctx context.Context, key *domain.BusinessKey) error {
+	query := `
+		INSERT INTO business_keys (id, business_id, algorithm, secret, public_key, status, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+	`
+
+	_, err := r.db.DB(ctx).Exec(ctx, query,
+		key.ID, key.BusinessID, key.Algorithm, key.Secret, key.PublicKey, key.Status, key.CreatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return apperror.NewConflict("business already has an active signing key")
+		}
+		return apperror.NewDatabaseError("failed to create business key", err)
+	}
+
+	return nil
+}
+
+// FindByID finds a signing key by its kid
+func (r *PostgresBusinessKeyRepository) FindByID(ctx context.Context, id string) (*domain.BusinessKey, error) {
+	query := `
+		SELECT id, business_id, algorithm, secret, COALESCE(public_key, ''), status, created_at, COALESCE(retired_at, 0), COALESCE(expires_at, 0)
+		FROM business_keys WHERE id = $1
+	`
+
+	k := &domain.BusinessKey{}
+	err := r.db.DB(ctx).QueryRow(ctx, query, id).Scan(
+		&k.ID, &k.BusinessID, &k.Algorithm, &k.Secret, &k.PublicKey, &k.Status, &k.CreatedAt, &k.RetiredAt, &k.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("business key")
+		}
+		return nil, apperror.NewDatabaseError("failed to find business key", err)
+	}
+
+	return k, nil
+}
+
+// FindActiveByBusinessID finds the key currently used to sign new tickets with algorithm
+func (r *PostgresBusinessKeyRepository) FindActiveByBusinessID(ctx context.Context, businessID, algorithm string) (*domain.BusinessKey, error) {
+	query := `
+		SELECT id, business_id, algorithm, secret, COALESCE(public_key, ''), status, created_at, COALESCE(retired_at, 0), COALESCE(expires_at, 0)
+		FROM business_keys WHERE business_id = $1 AND algorithm = $2 AND status = $3
+	`
+
+	k := &domain.BusinessKey{}
+	err := r.db.DB(ctx).QueryRow(ctx, query, businessID, algorithm, domain.KeyStatusActive).Scan(
+		&k.ID, &k.BusinessID, &k.Algorithm, &k.Secret, &k.PublicKey, &k.Status, &k.CreatedAt, &k.RetiredAt, &k.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("active business key")
+		}
+		return nil, apperror.NewDatabaseError("failed to find active business key", err)
+	}
+
+	return k, nil
+}
+
+// ListByBusinessID lists a business's keyring, newest first
+func (r *PostgresBusinessKeyRepository) ListByBusinessID(ctx context.Context, businessID string) ([]domain.BusinessKey, error) {
+	query := `
+		SELECT id, business_id, algorithm, secret, COALESCE(public_key, ''), status, created_at, COALESCE(retired_at, 0), COALESCE(expires_at, 0)
+		FROM business_keys WHERE business_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.DB(ctx).Query(ctx, query, businessID)
+	if err != nil {
+		return nil, apperror.NewDatabaseError("failed to list business keys", err)
+	}
+	defer rows.Close()
+
+	keys := []domain.BusinessKey{}
+	for rows.Next() {
+		k := domain.BusinessKey{}
+		if err := rows.Scan(&k.ID, &k.BusinessID, &k.Algorithm, &k.Secret, &k.PublicKey, &k.Status, &k.CreatedAt, &k.RetiredAt, &k.ExpiresAt); err != nil {
+			return nil, apperror.NewDatabaseError("failed to scan business key", err)
+		}
+		keys = append(keys, k)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, apperror.NewDatabaseError("failed to iterate business keys", err)
+	}
+
+	return keys, nil
+}
+
+// Retire marks a key as retired; it keeps verifying tickets until expiresAt
+func (r *PostgresBusinessKeyRepository) Retire(ctx context.Context, id string, retiredAt, expiresAt int64) error {
+	query := `
+		UPDATE business_keys
+		SET status = $1, retired_at = $2, expires_at = $3
+		WHERE id = $4
+	`
+
+	result, err := r.db.DB(ctx).Exec(ctx, query, domain.KeyStatusRetired, retiredAt, expiresAt, id)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to retire business key", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewNotFound("business key")
+	}
+
+	return nil
+}
@@ -24,12 +24,12 @@ func NewPostgresBusinessRepository(db *database.Pool) *PostgresBusinessRepositor
 // Create creates a new business
 func (r *PostgresBusinessRepository) Create(ctx context.Context, b *domain.Business) error {
 	query := `
-		INSERT INTO businesses (id, name, email, password, role, hmac_key, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO businesses (id, name, email, password, role, hmac_key, email_verified_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, 0), $8, $9)
 	`
 
-	_, err := r.db.Exec(ctx, query,
-		b.ID, b.Name, b.Email, b.Password, b.Role, b.HMACKey, b.CreatedAt, b.UpdatedAt,
+	_, err := r.db.DB(ctx).Exec(ctx, query,
+		b.ID, b.Name, b.Email, b.Password, b.Role, b.HMACKey, b.EmailVerifiedAt, b.CreatedAt, b.UpdatedAt,
 	)
 
 	if err != nil {
@@ -45,13 +45,13 @@ func (r *PostgresBusinessRepository) Create(ctx context.Context, b *domain.Busin
 // FindByID finds a business by ID
 func (r *PostgresBusinessRepository) FindByID(ctx context.Context, id string) (*domain.Business, error) {
 	query := `
-		SELECT id, name, email, password, role, hmac_key, created_at, updated_at
+		SELECT id, name, email, password, role, hmac_key, COALESCE(email_verified_at, 0), created_at, updated_at
 		FROM businesses WHERE id = $1
 	`
 
 	b := &domain.Business{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&b.ID, &b.Name, &b.Email, &b.Password, &b.Role, &b.HMACKey, &b.CreatedAt, &b.UpdatedAt,
+	err := r.db.DB(ctx).QueryRow(ctx, query, id).Scan(
+		&b.ID, &b.Name, &b.Email, &b.Password, &b.Role, &b.HMACKey, &b.EmailVerifiedAt, &b.CreatedAt, &b.UpdatedAt,
 	)
 
 	if err != nil {
@@ -67,13 +67,13 @@ func (r *PostgresBusinessRepository) FindByID(ctx context.Context, id string) (*
 // FindByEmail finds a business by email
 func (r *PostgresBusinessRepository) FindByEmail(ctx context.Context, email string) (*domain.Business, error) {
 	query := `
-		SELECT id, name, email, password, role, hmac_key, created_at, updated_at
+		SELECT id, name, email, password, role, hmac_key, COALESCE(email_verified_at, 0), created_at, updated_at
 		FROM businesses WHERE email = $1
 	`
 
 	b := &domain.Business{}
-	err := r.db.QueryRow(ctx, query, email).Scan(
-		&b.ID, &b.Name, &b.Email, &b.Password, &b.Role, &b.HMACKey, &b.CreatedAt, &b.UpdatedAt,
+	err := r.db.DB(ctx).QueryRow(ctx, query, email).Scan(
+		&b.ID, &b.Name, &b.Email, &b.Password, &b.Role, &b.HMACKey, &b.EmailVerifiedAt, &b.CreatedAt, &b.UpdatedAt,
 	)
 
 	if err != nil {
@@ -90,17 +90,21 @@ func (r *PostgresBusinessRepository) FindByEmail(ctx context.Context, email stri
 func (r *PostgresBusinessRepository) Update(ctx context.Context, b *domain.Business) error {
 	query := `
 		UPDATE businesses
-		SET name = $2, email = $3, password = $4, hmac_key = $5, updated_at = NOW()
+		SET name = $2, email = $3, password = $4, hmac_key = $5, email_verified_at = NULLIF($6, 0), updated_at = $7
 		WHERE id = $1
 	`
 
-	_, err := r.db.Exec(ctx, query,
-		b.ID, b.Name, b.Email, b.Password, b.HMACKey,
+	result, err := r.db.DB(ctx).Exec(ctx, query,
+		b.ID, b.Name, b.Email, b.Password, b.HMACKey, b.EmailVerifiedAt, domain.NowTimestamp(),
 	)
 
 	if err != nil {
 		return apperror.NewDatabaseError("failed to update business", err)
 	}
 
+	if result.RowsAffected() == 0 {
+		return apperror.NewNotFound("business")
+	}
+
 	return nil
 }
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/database"
+	"CLOAKBE/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// businessTokenColumns is the select list matching scanBusinessToken
+const businessTokenColumns = `id, business_id, purpose, token_hash, expires_at, COALESCE(used_at, 0), created_at`
+
+func scanBusinessToken(row rowScanner, t *domain.BusinessToken) error {
+	return row.Scan(&t.ID, &t.BusinessID, &t.Purpose, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt)
+}
+
+// PostgresBusinessTokenRepository implements BusinessTokenRepository for PostgreSQL
+type PostgresBusinessTokenRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresBusinessTokenRepository creates a new business token repository
+func NewPostgresBusinessTokenRepository(db *database.Pool) *PostgresBusinessTokenRepository {
+	return &PostgresBusinessTokenRepository{db: db}
+}
+
+// Create inserts a new business token
+func (r *PostgresBusinessTokenRepository) Create(ctx context.Context, token *domain.BusinessToken) error {
+	query := `
+		INSERT INTO business_tokens (id, business_id, purpose, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.DB(ctx).Exec(ctx, query,
+		token.ID, token.BusinessID, token.Purpose, token.TokenHash, token.ExpiresAt, token.CreatedAt,
+	)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to create business token", err)
+	}
+
+	return nil
+}
+
+// FindByTokenHash retrieves a business token by the hash of the token
+func (r *PostgresBusinessTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.BusinessToken, error) {
+	return r.find(ctx, `SELECT `+businessTokenColumns+` FROM business_tokens WHERE token_hash = $1`, tokenHash)
+}
+
+// FindLatest returns the newest token of a purpose issued to a business
+func (r *PostgresBusinessTokenRepository) FindLatest(ctx context.Context, businessID, purpose string) (*domain.BusinessToken, error) {
+	query := `
+		SELECT ` + businessTokenColumns + ` FROM business_tokens
+		WHERE business_id = $1 AND purpose = $2
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+	return r.find(ctx, query, businessID, purpose)
+}
+
+// Use marks a token used, failing with a conflict if it already was
+func (r *PostgresBusinessTokenRepository) Use(ctx context.Context, id string, usedAt int64) error {
+	query := `UPDATE business_tokens SET used_at = $2 WHERE id = $1 AND used_at IS NULL`
+
+	result, err := r.db.DB(ctx).Exec(ctx, query, id, usedAt)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to use business token", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewConflict("token was already used")
+	}
+
+	return nil
+}
+
+// RetireByBusiness marks every unused token of a purpose used
+func (r *PostgresBusinessTokenRepository) RetireByBusiness(ctx context.Context, businessID, purpose string, usedAt int64) error {
+	query := `UPDATE business_tokens SET used_at = $3 WHERE business_id = $1 AND purpose = $2 AND used_at IS NULL`
+
+	if _, err := r.db.DB(ctx).Exec(ctx, query, businessID, purpose, usedAt); err != nil {
+		return apperror.NewDatabaseError("failed to retire business tokens", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresBusinessTokenRepository) find(ctx context.Context, query string, args ...any) (*domain.BusinessToken, error) {
+	token := &domain.BusinessToken{}
+	if err := scanBusinessToken(r.db.DB(ctx).QueryRow(ctx, query, args...), token); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("business token")
+		}
+		return nil, apperror.NewDatabaseError("failed to find business token", err)
+	}
+	return token, nil
+}
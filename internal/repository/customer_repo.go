@@ -12,6 +12,13 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
+// customerColumns is the select list matching scanCustomer
+const customerColumns = `id, COALESCE(email, ''), COALESCE(phone, ''), COALESCE(phone_verified_at, 0), created_at`
+
+func scanCustomer(row rowScanner, c *domain.Customer) error {
+	return row.Scan(&c.ID, &c.Email, &c.Phone, &c.PhoneVerifiedAt, &c.CreatedAt)
+}
+
 type PostgresCustomerRepository struct {
 	db *database.Pool
 }
@@ -22,15 +29,18 @@ func NewPostgresCustomerRepository(db *database.Pool) *PostgresCustomerRepositor
 
 func (r *PostgresCustomerRepository) Create(ctx context.Context, customer *domain.Customer) error {
 	query := `
-		INSERT INTO customers (id, email, phone, created_at)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO customers (id, email, phone, phone_verified_at, created_at)
+		VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, 0), $5)
 	`
 
-	_, err := r.db.Exec(ctx, query, customer.ID, customer.Email, customer.Phone, customer.CreatedAt)
+	_, err := r.db.DB(ctx).Exec(ctx, query, customer.ID, customer.Email, customer.Phone, customer.PhoneVerifiedAt, customer.CreatedAt)
 	if err != nil {
 		if err.Error() == "ERROR: duplicate key value violates unique constraint \"customers_email_key\" (SQLSTATE 23505)" {
 			return apperror.NewConflict("email already exists")
 		}
+		if err.Error() == "ERROR: duplicate key value violates unique constraint \"idx_customers_verified_phone\" (SQLSTATE 23505)" {
+			return apperror.NewConflict("phone already exists")
+		}
 		return apperror.NewDatabaseError("failed to create customer", err)
 	}
 
@@ -38,45 +48,16 @@ func (r *PostgresCustomerRepository) Create(ctx context.Context, customer *domai
 }
 
 func (r *PostgresCustomerRepository) FindByID(ctx context.Context, id string) (*domain.Customer, error) {
-	query := `
-		SELECT id, email, phone, created_at
-		FROM customers
-		WHERE id = $1
-	`
-
-	row := r.db.QueryRow(ctx, query, id)
-	customer := &domain.Customer{}
-
-	err := row.Scan(&customer.ID, &customer.Email, &customer.Phone, &customer.CreatedAt)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, apperror.NewNotFound("customer")
-		}
-		return nil, apperror.NewDatabaseError("failed to find customer", err)
-	}
-
-	return customer, nil
+	return r.find(ctx, `SELECT `+customerColumns+` FROM customers WHERE id = $1`, id)
 }
 
 func (r *PostgresCustomerRepository) FindByEmail(ctx context.Context, email string) (*domain.Customer, error) {
-	query := `
-		SELECT id, email, phone, created_at
-		FROM customers
-		WHERE email = $1
-	`
-
-	row := r.db.QueryRow(ctx, query, email)
-	customer := &domain.Customer{}
-
-	err := row.Scan(&customer.ID, &customer.Email, &customer.Phone, &customer.CreatedAt)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, apperror.NewNotFound("customer")
-		}
-		return nil, apperror.NewDatabaseError("failed to find customer", err)
-	}
+	return r.find(ctx, `SELECT `+customerColumns+` FROM customers WHERE email = $1`, email)
+}
 
-	return customer, nil
+// FindByVerifiedPhone retrieves the customer who proved ownership of a phone
+func (r *PostgresCustomerRepository) FindByVerifiedPhone(ctx context.Context, phone string) (*domain.Customer, error) {
+	return r.find(ctx, `SELECT `+customerColumns+` FROM customers WHERE phone = $1 AND phone_verified_at IS NOT NULL`, phone)
 }
 
 // FindOrCreate returns existing customer or creates new one (upsert pattern)
@@ -105,3 +86,15 @@ func (r *PostgresCustomerRepository) FindOrCreate(ctx context.Context, email, ph
 
 	return nil, err
 }
+
+func (r *PostgresCustomerRepository) find(ctx context.Context, query string, args ...any) (*domain.Customer, error) {
+	customer := &domain.Customer{}
+	if err := scanCustomer(r.db.DB(ctx).QueryRow(ctx, query, args...), customer); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("customer")
+		}
+		return nil, apperror.NewDatabaseError("failed to find customer", err)
+	}
+
+	return customer, nil
+}
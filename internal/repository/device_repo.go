@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/database"
+	"CLOAKBE/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// deviceColumns is the select list matching scanDevice
+const deviceColumns = `id, business_id, name, token_hash, service_ids, actions, COALESCE(last_seen_at, 0),
+		COALESCE(revoked_at, 0), created_at, updated_at`
+
+func scanDevice(row rowScanner, d *domain.Device) error {
+	return row.Scan(
+		&d.ID, &d.BusinessID, &d.Name, &d.TokenHash, &d.ServiceIDs, &d.Actions, &d.LastSeenAt,
+		&d.RevokedAt, &d.CreatedAt, &d.UpdatedAt,
+	)
+}
+
+// PostgresDeviceRepository implements DeviceRepository for PostgreSQL
+type PostgresDeviceRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresDeviceRepository creates a new device repository
+func NewPostgresDeviceRepository(db *database.Pool) *PostgresDeviceRepository {
+	return &PostgresDeviceRepository{db: db}
+}
+
+// Create inserts a new device
+func (r *PostgresDeviceRepository) Create(ctx context.Context, device *domain.Device) error {
+	query := `
+		INSERT INTO devices (id, business_id, name, token_hash, service_ids, actions, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.DB(ctx).Exec(ctx, query,
+		device.ID, device.BusinessID, device.Name, device.TokenHash, device.ServiceIDs, device.Actions,
+		device.CreatedAt, device.UpdatedAt,
+	)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to create device", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a device by ID
+func (r *PostgresDeviceRepository) FindByID(ctx context.Context, id string) (*domain.Device, error) {
+	return r.find(ctx, `SELECT `+deviceColumns+` FROM devices WHERE id = $1`, id)
+}
+
+// FindByTokenHash retrieves the device a token was issued to
+func (r *PostgresDeviceRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.Device, error) {
+	return r.find(ctx, `SELECT `+deviceColumns+` FROM devices WHERE token_hash = $1`, tokenHash)
+}
+
+// ListByBusinessID lists a business's devices, oldest first
+func (r *PostgresDeviceRepository) ListByBusinessID(ctx context.Context, businessID string) ([]domain.Device, error) {
+	query := `SELECT ` + deviceColumns + ` FROM devices WHERE business_id = $1 ORDER BY created_at ASC`
+
+	rows, err := r.db.DB(ctx).Query(ctx, query, businessID)
+	if err != nil {
+		return nil, apperror.NewDatabaseError("failed to list devices", err)
+	}
+	defer rows.Close()
+
+	devices := []domain.Device{}
+	for rows.Next() {
+		var device domain.Device
+		if err := scanDevice(rows, &device); err != nil {
+			return nil, apperror.NewDatabaseError("failed to scan device", err)
+		}
+		devices = append(devices, device)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDatabaseError("failed to iterate devices", err)
+	}
+
+	return devices, nil
+}
+
+// Update saves a device's name, scope and revocation
+func (r *PostgresDeviceRepository) Update(ctx context.Context, device *domain.Device) error {
+	query := `
+		UPDATE devices
+		SET name = $2, service_ids = $3, actions = $4, revoked_at = NULLIF($5, 0), updated_at = $6
+		WHERE id = $1
+	`
+
+	result, err := r.db.DB(ctx).Exec(ctx, query,
+		device.ID, device.Name, device.ServiceIDs, device.Actions, device.RevokedAt, device.UpdatedAt,
+	)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to update device", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewNotFound("device")
+	}
+
+	return nil
+}
+
+// UpdateLastSeen records when the device last made an authenticated request
+func (r *PostgresDeviceRepository) UpdateLastSeen(ctx context.Context, id string, lastSeenAt int64) error {
+	_, err := r.db.DB(ctx).Exec(ctx, `UPDATE devices SET last_seen_at = $2 WHERE id = $1`, id, lastSeenAt)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to update device last seen", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresDeviceRepository) find(ctx context.Context, query string, args ...any) (*domain.Device, error) {
+	d := &domain.Device{}
+	if err := scanDevice(r.db.DB(ctx).QueryRow(ctx, query, args...), d); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("device")
+		}
+		return nil, apperror.NewDatabaseError("failed to find device", err)
+	}
+	return d, nil
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/database"
+	"CLOAKBE/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// itemTypeColumns is the select list matching scanItemType
+const itemTypeColumns = `id, service_id, name, COALESCE(zone_id, ''), created_at, updated_at`
+
+func scanItemType(row rowScanner, t *domain.ItemType) error {
+	return row.Scan(&t.ID, &t.ServiceID, &t.Name, &t.ZoneID, &t.CreatedAt, &t.UpdatedAt)
+}
+
+// ticketItemColumns is the select list matching scanTicketItem
+const ticketItemColumns = `id, ticket_id, type, COALESCE(description, ''), COALESCE(count, 0), seq, created_at`
+
+func scanTicketItem(row rowScanner, i *domain.TicketItem) error {
+	return row.Scan(&i.ID, &i.TicketID, &i.Type, &i.Description, &i.Count, &i.Seq, &i.CreatedAt)
+}
+
+// PostgresItemTypeRepository implements ItemTypeRepository for PostgreSQL
+type PostgresItemTypeRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresItemTypeRepository creates a new item type repository
+func NewPostgresItemTypeRepository(db *database.Pool) *PostgresItemTypeRepository {
+	return &PostgresItemTypeRepository{db: db}
+}
+
+// Create inserts a new item type
+func (r *PostgresItemTypeRepository) Create(ctx context.Context, itemType *domain.ItemType) error {
+	query := `
+		INSERT INTO item_types (id, service_id, name, zone_id, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6)
+	`
+
+	_, err := r.db.DB(ctx).Exec(ctx, query,
+		itemType.ID, itemType.ServiceID, itemType.Name, itemType.ZoneID, itemType.CreatedAt, itemType.UpdatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return apperror.NewConflict("item type already exists for this service")
+		}
+		return apperror.NewDatabaseError("failed to create item type", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves an item type by ID
+func (r *PostgresItemTypeRepository) FindByID(ctx context.Context, id string) (*domain.ItemType, error) {
+	query := `SELECT ` + itemTypeColumns + ` FROM item_types WHERE id = $1`
+	return r.find(ctx, query, id)
+}
+
+// FindByName retrieves a service's item type by name
+func (r *PostgresItemTypeRepository) FindByName(ctx context.Context, serviceID, name string) (*domain.ItemType, error) {
+	query := `SELECT ` + itemTypeColumns + ` FROM item_types WHERE service_id = $1 AND name = $2`
+	return r.find(ctx, query, serviceID, name)
+}
+
+// ListByServiceID lists the item types of a service by name
+func (r *PostgresItemTypeRepository) ListByServiceID(ctx context.Context, serviceID string) ([]domain.ItemType, error) {
+	query := `SELECT ` + itemTypeColumns + ` FROM item_types WHERE service_id = $1 ORDER BY name ASC`
+
+	rows, err := r.db.DB(ctx).Query(ctx, query, serviceID)
+	if err != nil {
+		return nil, apperror.NewDatabaseError("failed to list item types", err)
+	}
+	defer rows.Close()
+
+	itemTypes := []domain.ItemType{}
+	for rows.Next() {
+		var t domain.ItemType
+		if err := scanItemType(rows, &t); err != nil {
+			return nil, apperror.NewDatabaseError("failed to scan item type", err)
+		}
+		itemTypes = append(itemTypes, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDatabaseError("failed to iterate item types", err)
+	}
+
+	return itemTypes, nil
+}
+
+// Delete removes an item type. Items already recorded under it keep their type name.
+func (r *PostgresItemTypeRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.DB(ctx).Exec(ctx, `DELETE FROM item_types WHERE id = $1`, id)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to delete item type", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewNotFound("item type")
+	}
+
+	return nil
+}
+
+func (r *PostgresItemTypeRepository) find(ctx context.Context, query string, args ...any) (*domain.ItemType, error) {
+	t := &domain.ItemType{}
+	if err := scanItemType(r.db.DB(ctx).QueryRow(ctx, query, args...), t); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("item type")
+		}
+		return nil, apperror.NewDatabaseError("failed to find item type", err)
+	}
+	return t, nil
+}
+
+// PostgresTicketItemRepository implements TicketItemRepository for PostgreSQL
+type PostgresTicketItemRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresTicketItemRepository creates a new ticket item repository
+func NewPostgresTicketItemRepository(db *database.Pool) *PostgresTicketItemRepository {
+	return &PostgresTicketItemRepository{db: db}
+}
+
+// CreateBatch inserts multiple items in order and sets their Seq
+func (r *PostgresTicketItemRepository) CreateBatch(ctx context.Context, items []domain.TicketItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	query := `
+		INSERT INTO ticket_items (id, ticket_id, type, description, count, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, 0), $6)
+		RETURNING seq
+	`
+
+	for _, item := range items {
+		batch.Queue(query, item.ID, item.TicketID, item.Type, item.Description, item.Count, item.CreatedAt)
+	}
+
+	results := r.db.DB(ctx).SendBatch(ctx, batch)
+	defer results.Close()
+
+	for i := range items {
+		if err := results.QueryRow().Scan(&items[i].Seq); err != nil {
+			return apperror.NewDatabaseError("failed to create ticket item in batch", err)
+		}
+	}
+
+	return nil
+}
+
+// FindByID retrieves a ticket item by ID
+func (r *PostgresTicketItemRepository) FindByID(ctx context.Context, id string) (*domain.TicketItem, error) {
+	query := `SELECT ` + ticketItemColumns + ` FROM ticket_items WHERE id = $1`
+
+	i := &domain.TicketItem{}
+	if err := scanTicketItem(r.db.DB(ctx).QueryRow(ctx, query, id), i); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("ticket item")
+		}
+		return nil, apperror.NewDatabaseError("failed to find ticket item", err)
+	}
+
+	return i, nil
+}
+
+// ListByTicketID lists a ticket's items in the order they were added
+func (r *PostgresTicketItemRepository) ListByTicketID(ctx context.Context, ticketID string) ([]domain.TicketItem, error) {
+	query := `SELECT ` + ticketItemColumns + ` FROM ticket_items WHERE ticket_id = $1 ORDER BY seq ASC`
+
+	rows, err := r.db.DB(ctx).Query(ctx, query, ticketID)
+	if err != nil {
+		return nil, apperror.NewDatabaseError("failed to list ticket items", err)
+	}
+	defer rows.Close()
+
+	items := []domain.TicketItem{}
+	for rows.Next() {
+		var i domain.TicketItem
+		if err := scanTicketItem(rows, &i); err != nil {
+			return nil, apperror.NewDatabaseError("failed to scan ticket item", err)
+		}
+		items = append(items, i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDatabaseError("failed to iterate ticket items", err)
+	}
+
+	return items, nil
+}
+
+// Delete removes a ticket item
+func (r *PostgresTicketItemRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.DB(ctx).Exec(ctx, `DELETE FROM ticket_items WHERE id = $1`, id)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to delete ticket item", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewNotFound("ticket item")
+	}
+
+	return nil
+}
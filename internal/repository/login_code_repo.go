@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/database"
+	"CLOAKBE/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// loginCodeColumns is the select list matching scanLoginCode
+const loginCodeColumns = `id, channel, destination, COALESCE(phone, ''), code_hash, link_hash, attempts, expires_at,
+		COALESCE(consumed_at, 0), created_at`
+
+func scanLoginCode(row rowScanner, c *domain.LoginCode) error {
+	return row.Scan(
+		&c.ID, &c.Channel, &c.Destination, &c.Phone, &c.CodeHash, &c.LinkHash, &c.Attempts, &c.ExpiresAt,
+		&c.ConsumedAt, &c.CreatedAt,
+	)
+}
+
+// PostgresLoginCodeRepository implements LoginCodeRepository for PostgreSQL
+type PostgresLoginCodeRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresLoginCodeRepository creates a new login code repository
+func NewPostgresLoginCodeRepository(db *database.Pool) *PostgresLoginCodeRepository {
+	return &PostgresLoginCodeRepository{db: db}
+}
+
+// Create inserts a new login code
+func (r *PostgresLoginCodeRepository) Create(ctx context.Context, code *domain.LoginCode) error {
+	query := `
+		INSERT INTO login_codes (id, channel, destination, phone, code_hash, link_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8)
+	`
+
+	_, err := r.db.DB(ctx).Exec(ctx, query,
+		code.ID, code.Channel, code.Destination, code.Phone, code.CodeHash, code.LinkHash, code.ExpiresAt, code.CreatedAt,
+	)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to create login code", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a login code by ID
+func (r *PostgresLoginCodeRepository) FindByID(ctx context.Context, id string) (*domain.LoginCode, error) {
+	return r.find(ctx, `SELECT `+loginCodeColumns+` FROM login_codes WHERE id = $1`, id)
+}
+
+// FindByLinkHash retrieves the login code a magic link belongs to
+func (r *PostgresLoginCodeRepository) FindByLinkHash(ctx context.Context, linkHash string) (*domain.LoginCode, error) {
+	return r.find(ctx, `SELECT `+loginCodeColumns+` FROM login_codes WHERE link_hash = $1`, linkHash)
+}
+
+// LockDestination holds a lock on a destination until the transaction
+// ends, so code requests for it take turns. The destination need not have a
+// row yet, so a transaction-level advisory lock on its hash is used.
+func (r *PostgresLoginCodeRepository) LockDestination(ctx context.Context, destination string) error {
+	query := `SELECT pg_advisory_xact_lock(hashtext('login_codes:' || $1))`
+
+	if _, err := r.db.DB(ctx).Exec(ctx, query, destination); err != nil {
+		return apperror.NewDatabaseError("failed to lock login destination", err)
+	}
+
+	return nil
+}
+
+// ListSince lists the codes sent to a destination since a time, newest first
+func (r *PostgresLoginCodeRepository) ListSince(ctx context.Context, destination string, since int64) ([]domain.LoginCode, error) {
+	query := `
+		SELECT ` + loginCodeColumns + ` FROM login_codes
+		WHERE destination = $1 AND created_at >= $2
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.DB(ctx).Query(ctx, query, destination, since)
+	if err != nil {
+		return nil, apperror.NewDatabaseError("failed to list login codes", err)
+	}
+	defer rows.Close()
+
+	codes := []domain.LoginCode{}
+	for rows.Next() {
+		var code domain.LoginCode
+		if err := scanLoginCode(rows, &code); err != nil {
+			return nil, apperror.NewDatabaseError("failed to scan login code", err)
+		}
+		codes = append(codes, code)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDatabaseError("failed to iterate login codes", err)
+	}
+
+	return codes, nil
+}
+
+// RecordAttempt counts a verification attempt on an unused code and
+// returns the new count, failing with a conflict once maxAttempts were made
+func (r *PostgresLoginCodeRepository) RecordAttempt(ctx context.Context, id string, maxAttempts int) (int, error) {
+	query := `
+		UPDATE login_codes SET attempts = attempts + 1
+		WHERE id = $1 AND consumed_at IS NULL AND attempts < $2
+		RETURNING attempts
+	`
+
+	var attempts int
+	if err := r.db.DB(ctx).QueryRow(ctx, query, id, maxAttempts).Scan(&attempts); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, apperror.NewConflict("login code is used up")
+		}
+		return 0, apperror.NewDatabaseError("failed to record login attempt", err)
+	}
+
+	return attempts, nil
+}
+
+// Consume marks a code used, failing with a conflict if it already was or
+// more than maxAttempts were made
+func (r *PostgresLoginCodeRepository) Consume(ctx context.Context, id string, consumedAt int64, maxAttempts int) error {
+	query := `UPDATE login_codes SET consumed_at = $2 WHERE id = $1 AND consumed_at IS NULL AND attempts <= $3`
+
+	result, err := r.db.DB(ctx).Exec(ctx, query, id, consumedAt, maxAttempts)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to consume login code", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewConflict("login code was already used")
+	}
+
+	return nil
+}
+
+// ConsumeByDestination retires every unused code of a destination
+func (r *PostgresLoginCodeRepository) ConsumeByDestination(ctx context.Context, destination string, consumedAt int64) error {
+	query := `UPDATE login_codes SET consumed_at = $2 WHERE destination = $1 AND consumed_at IS NULL`
+
+	if _, err := r.db.DB(ctx).Exec(ctx, query, destination, consumedAt); err != nil {
+		return apperror.NewDatabaseError("failed to retire login codes", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresLoginCodeRepository) find(ctx context.Context, query string, args ...any) (*domain.LoginCode, error) {
+	code := &domain.LoginCode{}
+	if err := scanLoginCode(r.db.DB(ctx).QueryRow(ctx, query, args...), code); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("login code")
+		}
+		return nil, apperror.NewDatabaseError("failed to find login code", err)
+	}
+	return code, nil
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"context"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// AuthSessionRepository implements AuthSessionRepository in memory
+type AuthSessionRepository struct {
+	store *Store
+}
+
+// NewAuthSessionRepository creates a new in-memory auth session repository
+func NewAuthSessionRepository(store *Store) *AuthSessionRepository {
+	return &AuthSessionRepository{store: store}
+}
+
+// Create inserts a new session
+func (r *AuthSessionRepository) Create(ctx context.Context, session *domain.AuthSession) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.sessions[session.ID]; ok {
+		return apperror.NewDatabaseError("failed to create session", errUniqueViolation("auth_sessions_pkey"))
+	}
+	if session.BusinessID != "" {
+		if _, ok := r.store.businesses[session.BusinessID]; !ok {
+			return apperror.NewDatabaseError("failed to create session", errForeignKey("business_id"))
+		}
+	}
+	if session.StaffID != "" {
+		if _, ok := r.store.staff[session.StaffID]; !ok {
+			return apperror.NewDatabaseError("failed to create session", errForeignKey("staff_id"))
+		}
+	}
+
+	put(ctx, r.store, r.store.sessions, session.ID, *session)
+	return nil
+}
+
+// FindByID retrieves a session by ID
+func (r *AuthSessionRepository) FindByID(ctx context.Context, id string) (*domain.AuthSession, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	session, ok := r.store.sessions[id]
+	if !ok {
+		return nil, apperror.NewNotFound("session")
+	}
+
+	return &session, nil
+}
+
+// Revoke revokes a session; revoking a revoked session is a no-op
+func (r *AuthSessionRepository) Revoke(ctx context.Context, id string, revokedAt int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	session, ok := r.store.sessions[id]
+	if !ok || session.IsRevoked() {
+		return nil
+	}
+
+	session.RevokedAt = revokedAt
+	session.UpdatedAt = revokedAt
+	put(ctx, r.store, r.store.sessions, id, session)
+	return nil
+}
+
+// RevokeBySubject revokes every live session of a subject and returns their IDs
+func (r *AuthSessionRepository) RevokeBySubject(ctx context.Context, subject string, revokedAt int64) ([]string, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ids := []string{}
+	for id, session := range r.store.sessions {
+		if session.Subject != subject || session.IsRevoked() {
+			continue
+		}
+		session.RevokedAt = revokedAt
+		session.UpdatedAt = revokedAt
+		put(ctx, r.store, r.store.sessions, id, session)
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
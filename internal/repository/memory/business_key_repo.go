@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// BusinessKeyRepository implements BusinessKeyRepository in memory
+type BusinessKeyRepository struct {
+	store *Store
+}
+
+// NewBusinessKeyRepository creates a new in-memory business key repository
+func NewBusinessKeyRepository(store *Store) *BusinessKeyRepository {
+	return &BusinessKeyRepository{store: store}
+}
+
+// Create inserts a new signing key
+func (r *BusinessKeyRepository) Create(ctx context.Context, key *domain.BusinessKey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.keys[key.ID]; ok {
+		return apperror.NewDatabaseError("failed to create business key", errUniqueViolation("business_keys_pkey"))
+	}
+	if _, ok := r.store.businesses[key.BusinessID]; !ok {
+		return apperror.NewDatabaseError("failed to create business key", errForeignKey("business_id"))
+	}
+	if key.Status == domain.KeyStatusActive {
+		if _, ok := r.findActive(key.BusinessID, key.Algorithm); ok {
+			return apperror.NewConflict("business already has an active signing key")
+		}
+	}
+
+	put(ctx, r.store, r.store.keys, key.ID, *key)
+	return nil
+}
+
+// FindByID finds a signing key by its kid
+func (r *BusinessKeyRepository) FindByID(ctx context.Context, id string) (*domain.BusinessKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	key, ok := r.store.keys[id]
+	if !ok {
+		return nil, apperror.NewNotFound("business key")
+	}
+
+	return &key, nil
+}
+
+// FindActiveByBusinessID finds the key currently used to sign new tickets with algorithm
+func (r *BusinessKeyRepository) FindActiveByBusinessID(ctx context.Context, businessID, algorithm string) (*domain.BusinessKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	key, ok := r.findActive(businessID, algorithm)
+	if !ok {
+		return nil, apperror.NewNotFound("active business key")
+	}
+
+	return &key, nil
+}
+
+func (r *BusinessKeyRepository) findActive(businessID, algorithm string) (domain.BusinessKey, bool) {
+	for _, key := range r.store.keys {
+		if key.BusinessID == businessID && key.Algorithm == algorithm && key.Status == domain.KeyStatusActive {
+			return key, true
+		}
+	}
+	return domain.BusinessKey{}, false
+}
+
+// ListByBusinessID lists a business's keyring, newest first
+func (r *BusinessKeyRepository) ListByBusinessID(ctx context.Context, businessID string) ([]domain.BusinessKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	keys := []domain.BusinessKey{}
+	for _, key := range r.store.keys {
+		if key.BusinessID == businessID {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt > keys[j].CreatedAt
+	})
+
+	return keys, nil
+}
+
+// Retire marks a key as retired; it keeps verifying tickets until expiresAt
+func (r *BusinessKeyRepository) Retire(ctx context.Context, id string, retiredAt, expiresAt int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key, ok := r.store.keys[id]
+	if !ok {
+		return apperror.NewNotFound("business key")
+	}
+
+	key.Status = domain.KeyStatusRetired
+	key.RetiredAt = retiredAt
+	key.ExpiresAt = expiresAt
+	put(ctx, r.store, r.store.keys, id, key)
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// BusinessRepository implements BusinessRepository in memory
+type BusinessRepository struct {
+	store *Store
+}
+
+// NewBusinessRepository creates a new in-memory business repository
+func NewBusinessRepository(store *Store) *BusinessRepository {
+	return &BusinessRepository{store: store}
+}
+
+// Create creates a new business
+func (r *BusinessRepository) Create(ctx context.Context, b *domain.Business) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.businesses {
+		if existing.Email == b.Email {
+			return apperror.NewConflict("email already registered")
+		}
+	}
+
+	put(ctx, r.store, r.store.businesses, b.ID, *b)
+	return nil
+}
+
+// FindByID finds a business by ID
+func (r *BusinessRepository) FindByID(ctx context.Context, id string) (*domain.Business, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	b, ok := r.store.businesses[id]
+	if !ok {
+		return nil, apperror.NewNotFound("business")
+	}
+
+	return &b, nil
+}
+
+// FindByEmail finds a business by email
+func (r *BusinessRepository) FindByEmail(ctx context.Context, email string) (*domain.Business, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, b := range r.store.businesses {
+		if b.Email == email {
+			return &b, nil
+		}
+	}
+
+	return nil, apperror.NewNotFound("business")
+}
+
+// Update updates a business
+func (r *BusinessRepository) Update(ctx context.Context, b *domain.Business) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.businesses[b.ID]
+	if !ok {
+		return apperror.NewNotFound("business")
+	}
+
+	existing.Name = b.Name
+	existing.Email = b.Email
+	existing.Password = b.Password
+	existing.HMACKey = b.HMACKey
+	existing.EmailVerifiedAt = b.EmailVerifiedAt
+	existing.UpdatedAt = domain.NowTimestamp()
+
+	put(ctx, r.store, r.store.businesses, b.ID, existing)
+	return nil
+}
@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// BusinessTokenRepository implements BusinessTokenRepository in memory
+type BusinessTokenRepository struct {
+	store *Store
+}
+
+// NewBusinessTokenRepository creates a new in-memory business token repository
+func NewBusinessTokenRepository(store *Store) *BusinessTokenRepository {
+	return &BusinessTokenRepository{store: store}
+}
+
+// Create inserts a new business token
+func (r *BusinessTokenRepository) Create(ctx context.Context, token *domain.BusinessToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.businesses[token.BusinessID]; !ok {
+		return apperror.NewDatabaseError("failed to create business token", errForeignKey("business_id"))
+	}
+	if _, ok := r.store.businessTokens[token.ID]; ok {
+		return apperror.NewDatabaseError("failed to create business token", errUniqueViolation("business_tokens_pkey"))
+	}
+	for _, existing := range r.store.businessTokens {
+		if existing.TokenHash == token.TokenHash {
+			return apperror.NewDatabaseError("failed to create business token", errUniqueViolation("business_tokens_token_hash_key"))
+		}
+	}
+
+	put(ctx, r.store, r.store.businessTokens, token.ID, *token)
+	return nil
+}
+
+// FindByTokenHash retrieves a business token by the hash of the token
+func (r *BusinessTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.BusinessToken, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, token := range r.store.businessTokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+
+	return nil, apperror.NewNotFound("business token")
+}
+
+// FindLatest returns the newest token of a purpose issued to a business
+func (r *BusinessTokenRepository) FindLatest(ctx context.Context, businessID, purpose string) (*domain.BusinessToken, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var latest *domain.BusinessToken
+	for _, token := range r.store.businessTokens {
+		if token.BusinessID != businessID || token.Purpose != purpose {
+			continue
+		}
+		if latest == nil || token.CreatedAt > latest.CreatedAt {
+			token := token
+			latest = &token
+		}
+	}
+
+	if latest == nil {
+		return nil, apperror.NewNotFound("business token")
+	}
+
+	return latest, nil
+}
+
+// Use marks a token used, failing with a conflict if it already was
+func (r *BusinessTokenRepository) Use(ctx context.Context, id string, usedAt int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	token, ok := r.store.businessTokens[id]
+	if !ok || token.UsedAt != 0 {
+		return apperror.NewConflict("token was already used")
+	}
+
+	token.UsedAt = usedAt
+	put(ctx, r.store, r.store.businessTokens, id, token)
+	return nil
+}
+
+// RetireByBusiness marks every unused token of a purpose used
+func (r *BusinessTokenRepository) RetireByBusiness(ctx context.Context, businessID, purpose string, usedAt int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, token := range r.store.businessTokens {
+		if token.BusinessID == businessID && token.Purpose == purpose && token.UsedAt == 0 {
+			token.UsedAt = usedAt
+			put(ctx, r.store, r.store.businessTokens, id, token)
+		}
+	}
+
+	return nil
+}
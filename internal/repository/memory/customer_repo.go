@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"context"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// CustomerRepository implements CustomerRepository in memory
+type CustomerRepository struct {
+	store *Store
+}
+
+// NewCustomerRepository creates a new in-memory customer repository
+func NewCustomerRepository(store *Store) *CustomerRepository {
+	return &CustomerRepository{store: store}
+}
+
+func (r *CustomerRepository) Create(ctx context.Context, customer *domain.Customer) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.create(ctx, customer)
+}
+
+// create inserts a customer; callers must hold the write lock
+func (r *CustomerRepository) create(ctx context.Context, customer *domain.Customer) error {
+	if customer.Email != "" {
+		if _, ok := r.findByEmail(customer.Email); ok {
+			return apperror.NewConflict("email already exists")
+		}
+	}
+	if customer.PhoneVerifiedAt != 0 {
+		if _, ok := r.findByVerifiedPhone(customer.Phone); ok {
+			return apperror.NewConflict("phone already exists")
+		}
+	}
+
+	put(ctx, r.store, r.store.customers, customer.ID, *customer)
+	return nil
+}
+
+func (r *CustomerRepository) FindByID(ctx context.Context, id string) (*domain.Customer, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	customer, ok := r.store.customers[id]
+	if !ok {
+		return nil, apperror.NewNotFound("customer")
+	}
+
+	return &customer, nil
+}
+
+func (r *CustomerRepository) FindByEmail(ctx context.Context, email string) (*domain.Customer, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	customer, ok := r.findByEmail(email)
+	if !ok {
+		return nil, apperror.NewNotFound("customer")
+	}
+
+	return &customer, nil
+}
+
+// FindByVerifiedPhone retrieves the customer who proved ownership of a phone
+func (r *CustomerRepository) FindByVerifiedPhone(ctx context.Context, phone string) (*domain.Customer, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	customer, ok := r.findByVerifiedPhone(phone)
+	if !ok {
+		return nil, apperror.NewNotFound("customer")
+	}
+
+	return &customer, nil
+}
+
+func (r *CustomerRepository) findByVerifiedPhone(phone string) (domain.Customer, bool) {
+	for _, customer := range r.store.customers {
+		if customer.PhoneVerifiedAt != 0 && customer.Phone == phone {
+			return customer, true
+		}
+	}
+	return domain.Customer{}, false
+}
+
+func (r *CustomerRepository) findByEmail(email string) (domain.Customer, bool) {
+	if email == "" {
+		// NULL in the customers table never matches
+		return domain.Customer{}, false
+	}
+	for _, customer := range r.store.customers {
+		if customer.Email == email {
+			return customer, true
+		}
+	}
+	return domain.Customer{}, false
+}
+
+// FindOrCreate returns existing customer or creates new one (upsert pattern)
+func (r *CustomerRepository) FindOrCreate(ctx context.Context, email, phone string) (*domain.Customer, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if customer, ok := r.findByEmail(email); ok {
+		return &customer, nil
+	}
+
+	newCustomer := &domain.Customer{
+		ID:        uuid.New().String(),
+		Email:     email,
+		Phone:     phone,
+		CreatedAt: domain.NowTimestamp(),
+	}
+
+	if err := r.create(ctx, newCustomer); err != nil {
+		return nil, err
+	}
+
+	return newCustomer, nil
+}
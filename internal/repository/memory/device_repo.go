@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// DeviceRepository implements DeviceRepository in memory
+type DeviceRepository struct {
+	store *Store
+}
+
+// NewDeviceRepository creates a new in-memory device repository
+func NewDeviceRepository(store *Store) *DeviceRepository {
+	return &DeviceRepository{store: store}
+}
+
+// Create inserts a new device
+func (r *DeviceRepository) Create(ctx context.Context, device *domain.Device) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.devices[device.ID]; ok {
+		return apperror.NewDatabaseError("failed to create device", errUniqueViolation("devices_pkey"))
+	}
+	if _, ok := r.store.businesses[device.BusinessID]; !ok {
+		return apperror.NewDatabaseError("failed to create device", errForeignKey("business_id"))
+	}
+	for _, existing := range r.store.devices {
+		if existing.TokenHash == device.TokenHash {
+			return apperror.NewDatabaseError("failed to create device", errUniqueViolation("devices_token_hash_key"))
+		}
+	}
+
+	put(ctx, r.store, r.store.devices, device.ID, cloneDevice(*device))
+	return nil
+}
+
+// FindByID retrieves a device by ID
+func (r *DeviceRepository) FindByID(ctx context.Context, id string) (*domain.Device, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	device, ok := r.store.devices[id]
+	if !ok {
+		return nil, apperror.NewNotFound("device")
+	}
+
+	device = cloneDevice(device)
+	return &device, nil
+}
+
+// FindByTokenHash retrieves the device a token was issued to
+func (r *DeviceRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.Device, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, device := range r.store.devices {
+		if device.TokenHash == tokenHash {
+			device = cloneDevice(device)
+			return &device, nil
+		}
+	}
+
+	return nil, apperror.NewNotFound("device")
+}
+
+// ListByBusinessID lists a business's devices, oldest first
+func (r *DeviceRepository) ListByBusinessID(ctx context.Context, businessID string) ([]domain.Device, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	devices := []domain.Device{}
+	for _, device := range r.store.devices {
+		if device.BusinessID == businessID {
+			devices = append(devices, cloneDevice(device))
+		}
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].CreatedAt < devices[j].CreatedAt
+	})
+
+	return devices, nil
+}
+
+// Update saves a device's name, scope and revocation
+func (r *DeviceRepository) Update(ctx context.Context, device *domain.Device) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.devices[device.ID]
+	if !ok {
+		return apperror.NewNotFound("device")
+	}
+
+	existing.Name = device.Name
+	existing.ServiceIDs = append([]string{}, device.ServiceIDs...)
+	existing.Actions = append([]string{}, device.Actions...)
+	existing.RevokedAt = device.RevokedAt
+	existing.UpdatedAt = device.UpdatedAt
+
+	put(ctx, r.store, r.store.devices, device.ID, existing)
+	return nil
+}
+
+// UpdateLastSeen records when the device last made an authenticated request
+func (r *DeviceRepository) UpdateLastSeen(ctx context.Context, id string, lastSeenAt int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	device, ok := r.store.devices[id]
+	if !ok {
+		return nil
+	}
+
+	device.LastSeenAt = lastSeenAt
+	put(ctx, r.store, r.store.devices, id, device)
+	return nil
+}
+
+// cloneDevice copies a device so callers cannot modify the stored slices
+func cloneDevice(device domain.Device) domain.Device {
+	device.ServiceIDs = append([]string{}, device.ServiceIDs...)
+	device.Actions = append([]string{}, device.Actions...)
+	return device
+}
@@ -0,0 +1,187 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// ItemTypeRepository implements ItemTypeRepository in memory
+type ItemTypeRepository struct {
+	store *Store
+}
+
+// NewItemTypeRepository creates a new in-memory item type repository
+func NewItemTypeRepository(store *Store) *ItemTypeRepository {
+	return &ItemTypeRepository{store: store}
+}
+
+// Create inserts a new item type
+func (r *ItemTypeRepository) Create(ctx context.Context, itemType *domain.ItemType) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.itemTypes[itemType.ID]; ok {
+		return apperror.NewDatabaseError("failed to create item type", errUniqueViolation("item_types_pkey"))
+	}
+	if _, ok := r.store.services[itemType.ServiceID]; !ok {
+		return apperror.NewDatabaseError("failed to create item type", errForeignKey("service_id"))
+	}
+	if itemType.ZoneID != "" {
+		if _, ok := r.store.zones[itemType.ZoneID]; !ok {
+			return apperror.NewDatabaseError("failed to create item type", errForeignKey("zone_id"))
+		}
+	}
+	for _, existing := range r.store.itemTypes {
+		if existing.ServiceID == itemType.ServiceID && existing.Name == itemType.Name {
+			return apperror.NewConflict("item type already exists for this service")
+		}
+	}
+
+	put(ctx, r.store, r.store.itemTypes, itemType.ID, *itemType)
+	return nil
+}
+
+// FindByID retrieves an item type by ID
+func (r *ItemTypeRepository) FindByID(ctx context.Context, id string) (*domain.ItemType, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	itemType, ok := r.store.itemTypes[id]
+	if !ok {
+		return nil, apperror.NewNotFound("item type")
+	}
+
+	return &itemType, nil
+}
+
+// FindByName retrieves a service's item type by name
+func (r *ItemTypeRepository) FindByName(ctx context.Context, serviceID, name string) (*domain.ItemType, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, itemType := range r.store.itemTypes {
+		if itemType.ServiceID == serviceID && itemType.Name == name {
+			return &itemType, nil
+		}
+	}
+
+	return nil, apperror.NewNotFound("item type")
+}
+
+// ListByServiceID lists the item types of a service by name
+func (r *ItemTypeRepository) ListByServiceID(ctx context.Context, serviceID string) ([]domain.ItemType, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	itemTypes := []domain.ItemType{}
+	for _, itemType := range r.store.itemTypes {
+		if itemType.ServiceID == serviceID {
+			itemTypes = append(itemTypes, itemType)
+		}
+	}
+
+	sort.Slice(itemTypes, func(i, j int) bool {
+		return itemTypes[i].Name < itemTypes[j].Name
+	})
+
+	return itemTypes, nil
+}
+
+// Delete removes an item type. Items already recorded under it keep their type name.
+func (r *ItemTypeRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.itemTypes[id]; !ok {
+		return apperror.NewNotFound("item type")
+	}
+
+	remove(ctx, r.store, r.store.itemTypes, id)
+	return nil
+}
+
+// TicketItemRepository implements TicketItemRepository in memory
+type TicketItemRepository struct {
+	store *Store
+}
+
+// NewTicketItemRepository creates a new in-memory ticket item repository
+func NewTicketItemRepository(store *Store) *TicketItemRepository {
+	return &TicketItemRepository{store: store}
+}
+
+// CreateBatch inserts multiple items in order and sets their Seq; either all
+// of them are stored or none
+func (r *TicketItemRepository) CreateBatch(ctx context.Context, items []domain.TicketItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, item := range items {
+		if _, ok := r.store.ticketItems[item.ID]; ok {
+			return apperror.NewDatabaseError("failed to create ticket item in batch", errUniqueViolation("ticket_items_pkey"))
+		}
+		if _, ok := r.store.tickets[item.TicketID]; !ok {
+			return apperror.NewDatabaseError("failed to create ticket item in batch", errForeignKey("ticket_id"))
+		}
+	}
+
+	for i := range items {
+		r.store.ticketItemSeq++
+		items[i].Seq = r.store.ticketItemSeq
+		put(ctx, r.store, r.store.ticketItems, items[i].ID, items[i])
+	}
+
+	return nil
+}
+
+// FindByID retrieves a ticket item by ID
+func (r *TicketItemRepository) FindByID(ctx context.Context, id string) (*domain.TicketItem, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	item, ok := r.store.ticketItems[id]
+	if !ok {
+		return nil, apperror.NewNotFound("ticket item")
+	}
+
+	return &item, nil
+}
+
+// ListByTicketID lists a ticket's items in the order they were added
+func (r *TicketItemRepository) ListByTicketID(ctx context.Context, ticketID string) ([]domain.TicketItem, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	items := []domain.TicketItem{}
+	for _, item := range r.store.ticketItems {
+		if item.TicketID == ticketID {
+			items = append(items, item)
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Seq < items[j].Seq
+	})
+
+	return items, nil
+}
+
+// Delete removes a ticket item
+func (r *TicketItemRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.ticketItems[id]; !ok {
+		return apperror.NewNotFound("ticket item")
+	}
+
+	remove(ctx, r.store, r.store.ticketItems, id)
+	return nil
+}
@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// LoginCodeRepository implements LoginCodeRepository in memory
+type LoginCodeRepository struct {
+	store *Store
+}
+
+// NewLoginCodeRepository creates a new in-memory login code repository
+func NewLoginCodeRepository(store *Store) *LoginCodeRepository {
+	return &LoginCodeRepository{store: store}
+}
+
+// Create inserts a new login code
+func (r *LoginCodeRepository) Create(ctx context.Context, code *domain.LoginCode) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.loginCodes[code.ID]; ok {
+		return apperror.NewDatabaseError("failed to create login code", errUniqueViolation("login_codes_pkey"))
+	}
+	for _, existing := range r.store.loginCodes {
+		if existing.LinkHash == code.LinkHash {
+			return apperror.NewDatabaseError("failed to create login code", errUniqueViolation("login_codes_link_hash_key"))
+		}
+	}
+
+	put(ctx, r.store, r.store.loginCodes, code.ID, *code)
+	return nil
+}
+
+// FindByID retrieves a login code by ID
+func (r *LoginCodeRepository) FindByID(ctx context.Context, id string) (*domain.LoginCode, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	code, ok := r.store.loginCodes[id]
+	if !ok {
+		return nil, apperror.NewNotFound("login code")
+	}
+
+	return &code, nil
+}
+
+// FindByLinkHash retrieves the login code a magic link belongs to
+func (r *LoginCodeRepository) FindByLinkHash(ctx context.Context, linkHash string) (*domain.LoginCode, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, code := range r.store.loginCodes {
+		if code.LinkHash == linkHash {
+			return &code, nil
+		}
+	}
+
+	return nil, apperror.NewNotFound("login code")
+}
+
+// LockDestination is a no-op: units of work on the store already run one
+// at a time
+func (r *LoginCodeRepository) LockDestination(ctx context.Context, destination string) error {
+	return nil
+}
+
+// ListSince lists the codes sent to a destination since a time, newest first
+func (r *LoginCodeRepository) ListSince(ctx context.Context, destination string, since int64) ([]domain.LoginCode, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	codes := []domain.LoginCode{}
+	for _, code := range r.store.loginCodes {
+		if code.Destination == destination && code.CreatedAt >= since {
+			codes = append(codes, code)
+		}
+	}
+
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i].CreatedAt > codes[j].CreatedAt
+	})
+
+	return codes, nil
+}
+
+// RecordAttempt counts a verification attempt on an unused code and
+// returns the new count, failing with a conflict once maxAttempts were made
+func (r *LoginCodeRepository) RecordAttempt(ctx context.Context, id string, maxAttempts int) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	code, ok := r.store.loginCodes[id]
+	if !ok || code.ConsumedAt != 0 || code.Attempts >= maxAttempts {
+		return 0, apperror.NewConflict("login code is used up")
+	}
+
+	code.Attempts++
+	put(ctx, r.store, r.store.loginCodes, id, code)
+	return code.Attempts, nil
+}
+
+// Consume marks a code used, failing with a conflict if it already was or
+// more than maxAttempts were made
+func (r *LoginCodeRepository) Consume(ctx context.Context, id string, consumedAt int64, maxAttempts int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	code, ok := r.store.loginCodes[id]
+	if !ok || code.ConsumedAt != 0 || code.Attempts > maxAttempts {
+		return apperror.NewConflict("login code was already used")
+	}
+
+	code.ConsumedAt = consumedAt
+	put(ctx, r.store, r.store.loginCodes, id, code)
+	return nil
+}
+
+// ConsumeByDestination retires every unused code of a destination
+func (r *LoginCodeRepository) ConsumeByDestination(ctx context.Context, destination string, consumedAt int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, code := range r.store.loginCodes {
+		if code.Destination == destination && code.ConsumedAt == 0 {
+			code.ConsumedAt = consumedAt
+			put(ctx, r.store, r.store.loginCodes, id, code)
+		}
+	}
+
+	return nil
+}
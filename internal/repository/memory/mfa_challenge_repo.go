@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// MFAChallengeRepository implements MFAChallengeRepository in memory
+type MFAChallengeRepository struct {
+	store *Store
+}
+
+// NewMFAChallengeRepository creates a new in-memory login challenge repository
+func NewMFAChallengeRepository(store *Store) *MFAChallengeRepository {
+	return &MFAChallengeRepository{store: store}
+}
+
+// Create inserts a new login challenge
+func (r *MFAChallengeRepository) Create(ctx context.Context, c *domain.MFAChallenge) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.mfaChallenges[c.ID]; ok {
+		return apperror.NewDatabaseError("failed to create login challenge", errUniqueViolation("mfa_challenges_pkey"))
+	}
+	for _, existing := range r.store.mfaChallenges {
+		if existing.TokenHash == c.TokenHash {
+			return apperror.NewDatabaseError("failed to create login challenge", errUniqueViolation("mfa_challenges_token_hash_key"))
+		}
+	}
+
+	put(ctx, r.store, r.store.mfaChallenges, c.ID, *c)
+	return nil
+}
+
+// FindByTokenHash retrieves a login challenge by the hash of its token
+func (r *MFAChallengeRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.MFAChallenge, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, c := range r.store.mfaChallenges {
+		if c.TokenHash == tokenHash {
+			return &c, nil
+		}
+	}
+
+	return nil, apperror.NewNotFound("login challenge")
+}
+
+// RecordAttempt counts a code check on an unused challenge and returns
+// the new count, failing with a conflict once maxAttempts were made
+func (r *MFAChallengeRepository) RecordAttempt(ctx context.Context, id string, maxAttempts int) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	c, ok := r.store.mfaChallenges[id]
+	if !ok || c.UsedAt != 0 || c.Attempts >= maxAttempts {
+		return 0, apperror.NewConflict("login challenge is used up")
+	}
+
+	c.Attempts++
+	put(ctx, r.store, r.store.mfaChallenges, id, c)
+	return c.Attempts, nil
+}
+
+// Use marks a challenge completed, failing with a conflict if it already was
+func (r *MFAChallengeRepository) Use(ctx context.Context, id string, usedAt int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	c, ok := r.store.mfaChallenges[id]
+	if !ok || c.UsedAt != 0 {
+		return apperror.NewConflict("login challenge was already completed")
+	}
+
+	c.UsedAt = usedAt
+	put(ctx, r.store, r.store.mfaChallenges, id, c)
+	return nil
+}
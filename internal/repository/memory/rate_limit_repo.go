@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"context"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// RateLimitRepository implements RateLimitRepository in memory
+type RateLimitRepository struct {
+	store *Store
+}
+
+// NewRateLimitRepository creates a new in-memory rate limit repository
+func NewRateLimitRepository(store *Store) *RateLimitRepository {
+	return &RateLimitRepository{store: store}
+}
+
+// Hit counts a request under key and returns the updated counter
+func (r *RateLimitRepository) Hit(ctx context.Context, key string, window int64, now int64) (*domain.RateLimitCounter, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	counter, ok := r.store.rateLimits[key]
+	if !ok || counter.ResetAt <= now {
+		counter = domain.RateLimitCounter{Key: key, ResetAt: now + window}
+	}
+
+	counter.Count++
+	put(ctx, r.store, r.store.rateLimits, key, counter)
+	return &counter, nil
+}
+
+// DeleteExpired removes counters whose window ended before now
+func (r *RateLimitRepository) DeleteExpired(ctx context.Context, now int64) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var deleted int64
+	for key, counter := range r.store.rateLimits {
+		if counter.ResetAt <= now {
+			remove(ctx, r.store, r.store.rateLimits, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// LoginLockoutRepository implements LoginLockoutRepository in memory
+type LoginLockoutRepository struct {
+	store *Store
+}
+
+// NewLoginLockoutRepository creates a new in-memory login lockout repository
+func NewLoginLockoutRepository(store *Store) *LoginLockoutRepository {
+	return &LoginLockoutRepository{store: store}
+}
+
+// FindByKey retrieves the lockout of an account
+func (r *LoginLockoutRepository) FindByKey(ctx context.Context, key string) (*domain.LoginLockout, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	lockout, ok := r.store.loginLockouts[key]
+	if !ok {
+		return nil, apperror.NewNotFound("login lockout")
+	}
+
+	return &lockout, nil
+}
+
+// RecordFailure counts a bad password, starting over when the last one is
+// older than resetBefore, and returns the updated lockout
+func (r *LoginLockoutRepository) RecordFailure(ctx context.Context, key string, now, resetBefore int64) (*domain.LoginLockout, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	lockout, ok := r.store.loginLockouts[key]
+	if !ok {
+		lockout = domain.LoginLockout{Key: key}
+	}
+	if lockout.LastFailureAt < resetBefore {
+		lockout.Failures = 0
+	}
+
+	lockout.Failures++
+	lockout.LastFailureAt = now
+	put(ctx, r.store, r.store.loginLockouts, key, lockout)
+	return &lockout, nil
+}
+
+// Lock refuses logins to an account until a time
+func (r *LoginLockoutRepository) Lock(ctx context.Context, key string, until int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	lockout, ok := r.store.loginLockouts[key]
+	if !ok {
+		return apperror.NewNotFound("login lockout")
+	}
+
+	lockout.LockedUntil = until
+	put(ctx, r.store, r.store.loginLockouts, key, lockout)
+	return nil
+}
+
+// Delete forgets the failures of an account
+func (r *LoginLockoutRepository) Delete(ctx context.Context, key string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	remove(ctx, r.store, r.store.loginLockouts, key)
+	return nil
+}
+
+// DeleteStale removes lockouts whose last failure is older than before and
+// that are no longer locked
+func (r *LoginLockoutRepository) DeleteStale(ctx context.Context, before, now int64) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var deleted int64
+	for key, lockout := range r.store.loginLockouts {
+		if lockout.LastFailureAt < before && lockout.LockedUntil <= now {
+			remove(ctx, r.store, r.store.loginLockouts, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// RefreshTokenRepository implements RefreshTokenRepository in memory
+type RefreshTokenRepository struct {
+	store *Store
+}
+
+// NewRefreshTokenRepository creates a new in-memory refresh token repository
+func NewRefreshTokenRepository(store *Store) *RefreshTokenRepository {
+	return &RefreshTokenRepository{store: store}
+}
+
+// Create inserts a new refresh token
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.refreshTokens[token.ID]; ok {
+		return apperror.NewDatabaseError("failed to create refresh token", errUniqueViolation("refresh_tokens_pkey"))
+	}
+	if _, ok := r.store.sessions[token.SessionID]; !ok {
+		return apperror.NewDatabaseError("failed to create refresh token", errForeignKey("session_id"))
+	}
+	for _, existing := range r.store.refreshTokens {
+		if existing.TokenHash == token.TokenHash {
+			return apperror.NewDatabaseError("failed to create refresh token", errUniqueViolation("refresh_tokens_token_hash_key"))
+		}
+	}
+
+	put(ctx, r.store, r.store.refreshTokens, token.ID, *token)
+	return nil
+}
+
+// FindByTokenHash retrieves a refresh token by its hash
+func (r *RefreshTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, token := range r.store.refreshTokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+
+	return nil, apperror.NewNotFound("refresh token")
+}
+
+// MarkRotated records that a token was exchanged, failing with a conflict if
+// it already was
+func (r *RefreshTokenRepository) MarkRotated(ctx context.Context, id string, rotatedAt int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	token, ok := r.store.refreshTokens[id]
+	if !ok || token.RotatedAt != 0 {
+		return apperror.NewConflict("refresh token was already used")
+	}
+
+	token.RotatedAt = rotatedAt
+	put(ctx, r.store, r.store.refreshTokens, id, token)
+	return nil
+}
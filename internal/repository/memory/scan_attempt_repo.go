@@ -0,0 +1,222 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// ScanAttemptRepository implements ScanAttemptRepository in memory
+type ScanAttemptRepository struct {
+	store *Store
+}
+
+// NewScanAttemptRepository creates a new in-memory scan attempt repository
+func NewScanAttemptRepository(store *Store) *ScanAttemptRepository {
+	return &ScanAttemptRepository{store: store}
+}
+
+// Create appends an attempt and sets its Seq
+func (r *ScanAttemptRepository) Create(ctx context.Context, attempt *domain.ScanAttempt) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.scanAttempts[attempt.ID]; ok {
+		return apperror.NewDatabaseError("failed to create scan attempt", errUniqueViolation("scan_attempts_pkey"))
+	}
+	if _, ok := r.store.businesses[attempt.BusinessID]; !ok {
+		return apperror.NewDatabaseError("failed to create scan attempt", errForeignKey("business_id"))
+	}
+
+	r.store.scanAttemptSeq++
+	attempt.Seq = r.store.scanAttemptSeq
+
+	put(ctx, r.store, r.store.scanAttempts, attempt.ID, *attempt)
+	return nil
+}
+
+// List lists attempts matching filter in the order they were written
+func (r *ScanAttemptRepository) List(ctx context.Context, filter domain.ScanAttemptFilter) ([]domain.ScanAttempt, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	attempts := []domain.ScanAttempt{}
+	for _, attempt := range r.store.scanAttempts {
+		if attempt.BusinessID != filter.BusinessID || attempt.Seq <= filter.AfterSeq {
+			continue
+		}
+		if filter.Outcome != "" && attempt.Outcome != filter.Outcome {
+			continue
+		}
+		if filter.TicketID != "" && attempt.TicketID != filter.TicketID {
+			continue
+		}
+		if filter.DeviceID != "" && attempt.DeviceID != filter.DeviceID {
+			continue
+		}
+		if filter.StaffID != "" && attempt.StaffID != filter.StaffID {
+			continue
+		}
+		if filter.From != 0 && attempt.AttemptedAt < filter.From {
+			continue
+		}
+		if filter.To != 0 && attempt.AttemptedAt > filter.To {
+			continue
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	sort.Slice(attempts, func(i, j int) bool {
+		return attempts[i].Seq < attempts[j].Seq
+	})
+	if filter.Limit > 0 && len(attempts) > filter.Limit {
+		attempts = attempts[:filter.Limit]
+	}
+
+	return attempts, nil
+}
+
+// CountByScanner counts a business's attempts with outcome made since a
+// time by exactly this device and staff member ("" for none)
+func (r *ScanAttemptRepository) CountByScanner(ctx context.Context, businessID, deviceID, staffID, outcome string, since int64) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, attempt := range r.store.scanAttempts {
+		if attempt.BusinessID == businessID && attempt.Outcome == outcome &&
+			attempt.DeviceID == deviceID && attempt.StaffID == staffID && attempt.AttemptedAt >= since {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// FindLatestByOtherScanner finds a ticket's latest attempt with outcome
+// made since a time by any scanner other than this device and staff member
+func (r *ScanAttemptRepository) FindLatestByOtherScanner(ctx context.Context, businessID, ticketID, deviceID, staffID, outcome string, since int64) (*domain.ScanAttempt, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var latest *domain.ScanAttempt
+	for _, attempt := range r.store.scanAttempts {
+		if attempt.BusinessID != businessID || attempt.TicketID != ticketID || attempt.Outcome != outcome ||
+			attempt.AttemptedAt < since || (attempt.DeviceID == deviceID && attempt.StaffID == staffID) {
+			continue
+		}
+		if latest == nil || attempt.Seq > latest.Seq {
+			attempt := attempt
+			latest = &attempt
+		}
+	}
+	if latest == nil {
+		return nil, apperror.NewNotFound("scan attempt")
+	}
+
+	return latest, nil
+}
+
+// ScanAlertRepository implements ScanAlertRepository in memory
+type ScanAlertRepository struct {
+	store *Store
+}
+
+// NewScanAlertRepository creates a new in-memory scan alert repository
+func NewScanAlertRepository(store *Store) *ScanAlertRepository {
+	return &ScanAlertRepository{store: store}
+}
+
+// Create inserts a new alert
+func (r *ScanAlertRepository) Create(ctx context.Context, alert *domain.ScanAlert) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.scanAlerts[alert.ID]; ok {
+		return apperror.NewDatabaseError("failed to create scan alert", errUniqueViolation("scan_alerts_pkey"))
+	}
+	if _, ok := r.store.businesses[alert.BusinessID]; !ok {
+		return apperror.NewDatabaseError("failed to create scan alert", errForeignKey("business_id"))
+	}
+	if _, ok := r.store.scanAttempts[alert.AttemptID]; !ok {
+		return apperror.NewDatabaseError("failed to create scan alert", errForeignKey("attempt_id"))
+	}
+
+	stored := *alert
+	if stored.Details == nil {
+		stored.Details = map[string]interface{}{}
+	}
+
+	put(ctx, r.store, r.store.scanAlerts, alert.ID, stored)
+	return nil
+}
+
+// FindByID finds an alert by ID
+func (r *ScanAlertRepository) FindByID(ctx context.Context, id string) (*domain.ScanAlert, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	alert, ok := r.store.scanAlerts[id]
+	if !ok {
+		return nil, apperror.NewNotFound("scan alert")
+	}
+
+	return &alert, nil
+}
+
+// List lists alerts matching filter, newest first
+func (r *ScanAlertRepository) List(ctx context.Context, filter domain.ScanAlertFilter) ([]domain.ScanAlert, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	alerts := []domain.ScanAlert{}
+	for _, alert := range r.store.scanAlerts {
+		if alert.BusinessID != filter.BusinessID {
+			continue
+		}
+		if filter.Rule != "" && alert.Rule != filter.Rule {
+			continue
+		}
+		if filter.Status != "" && alert.Status() != filter.Status {
+			continue
+		}
+		if filter.From != 0 && alert.CreatedAt < filter.From {
+			continue
+		}
+		if filter.To != 0 && alert.CreatedAt > filter.To {
+			continue
+		}
+		alerts = append(alerts, alert)
+	}
+
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].CreatedAt != alerts[j].CreatedAt {
+			return alerts[i].CreatedAt > alerts[j].CreatedAt
+		}
+		return alerts[i].ID < alerts[j].ID
+	})
+	if filter.Limit > 0 && len(alerts) > filter.Limit {
+		alerts = alerts[:filter.Limit]
+	}
+
+	return alerts, nil
+}
+
+// Acknowledge closes an open alert, failing with a conflict if it already
+// was acknowledged
+func (r *ScanAlertRepository) Acknowledge(ctx context.Context, id, acknowledgedBy string, acknowledgedAt int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	alert, ok := r.store.scanAlerts[id]
+	if !ok || alert.AcknowledgedAt != 0 {
+		return apperror.NewConflict("scan alert was already acknowledged")
+	}
+
+	alert.AcknowledgedAt = acknowledgedAt
+	alert.AcknowledgedBy = acknowledgedBy
+	put(ctx, r.store, r.store.scanAlerts, id, alert)
+	return nil
+}
@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// ServiceRepository implements ServiceRepository in memory
+type ServiceRepository struct {
+	store *Store
+}
+
+// NewServiceRepository creates a new in-memory service repository
+func NewServiceRepository(store *Store) *ServiceRepository {
+	return &ServiceRepository{store: store}
+}
+
+func (r *ServiceRepository) Create(ctx context.Context, service *domain.Service) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.businesses[service.BusinessID]; !ok {
+		return apperror.NewDatabaseError("failed to create service", errForeignKey("business_id"))
+	}
+
+	put(ctx, r.store, r.store.services, service.ID, *service)
+	return nil
+}
+
+func (r *ServiceRepository) FindByID(ctx context.Context, id string) (*domain.Service, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	service, ok := r.store.services[id]
+	if !ok {
+		return nil, apperror.NewNotFound("service")
+	}
+
+	return &service, nil
+}
+
+// LockByID finds a service; each store operation is already serialized by the store lock
+func (r *ServiceRepository) LockByID(ctx context.Context, id string) (*domain.Service, error) {
+	return r.FindByID(ctx, id)
+}
+
+// ShareLockByID finds a service; each store operation is already serialized by the store lock
+func (r *ServiceRepository) ShareLockByID(ctx context.Context, id string) (*domain.Service, error) {
+	return r.FindByID(ctx, id)
+}
+
+func (r *ServiceRepository) ListByBusinessID(ctx context.Context, businessID string) ([]domain.Service, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	services := []domain.Service{}
+	for _, service := range r.store.services {
+		if service.BusinessID == businessID {
+			services = append(services, service)
+		}
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].CreatedAt > services[j].CreatedAt
+	})
+
+	return services, nil
+}
+
+func (r *ServiceRepository) Update(ctx context.Context, service *domain.Service) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.services[service.ID]
+	if !ok {
+		return apperror.NewNotFound("service")
+	}
+
+	existing.Name = service.Name
+	existing.TotalSlots = service.TotalSlots
+	existing.TicketTTL = service.TicketTTL
+	existing.ClosesAt = service.ClosesAt
+	existing.ArchivedAt = service.ArchivedAt
+	existing.UpdatedAt = service.UpdatedAt
+
+	put(ctx, r.store, r.store.services, service.ID, existing)
+	return nil
+}
+
+// Delete removes a service together with its slots and tickets, matching
+// the ON DELETE CASCADE foreign keys of the PostgreSQL schema
+func (r *ServiceRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.services[id]; !ok {
+		return apperror.NewNotFound("service")
+	}
+
+	for ticketID, ticket := range r.store.tickets {
+		if ticket.ServiceID == id {
+			removeTicketChildren(ctx, r.store, ticketID)
+			remove(ctx, r.store, r.store.tickets, ticketID)
+		}
+	}
+	for slotID, slot := range r.store.slots {
+		if slot.ServiceID == id {
+			remove(ctx, r.store, r.store.slots, slotID)
+		}
+	}
+	for holdID, hold := range r.store.holds {
+		if hold.ServiceID == id {
+			remove(ctx, r.store, r.store.holds, holdID)
+		}
+	}
+	for entryID, entry := range r.store.waitlist {
+		if entry.ServiceID == id {
+			remove(ctx, r.store, r.store.waitlist, entryID)
+		}
+	}
+	for typeID, itemType := range r.store.itemTypes {
+		if itemType.ServiceID == id {
+			remove(ctx, r.store, r.store.itemTypes, typeID)
+		}
+	}
+	for zoneID, zone := range r.store.zones {
+		if zone.ServiceID == id {
+			remove(ctx, r.store, r.store.zones, zoneID)
+		}
+	}
+	remove(ctx, r.store, r.store.services, id)
+
+	return nil
+}
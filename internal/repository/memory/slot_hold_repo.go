@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// SlotHoldRepository implements SlotHoldRepository in memory
+type SlotHoldRepository struct {
+	store *Store
+}
+
+// NewSlotHoldRepository creates a new in-memory slot hold repository
+func NewSlotHoldRepository(store *Store) *SlotHoldRepository {
+	return &SlotHoldRepository{store: store}
+}
+
+// Create inserts a new hold
+func (r *SlotHoldRepository) Create(ctx context.Context, hold *domain.SlotHold) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.holds[hold.ID]; ok {
+		return apperror.NewDatabaseError("failed to create slot hold", errUniqueViolation("slot_holds_pkey"))
+	}
+	if _, ok := r.store.services[hold.ServiceID]; !ok {
+		return apperror.NewDatabaseError("failed to create slot hold", errForeignKey("service_id"))
+	}
+	if _, ok := r.store.slots[hold.SlotID]; !ok {
+		return apperror.NewDatabaseError("failed to create slot hold", errForeignKey("slot_id"))
+	}
+	if _, ok := r.store.customers[hold.CustomerID]; !ok {
+		return apperror.NewDatabaseError("failed to create slot hold", errForeignKey("customer_id"))
+	}
+	for _, existing := range r.store.holds {
+		if existing.ServiceID == hold.ServiceID && existing.CustomerID == hold.CustomerID && existing.Status == domain.HoldStatusActive {
+			return apperror.NewConflict("customer already holds a slot for this service")
+		}
+	}
+
+	put(ctx, r.store, r.store.holds, hold.ID, *hold)
+	return nil
+}
+
+// FindByID retrieves a hold by ID
+func (r *SlotHoldRepository) FindByID(ctx context.Context, id string) (*domain.SlotHold, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	hold, ok := r.store.holds[id]
+	if !ok {
+		return nil, apperror.NewNotFound("slot hold")
+	}
+
+	return &hold, nil
+}
+
+// ListActiveByServiceID lists the active holds of a service
+func (r *SlotHoldRepository) ListActiveByServiceID(ctx context.Context, serviceID string) ([]domain.SlotHold, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	holds := []domain.SlotHold{}
+	for _, hold := range r.store.holds {
+		if hold.ServiceID == serviceID && hold.Status == domain.HoldStatusActive {
+			holds = append(holds, hold)
+		}
+	}
+
+	sort.Slice(holds, func(i, j int) bool {
+		return holds[i].ExpiresAt < holds[j].ExpiresAt
+	})
+
+	return holds, nil
+}
+
+// ListExpired lists active holds whose expiry has passed
+func (r *SlotHoldRepository) ListExpired(ctx context.Context, now int64, limit int) ([]domain.SlotHold, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	holds := []domain.SlotHold{}
+	for _, hold := range r.store.holds {
+		if hold.Status == domain.HoldStatusActive && hold.ExpiresAt <= now {
+			holds = append(holds, hold)
+		}
+	}
+
+	sort.Slice(holds, func(i, j int) bool {
+		return holds[i].ExpiresAt < holds[j].ExpiresAt
+	})
+	if len(holds) > limit {
+		holds = holds[:limit]
+	}
+
+	return holds, nil
+}
+
+// Update saves a hold's state if it still has fromStatus
+func (r *SlotHoldRepository) Update(ctx context.Context, hold *domain.SlotHold, fromStatus string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.holds[hold.ID]
+	if !ok || existing.Status != fromStatus {
+		return apperror.NewConflict("slot hold is no longer " + fromStatus)
+	}
+
+	existing.Status = hold.Status
+	existing.TicketID = hold.TicketID
+	existing.UpdatedAt = hold.UpdatedAt
+
+	put(ctx, r.store, r.store.holds, hold.ID, existing)
+	return nil
+}
@@ -0,0 +1,284 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// SlotRepository implements SlotRepository in memory
+type SlotRepository struct {
+	store *Store
+}
+
+// NewSlotRepository creates a new in-memory slot repository
+func NewSlotRepository(store *Store) *SlotRepository {
+	return &SlotRepository{store: store}
+}
+
+func (r *SlotRepository) Create(ctx context.Context, slot *domain.Slot) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := r.checkInsert(*slot); err != nil {
+		return apperror.NewDatabaseError("failed to create slot", err)
+	}
+
+	put(ctx, r.store, r.store.slots, slot.ID, *slot)
+	return nil
+}
+
+// CreateBatch inserts multiple slots; either all of them are stored or none
+func (r *SlotRepository) CreateBatch(ctx context.Context, slots []domain.Slot) error {
+	if len(slots) == 0 {
+		return nil
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	seen := make(map[string]map[int]bool)
+	for _, slot := range slots {
+		if err := r.checkInsert(slot); err != nil {
+			return apperror.NewDatabaseError("failed to create slot in batch", err)
+		}
+		if seen[slot.ServiceID] == nil {
+			seen[slot.ServiceID] = make(map[int]bool)
+		}
+		if seen[slot.ServiceID][slot.SlotNumber] {
+			return apperror.NewDatabaseError("failed to create slot in batch", errUniqueViolation("slots_service_id_slot_number_key"))
+		}
+		seen[slot.ServiceID][slot.SlotNumber] = true
+	}
+
+	for _, slot := range slots {
+		put(ctx, r.store, r.store.slots, slot.ID, slot)
+	}
+
+	return nil
+}
+
+// checkInsert enforces the constraints of the slots table; callers must hold the lock
+func (r *SlotRepository) checkInsert(slot domain.Slot) error {
+	if _, ok := r.store.services[slot.ServiceID]; !ok {
+		return errForeignKey("service_id")
+	}
+	if slot.ZoneID != "" {
+		if _, ok := r.store.zones[slot.ZoneID]; !ok {
+			return errForeignKey("zone_id")
+		}
+	}
+	if _, ok := r.store.slots[slot.ID]; ok {
+		return errUniqueViolation("slots_pkey")
+	}
+	for _, existing := range r.store.slots {
+		if existing.ServiceID == slot.ServiceID && existing.SlotNumber == slot.SlotNumber {
+			return errUniqueViolation("slots_service_id_slot_number_key")
+		}
+	}
+	return nil
+}
+
+func (r *SlotRepository) FindByID(ctx context.Context, id string) (*domain.Slot, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	slot, ok := r.store.slots[id]
+	if !ok {
+		return nil, apperror.NewNotFound("slot")
+	}
+
+	return &slot, nil
+}
+
+// ListByServiceID retrieves all slots for a service
+func (r *SlotRepository) ListByServiceID(ctx context.Context, serviceID string) ([]domain.Slot, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.listByServiceID(serviceID), nil
+}
+
+// listByServiceID returns the slots of a service ordered by slot number; callers must hold the lock
+func (r *SlotRepository) listByServiceID(serviceID string) []domain.Slot {
+	slots := []domain.Slot{}
+	for _, slot := range r.store.slots {
+		if slot.ServiceID == serviceID {
+			slots = append(slots, slot)
+		}
+	}
+
+	sort.Slice(slots, func(i, j int) bool {
+		return slots[i].SlotNumber < slots[j].SlotNumber
+	})
+
+	return slots
+}
+
+// ClaimNextFreeSlot claims the lowest numbered free slot, restricted to one
+// zone unless zoneID is empty. The store's write lock makes the find-and-mark
+// step atomic across concurrent check-ins.
+func (r *SlotRepository) ClaimNextFreeSlot(ctx context.Context, serviceID, zoneID string) (*domain.Slot, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, slot := range r.listByServiceID(serviceID) {
+		if slot.Status != domain.SlotStatusFree || (zoneID != "" && slot.ZoneID != zoneID) {
+			continue
+		}
+
+		slot.Status = domain.SlotStatusOccupied
+		slot.UpdatedAt = domain.NowTimestamp()
+		put(ctx, r.store, r.store.slots, slot.ID, slot)
+
+		return &slot, nil
+	}
+
+	return nil, apperror.NewConflict("no free slots available")
+}
+
+// ClaimSlot claims one specific slot, failing with a conflict if it is not free
+func (r *SlotRepository) ClaimSlot(ctx context.Context, serviceID string, slotNumber int) (*domain.Slot, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, slot := range r.store.slots {
+		if slot.ServiceID != serviceID || slot.SlotNumber != slotNumber {
+			continue
+		}
+
+		if slot.Status != domain.SlotStatusFree {
+			appErr := apperror.NewConflict("slot is already occupied")
+			appErr.Details["slot_number"] = slotNumber
+			return nil, appErr
+		}
+
+		slot.Status = domain.SlotStatusOccupied
+		slot.UpdatedAt = domain.NowTimestamp()
+		put(ctx, r.store, r.store.slots, slot.ID, slot)
+
+		return &slot, nil
+	}
+
+	return nil, apperror.NewNotFound("slot")
+}
+
+// DeleteTrailing removes the slots numbered above keep, or none of them if
+// any is not free
+func (r *SlotRepository) DeleteTrailing(ctx context.Context, serviceID string, keep int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	trailing := []domain.Slot{}
+	for _, slot := range r.listByServiceID(serviceID) {
+		if slot.SlotNumber > keep {
+			trailing = append(trailing, slot)
+		}
+	}
+
+	return r.deleteFree(ctx, trailing)
+}
+
+// DeleteZoneSlots keeps the keep lowest numbered slots of a zone and removes
+// the rest, or none of them if any is not free
+func (r *SlotRepository) DeleteZoneSlots(ctx context.Context, zoneID string, keep int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	zone, ok := r.store.zones[zoneID]
+	if !ok {
+		return nil
+	}
+
+	inZone := []domain.Slot{}
+	for _, slot := range r.listByServiceID(zone.ServiceID) {
+		if slot.ZoneID == zoneID {
+			inZone = append(inZone, slot)
+		}
+	}
+	if len(inZone) <= keep {
+		return nil
+	}
+
+	return r.deleteFree(ctx, inZone[keep:])
+}
+
+// deleteFree deletes slots if all of them are free; callers must hold the lock
+func (r *SlotRepository) deleteFree(ctx context.Context, slots []domain.Slot) error {
+	blocked := []int{}
+	for _, slot := range slots {
+		if slot.Status != domain.SlotStatusFree {
+			blocked = append(blocked, slot.SlotNumber)
+		}
+	}
+
+	if len(blocked) > 0 {
+		appErr := apperror.NewConflict("cannot remove slots that are in use")
+		appErr.Details["slot_numbers"] = blocked
+		return appErr
+	}
+
+	for _, slot := range slots {
+		remove(ctx, r.store, r.store.slots, slot.ID)
+	}
+
+	return nil
+}
+
+// UpdateStatus updates a slot's status (used for releasing tickets)
+func (r *SlotRepository) UpdateStatus(ctx context.Context, id string, status string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	slot, ok := r.store.slots[id]
+	if !ok {
+		return apperror.NewNotFound("slot")
+	}
+
+	slot.Status = status
+	slot.UpdatedAt = domain.NowTimestamp()
+	put(ctx, r.store, r.store.slots, id, slot)
+
+	return nil
+}
+
+// CountSlotsByStatus returns counts of slots in each status for a service
+func (r *SlotRepository) CountSlotsByStatus(ctx context.Context, serviceID string) (domain.SlotCounts, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	counts := domain.SlotCounts{}
+	for _, slot := range r.store.slots {
+		if slot.ServiceID == serviceID {
+			counts[slot.Status]++
+		}
+	}
+
+	return counts, nil
+}
+
+// CountSlotsByZone returns slot counts for every zone of a service that has slots
+func (r *SlotRepository) CountSlotsByZone(ctx context.Context, serviceID string) ([]domain.ZoneSlotCount, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	byZone := make(map[string]domain.SlotCounts)
+	for _, slot := range r.store.slots {
+		if slot.ServiceID != serviceID || slot.ZoneID == "" {
+			continue
+		}
+		if byZone[slot.ZoneID] == nil {
+			byZone[slot.ZoneID] = domain.SlotCounts{}
+		}
+		byZone[slot.ZoneID][slot.Status]++
+	}
+
+	counts := []domain.ZoneSlotCount{}
+	for zoneID, zoneCounts := range byZone {
+		counts = append(counts, domain.ZoneSlotCount{ZoneID: zoneID, Counts: zoneCounts})
+	}
+
+	return counts, nil
+}
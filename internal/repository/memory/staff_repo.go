@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// StaffRepository implements StaffRepository in memory
+type StaffRepository struct {
+	store *Store
+}
+
+// NewStaffRepository creates a new in-memory staff repository
+func NewStaffRepository(store *Store) *StaffRepository {
+	return &StaffRepository{store: store}
+}
+
+// Create inserts a new staff member
+func (r *StaffRepository) Create(ctx context.Context, staff *domain.Staff) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.staff[staff.ID]; ok {
+		return apperror.NewDatabaseError("failed to create staff member", errUniqueViolation("staff_pkey"))
+	}
+	if _, ok := r.store.businesses[staff.BusinessID]; !ok {
+		return apperror.NewDatabaseError("failed to create staff member", errForeignKey("business_id"))
+	}
+	for _, existing := range r.store.staff {
+		if existing.BusinessID == staff.BusinessID && existing.Email == staff.Email {
+			return apperror.NewConflict("staff member already exists")
+		}
+		if staff.InviteTokenHash != "" && existing.InviteTokenHash == staff.InviteTokenHash {
+			return apperror.NewConflict("staff member already exists")
+		}
+	}
+
+	put(ctx, r.store, r.store.staff, staff.ID, *staff)
+	return nil
+}
+
+// FindByID retrieves a staff member by ID
+func (r *StaffRepository) FindByID(ctx context.Context, id string) (*domain.Staff, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	staff, ok := r.store.staff[id]
+	if !ok {
+		return nil, apperror.NewNotFound("staff member")
+	}
+
+	return &staff, nil
+}
+
+// FindByInviteToken retrieves the staff member a pending invite was sent to
+func (r *StaffRepository) FindByInviteToken(ctx context.Context, tokenHash string) (*domain.Staff, error) {
+	return r.find(func(staff domain.Staff) bool {
+		return tokenHash != "" && staff.InviteTokenHash == tokenHash
+	})
+}
+
+// FindByEmail retrieves a business's staff member by email
+func (r *StaffRepository) FindByEmail(ctx context.Context, businessID, email string) (*domain.Staff, error) {
+	return r.find(func(staff domain.Staff) bool {
+		return staff.BusinessID == businessID && staff.Email == email
+	})
+}
+
+// ListByEmail lists the staff accounts of one person across businesses
+func (r *StaffRepository) ListByEmail(ctx context.Context, email string) ([]domain.Staff, error) {
+	return r.list(func(staff domain.Staff) bool {
+		return staff.Email == email
+	}), nil
+}
+
+// ListByBusinessID lists a business's staff, oldest first
+func (r *StaffRepository) ListByBusinessID(ctx context.Context, businessID string) ([]domain.Staff, error) {
+	return r.list(func(staff domain.Staff) bool {
+		return staff.BusinessID == businessID
+	}), nil
+}
+
+// Update saves a staff member's profile, role, status, password and invite
+func (r *StaffRepository) Update(ctx context.Context, staff *domain.Staff) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.staff[staff.ID]
+	if !ok {
+		return apperror.NewNotFound("staff member")
+	}
+
+	existing.Name = staff.Name
+	existing.Role = staff.Role
+	existing.Status = staff.Status
+	existing.Password = staff.Password
+	existing.InviteTokenHash = staff.InviteTokenHash
+	existing.InviteExpiresAt = staff.InviteExpiresAt
+	existing.UpdatedAt = staff.UpdatedAt
+
+	put(ctx, r.store, r.store.staff, staff.ID, existing)
+	return nil
+}
+
+// AcceptInvite activates an invited staff member with the name and
+// password given, failing with a conflict unless the invite with tokenHash
+// is still open
+func (r *StaffRepository) AcceptInvite(ctx context.Context, staff *domain.Staff, tokenHash string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.staff[staff.ID]
+	if !ok || existing.Status != domain.StaffStatusInvited || existing.InviteTokenHash != tokenHash {
+		return apperror.NewConflict("invite was already accepted")
+	}
+
+	existing.Name = staff.Name
+	existing.Password = staff.Password
+	existing.Status = domain.StaffStatusActive
+	existing.InviteTokenHash = ""
+	existing.InviteExpiresAt = 0
+	existing.UpdatedAt = staff.UpdatedAt
+
+	put(ctx, r.store, r.store.staff, staff.ID, existing)
+	return nil
+}
+
+func (r *StaffRepository) find(match func(domain.Staff) bool) (*domain.Staff, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, staff := range r.store.staff {
+		if match(staff) {
+			return &staff, nil
+		}
+	}
+
+	return nil, apperror.NewNotFound("staff member")
+}
+
+func (r *StaffRepository) list(match func(domain.Staff) bool) []domain.Staff {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	staff := []domain.Staff{}
+	for _, s := range r.store.staff {
+		if match(s) {
+			staff = append(staff, s)
+		}
+	}
+
+	sort.Slice(staff, func(i, j int) bool {
+		return staff[i].CreatedAt < staff[j].CreatedAt
+	})
+
+	return staff
+}
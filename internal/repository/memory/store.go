@@ -0,0 +1,178 @@
+// Package memory provides in-process implementations of the domain
+// repositories. It is used for unit tests and for running the API without
+// PostgreSQL (STORAGE=memory).
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"CLOAKBE/internal/domain"
+)
+
+// Store holds every table in memory behind a single lock
+type Store struct {
+	mu             sync.RWMutex
+	txMu           sync.Mutex // Held for the whole of a unit of work
+	businesses     map[string]domain.Business
+	keys           map[string]domain.BusinessKey
+	customers      map[string]domain.Customer
+	services       map[string]domain.Service
+	zones          map[string]domain.Zone
+	slots          map[string]domain.Slot
+	tickets        map[string]domain.Ticket
+	syncEvents     map[string]domain.SyncEvent
+	waitlist       map[string]domain.WaitlistEntry
+	holds          map[string]domain.SlotHold
+	itemTypes      map[string]domain.ItemType
+	ticketItems    map[string]domain.TicketItem
+	reissues       map[string]domain.TicketReissue
+	transfers      map[string]domain.TicketTransfer
+	staff          map[string]domain.Staff
+	devices        map[string]domain.Device
+	sessions       map[string]domain.AuthSession
+	refreshTokens  map[string]domain.RefreshToken
+	loginCodes     map[string]domain.LoginCode
+	businessTokens map[string]domain.BusinessToken
+	totpFactors    map[string]domain.TOTPFactor
+	recoveryCodes  map[string]domain.RecoveryCode
+	mfaChallenges  map[string]domain.MFAChallenge
+	rateLimits     map[string]domain.RateLimitCounter
+	loginLockouts  map[string]domain.LoginLockout
+	ticketEvents   map[string]domain.TicketEvent
+	scanAttempts   map[string]domain.ScanAttempt
+	scanAlerts     map[string]domain.ScanAlert
+
+	waitlistSeq    int64 // Last assigned WaitlistEntry.Seq, like a BIGSERIAL
+	ticketItemSeq  int64 // Last assigned TicketItem.Seq
+	ticketEventSeq int64 // Last assigned TicketEvent.Seq
+	scanAttemptSeq int64 // Last assigned ScanAttempt.Seq
+}
+
+// NewStore creates an empty in-memory store
+func NewStore() *Store {
+	return &Store{
+		businesses:     make(map[string]domain.Business),
+		keys:           make(map[string]domain.BusinessKey),
+		customers:      make(map[string]domain.Customer),
+		services:       make(map[string]domain.Service),
+		zones:          make(map[string]domain.Zone),
+		slots:          make(map[string]domain.Slot),
+		tickets:        make(map[string]domain.Ticket),
+		syncEvents:     make(map[string]domain.SyncEvent),
+		waitlist:       make(map[string]domain.WaitlistEntry),
+		holds:          make(map[string]domain.SlotHold),
+		itemTypes:      make(map[string]domain.ItemType),
+		ticketItems:    make(map[string]domain.TicketItem),
+		reissues:       make(map[string]domain.TicketReissue),
+		transfers:      make(map[string]domain.TicketTransfer),
+		staff:          make(map[string]domain.Staff),
+		devices:        make(map[string]domain.Device),
+		sessions:       make(map[string]domain.AuthSession),
+		refreshTokens:  make(map[string]domain.RefreshToken),
+		loginCodes:     make(map[string]domain.LoginCode),
+		businessTokens: make(map[string]domain.BusinessToken),
+		totpFactors:    make(map[string]domain.TOTPFactor),
+		recoveryCodes:  make(map[string]domain.RecoveryCode),
+		mfaChallenges:  make(map[string]domain.MFAChallenge),
+		rateLimits:     make(map[string]domain.RateLimitCounter),
+		loginLockouts:  make(map[string]domain.LoginLockout),
+		ticketEvents:   make(map[string]domain.TicketEvent),
+		scanAttempts:   make(map[string]domain.ScanAttempt),
+		scanAlerts:     make(map[string]domain.ScanAlert),
+	}
+}
+
+type txKey struct{}
+
+// journal collects undo steps for the writes made inside a unit of work
+type journal struct {
+	undo []func()
+}
+
+// record registers an undo step when ctx belongs to a unit of work.
+// Callers must hold s.mu.
+func (s *Store) record(ctx context.Context, undo func()) {
+	if j, ok := ctx.Value(txKey{}).(*journal); ok {
+		j.undo = append(j.undo, undo)
+	}
+}
+
+// put stores v under id and journals the previous value. The key is copied:
+// IDs taken from route parameters share the request buffer, and overwriting
+// an entry also replaces its key.
+func put[T any](ctx context.Context, s *Store, m map[string]T, id string, v T) {
+	id = strings.Clone(id)
+	prev, existed := m[id]
+	m[id] = v
+	s.record(ctx, func() {
+		if existed {
+			m[id] = prev
+		} else {
+			delete(m, id)
+		}
+	})
+}
+
+// remove deletes id and journals the removed value
+func remove[T any](ctx context.Context, s *Store, m map[string]T, id string) {
+	prev, existed := m[id]
+	if !existed {
+		return
+	}
+	delete(m, id)
+	s.record(ctx, func() {
+		m[id] = prev
+	})
+}
+
+// errForeignKey mirrors a PostgreSQL foreign key violation
+func errForeignKey(column string) error {
+	return fmt.Errorf("violates foreign key constraint on %s", column)
+}
+
+// errUniqueViolation mirrors a PostgreSQL unique constraint violation
+func errUniqueViolation(constraint string) error {
+	return fmt.Errorf("duplicate key value violates unique constraint %q", constraint)
+}
+
+// TxManager implements domain.TxManager on top of a Store. Units of work
+// run one at a time, and writes made in a failed one are undone by restoring
+// whole entries. Concurrent readers are not isolated, and repository calls
+// made outside a unit of work do not wait for one: a rollback overwrites
+// their changes to entries the failed unit of work also wrote.
+type TxManager struct {
+	store *Store
+}
+
+// NewTxManager creates a new in-memory transaction manager
+func NewTxManager(store *Store) *TxManager {
+	return &TxManager{store: store}
+}
+
+// WithinTx runs fn and rolls back its writes if it returns an error.
+// Nested calls join the outer unit of work.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*journal); ok {
+		return fn(ctx)
+	}
+
+	// One unit of work at a time, so a rollback cannot restore entries over
+	// another's writes
+	m.store.txMu.Lock()
+	defer m.store.txMu.Unlock()
+
+	j := &journal{}
+	if err := fn(context.WithValue(ctx, txKey{}, j)); err != nil {
+		m.store.mu.Lock()
+		for i := len(j.undo) - 1; i >= 0; i-- {
+			j.undo[i]()
+		}
+		m.store.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+var errRollback = errors.New("rollback")
+
+// putBusiness writes a business the way repositories do
+func putBusiness(ctx context.Context, s *Store, id, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	put(ctx, s, s.businesses, id, domain.Business{ID: id, Name: name})
+}
+
+func removeBusiness(ctx context.Context, s *Store, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	remove(ctx, s, s.businesses, id)
+}
+
+// businessName returns the name stored for id, or "" when there is none
+func businessName(s *Store, id string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.businesses[id].Name
+}
+
+func TestWithinTxKeepsWritesOnSuccess(t *testing.T) {
+	s := NewStore()
+	tx := NewTxManager(s)
+
+	err := tx.WithinTx(context.Background(), func(ctx context.Context) error {
+		putBusiness(ctx, s, "a", "first")
+		putBusiness(ctx, s, "a", "second")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithinTx: %v", err)
+	}
+
+	if got := businessName(s, "a"); got != "second" {
+		t.Fatalf("name: got %q, want %q", got, "second")
+	}
+}
+
+func TestWithinTxUndoesWritesOnError(t *testing.T) {
+	s := NewStore()
+	tx := NewTxManager(s)
+	ctx := context.Background()
+
+	putBusiness(ctx, s, "kept", "original")
+	putBusiness(ctx, s, "removed", "original")
+
+	err := tx.WithinTx(ctx, func(ctx context.Context) error {
+		putBusiness(ctx, s, "new", "inserted")
+		putBusiness(ctx, s, "new", "updated")
+		putBusiness(ctx, s, "kept", "updated")
+		removeBusiness(ctx, s, "removed")
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("WithinTx: got %v, want %v", err, errRollback)
+	}
+
+	s.mu.RLock()
+	_, exists := s.businesses["new"]
+	s.mu.RUnlock()
+	if exists {
+		t.Fatal("insert was not undone")
+	}
+	if got := businessName(s, "kept"); got != "original" {
+		t.Fatalf("update was not undone: got %q", got)
+	}
+	if got := businessName(s, "removed"); got != "original" {
+		t.Fatalf("remove was not undone: got %q", got)
+	}
+}
+
+func TestWithinTxNestedCallsJoinOuter(t *testing.T) {
+	s := NewStore()
+	tx := NewTxManager(s)
+
+	// The inner failure is handled by the outer unit of work, which commits
+	err := tx.WithinTx(context.Background(), func(ctx context.Context) error {
+		innerErr := tx.WithinTx(ctx, func(ctx context.Context) error {
+			putBusiness(ctx, s, "a", "inner")
+			return errRollback
+		})
+		if !errors.Is(innerErr, errRollback) {
+			t.Fatalf("inner WithinTx: got %v, want %v", innerErr, errRollback)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithinTx: %v", err)
+	}
+	if got := businessName(s, "a"); got != "inner" {
+		t.Fatalf("joined write: got %q, want %q", got, "inner")
+	}
+
+	// An outer failure undoes what the inner call wrote
+	err = tx.WithinTx(context.Background(), func(ctx context.Context) error {
+		if err := tx.WithinTx(ctx, func(ctx context.Context) error {
+			putBusiness(ctx, s, "b", "inner")
+			return nil
+		}); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("WithinTx: got %v, want %v", err, errRollback)
+	}
+	if got := businessName(s, "b"); got != "" {
+		t.Fatalf("inner write was not undone: got %q", got)
+	}
+}
+
+func TestWithinTxSerializesUnitsOfWork(t *testing.T) {
+	s := NewStore()
+	tx := NewTxManager(s)
+	ctx := context.Background()
+
+	putBusiness(ctx, s, "a", "original")
+
+	var wg sync.WaitGroup
+	err := tx.WithinTx(ctx, func(ctx context.Context) error {
+		putBusiness(ctx, s, "a", "failed")
+
+		// A second unit of work writes the same entry while this one is open
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := tx.WithinTx(context.Background(), func(ctx context.Context) error {
+				putBusiness(ctx, s, "a", "committed")
+				return nil
+			}); err != nil {
+				t.Errorf("second WithinTx: %v", err)
+			}
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("WithinTx: got %v, want %v", err, errRollback)
+	}
+	wg.Wait()
+
+	// The rollback must not restore "original" over the committed write
+	if got := businessName(s, "a"); got != "committed" {
+		t.Fatalf("name: got %q, want %q", got, "committed")
+	}
+}
+
+func TestPutCopiesKey(t *testing.T) {
+	s := NewStore()
+	ctx := context.Background()
+
+	// Route parameters can share a buffer that is reused by the next request
+	buf := []byte("business-1")
+	id := unsafe.String(&buf[0], len(buf))
+	putBusiness(ctx, s, id, "stored")
+	copy(buf, "business-2")
+
+	if got := businessName(s, "business-1"); got != "stored" {
+		t.Fatalf("lookup by the original ID: got %q, want %q", got, "stored")
+	}
+}
+
+func TestClaimNextFreeSlotConcurrently(t *testing.T) {
+	s := NewStore()
+	ctx := context.Background()
+	now := domain.NowTimestamp()
+
+	putBusiness(ctx, s, "business", "Cloakroom")
+	services := NewServiceRepository(s)
+	if err := services.Create(ctx, &domain.Service{ID: "service", BusinessID: "business", Name: "Coats", TotalSlots: 5, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("create service: %v", err)
+	}
+
+	const totalSlots = 5
+	slots := NewSlotRepository(s)
+	batch := make([]domain.Slot, totalSlots)
+	for i := range batch {
+		batch[i] = domain.Slot{
+			ID:         "slot-" + string(rune('a'+i)),
+			ServiceID:  "service",
+			SlotNumber: i + 1,
+			Status:     domain.SlotStatusFree,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+	}
+	if err := slots.CreateBatch(ctx, batch); err != nil {
+		t.Fatalf("create slots: %v", err)
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		claimed   = make(map[string]bool)
+		conflicts int
+	)
+	for i := 0; i < 2*totalSlots; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slot, err := slots.ClaimNextFreeSlot(ctx, "service", "")
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case apperror.IsConflict(err):
+				conflicts++
+			case err != nil:
+				t.Errorf("claim: %v", err)
+			case claimed[slot.ID]:
+				t.Errorf("slot %s claimed twice", slot.ID)
+			default:
+				claimed[slot.ID] = true
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(claimed) != totalSlots || conflicts != totalSlots {
+		t.Fatalf("got %d claims and %d conflicts, want %d of each", len(claimed), conflicts, totalSlots)
+	}
+}
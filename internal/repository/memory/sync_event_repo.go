@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// SyncEventRepository implements SyncEventRepository in memory
+type SyncEventRepository struct {
+	store *Store
+}
+
+// NewSyncEventRepository creates a new in-memory sync event repository
+func NewSyncEventRepository(store *Store) *SyncEventRepository {
+	return &SyncEventRepository{store: store}
+}
+
+// Create records a replayed device event
+func (r *SyncEventRepository) Create(ctx context.Context, e *domain.SyncEvent) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := syncEventKey(e.BusinessID, e.ID)
+	if _, ok := r.store.syncEvents[key]; ok {
+		return apperror.NewConflict("sync event already recorded")
+	}
+	if _, ok := r.store.businesses[e.BusinessID]; !ok {
+		return apperror.NewDatabaseError("failed to create sync event", errForeignKey("business_id"))
+	}
+
+	put(ctx, r.store, r.store.syncEvents, key, *e)
+	return nil
+}
+
+// FindByID finds a business's replayed event by its device-generated ID
+func (r *SyncEventRepository) FindByID(ctx context.Context, businessID, id string) (*domain.SyncEvent, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	e, ok := r.store.syncEvents[syncEventKey(businessID, id)]
+	if !ok {
+		return nil, apperror.NewNotFound("sync event")
+	}
+
+	return &e, nil
+}
+
+// FindAppliedReleaseByTicketID finds the device release that released a ticket
+func (r *SyncEventRepository) FindAppliedReleaseByTicketID(ctx context.Context, ticketID string) (*domain.SyncEvent, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var found *domain.SyncEvent
+	for _, e := range r.store.syncEvents {
+		if e.TicketID != ticketID || e.Type != domain.SyncEventTypeRelease || e.Result != domain.SyncResultApplied {
+			continue
+		}
+		if found == nil || e.ReceivedAt < found.ReceivedAt {
+			e := e
+			found = &e
+		}
+	}
+
+	if found == nil {
+		return nil, apperror.NewNotFound("sync event")
+	}
+
+	return found, nil
+}
+
+// syncEventKey keys events by business, since devices of different
+// businesses may generate the same event ID
+func syncEventKey(businessID, id string) string {
+	return businessID + "/" + id
+}
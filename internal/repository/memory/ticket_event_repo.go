@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// TicketEventRepository implements TicketEventRepository in memory
+type TicketEventRepository struct {
+	store *Store
+}
+
+// NewTicketEventRepository creates a new in-memory ticket event repository
+func NewTicketEventRepository(store *Store) *TicketEventRepository {
+	return &TicketEventRepository{store: store}
+}
+
+// Create appends an event and sets its Seq
+func (r *TicketEventRepository) Create(ctx context.Context, event *domain.TicketEvent) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.ticketEvents[event.ID]; ok {
+		return apperror.NewDatabaseError("failed to create ticket event", errUniqueViolation("ticket_events_pkey"))
+	}
+
+	r.store.ticketEventSeq++
+	event.Seq = r.store.ticketEventSeq
+	if event.Metadata == nil {
+		event.Metadata = map[string]interface{}{}
+	}
+
+	put(ctx, r.store, r.store.ticketEvents, event.ID, *event)
+	return nil
+}
+
+// ListByTicketID lists a business's events of a ticket in the order they
+// were written
+func (r *TicketEventRepository) ListByTicketID(ctx context.Context, ticketID, businessID string) ([]domain.TicketEvent, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	events := []domain.TicketEvent{}
+	for _, event := range r.store.ticketEvents {
+		if event.TicketID == ticketID && event.BusinessID == businessID {
+			events = append(events, event)
+		}
+	}
+
+	sortTicketEvents(events)
+	return events, nil
+}
+
+// List lists events matching filter in the order they were written
+func (r *TicketEventRepository) List(ctx context.Context, filter domain.TicketEventFilter) ([]domain.TicketEvent, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	events := []domain.TicketEvent{}
+	for _, event := range r.store.ticketEvents {
+		if event.BusinessID != filter.BusinessID || event.Seq <= filter.AfterSeq {
+			continue
+		}
+		if filter.ServiceID != "" && event.ServiceID != filter.ServiceID {
+			continue
+		}
+		if filter.Type != "" && event.Type != filter.Type {
+			continue
+		}
+		if filter.From != 0 && event.OccurredAt < filter.From {
+			continue
+		}
+		if filter.To != 0 && event.OccurredAt > filter.To {
+			continue
+		}
+		events = append(events, event)
+	}
+
+	sortTicketEvents(events)
+	if filter.Limit > 0 && len(events) > filter.Limit {
+		events = events[:filter.Limit]
+	}
+
+	return events, nil
+}
+
+func sortTicketEvents(events []domain.TicketEvent) {
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Seq < events[j].Seq
+	})
+}
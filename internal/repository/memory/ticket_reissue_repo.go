@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// TicketReissueRepository implements TicketReissueRepository in memory
+type TicketReissueRepository struct {
+	store *Store
+}
+
+// NewTicketReissueRepository creates a new in-memory ticket reissue repository
+func NewTicketReissueRepository(store *Store) *TicketReissueRepository {
+	return &TicketReissueRepository{store: store}
+}
+
+// Create records a reissue and its revoked digest
+func (r *TicketReissueRepository) Create(ctx context.Context, reissue *domain.TicketReissue) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.reissues[reissue.ID]; ok {
+		return apperror.NewDatabaseError("failed to create ticket reissue", errUniqueViolation("ticket_reissues_pkey"))
+	}
+	if _, ok := r.store.tickets[reissue.TicketID]; !ok {
+		return apperror.NewDatabaseError("failed to create ticket reissue", errForeignKey("ticket_id"))
+	}
+	for _, existing := range r.store.reissues {
+		if existing.RevokedDigest == reissue.RevokedDigest {
+			return apperror.NewConflict("ticket was already reissued")
+		}
+	}
+
+	put(ctx, r.store, r.store.reissues, reissue.ID, *reissue)
+	return nil
+}
+
+// FindByRevokedDigest finds the reissue that revoked a signature digest
+func (r *TicketReissueRepository) FindByRevokedDigest(ctx context.Context, digest string) (*domain.TicketReissue, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, reissue := range r.store.reissues {
+		if reissue.RevokedDigest == digest {
+			return &reissue, nil
+		}
+	}
+
+	return nil, apperror.NewNotFound("ticket reissue")
+}
+
+// ListByTicketID lists the reissues of a ticket, oldest first
+func (r *TicketReissueRepository) ListByTicketID(ctx context.Context, ticketID string) ([]domain.TicketReissue, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	reissues := []domain.TicketReissue{}
+	for _, reissue := range r.store.reissues {
+		if reissue.TicketID == ticketID {
+			reissues = append(reissues, reissue)
+		}
+	}
+
+	sort.Slice(reissues, func(i, j int) bool {
+		return reissues[i].CreatedAt < reissues[j].CreatedAt
+	})
+
+	return reissues, nil
+}
@@ -0,0 +1,324 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// TicketRepository implements TicketRepository in memory
+type TicketRepository struct {
+	store *Store
+}
+
+// NewTicketRepository creates a new in-memory ticket repository
+func NewTicketRepository(store *Store) *TicketRepository {
+	return &TicketRepository{store: store}
+}
+
+// Create inserts a new ticket
+func (r *TicketRepository) Create(ctx context.Context, ticket *domain.Ticket) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.tickets[ticket.ID]; ok {
+		return apperror.NewDatabaseError("failed to create ticket", errUniqueViolation("tickets_pkey"))
+	}
+	if _, ok := r.store.services[ticket.ServiceID]; !ok {
+		return apperror.NewDatabaseError("failed to create ticket", errForeignKey("service_id"))
+	}
+	if ticket.CustomerID != "" {
+		if _, ok := r.store.customers[ticket.CustomerID]; !ok {
+			return apperror.NewDatabaseError("failed to create ticket", errForeignKey("customer_id"))
+		}
+	}
+	if ticket.IssuedBy != "" {
+		if _, ok := r.store.staff[ticket.IssuedBy]; !ok {
+			return apperror.NewDatabaseError("failed to create ticket", errForeignKey("issued_by"))
+		}
+	}
+
+	put(ctx, r.store, r.store.tickets, ticket.ID, *ticket)
+	return nil
+}
+
+// FindByID retrieves a ticket by ID
+func (r *TicketRepository) FindByID(ctx context.Context, id string) (*domain.Ticket, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	ticket, ok := r.store.tickets[id]
+	if !ok {
+		return nil, apperror.NewNotFound("ticket")
+	}
+
+	return &ticket, nil
+}
+
+// FindByHMAC finds a ticket by HMAC digest
+func (r *TicketRepository) FindByHMAC(ctx context.Context, hmacDigest string) (*domain.Ticket, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, ticket := range r.store.tickets {
+		if ticket.HMACDigest == hmacDigest {
+			return &ticket, nil
+		}
+	}
+
+	return nil, apperror.NewNotFound("ticket")
+}
+
+// ListByCustomerID lists tickets for a customer, newest first, optionally
+// restricted to the services of one business
+func (r *TicketRepository) ListByCustomerID(ctx context.Context, customerID, businessID string) ([]domain.Ticket, error) {
+	return r.list(func(t domain.Ticket) bool {
+		return t.CustomerID == customerID && (businessID == "" || r.store.services[t.ServiceID].BusinessID == businessID)
+	}, func(a, b domain.Ticket) bool {
+		return a.IssuedAt > b.IssuedAt
+	}), nil
+}
+
+// ListActiveByServiceID lists active tickets for a service
+func (r *TicketRepository) ListActiveByServiceID(ctx context.Context, serviceID string) ([]domain.Ticket, error) {
+	return r.list(func(t domain.Ticket) bool {
+		return t.ServiceID == serviceID && t.Status == domain.TicketStatusActive
+	}, func(a, b domain.Ticket) bool {
+		return a.SlotNumber < b.SlotNumber
+	}), nil
+}
+
+// CountByServiceID counts the tickets a service ever issued, whatever
+// their status
+func (r *TicketRepository) CountByServiceID(ctx context.Context, serviceID string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, ticket := range r.store.tickets {
+		if ticket.ServiceID == serviceID {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// ListExpirable lists active tickets past their service's TTL or closing time,
+// optionally restricted to one business
+func (r *TicketRepository) ListExpirable(ctx context.Context, businessID string, now int64, limit int) ([]domain.Ticket, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tickets := make([]domain.Ticket, 0)
+	for _, ticket := range r.store.tickets {
+		if ticket.Status != domain.TicketStatusActive {
+			continue
+		}
+		service, ok := r.store.services[ticket.ServiceID]
+		if !ok || (businessID != "" && service.BusinessID != businessID) {
+			continue
+		}
+		if service.ExpiryReason(ticket.IssuedAt, now) != "" {
+			tickets = append(tickets, ticket)
+		}
+	}
+
+	sort.Slice(tickets, func(i, j int) bool {
+		return tickets[i].IssuedAt < tickets[j].IssuedAt
+	})
+	if len(tickets) > limit {
+		tickets = tickets[:limit]
+	}
+
+	return tickets, nil
+}
+
+// Search lists a business's tickets matching a lost-ticket lookup, newest first
+func (r *TicketRepository) Search(ctx context.Context, search domain.TicketSearch) ([]domain.Ticket, error) {
+	tickets := r.list(func(t domain.Ticket) bool {
+		customer := r.store.customers[t.CustomerID]
+		return r.store.services[t.ServiceID].BusinessID == search.BusinessID &&
+			(search.ServiceID == "" || t.ServiceID == search.ServiceID) &&
+			(search.CustomerEmail == "" || (t.CustomerID != "" && strings.EqualFold(customer.Email, search.CustomerEmail))) &&
+			(search.CustomerPhone == "" || (t.CustomerID != "" && customer.Phone == search.CustomerPhone)) &&
+			(search.SlotNumber == 0 || t.SlotNumber == search.SlotNumber) &&
+			(search.IssuedFrom == 0 || t.IssuedAt >= search.IssuedFrom) &&
+			(search.IssuedTo == 0 || t.IssuedAt <= search.IssuedTo) &&
+			(search.Status == "" || t.Status == search.Status)
+	}, func(a, b domain.Ticket) bool {
+		return a.IssuedAt > b.IssuedAt
+	})
+	if len(tickets) > search.Limit {
+		tickets = tickets[:search.Limit]
+	}
+
+	return tickets, nil
+}
+
+// list returns the matching tickets; match runs under the store's read lock
+func (r *TicketRepository) list(match func(domain.Ticket) bool, less func(a, b domain.Ticket) bool) []domain.Ticket {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tickets := make([]domain.Ticket, 0)
+	for _, ticket := range r.store.tickets {
+		if match(ticket) {
+			tickets = append(tickets, ticket)
+		}
+	}
+
+	sort.Slice(tickets, func(i, j int) bool {
+		return less(tickets[i], tickets[j])
+	})
+
+	return tickets
+}
+
+// UpdateStatus changes the status of an active ticket. It returns a
+// conflict when the ticket is no longer active.
+func (r *TicketRepository) UpdateStatus(ctx context.Context, id string, status string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ticket, ok := r.store.tickets[id]
+	if !ok || ticket.Status != domain.TicketStatusActive {
+		return apperror.NewConflict("ticket is no longer active")
+	}
+
+	now := domain.NowTimestamp()
+	ticket.Status = status
+	if status == domain.TicketStatusReleased {
+		ticket.ReleasedAt = now
+	}
+	ticket.UpdatedAt = now
+	put(ctx, r.store, r.store.tickets, id, ticket)
+
+	return nil
+}
+
+// MarkScanned records when and by which staff member ("" for the business
+// account) a ticket was last scanned
+func (r *TicketRepository) MarkScanned(ctx context.Context, id, staffID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ticket, ok := r.store.tickets[id]
+	if !ok {
+		return apperror.NewNotFound("ticket")
+	}
+
+	ticket.ScannedBy = staffID
+	ticket.ScannedAt = domain.NowTimestamp()
+	put(ctx, r.store, r.store.tickets, id, ticket)
+
+	return nil
+}
+
+// MarkReleased releases an active ticket, recording the staff member (""
+// for the business account) who handed the items back. It returns a
+// conflict when the ticket was released or expired concurrently.
+func (r *TicketRepository) MarkReleased(ctx context.Context, id, staffID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ticket, ok := r.store.tickets[id]
+	if !ok || ticket.Status != domain.TicketStatusActive {
+		return apperror.NewConflict("ticket is no longer active")
+	}
+
+	now := domain.NowTimestamp()
+	ticket.Status = domain.TicketStatusReleased
+	ticket.ReleasedAt = now
+	ticket.ReleasedBy = staffID
+	ticket.UpdatedAt = now
+	put(ctx, r.store, r.store.tickets, id, ticket)
+
+	return nil
+}
+
+// UpdateDigest replaces the signature digest of an active ticket. It returns
+// a conflict when the ticket is no longer active or its code was replaced
+// concurrently.
+func (r *TicketRepository) UpdateDigest(ctx context.Context, id, fromDigest, toDigest string, generation int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ticket, ok := r.store.tickets[id]
+	if !ok || ticket.Status != domain.TicketStatusActive || ticket.HMACDigest != fromDigest {
+		return apperror.NewConflict("ticket is no longer active or was already reissued")
+	}
+
+	ticket.HMACDigest = toDigest
+	ticket.QRGeneration = generation
+	ticket.UpdatedAt = domain.NowTimestamp()
+	put(ctx, r.store, r.store.tickets, id, ticket)
+
+	return nil
+}
+
+// UpdateCustomer moves an active ticket from one customer to another. It
+// returns a conflict when the ticket is no longer active or changed owner.
+func (r *TicketRepository) UpdateCustomer(ctx context.Context, id, fromCustomerID, toCustomerID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ticket, ok := r.store.tickets[id]
+	if !ok || ticket.Status != domain.TicketStatusActive || ticket.CustomerID != fromCustomerID {
+		return apperror.NewConflict("ticket is no longer active or changed owner")
+	}
+	if _, ok := r.store.customers[toCustomerID]; !ok {
+		return apperror.NewDatabaseError("failed to update ticket customer", errForeignKey("customer_id"))
+	}
+
+	ticket.CustomerID = toCustomerID
+	ticket.UpdatedAt = domain.NowTimestamp()
+	put(ctx, r.store, r.store.tickets, id, ticket)
+
+	return nil
+}
+
+// Expire moves an active ticket to expired. It returns a conflict when the
+// ticket is no longer active.
+func (r *TicketRepository) Expire(ctx context.Context, id, reason string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ticket, ok := r.store.tickets[id]
+	if !ok || ticket.Status != domain.TicketStatusActive {
+		return apperror.NewConflict("ticket is no longer active")
+	}
+
+	now := domain.NowTimestamp()
+	ticket.Status = domain.TicketStatusExpired
+	ticket.ExpiredAt = now
+	ticket.ExpiryReason = reason
+	ticket.UpdatedAt = now
+	put(ctx, r.store, r.store.tickets, id, ticket)
+
+	return nil
+}
+
+// removeTicketChildren deletes the rows that reference a ticket, matching
+// the ON DELETE CASCADE foreign keys of the PostgreSQL schema; callers must
+// hold the lock
+func removeTicketChildren(ctx context.Context, s *Store, ticketID string) {
+	for itemID, item := range s.ticketItems {
+		if item.TicketID == ticketID {
+			remove(ctx, s, s.ticketItems, itemID)
+		}
+	}
+	for reissueID, reissue := range s.reissues {
+		if reissue.TicketID == ticketID {
+			remove(ctx, s, s.reissues, reissueID)
+		}
+	}
+	for transferID, transfer := range s.transfers {
+		if transfer.TicketID == ticketID {
+			remove(ctx, s, s.transfers, transferID)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// TicketTransferRepository implements TicketTransferRepository in memory
+type TicketTransferRepository struct {
+	store *Store
+}
+
+// NewTicketTransferRepository creates a new in-memory ticket transfer repository
+func NewTicketTransferRepository(store *Store) *TicketTransferRepository {
+	return &TicketTransferRepository{store: store}
+}
+
+// Create inserts a new pending transfer
+func (r *TicketTransferRepository) Create(ctx context.Context, transfer *domain.TicketTransfer) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.transfers[transfer.ID]; ok {
+		return apperror.NewDatabaseError("failed to create ticket transfer", errUniqueViolation("ticket_transfers_pkey"))
+	}
+	if _, ok := r.store.tickets[transfer.TicketID]; !ok {
+		return apperror.NewDatabaseError("failed to create ticket transfer", errForeignKey("ticket_id"))
+	}
+	if _, ok := r.store.customers[transfer.FromCustomerID]; !ok {
+		return apperror.NewDatabaseError("failed to create ticket transfer", errForeignKey("from_customer_id"))
+	}
+	for _, existing := range r.store.transfers {
+		if existing.TicketID == transfer.TicketID && existing.Status == domain.TransferStatusPending {
+			return apperror.NewConflict("ticket already has a pending transfer")
+		}
+	}
+
+	put(ctx, r.store, r.store.transfers, transfer.ID, *transfer)
+	return nil
+}
+
+// FindByID retrieves a transfer by ID
+func (r *TicketTransferRepository) FindByID(ctx context.Context, id string) (*domain.TicketTransfer, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	transfer, ok := r.store.transfers[id]
+	if !ok {
+		return nil, apperror.NewNotFound("ticket transfer")
+	}
+
+	return &transfer, nil
+}
+
+// FindByRevokedDigest finds the accepted transfer that revoked a signature digest
+func (r *TicketTransferRepository) FindByRevokedDigest(ctx context.Context, digest string) (*domain.TicketTransfer, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, transfer := range r.store.transfers {
+		if transfer.RevokedDigest != "" && transfer.RevokedDigest == digest {
+			return &transfer, nil
+		}
+	}
+
+	return nil, apperror.NewNotFound("ticket transfer")
+}
+
+// ListByTicketID lists the transfers of a ticket, oldest first
+func (r *TicketTransferRepository) ListByTicketID(ctx context.Context, ticketID string) ([]domain.TicketTransfer, error) {
+	return r.list(func(transfer domain.TicketTransfer) bool {
+		return transfer.TicketID == ticketID
+	}), nil
+}
+
+// ListPendingForRecipient lists pending transfers addressed to an email
+// (case-insensitive) or phone number, oldest first
+func (r *TicketTransferRepository) ListPendingForRecipient(ctx context.Context, email, phone string) ([]domain.TicketTransfer, error) {
+	return r.list(func(transfer domain.TicketTransfer) bool {
+		if transfer.Status != domain.TransferStatusPending {
+			return false
+		}
+		return (email != "" && strings.EqualFold(transfer.ToEmail, email)) ||
+			(phone != "" && transfer.ToPhone == phone)
+	}), nil
+}
+
+func (r *TicketTransferRepository) list(match func(domain.TicketTransfer) bool) []domain.TicketTransfer {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	transfers := []domain.TicketTransfer{}
+	for _, transfer := range r.store.transfers {
+		if match(transfer) {
+			transfers = append(transfers, transfer)
+		}
+	}
+
+	sort.Slice(transfers, func(i, j int) bool {
+		return transfers[i].CreatedAt < transfers[j].CreatedAt
+	})
+
+	return transfers
+}
+
+// Update saves a transfer's state if it still has fromStatus
+func (r *TicketTransferRepository) Update(ctx context.Context, transfer *domain.TicketTransfer, fromStatus string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.transfers[transfer.ID]
+	if !ok || existing.Status != fromStatus {
+		return apperror.NewConflict("ticket transfer is no longer " + fromStatus)
+	}
+
+	if transfer.RevokedDigest != "" {
+		for id, other := range r.store.transfers {
+			if id != transfer.ID && other.RevokedDigest == transfer.RevokedDigest {
+				return apperror.NewConflict("ticket was already transferred")
+			}
+		}
+	}
+
+	existing.Status = transfer.Status
+	existing.ToCustomerID = transfer.ToCustomerID
+	existing.RevokedDigest = transfer.RevokedDigest
+	existing.UpdatedAt = transfer.UpdatedAt
+
+	put(ctx, r.store, r.store.transfers, transfer.ID, existing)
+	return nil
+}
@@ -0,0 +1,159 @@
+package memory
+
+import (
+	"context"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// TOTPFactorRepository implements TOTPFactorRepository in memory
+type TOTPFactorRepository struct {
+	store *Store
+}
+
+// NewTOTPFactorRepository creates a new in-memory TOTP factor repository
+func NewTOTPFactorRepository(store *Store) *TOTPFactorRepository {
+	return &TOTPFactorRepository{store: store}
+}
+
+// Save creates a subject's factor or replaces a pending one
+func (r *TOTPFactorRepository) Save(ctx context.Context, f *domain.TOTPFactor) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if existing, ok := r.store.totpFactors[f.SubjectID]; ok && existing.IsEnabled() {
+		return apperror.NewConflict("two-factor authentication is already enabled")
+	}
+
+	factor := *f
+	factor.ConfirmedAt = 0
+	factor.LastUsedStep = 0
+	put(ctx, r.store, r.store.totpFactors, f.SubjectID, factor)
+	return nil
+}
+
+// FindBySubject retrieves the factor of a business or staff member
+func (r *TOTPFactorRepository) FindBySubject(ctx context.Context, subjectID string) (*domain.TOTPFactor, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	f, ok := r.store.totpFactors[subjectID]
+	if !ok {
+		return nil, apperror.NewNotFound("TOTP factor")
+	}
+
+	return &f, nil
+}
+
+// Confirm turns a pending factor on
+func (r *TOTPFactorRepository) Confirm(ctx context.Context, subjectID string, confirmedAt int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	f, ok := r.store.totpFactors[subjectID]
+	if !ok || f.IsEnabled() {
+		return apperror.NewConflict("two-factor authentication is already enabled")
+	}
+
+	f.ConfirmedAt = confirmedAt
+	f.UpdatedAt = confirmedAt
+	put(ctx, r.store, r.store.totpFactors, subjectID, f)
+	return nil
+}
+
+// UseStep records an accepted time step, failing with a conflict if that
+// step or a later one was already used
+func (r *TOTPFactorRepository) UseStep(ctx context.Context, subjectID string, step int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	f, ok := r.store.totpFactors[subjectID]
+	if !ok || f.LastUsedStep >= step {
+		return apperror.NewConflict("code was already used")
+	}
+
+	f.LastUsedStep = step
+	put(ctx, r.store, r.store.totpFactors, subjectID, f)
+	return nil
+}
+
+// Delete removes a subject's factor and its recovery codes
+func (r *TOTPFactorRepository) Delete(ctx context.Context, subjectID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.totpFactors[subjectID]; !ok {
+		return apperror.NewNotFound("TOTP factor")
+	}
+
+	for id, code := range r.store.recoveryCodes {
+		if code.SubjectID == subjectID {
+			remove(ctx, r.store, r.store.recoveryCodes, id)
+		}
+	}
+	remove(ctx, r.store, r.store.totpFactors, subjectID)
+	return nil
+}
+
+// RecoveryCodeRepository implements RecoveryCodeRepository in memory
+type RecoveryCodeRepository struct {
+	store *Store
+}
+
+// NewRecoveryCodeRepository creates a new in-memory recovery code repository
+func NewRecoveryCodeRepository(store *Store) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{store: store}
+}
+
+// Replace swaps a subject's recovery codes for a new set
+func (r *RecoveryCodeRepository) Replace(ctx context.Context, subjectID string, codes []domain.RecoveryCode) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.totpFactors[subjectID]; !ok {
+		return apperror.NewDatabaseError("failed to create recovery code in batch", errForeignKey("subject_id"))
+	}
+
+	for id, code := range r.store.recoveryCodes {
+		if code.SubjectID == subjectID {
+			remove(ctx, r.store, r.store.recoveryCodes, id)
+		}
+	}
+	for _, code := range codes {
+		put(ctx, r.store, r.store.recoveryCodes, code.ID, code)
+	}
+
+	return nil
+}
+
+// Use marks an unused code of the subject used
+func (r *RecoveryCodeRepository) Use(ctx context.Context, subjectID, codeHash string, usedAt int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, code := range r.store.recoveryCodes {
+		if code.SubjectID == subjectID && code.CodeHash == codeHash && code.UsedAt == 0 {
+			code.UsedAt = usedAt
+			put(ctx, r.store, r.store.recoveryCodes, id, code)
+			return nil
+		}
+	}
+
+	return apperror.NewNotFound("recovery code")
+}
+
+// CountUnused counts the recovery codes a subject has left
+func (r *RecoveryCodeRepository) CountUnused(ctx context.Context, subjectID string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, code := range r.store.recoveryCodes {
+		if code.SubjectID == subjectID && code.UsedAt == 0 {
+			count++
+		}
+	}
+
+	return count, nil
+}
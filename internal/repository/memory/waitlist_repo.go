@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// WaitlistRepository implements WaitlistRepository in memory
+type WaitlistRepository struct {
+	store *Store
+}
+
+// NewWaitlistRepository creates a new in-memory waitlist repository
+func NewWaitlistRepository(store *Store) *WaitlistRepository {
+	return &WaitlistRepository{store: store}
+}
+
+// Create queues a new entry and sets its Seq
+func (r *WaitlistRepository) Create(ctx context.Context, entry *domain.WaitlistEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.waitlist[entry.ID]; ok {
+		return apperror.NewDatabaseError("failed to create waitlist entry", errUniqueViolation("waitlist_entries_pkey"))
+	}
+	if _, ok := r.store.services[entry.ServiceID]; !ok {
+		return apperror.NewDatabaseError("failed to create waitlist entry", errForeignKey("service_id"))
+	}
+	if entry.ZoneID != "" {
+		if _, ok := r.store.zones[entry.ZoneID]; !ok {
+			return apperror.NewDatabaseError("failed to create waitlist entry", errForeignKey("zone_id"))
+		}
+	}
+	if entry.CustomerID != "" {
+		if _, ok := r.store.customers[entry.CustomerID]; !ok {
+			return apperror.NewDatabaseError("failed to create waitlist entry", errForeignKey("customer_id"))
+		}
+		for _, existing := range r.store.waitlist {
+			if existing.ServiceID == entry.ServiceID && existing.CustomerID == entry.CustomerID && existing.IsOpen() {
+				return apperror.NewConflict("customer is already on the waitlist")
+			}
+		}
+	}
+
+	r.store.waitlistSeq++
+	entry.Seq = r.store.waitlistSeq
+	put(ctx, r.store, r.store.waitlist, entry.ID, *entry)
+	return nil
+}
+
+// FindByID retrieves a waitlist entry by ID
+func (r *WaitlistRepository) FindByID(ctx context.Context, id string) (*domain.WaitlistEntry, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	entry, ok := r.store.waitlist[id]
+	if !ok {
+		return nil, apperror.NewNotFound("waitlist entry")
+	}
+
+	return &entry, nil
+}
+
+// FindOpenByCustomer finds a customer's waiting or offered entry for a service
+func (r *WaitlistRepository) FindOpenByCustomer(ctx context.Context, serviceID, customerID string) (*domain.WaitlistEntry, error) {
+	entries := r.list(func(e domain.WaitlistEntry) bool {
+		return e.ServiceID == serviceID && e.CustomerID == customerID && e.IsOpen()
+	})
+	if len(entries) == 0 {
+		return nil, apperror.NewNotFound("waitlist entry")
+	}
+
+	return &entries[0], nil
+}
+
+// ListOpenByServiceID lists the waiting and offered entries of a service in queue order
+func (r *WaitlistRepository) ListOpenByServiceID(ctx context.Context, serviceID string) ([]domain.WaitlistEntry, error) {
+	return r.list(func(e domain.WaitlistEntry) bool {
+		return e.ServiceID == serviceID && e.IsOpen()
+	}), nil
+}
+
+// LockNextWaiting returns the first waiting entry that accepts a slot in zoneID
+func (r *WaitlistRepository) LockNextWaiting(ctx context.Context, serviceID, zoneID string) (*domain.WaitlistEntry, error) {
+	entries := r.list(func(e domain.WaitlistEntry) bool {
+		return e.ServiceID == serviceID && e.Status == domain.WaitlistStatusWaiting && (e.ZoneID == "" || e.ZoneID == zoneID)
+	})
+	if len(entries) == 0 {
+		return nil, apperror.NewNotFound("waitlist entry")
+	}
+
+	return &entries[0], nil
+}
+
+// ListExpiredOffers lists offered entries whose reservation window has passed
+func (r *WaitlistRepository) ListExpiredOffers(ctx context.Context, now int64, limit int) ([]domain.WaitlistEntry, error) {
+	entries := r.list(func(e domain.WaitlistEntry) bool {
+		return e.Status == domain.WaitlistStatusOffered && e.OfferExpiresAt <= now
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return entries, nil
+}
+
+// Update saves an entry's state if it still has fromStatus
+func (r *WaitlistRepository) Update(ctx context.Context, entry *domain.WaitlistEntry, fromStatus string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.waitlist[entry.ID]
+	if !ok || existing.Status != fromStatus {
+		return apperror.NewConflict("waitlist entry is no longer " + fromStatus)
+	}
+
+	existing.Status = entry.Status
+	existing.SlotID = entry.SlotID
+	existing.SlotNumber = entry.SlotNumber
+	existing.OfferedAt = entry.OfferedAt
+	existing.OfferExpiresAt = entry.OfferExpiresAt
+	existing.TicketID = entry.TicketID
+	existing.UpdatedAt = entry.UpdatedAt
+
+	put(ctx, r.store, r.store.waitlist, entry.ID, existing)
+	return nil
+}
+
+// list returns the matching entries in queue order
+func (r *WaitlistRepository) list(match func(domain.WaitlistEntry) bool) []domain.WaitlistEntry {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	entries := []domain.WaitlistEntry{}
+	for _, entry := range r.store.waitlist {
+		if match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Seq < entries[j].Seq
+	})
+
+	return entries
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// ZoneRepository implements ZoneRepository in memory
+type ZoneRepository struct {
+	store *Store
+}
+
+// NewZoneRepository creates a new in-memory zone repository
+func NewZoneRepository(store *Store) *ZoneRepository {
+	return &ZoneRepository{store: store}
+}
+
+// Create inserts a new zone
+func (r *ZoneRepository) Create(ctx context.Context, zone *domain.Zone) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.services[zone.ServiceID]; !ok {
+		return apperror.NewDatabaseError("failed to create zone", errForeignKey("service_id"))
+	}
+	if _, ok := r.store.zones[zone.ID]; ok {
+		return apperror.NewDatabaseError("failed to create zone", errUniqueViolation("zones_pkey"))
+	}
+	if r.nameTaken(zone) {
+		return apperror.NewConflict("zone name already exists for this service")
+	}
+
+	put(ctx, r.store, r.store.zones, zone.ID, *zone)
+	return nil
+}
+
+// FindByID retrieves a zone by ID
+func (r *ZoneRepository) FindByID(ctx context.Context, id string) (*domain.Zone, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	zone, ok := r.store.zones[id]
+	if !ok {
+		return nil, apperror.NewNotFound("zone")
+	}
+
+	return &zone, nil
+}
+
+// ListByServiceID lists the zones of a service in creation order
+func (r *ZoneRepository) ListByServiceID(ctx context.Context, serviceID string) ([]domain.Zone, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	zones := []domain.Zone{}
+	for _, zone := range r.store.zones {
+		if zone.ServiceID == serviceID {
+			zones = append(zones, zone)
+		}
+	}
+
+	sort.Slice(zones, func(i, j int) bool {
+		if zones[i].CreatedAt != zones[j].CreatedAt {
+			return zones[i].CreatedAt < zones[j].CreatedAt
+		}
+		return zones[i].Name < zones[j].Name
+	})
+
+	return zones, nil
+}
+
+// Update changes a zone's name and capacity
+func (r *ZoneRepository) Update(ctx context.Context, zone *domain.Zone) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.zones[zone.ID]
+	if !ok {
+		return apperror.NewNotFound("zone")
+	}
+	if r.nameTaken(zone) {
+		return apperror.NewConflict("zone name already exists for this service")
+	}
+
+	existing.Name = zone.Name
+	existing.Capacity = zone.Capacity
+	existing.UpdatedAt = zone.UpdatedAt
+
+	put(ctx, r.store, r.store.zones, zone.ID, existing)
+	return nil
+}
+
+// nameTaken enforces UNIQUE(service_id, name); callers must hold the lock
+func (r *ZoneRepository) nameTaken(zone *domain.Zone) bool {
+	for _, existing := range r.store.zones {
+		if existing.ID != zone.ID && existing.ServiceID == zone.ServiceID && existing.Name == zone.Name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/database"
+	"CLOAKBE/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresMFAChallengeRepository implements MFAChallengeRepository for PostgreSQL
+type PostgresMFAChallengeRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresMFAChallengeRepository creates a new login challenge repository
+func NewPostgresMFAChallengeRepository(db *database.Pool) *PostgresMFAChallengeRepository {
+	return &PostgresMFAChallengeRepository{db: db}
+}
+
+// Create inserts a new login challenge
+func (r *PostgresMFAChallengeRepository) Create(ctx context.Context, c *domain.MFAChallenge) error {
+	query := `
+		INSERT INTO mfa_challenges (id, subject, user_id, email, role, business_id, staff_id, staff_role,
+			token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), $9, $10, $11)
+	`
+
+	_, err := r.db.DB(ctx).Exec(ctx, query,
+		c.ID, c.Subject, c.UserID, c.Email, c.Role, c.BusinessID, c.StaffID, c.StaffRole,
+		c.TokenHash, c.ExpiresAt, c.CreatedAt,
+	)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to create login challenge", err)
+	}
+
+	return nil
+}
+
+// FindByTokenHash retrieves a login challenge by the hash of its token
+func (r *PostgresMFAChallengeRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.MFAChallenge, error) {
+	query := `
+		SELECT id, subject, user_id, COALESCE(email, ''), role, COALESCE(business_id, ''), COALESCE(staff_id, ''),
+			COALESCE(staff_role, ''), token_hash, attempts, expires_at, COALESCE(used_at, 0), created_at
+		FROM mfa_challenges WHERE token_hash = $1
+	`
+
+	c := &domain.MFAChallenge{}
+	err := r.db.DB(ctx).QueryRow(ctx, query, tokenHash).Scan(
+		&c.ID, &c.Subject, &c.UserID, &c.Email, &c.Role, &c.BusinessID, &c.StaffID,
+		&c.StaffRole, &c.TokenHash, &c.Attempts, &c.ExpiresAt, &c.UsedAt, &c.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("login challenge")
+		}
+		return nil, apperror.NewDatabaseError("failed to find login challenge", err)
+	}
+
+	return c, nil
+}
+
+// RecordAttempt counts a code check on an unused challenge and returns
+// the new count, failing with a conflict once maxAttempts were made
+func (r *PostgresMFAChallengeRepository) RecordAttempt(ctx context.Context, id string, maxAttempts int) (int, error) {
+	query := `
+		UPDATE mfa_challenges SET attempts = attempts + 1
+		WHERE id = $1 AND used_at IS NULL AND attempts < $2
+		RETURNING attempts
+	`
+
+	var attempts int
+	if err := r.db.DB(ctx).QueryRow(ctx, query, id, maxAttempts).Scan(&attempts); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, apperror.NewConflict("login challenge is used up")
+		}
+		return 0, apperror.NewDatabaseError("failed to record challenge attempt", err)
+	}
+
+	return attempts, nil
+}
+
+// Use marks a challenge completed, failing with a conflict if it already was
+func (r *PostgresMFAChallengeRepository) Use(ctx context.Context, id string, usedAt int64) error {
+	query := `UPDATE mfa_challenges SET used_at = $2 WHERE id = $1 AND used_at IS NULL`
+
+	result, err := r.db.DB(ctx).Exec(ctx, query, id, usedAt)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to complete login challenge", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewConflict("login challenge was already completed")
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/database"
+	"CLOAKBE/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresRateLimitRepository implements RateLimitRepository for PostgreSQL
+type PostgresRateLimitRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresRateLimitRepository creates a new rate limit repository
+func NewPostgresRateLimitRepository(db *database.Pool) *PostgresRateLimitRepository {
+	return &PostgresRateLimitRepository{db: db}
+}
+
+// Hit counts a request under key in a single upsert, so instances sharing
+// the database never lose a count
+func (r *PostgresRateLimitRepository) Hit(ctx context.Context, key string, window int64, now int64) (*domain.RateLimitCounter, error) {
+	query := `
+		INSERT INTO rate_limits (key, count, reset_at)
+		VALUES ($1, 1, $2::BIGINT + $3::BIGINT)
+		ON CONFLICT (key) DO UPDATE SET
+			count = CASE WHEN rate_limits.reset_at <= $2 THEN 1 ELSE rate_limits.count + 1 END,
+			reset_at = CASE WHEN rate_limits.reset_at <= $2 THEN $2::BIGINT + $3::BIGINT ELSE rate_limits.reset_at END
+		RETURNING count, reset_at
+	`
+
+	counter := &domain.RateLimitCounter{Key: key}
+	if err := r.db.DB(ctx).QueryRow(ctx, query, key, now, window).Scan(&counter.Count, &counter.ResetAt); err != nil {
+		return nil, apperror.NewDatabaseError("failed to count request", err)
+	}
+
+	return counter, nil
+}
+
+// DeleteExpired removes counters whose window ended before now
+func (r *PostgresRateLimitRepository) DeleteExpired(ctx context.Context, now int64) (int64, error) {
+	result, err := r.db.DB(ctx).Exec(ctx, `DELETE FROM rate_limits WHERE reset_at <= $1`, now)
+	if err != nil {
+		return 0, apperror.NewDatabaseError("failed to delete expired rate limits", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// loginLockoutColumns is the select list matching scanLoginLockout
+const loginLockoutColumns = `key, failures, COALESCE(locked_until, 0), last_failure_at`
+
+func scanLoginLockout(row rowScanner, l *domain.LoginLockout) error {
+	return row.Scan(&l.Key, &l.Failures, &l.LockedUntil, &l.LastFailureAt)
+}
+
+// PostgresLoginLockoutRepository implements LoginLockoutRepository for PostgreSQL
+type PostgresLoginLockoutRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresLoginLockoutRepository creates a new login lockout repository
+func NewPostgresLoginLockoutRepository(db *database.Pool) *PostgresLoginLockoutRepository {
+	return &PostgresLoginLockoutRepository{db: db}
+}
+
+// FindByKey retrieves the lockout of an account
+func (r *PostgresLoginLockoutRepository) FindByKey(ctx context.Context, key string) (*domain.LoginLockout, error) {
+	lockout := &domain.LoginLockout{}
+	err := scanLoginLockout(r.db.DB(ctx).QueryRow(ctx, `SELECT `+loginLockoutColumns+` FROM login_lockouts WHERE key = $1`, key), lockout)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("login lockout")
+		}
+		return nil, apperror.NewDatabaseError("failed to find login lockout", err)
+	}
+
+	return lockout, nil
+}
+
+// RecordFailure counts a bad password, starting over when the last one is
+// older than resetBefore, and returns the updated lockout
+func (r *PostgresLoginLockoutRepository) RecordFailure(ctx context.Context, key string, now, resetBefore int64) (*domain.LoginLockout, error) {
+	query := `
+		INSERT INTO login_lockouts (key, failures, last_failure_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_lockouts.last_failure_at < $3 THEN 1 ELSE login_lockouts.failures + 1 END,
+			last_failure_at = $2
+		RETURNING ` + loginLockoutColumns
+
+	lockout := &domain.LoginLockout{}
+	if err := scanLoginLockout(r.db.DB(ctx).QueryRow(ctx, query, key, now, resetBefore), lockout); err != nil {
+		return nil, apperror.NewDatabaseError("failed to record login failure", err)
+	}
+
+	return lockout, nil
+}
+
+// Lock refuses logins to an account until a time
+func (r *PostgresLoginLockoutRepository) Lock(ctx context.Context, key string, until int64) error {
+	result, err := r.db.DB(ctx).Exec(ctx, `UPDATE login_lockouts SET locked_until = $2 WHERE key = $1`, key, until)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to lock login", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewNotFound("login lockout")
+	}
+
+	return nil
+}
+
+// Delete forgets the failures of an account
+func (r *PostgresLoginLockoutRepository) Delete(ctx context.Context, key string) error {
+	if _, err := r.db.DB(ctx).Exec(ctx, `DELETE FROM login_lockouts WHERE key = $1`, key); err != nil {
+		return apperror.NewDatabaseError("failed to delete login lockout", err)
+	}
+
+	return nil
+}
+
+// DeleteStale removes lockouts whose last failure is older than before and
+// that are no longer locked
+func (r *PostgresLoginLockoutRepository) DeleteStale(ctx context.Context, before, now int64) (int64, error) {
+	query := `DELETE FROM login_lockouts WHERE last_failure_at < $1 AND COALESCE(locked_until, 0) <= $2`
+
+	result, err := r.db.DB(ctx).Exec(ctx, query, before, now)
+	if err != nil {
+		return 0, apperror.NewDatabaseError("failed to delete stale login lockouts", err)
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/database"
+	"CLOAKBE/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresRefreshTokenRepository implements RefreshTokenRepository for PostgreSQL
+type PostgresRefreshTokenRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresRefreshTokenRepository creates a new refresh token repository
+func NewPostgresRefreshTokenRepository(db *database.Pool) *PostgresRefreshTokenRepository {
+	return &PostgresRefreshTokenRepository{db: db}
+}
+
+// Create inserts a new refresh token
+func (r *PostgresRefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (id, session_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.DB(ctx).Exec(ctx, query,
+		token.ID, token.SessionID, token.TokenHash, token.ExpiresAt, token.CreatedAt,
+	)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to create refresh token", err)
+	}
+
+	return nil
+}
+
+// FindByTokenHash retrieves a refresh token by its hash
+func (r *PostgresRefreshTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	query := `
+		SELECT id, session_id, token_hash, expires_at, COALESCE(rotated_at, 0), created_at
+		FROM refresh_tokens WHERE token_hash = $1
+	`
+
+	t := &domain.RefreshToken{}
+	err := r.db.DB(ctx).QueryRow(ctx, query, tokenHash).Scan(
+		&t.ID, &t.SessionID, &t.TokenHash, &t.ExpiresAt, &t.RotatedAt, &t.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("refresh token")
+		}
+		return nil, apperror.NewDatabaseError("failed to find refresh token", err)
+	}
+
+	return t, nil
+}
+
+// MarkRotated records that a token was exchanged, failing with a conflict if
+// it already was
+func (r *PostgresRefreshTokenRepository) MarkRotated(ctx context.Context, id string, rotatedAt int64) error {
+	query := `UPDATE refresh_tokens SET rotated_at = $2 WHERE id = $1 AND rotated_at IS NULL`
+
+	result, err := r.db.DB(ctx).Exec(ctx, query, id, rotatedAt)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to rotate refresh token", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewConflict("refresh token was already used")
+	}
+
+	return nil
+}
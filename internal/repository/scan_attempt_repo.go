@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/database"
+	"CLOAKBE/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// scanAttemptColumns is the select list matching scanScanAttempt
+const scanAttemptColumns = `id, seq, business_id, COALESCE(service_id, ''), COALESCE(ticket_id, ''), outcome,
+		COALESCE(qr_digest, ''), COALESCE(device_id, ''), COALESCE(staff_id, ''), attempted_at`
+
+func scanScanAttempt(row rowScanner, a *domain.ScanAttempt) error {
+	return row.Scan(
+		&a.ID, &a.Seq, &a.BusinessID, &a.ServiceID, &a.TicketID, &a.Outcome,
+		&a.QRDigest, &a.DeviceID, &a.StaffID, &a.AttemptedAt,
+	)
+}
+
+// PostgresScanAttemptRepository implements ScanAttemptRepository for PostgreSQL
+type PostgresScanAttemptRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresScanAttemptRepository creates a new scan attempt repository
+func NewPostgresScanAttemptRepository(db *database.Pool) *PostgresScanAttemptRepository {
+	return &PostgresScanAttemptRepository{db: db}
+}
+
+// Create appends an attempt and sets its Seq
+func (r *PostgresScanAttemptRepository) Create(ctx context.Context, attempt *domain.ScanAttempt) error {
+	query := `
+		INSERT INTO scan_attempts (id, business_id, service_id, ticket_id, outcome, qr_digest, device_id, staff_id, attempted_at)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), $9)
+		RETURNING seq
+	`
+
+	err := r.db.DB(ctx).QueryRow(ctx, query,
+		attempt.ID, attempt.BusinessID, attempt.ServiceID, attempt.TicketID, attempt.Outcome,
+		attempt.QRDigest, attempt.DeviceID, attempt.StaffID, attempt.AttemptedAt,
+	).Scan(&attempt.Seq)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to create scan attempt", err)
+	}
+
+	return nil
+}
+
+// List lists attempts matching filter in the order they were written
+func (r *PostgresScanAttemptRepository) List(ctx context.Context, filter domain.ScanAttemptFilter) ([]domain.ScanAttempt, error) {
+	query := `
+		SELECT ` + scanAttemptColumns + ` FROM scan_attempts
+		WHERE business_id = $1
+		  AND ($2 = '' OR outcome = $2)
+		  AND ($3 = '' OR ticket_id = $3)
+		  AND ($4 = '' OR device_id = $4)
+		  AND ($5 = '' OR staff_id = $5)
+		  AND ($6 = 0 OR attempted_at >= $6)
+		  AND ($7 = 0 OR attempted_at <= $7)
+		  AND seq > $8
+		ORDER BY seq
+		LIMIT $9
+	`
+
+	rows, err := r.db.DB(ctx).Query(ctx, query,
+		filter.BusinessID, filter.Outcome, filter.TicketID, filter.DeviceID, filter.StaffID,
+		filter.From, filter.To, filter.AfterSeq, filter.Limit,
+	)
+	if err != nil {
+		return nil, apperror.NewDatabaseError("failed to list scan attempts", err)
+	}
+	defer rows.Close()
+
+	attempts := []domain.ScanAttempt{}
+	for rows.Next() {
+		var attempt domain.ScanAttempt
+		if err := scanScanAttempt(rows, &attempt); err != nil {
+			return nil, apperror.NewDatabaseError("failed to scan scan attempt", err)
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDatabaseError("failed to iterate scan attempts", err)
+	}
+
+	return attempts, nil
+}
+
+// CountByScanner counts a business's attempts with outcome made since a
+// time by exactly this device and staff member ("" for none)
+func (r *PostgresScanAttemptRepository) CountByScanner(ctx context.Context, businessID, deviceID, staffID, outcome string, since int64) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM scan_attempts
+		WHERE business_id = $1 AND outcome = $2
+		  AND COALESCE(device_id, '') = $3 AND COALESCE(staff_id, '') = $4
+		  AND attempted_at >= $5
+	`
+
+	var count int
+	if err := r.db.DB(ctx).QueryRow(ctx, query, businessID, outcome, deviceID, staffID, since).Scan(&count); err != nil {
+		return 0, apperror.NewDatabaseError("failed to count scan attempts", err)
+	}
+
+	return count, nil
+}
+
+// FindLatestByOtherScanner finds a ticket's latest attempt with outcome
+// made since a time by any scanner other than this device and staff member
+func (r *PostgresScanAttemptRepository) FindLatestByOtherScanner(ctx context.Context, businessID, ticketID, deviceID, staffID, outcome string, since int64) (*domain.ScanAttempt, error) {
+	query := `
+		SELECT ` + scanAttemptColumns + ` FROM scan_attempts
+		WHERE business_id = $1 AND ticket_id = $2 AND outcome = $3
+		  AND attempted_at >= $4
+		  AND (COALESCE(device_id, '') <> $5 OR COALESCE(staff_id, '') <> $6)
+		ORDER BY seq DESC
+		LIMIT 1
+	`
+
+	var attempt domain.ScanAttempt
+	err := scanScanAttempt(r.db.DB(ctx).QueryRow(ctx, query, businessID, ticketID, outcome, since, deviceID, staffID), &attempt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("scan attempt")
+		}
+		return nil, apperror.NewDatabaseError("failed to find scan attempt", err)
+	}
+
+	return &attempt, nil
+}
+
+// scanAlertColumns is the select list matching scanScanAlert
+const scanAlertColumns = `id, business_id, rule, attempt_id, COALESCE(ticket_id, ''), COALESCE(device_id, ''),
+		COALESCE(staff_id, ''), details, created_at, COALESCE(acknowledged_at, 0), COALESCE(acknowledged_by, '')`
+
+func scanScanAlert(row rowScanner, a *domain.ScanAlert) error {
+	return row.Scan(
+		&a.ID, &a.BusinessID, &a.Rule, &a.AttemptID, &a.TicketID, &a.DeviceID,
+		&a.StaffID, &a.Details, &a.CreatedAt, &a.AcknowledgedAt, &a.AcknowledgedBy,
+	)
+}
+
+// PostgresScanAlertRepository implements ScanAlertRepository for PostgreSQL
+type PostgresScanAlertRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresScanAlertRepository creates a new scan alert repository
+func NewPostgresScanAlertRepository(db *database.Pool) *PostgresScanAlertRepository {
+	return &PostgresScanAlertRepository{db: db}
+}
+
+// Create inserts a new alert
+func (r *PostgresScanAlertRepository) Create(ctx context.Context, alert *domain.ScanAlert) error {
+	query := `
+		INSERT INTO scan_alerts (id, business_id, rule, attempt_id, ticket_id, device_id, staff_id, details, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), $8, $9)
+	`
+
+	details := alert.Details
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+
+	_, err := r.db.DB(ctx).Exec(ctx, query,
+		alert.ID, alert.BusinessID, alert.Rule, alert.AttemptID, alert.TicketID, alert.DeviceID, alert.StaffID,
+		details, alert.CreatedAt,
+	)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to create scan alert", err)
+	}
+
+	return nil
+}
+
+// FindByID finds an alert by ID
+func (r *PostgresScanAlertRepository) FindByID(ctx context.Context, id string) (*domain.ScanAlert, error) {
+	query := `SELECT ` + scanAlertColumns + ` FROM scan_alerts WHERE id = $1`
+
+	alert := &domain.ScanAlert{}
+	if err := scanScanAlert(r.db.DB(ctx).QueryRow(ctx, query, id), alert); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("scan alert")
+		}
+		return nil, apperror.NewDatabaseError("failed to find scan alert", err)
+	}
+
+	return alert, nil
+}
+
+// List lists alerts matching filter, newest first
+func (r *PostgresScanAlertRepository) List(ctx context.Context, filter domain.ScanAlertFilter) ([]domain.ScanAlert, error) {
+	query := `
+		SELECT ` + scanAlertColumns + ` FROM scan_alerts
+		WHERE business_id = $1
+		  AND ($2 = '' OR rule = $2)
+		  AND ($3 = '' OR ($3 = 'open') = (acknowledged_at IS NULL))
+		  AND ($4 = 0 OR created_at >= $4)
+		  AND ($5 = 0 OR created_at <= $5)
+		ORDER BY created_at DESC, id
+		LIMIT $6
+	`
+
+	rows, err := r.db.DB(ctx).Query(ctx, query,
+		filter.BusinessID, filter.Rule, filter.Status, filter.From, filter.To, filter.Limit,
+	)
+	if err != nil {
+		return nil, apperror.NewDatabaseError("failed to list scan alerts", err)
+	}
+	defer rows.Close()
+
+	alerts := []domain.ScanAlert{}
+	for rows.Next() {
+		var alert domain.ScanAlert
+		if err := scanScanAlert(rows, &alert); err != nil {
+			return nil, apperror.NewDatabaseError("failed to scan scan alert", err)
+		}
+		alerts = append(alerts, alert)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDatabaseError("failed to iterate scan alerts", err)
+	}
+
+	return alerts, nil
+}
+
+// Acknowledge closes an open alert, failing with a conflict if it already
+// was acknowledged
+func (r *PostgresScanAlertRepository) Acknowledge(ctx context.Context, id, acknowledgedBy string, acknowledgedAt int64) error {
+	query := `
+		UPDATE scan_alerts SET acknowledged_at = $3, acknowledged_by = $2
+		WHERE id = $1 AND acknowledged_at IS NULL
+	`
+
+	result, err := r.db.DB(ctx).Exec(ctx, query, id, acknowledgedBy, acknowledgedAt)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to acknowledge scan alert", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewConflict("scan alert was already acknowledged")
+	}
+
+	return nil
+}
@@ -11,6 +11,13 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
+// serviceColumns is the select list matching scanService
+const serviceColumns = `id, business_id, name, total_slots, ticket_ttl_seconds, closes_at, archived_at, created_at, updated_at`
+
+func scanService(row rowScanner, s *domain.Service) error {
+	return row.Scan(&s.ID, &s.BusinessID, &s.Name, &s.TotalSlots, &s.TicketTTL, &s.ClosesAt, &s.ArchivedAt, &s.CreatedAt, &s.UpdatedAt)
+}
+
 type PostgresServiceRepository struct {
 	db *database.Pool
 }
@@ -21,15 +28,18 @@ func NewPostgresServiceRepository(db *database.Pool) *PostgresServiceRepository
 
 func (r *PostgresServiceRepository) Create(ctx context.Context, service *domain.Service) error {
 	query := `
-		INSERT INTO services (id, business_id, name, total_slots, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO services (id, business_id, name, total_slots, ticket_ttl_seconds, closes_at, archived_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
-	_, err := r.db.Exec(ctx, query,
+	_, err := r.db.DB(ctx).Exec(ctx, query,
 		service.ID,
 		service.BusinessID,
 		service.Name,
 		service.TotalSlots,
+		service.TicketTTL,
+		service.ClosesAt,
+		service.ArchivedAt,
 		service.CreatedAt,
 		service.UpdatedAt,
 	)
@@ -42,16 +52,13 @@ func (r *PostgresServiceRepository) Create(ctx context.Context, service *domain.
 
 func (r *PostgresServiceRepository) FindByID(ctx context.Context, id string) (*domain.Service, error) {
 	query := `
-		SELECT id, business_id, name, total_slots, created_at, updated_at
+		SELECT ` + serviceColumns + `
 		FROM services
 		WHERE id = $1
 	`
 
-	row := r.db.QueryRow(ctx, query, id)
 	service := &domain.Service{}
-
-	err := row.Scan(&service.ID, &service.BusinessID, &service.Name, &service.TotalSlots, &service.CreatedAt, &service.UpdatedAt)
-	if err != nil {
+	if err := scanService(r.db.DB(ctx).QueryRow(ctx, query, id), service); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, apperror.NewNotFound("service")
 		}
@@ -61,15 +68,58 @@ func (r *PostgresServiceRepository) FindByID(ctx context.Context, id string) (*d
 	return service, nil
 }
 
+// LockByID finds a service and locks its row until the surrounding
+// transaction ends, serializing concurrent updates of the same service
+func (r *PostgresServiceRepository) LockByID(ctx context.Context, id string) (*domain.Service, error) {
+	query := `
+		SELECT ` + serviceColumns + `
+		FROM services
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	service := &domain.Service{}
+	if err := scanService(r.db.DB(ctx).QueryRow(ctx, query, id), service); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("service")
+		}
+		return nil, apperror.NewDatabaseError("failed to lock service", err)
+	}
+
+	return service, nil
+}
+
+// ShareLockByID finds a service and share-locks its row until the
+// surrounding transaction ends, so it cannot be archived, resized or deleted
+// while check-ins use it
+func (r *PostgresServiceRepository) ShareLockByID(ctx context.Context, id string) (*domain.Service, error) {
+	query := `
+		SELECT ` + serviceColumns + `
+		FROM services
+		WHERE id = $1
+		FOR SHARE
+	`
+
+	service := &domain.Service{}
+	if err := scanService(r.db.DB(ctx).QueryRow(ctx, query, id), service); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("service")
+		}
+		return nil, apperror.NewDatabaseError("failed to lock service", err)
+	}
+
+	return service, nil
+}
+
 func (r *PostgresServiceRepository) ListByBusinessID(ctx context.Context, businessID string) ([]domain.Service, error) {
 	query := `
-		SELECT id, business_id, name, total_slots, created_at, updated_at
+		SELECT ` + serviceColumns + `
 		FROM services
 		WHERE business_id = $1
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.Query(ctx, query, businessID)
+	rows, err := r.db.DB(ctx).Query(ctx, query, businessID)
 	if err != nil {
 		return nil, apperror.NewDatabaseError("failed to list services", err)
 	}
@@ -78,7 +128,7 @@ func (r *PostgresServiceRepository) ListByBusinessID(ctx context.Context, busine
 	services := []domain.Service{}
 	for rows.Next() {
 		service := domain.Service{}
-		if err := rows.Scan(&service.ID, &service.BusinessID, &service.Name, &service.TotalSlots, &service.CreatedAt, &service.UpdatedAt); err != nil {
+		if err := scanService(rows, &service); err != nil {
 			return nil, apperror.NewDatabaseError("failed to scan service", err)
 		}
 		services = append(services, service)
@@ -94,17 +144,19 @@ func (r *PostgresServiceRepository) ListByBusinessID(ctx context.Context, busine
 func (r *PostgresServiceRepository) Update(ctx context.Context, service *domain.Service) error {
 	query := `
 		UPDATE services
-		SET name = $1, total_slots = $2, updated_at = $3
-		WHERE id = $4
+		SET name = $1, total_slots = $2, ticket_ttl_seconds = $3, closes_at = $4, archived_at = $5, updated_at = $6
+		WHERE id = $7
 	`
 
-	result, err := r.db.Exec(ctx, query, service.Name, service.TotalSlots, service.UpdatedAt, service.ID)
+	result, err := r.db.DB(ctx).Exec(ctx, query,
+		service.Name, service.TotalSlots, service.TicketTTL, service.ClosesAt, service.ArchivedAt, service.UpdatedAt, service.ID,
+	)
 	if err != nil {
 		return apperror.NewDatabaseError("failed to update service", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return apperror.NewNotFound("service not found")
+		return apperror.NewNotFound("service")
 	}
 
 	return nil
@@ -113,13 +165,13 @@ func (r *PostgresServiceRepository) Update(ctx context.Context, service *domain.
 func (r *PostgresServiceRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM services WHERE id = $1`
 
-	result, err := r.db.Exec(ctx, query, id)
+	result, err := r.db.DB(ctx).Exec(ctx, query, id)
 	if err != nil {
 		return apperror.NewDatabaseError("failed to delete service", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return apperror.NewNotFound("service not found")
+		return apperror.NewNotFound("service")
 	}
 
 	return nil
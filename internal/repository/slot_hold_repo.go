@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/database"
+	"CLOAKBE/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// slotHoldColumns is the select list matching scanSlotHold
+const slotHoldColumns = `id, service_id, slot_id, slot_number, COALESCE(zone_id, ''), customer_id,
+		status, expires_at, COALESCE(ticket_id, ''), created_at, updated_at`
+
+func scanSlotHold(row rowScanner, h *domain.SlotHold) error {
+	return row.Scan(
+		&h.ID, &h.ServiceID, &h.SlotID, &h.SlotNumber, &h.ZoneID, &h.CustomerID,
+		&h.Status, &h.ExpiresAt, &h.TicketID, &h.CreatedAt, &h.UpdatedAt,
+	)
+}
+
+// PostgresSlotHoldRepository implements SlotHoldRepository for PostgreSQL
+type PostgresSlotHoldRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresSlotHoldRepository creates a new slot hold repository
+func NewPostgresSlotHoldRepository(db *database.Pool) *PostgresSlotHoldRepository {
+	return &PostgresSlotHoldRepository{db: db}
+}
+
+// Create inserts a new hold
+func (r *PostgresSlotHoldRepository) Create(ctx context.Context, hold *domain.SlotHold) error {
+	query := `
+		INSERT INTO slot_holds (id, service_id, slot_id, slot_number, zone_id, customer_id, status, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.db.DB(ctx).Exec(ctx, query,
+		hold.ID, hold.ServiceID, hold.SlotID, hold.SlotNumber, hold.ZoneID, hold.CustomerID,
+		hold.Status, hold.ExpiresAt, hold.CreatedAt, hold.UpdatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return apperror.NewConflict("customer already holds a slot for this service")
+		}
+		return apperror.NewDatabaseError("failed to create slot hold", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a hold by ID
+func (r *PostgresSlotHoldRepository) FindByID(ctx context.Context, id string) (*domain.SlotHold, error) {
+	query := `SELECT ` + slotHoldColumns + ` FROM slot_holds WHERE id = $1`
+
+	h := &domain.SlotHold{}
+	if err := scanSlotHold(r.db.DB(ctx).QueryRow(ctx, query, id), h); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("slot hold")
+		}
+		return nil, apperror.NewDatabaseError("failed to find slot hold", err)
+	}
+
+	return h, nil
+}
+
+// ListActiveByServiceID lists the active holds of a service
+func (r *PostgresSlotHoldRepository) ListActiveByServiceID(ctx context.Context, serviceID string) ([]domain.SlotHold, error) {
+	query := `
+		SELECT ` + slotHoldColumns + `
+		FROM slot_holds
+		WHERE service_id = $1 AND status = $2
+		ORDER BY expires_at ASC
+	`
+	return r.list(ctx, query, serviceID, domain.HoldStatusActive)
+}
+
+// ListExpired lists active holds whose expiry has passed
+func (r *PostgresSlotHoldRepository) ListExpired(ctx context.Context, now int64, limit int) ([]domain.SlotHold, error) {
+	query := `
+		SELECT ` + slotHoldColumns + `
+		FROM slot_holds
+		WHERE status = $1 AND expires_at <= $2
+		ORDER BY expires_at ASC
+		LIMIT $3
+	`
+	return r.list(ctx, query, domain.HoldStatusActive, now, limit)
+}
+
+func (r *PostgresSlotHoldRepository) list(ctx context.Context, query string, args ...any) ([]domain.SlotHold, error) {
+	rows, err := r.db.DB(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperror.NewDatabaseError("failed to list slot holds", err)
+	}
+	defer rows.Close()
+
+	holds := []domain.SlotHold{}
+	for rows.Next() {
+		var h domain.SlotHold
+		if err := scanSlotHold(rows, &h); err != nil {
+			return nil, apperror.NewDatabaseError("failed to scan slot hold", err)
+		}
+		holds = append(holds, h)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDatabaseError("failed to iterate slot holds", err)
+	}
+
+	return holds, nil
+}
+
+// Update saves a hold's state if it still has fromStatus, returning a
+// conflict when another request moved it first
+func (r *PostgresSlotHoldRepository) Update(ctx context.Context, hold *domain.SlotHold, fromStatus string) error {
+	query := `
+		UPDATE slot_holds
+		SET status = $1, ticket_id = NULLIF($2, ''), updated_at = $3
+		WHERE id = $4 AND status = $5
+	`
+
+	result, err := r.db.DB(ctx).Exec(ctx, query, hold.Status, hold.TicketID, hold.UpdatedAt, hold.ID, fromStatus)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to update slot hold", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewConflict("slot hold is no longer " + fromStatus)
+	}
+
+	return nil
+}
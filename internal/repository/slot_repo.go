@@ -11,6 +11,13 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
+// slotColumns is the select list matching scanSlot
+const slotColumns = `id, service_id, COALESCE(zone_id, ''), slot_number, status, created_at, updated_at`
+
+func scanSlot(row rowScanner, s *domain.Slot) error {
+	return row.Scan(&s.ID, &s.ServiceID, &s.ZoneID, &s.SlotNumber, &s.Status, &s.CreatedAt, &s.UpdatedAt)
+}
+
 type PostgresSlotRepository struct {
 	db *database.Pool
 }
@@ -21,13 +28,14 @@ func NewPostgresSlotRepository(db *database.Pool) *PostgresSlotRepository {
 
 func (r *PostgresSlotRepository) Create(ctx context.Context, slot *domain.Slot) error {
 	query := `
-		INSERT INTO slots (id, service_id, slot_number, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO slots (id, service_id, zone_id, slot_number, status, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7)
 	`
 
-	_, err := r.db.Exec(ctx, query,
+	_, err := r.db.DB(ctx).Exec(ctx, query,
 		slot.ID,
 		slot.ServiceID,
+		slot.ZoneID,
 		slot.SlotNumber,
 		slot.Status,
 		slot.CreatedAt,
@@ -48,15 +56,15 @@ func (r *PostgresSlotRepository) CreateBatch(ctx context.Context, slots []domain
 
 	batch := &pgx.Batch{}
 	query := `
-		INSERT INTO slots (id, service_id, slot_number, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO slots (id, service_id, zone_id, slot_number, status, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7)
 	`
 
 	for _, slot := range slots {
-		batch.Queue(query, slot.ID, slot.ServiceID, slot.SlotNumber, slot.Status, slot.CreatedAt, slot.UpdatedAt)
+		batch.Queue(query, slot.ID, slot.ServiceID, slot.ZoneID, slot.SlotNumber, slot.Status, slot.CreatedAt, slot.UpdatedAt)
 	}
 
-	results := r.db.SendBatch(ctx, batch)
+	results := r.db.DB(ctx).SendBatch(ctx, batch)
 	defer results.Close()
 
 	for i := 0; i < len(slots); i++ {
@@ -71,18 +79,18 @@ func (r *PostgresSlotRepository) CreateBatch(ctx context.Context, slots []domain
 
 func (r *PostgresSlotRepository) FindByID(ctx context.Context, id string) (*domain.Slot, error) {
 	query := `
-		SELECT id, service_id, slot_number, status, created_at, updated_at
+		SELECT ` + slotColumns + `
 		FROM slots
 		WHERE id = $1
 	`
 
-	row := r.db.QueryRow(ctx, query, id)
+	row := r.db.DB(ctx).QueryRow(ctx, query, id)
 	slot := &domain.Slot{}
 
-	err := row.Scan(&slot.ID, &slot.ServiceID, &slot.SlotNumber, &slot.Status, &slot.CreatedAt, &slot.UpdatedAt)
+	err := scanSlot(row, slot)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, apperror.NewNotFound("slot not found")
+			return nil, apperror.NewNotFound("slot")
 		}
 		return nil, apperror.NewDatabaseError("failed to find slot", err)
 	}
@@ -93,13 +101,13 @@ func (r *PostgresSlotRepository) FindByID(ctx context.Context, id string) (*doma
 // ListByServiceID retrieves all slots for a service
 func (r *PostgresSlotRepository) ListByServiceID(ctx context.Context, serviceID string) ([]domain.Slot, error) {
 	query := `
-		SELECT id, service_id, slot_number, status, created_at, updated_at
+		SELECT ` + slotColumns + `
 		FROM slots
 		WHERE service_id = $1
 		ORDER BY slot_number ASC
 	`
 
-	rows, err := r.db.Query(ctx, query, serviceID)
+	rows, err := r.db.DB(ctx).Query(ctx, query, serviceID)
 	if err != nil {
 		return nil, apperror.NewDatabaseError("failed to list slots", err)
 	}
@@ -108,7 +116,7 @@ func (r *PostgresSlotRepository) ListByServiceID(ctx context.Context, serviceID
 	slots := []domain.Slot{}
 	for rows.Next() {
 		slot := domain.Slot{}
-		if err := rows.Scan(&slot.ID, &slot.ServiceID, &slot.SlotNumber, &slot.Status, &slot.CreatedAt, &slot.UpdatedAt); err != nil {
+		if err := scanSlot(rows, &slot); err != nil {
 			return nil, apperror.NewDatabaseError("failed to scan slot", err)
 		}
 		slots = append(slots, slot)
@@ -124,8 +132,11 @@ func (r *PostgresSlotRepository) ListByServiceID(ctx context.Context, serviceID
 // ClaimNextFreeSlot claims the next available free slot using row-level locking
 // This prevents race conditions when multiple check-ins occur simultaneously
 // Uses: SELECT ... FOR UPDATE SKIP LOCKED to prevent deadlocks and allow concurrent operations
-func (r *PostgresSlotRepository) ClaimNextFreeSlot(ctx context.Context, serviceID string) (*domain.Slot, error) {
-	tx, err := r.db.Begin(ctx)
+// Inside a unit of work the inner transaction becomes a savepoint, so the slot stays locked
+// until the caller commits
+// An empty zoneID claims from any zone.
+func (r *PostgresSlotRepository) ClaimNextFreeSlot(ctx context.Context, serviceID, zoneID string) (*domain.Slot, error) {
+	tx, err := r.db.DB(ctx).Begin(ctx)
 	if err != nil {
 		return nil, apperror.NewDatabaseError("failed to begin transaction", err)
 	}
@@ -133,18 +144,18 @@ func (r *PostgresSlotRepository) ClaimNextFreeSlot(ctx context.Context, serviceI
 
 	// Find and lock the next free slot (SKIP LOCKED prevents waiting on locked rows)
 	selectQuery := `
-		SELECT id, service_id, slot_number, status, created_at, updated_at
+		SELECT ` + slotColumns + `
 		FROM slots
-		WHERE service_id = $1 AND status = $2
+		WHERE service_id = $1 AND status = $2 AND ($3 = '' OR zone_id = $3)
 		ORDER BY slot_number ASC
 		LIMIT 1
 		FOR UPDATE SKIP LOCKED
 	`
 
-	row := tx.QueryRow(ctx, selectQuery, serviceID, domain.SlotStatusFree)
+	row := tx.QueryRow(ctx, selectQuery, serviceID, domain.SlotStatusFree, zoneID)
 	slot := &domain.Slot{}
 
-	err = row.Scan(&slot.ID, &slot.ServiceID, &slot.SlotNumber, &slot.Status, &slot.CreatedAt, &slot.UpdatedAt)
+	err = scanSlot(row, slot)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, apperror.NewConflict("no free slots available")
@@ -157,13 +168,66 @@ func (r *PostgresSlotRepository) ClaimNextFreeSlot(ctx context.Context, serviceI
 		UPDATE slots
 		SET status = $1, updated_at = $2
 		WHERE id = $3
-		RETURNING id, service_id, slot_number, status, created_at, updated_at
+		RETURNING ` + slotColumns + `
 	`
 
 	now := domain.NowTimestamp()
 	updateRow := tx.QueryRow(ctx, updateQuery, domain.SlotStatusOccupied, now, slot.ID)
 
-	err = updateRow.Scan(&slot.ID, &slot.ServiceID, &slot.SlotNumber, &slot.Status, &slot.CreatedAt, &slot.UpdatedAt)
+	err = scanSlot(updateRow, slot)
+	if err != nil {
+		return nil, apperror.NewDatabaseError("failed to update slot status", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, apperror.NewDatabaseError("failed to commit transaction", err)
+	}
+
+	return slot, nil
+}
+
+// ClaimSlot claims one specific slot. Unlike ClaimNextFreeSlot it waits for a
+// concurrent claim of the same row to finish instead of skipping it, and
+// returns a conflict naming the slot when it is not free.
+func (r *PostgresSlotRepository) ClaimSlot(ctx context.Context, serviceID string, slotNumber int) (*domain.Slot, error) {
+	tx, err := r.db.DB(ctx).Begin(ctx)
+	if err != nil {
+		return nil, apperror.NewDatabaseError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	selectQuery := `
+		SELECT ` + slotColumns + `
+		FROM slots
+		WHERE service_id = $1 AND slot_number = $2
+		FOR UPDATE
+	`
+
+	row := tx.QueryRow(ctx, selectQuery, serviceID, slotNumber)
+	slot := &domain.Slot{}
+
+	err = scanSlot(row, slot)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("slot")
+		}
+		return nil, apperror.NewDatabaseError("failed to find slot", err)
+	}
+
+	if slot.Status != domain.SlotStatusFree {
+		return nil, newSlotOccupiedError(slotNumber)
+	}
+
+	updateQuery := `
+		UPDATE slots
+		SET status = $1, updated_at = $2
+		WHERE id = $3
+		RETURNING ` + slotColumns + `
+	`
+
+	updateRow := tx.QueryRow(ctx, updateQuery, domain.SlotStatusOccupied, domain.NowTimestamp(), slot.ID)
+
+	err = scanSlot(updateRow, slot)
 	if err != nil {
 		return nil, apperror.NewDatabaseError("failed to update slot status", err)
 	}
@@ -175,6 +239,98 @@ func (r *PostgresSlotRepository) ClaimNextFreeSlot(ctx context.Context, serviceI
 	return slot, nil
 }
 
+// newSlotOccupiedError reports that a requested slot is taken; it is kept
+// distinct from "no free slots available" so clients can offer another slot
+func newSlotOccupiedError(slotNumber int) error {
+	appErr := apperror.NewConflict("slot is already occupied")
+	appErr.Details["slot_number"] = slotNumber
+	return appErr
+}
+
+// DeleteTrailing removes the slots numbered above keep. The rows are locked
+// first so a concurrent check-in cannot claim one of them mid-resize; if any
+// of them is not free nothing is deleted and a conflict lists the blockers.
+func (r *PostgresSlotRepository) DeleteTrailing(ctx context.Context, serviceID string, keep int) error {
+	lockQuery := `
+		SELECT id, slot_number, status
+		FROM slots
+		WHERE service_id = $1 AND slot_number > $2
+		ORDER BY slot_number ASC
+		FOR UPDATE
+	`
+	return r.deleteFree(ctx, lockQuery, serviceID, keep)
+}
+
+// DeleteZoneSlots keeps the keep lowest numbered slots of a zone and removes
+// the rest, with the same locking and all-or-nothing rule as DeleteTrailing
+func (r *PostgresSlotRepository) DeleteZoneSlots(ctx context.Context, zoneID string, keep int) error {
+	lockQuery := `
+		SELECT id, slot_number, status
+		FROM slots
+		WHERE zone_id = $1
+		ORDER BY slot_number ASC
+		OFFSET $2
+		FOR UPDATE
+	`
+	return r.deleteFree(ctx, lockQuery, zoneID, keep)
+}
+
+// deleteFree locks the slots selected by lockQuery, which must return
+// id, slot_number and status, and deletes them if all of them are free
+func (r *PostgresSlotRepository) deleteFree(ctx context.Context, lockQuery string, args ...any) error {
+	tx, err := r.db.DB(ctx).Begin(ctx)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, lockQuery, args...)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to lock slots", err)
+	}
+
+	ids := []string{}
+	blocked := []int{}
+	for rows.Next() {
+		var id, status string
+		var number int
+		if err := rows.Scan(&id, &number, &status); err != nil {
+			rows.Close()
+			return apperror.NewDatabaseError("failed to scan slot", err)
+		}
+		ids = append(ids, id)
+		if status != domain.SlotStatusFree {
+			blocked = append(blocked, number)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return apperror.NewDatabaseError("failed to iterate slots", err)
+	}
+
+	if len(blocked) > 0 {
+		return newSlotsInUseError(blocked)
+	}
+
+	deleteQuery := `DELETE FROM slots WHERE id = ANY($1)`
+	if _, err := tx.Exec(ctx, deleteQuery, ids); err != nil {
+		return apperror.NewDatabaseError("failed to delete slots", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return apperror.NewDatabaseError("failed to commit transaction", err)
+	}
+
+	return nil
+}
+
+// newSlotsInUseError reports the slots that prevent a service from shrinking
+func newSlotsInUseError(slotNumbers []int) error {
+	appErr := apperror.NewConflict("cannot remove slots that are in use")
+	appErr.Details["slot_numbers"] = slotNumbers
+	return appErr
+}
+
 // UpdateStatus updates a slot's status (used for releasing tickets)
 func (r *PostgresSlotRepository) UpdateStatus(ctx context.Context, id string, status string) error {
 	query := `
@@ -183,31 +339,82 @@ func (r *PostgresSlotRepository) UpdateStatus(ctx context.Context, id string, st
 		WHERE id = $3
 	`
 
-	result, err := r.db.Exec(ctx, query, status, domain.NowTimestamp(), id)
+	result, err := r.db.DB(ctx).Exec(ctx, query, status, domain.NowTimestamp(), id)
 	if err != nil {
 		return apperror.NewDatabaseError("failed to update slot", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return apperror.NewNotFound("slot not found")
+		return apperror.NewNotFound("slot")
 	}
 
 	return nil
 }
 
 // CountSlotsByStatus returns counts of slots in each status for a service
-func (r *PostgresSlotRepository) CountSlotsByStatus(ctx context.Context, serviceID string) (total, occupied int, err error) {
+func (r *PostgresSlotRepository) CountSlotsByStatus(ctx context.Context, serviceID string) (domain.SlotCounts, error) {
 	query := `
-		SELECT COUNT(*) total,
-		       COUNT(CASE WHEN status = $1 THEN 1 END) occupied
+		SELECT status, COUNT(*)
 		FROM slots
-		WHERE service_id = $2
+		WHERE service_id = $1
+		GROUP BY status
 	`
 
-	row := r.db.QueryRow(ctx, query, domain.SlotStatusOccupied, serviceID)
-	if err := row.Scan(&total, &occupied); err != nil {
-		return 0, 0, apperror.NewDatabaseError("failed to count slots", err)
+	rows, err := r.db.DB(ctx).Query(ctx, query, serviceID)
+	if err != nil {
+		return nil, apperror.NewDatabaseError("failed to count slots", err)
+	}
+	defer rows.Close()
+
+	counts := domain.SlotCounts{}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, apperror.NewDatabaseError("failed to scan slot count", err)
+		}
+		counts[status] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDatabaseError("failed to iterate slot counts", err)
+	}
+
+	return counts, nil
+}
+
+// CountSlotsByZone returns slot counts for every zone of a service that has slots
+func (r *PostgresSlotRepository) CountSlotsByZone(ctx context.Context, serviceID string) ([]domain.ZoneSlotCount, error) {
+	query := `
+		SELECT zone_id, status, COUNT(*)
+		FROM slots
+		WHERE service_id = $1 AND zone_id IS NOT NULL
+		GROUP BY zone_id, status
+		ORDER BY zone_id
+	`
+
+	rows, err := r.db.DB(ctx).Query(ctx, query, serviceID)
+	if err != nil {
+		return nil, apperror.NewDatabaseError("failed to count slots by zone", err)
+	}
+	defer rows.Close()
+
+	counts := []domain.ZoneSlotCount{}
+	for rows.Next() {
+		var zoneID, status string
+		var count int
+		if err := rows.Scan(&zoneID, &status, &count); err != nil {
+			return nil, apperror.NewDatabaseError("failed to scan slot count", err)
+		}
+		if len(counts) == 0 || counts[len(counts)-1].ZoneID != zoneID {
+			counts = append(counts, domain.ZoneSlotCount{ZoneID: zoneID, Counts: domain.SlotCounts{}})
+		}
+		counts[len(counts)-1].Counts[status] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDatabaseError("failed to iterate slot counts", err)
 	}
 
-	return total, occupied, nil
+	return counts, nil
 }
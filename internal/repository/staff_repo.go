@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/database"
+	"CLOAKBE/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// staffColumns is the select list matching scanStaff
+const staffColumns = `id, business_id, email, COALESCE(name, ''), role, status, COALESCE(password, ''),
+		COALESCE(invite_token_hash, ''), COALESCE(invite_expires_at, 0), created_at, updated_at`
+
+func scanStaff(row rowScanner, s *domain.Staff) error {
+	return row.Scan(
+		&s.ID, &s.BusinessID, &s.Email, &s.Name, &s.Role, &s.Status, &s.Password,
+		&s.InviteTokenHash, &s.InviteExpiresAt, &s.CreatedAt, &s.UpdatedAt,
+	)
+}
+
+// PostgresStaffRepository implements StaffRepository for PostgreSQL
+type PostgresStaffRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresStaffRepository creates a new staff repository
+func NewPostgresStaffRepository(db *database.Pool) *PostgresStaffRepository {
+	return &PostgresStaffRepository{db: db}
+}
+
+// Create inserts a new staff member
+func (r *PostgresStaffRepository) Create(ctx context.Context, staff *domain.Staff) error {
+	query := `
+		INSERT INTO staff (id, business_id, email, name, role, status, password, invite_token_hash, invite_expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, 0), $10, $11)
+	`
+
+	_, err := r.db.DB(ctx).Exec(ctx, query,
+		staff.ID, staff.BusinessID, staff.Email, staff.Name, staff.Role, staff.Status, staff.Password,
+		staff.InviteTokenHash, staff.InviteExpiresAt, staff.CreatedAt, staff.UpdatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return apperror.NewConflict("staff member already exists")
+		}
+		return apperror.NewDatabaseError("failed to create staff member", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a staff member by ID
+func (r *PostgresStaffRepository) FindByID(ctx context.Context, id string) (*domain.Staff, error) {
+	return r.find(ctx, `SELECT `+staffColumns+` FROM staff WHERE id = $1`, id)
+}
+
+// FindByInviteToken retrieves the staff member a pending invite was sent to
+func (r *PostgresStaffRepository) FindByInviteToken(ctx context.Context, tokenHash string) (*domain.Staff, error) {
+	return r.find(ctx, `SELECT `+staffColumns+` FROM staff WHERE invite_token_hash = $1`, tokenHash)
+}
+
+// FindByEmail retrieves a business's staff member by email
+func (r *PostgresStaffRepository) FindByEmail(ctx context.Context, businessID, email string) (*domain.Staff, error) {
+	return r.find(ctx, `SELECT `+staffColumns+` FROM staff WHERE business_id = $1 AND email = $2`, businessID, email)
+}
+
+// ListByEmail lists the staff accounts of one person across businesses
+func (r *PostgresStaffRepository) ListByEmail(ctx context.Context, email string) ([]domain.Staff, error) {
+	return r.list(ctx, `SELECT `+staffColumns+` FROM staff WHERE email = $1 ORDER BY created_at ASC`, email)
+}
+
+// ListByBusinessID lists a business's staff, oldest first
+func (r *PostgresStaffRepository) ListByBusinessID(ctx context.Context, businessID string) ([]domain.Staff, error) {
+	return r.list(ctx, `SELECT `+staffColumns+` FROM staff WHERE business_id = $1 ORDER BY created_at ASC`, businessID)
+}
+
+// Update saves a staff member's profile, role, status, password and invite
+func (r *PostgresStaffRepository) Update(ctx context.Context, staff *domain.Staff) error {
+	query := `
+		UPDATE staff
+		SET name = NULLIF($2, ''), role = $3, status = $4, password = NULLIF($5, ''),
+			invite_token_hash = NULLIF($6, ''), invite_expires_at = NULLIF($7, 0), updated_at = $8
+		WHERE id = $1
+	`
+
+	result, err := r.db.DB(ctx).Exec(ctx, query,
+		staff.ID, staff.Name, staff.Role, staff.Status, staff.Password,
+		staff.InviteTokenHash, staff.InviteExpiresAt, staff.UpdatedAt,
+	)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to update staff member", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewNotFound("staff member")
+	}
+
+	return nil
+}
+
+// AcceptInvite activates an invited staff member with the name and
+// password given, failing with a conflict unless the invite with tokenHash
+// is still open
+func (r *PostgresStaffRepository) AcceptInvite(ctx context.Context, staff *domain.Staff, tokenHash string) error {
+	query := `
+		UPDATE staff
+		SET name = NULLIF($2, ''), password = $3, status = $4,
+			invite_token_hash = NULL, invite_expires_at = NULL, updated_at = $5
+		WHERE id = $1 AND status = $6 AND invite_token_hash = $7
+	`
+
+	result, err := r.db.DB(ctx).Exec(ctx, query,
+		staff.ID, staff.Name, staff.Password, domain.StaffStatusActive, staff.UpdatedAt,
+		domain.StaffStatusInvited, tokenHash,
+	)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to accept invite", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewConflict("invite was already accepted")
+	}
+
+	return nil
+}
+
+func (r *PostgresStaffRepository) find(ctx context.Context, query string, args ...any) (*domain.Staff, error) {
+	s := &domain.Staff{}
+	if err := scanStaff(r.db.DB(ctx).QueryRow(ctx, query, args...), s); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("staff member")
+		}
+		return nil, apperror.NewDatabaseError("failed to find staff member", err)
+	}
+	return s, nil
+}
+
+func (r *PostgresStaffRepository) list(ctx context.Context, query string, args ...any) ([]domain.Staff, error) {
+	rows, err := r.db.DB(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperror.NewDatabaseError("failed to list staff", err)
+	}
+	defer rows.Close()
+
+	staff := []domain.Staff{}
+	for rows.Next() {
+		var s domain.Staff
+		if err := scanStaff(rows, &s); err != nil {
+			return nil, apperror.NewDatabaseError("failed to scan staff member", err)
+		}
+		staff = append(staff, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDatabaseError("failed to iterate staff", err)
+	}
+
+	return staff, nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/database"
+	"CLOAKBE/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgresSyncEventRepository implements SyncEventRepository for PostgreSQL
+type PostgresSyncEventRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresSyncEventRepository creates a new sync event repository
+func NewPostgresSyncEventRepository(db *database.Pool) *PostgresSyncEventRepository {
+	return &PostgresSyncEventRepository{db: db}
+}
+
+// Create records a replayed device event
+func (r *PostgresSyncEventRepository) Create(ctx context.Context, e *domain.SyncEvent) error {
+	query := `
+		INSERT INTO sync_events (id, business_id, device_id, type, ticket_id, occurred_at, received_at, result, message)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9)
+	`
+
+	_, err := r.db.DB(ctx).Exec(ctx, query,
+		e.ID, e.BusinessID, e.DeviceID, e.Type, e.TicketID, e.OccurredAt, e.ReceivedAt, e.Result, e.Message,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return apperror.NewConflict("sync event already recorded")
+		}
+		return apperror.NewDatabaseError("failed to create sync event", err)
+	}
+
+	return nil
+}
+
+// FindByID finds a business's replayed event by its device-generated ID
+func (r *PostgresSyncEventRepository) FindByID(ctx context.Context, businessID, id string) (*domain.SyncEvent, error) {
+	query := `
+		SELECT id, business_id, device_id, type, COALESCE(ticket_id, ''), occurred_at, received_at, result, COALESCE(message, '')
+		FROM sync_events WHERE business_id = $1 AND id = $2
+	`
+
+	e := &domain.SyncEvent{}
+	err := r.db.DB(ctx).QueryRow(ctx, query, businessID, id).Scan(
+		&e.ID, &e.BusinessID, &e.DeviceID, &e.Type, &e.TicketID, &e.OccurredAt, &e.ReceivedAt, &e.Result, &e.Message,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("sync event")
+		}
+		return nil, apperror.NewDatabaseError("failed to find sync event", err)
+	}
+
+	return e, nil
+}
+
+// FindAppliedReleaseByTicketID finds the device release that released a ticket
+func (r *PostgresSyncEventRepository) FindAppliedReleaseByTicketID(ctx context.Context, ticketID string) (*domain.SyncEvent, error) {
+	query := `
+		SELECT id, business_id, device_id, type, COALESCE(ticket_id, ''), occurred_at, received_at, result, COALESCE(message, '')
+		FROM sync_events
+		WHERE ticket_id = $1 AND type = $2 AND result = $3
+		ORDER BY received_at ASC
+		LIMIT 1
+	`
+
+	e := &domain.SyncEvent{}
+	err := r.db.DB(ctx).QueryRow(ctx, query, ticketID, domain.SyncEventTypeRelease, domain.SyncResultApplied).Scan(
+		&e.ID, &e.BusinessID, &e.DeviceID, &e.Type, &e.TicketID, &e.OccurredAt, &e.ReceivedAt, &e.Result, &e.Message,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("sync event")
+		}
+		return nil, apperror.NewDatabaseError("failed to find sync event", err)
+	}
+
+	return e, nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/database"
+	"CLOAKBE/internal/domain"
+)
+
+// ticketEventColumns is the select list matching scanTicketEvent
+const ticketEventColumns = `id, seq, ticket_id, business_id, service_id, type, actor_type, COALESCE(actor_id, ''),
+		metadata, occurred_at`
+
+func scanTicketEvent(row rowScanner, e *domain.TicketEvent) error {
+	return row.Scan(
+		&e.ID, &e.Seq, &e.TicketID, &e.BusinessID, &e.ServiceID, &e.Type, &e.ActorType, &e.ActorID,
+		&e.Metadata, &e.OccurredAt,
+	)
+}
+
+// PostgresTicketEventRepository implements TicketEventRepository for PostgreSQL
+type PostgresTicketEventRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresTicketEventRepository creates a new ticket event repository
+func NewPostgresTicketEventRepository(db *database.Pool) *PostgresTicketEventRepository {
+	return &PostgresTicketEventRepository{db: db}
+}
+
+// Create appends an event and sets its Seq
+func (r *PostgresTicketEventRepository) Create(ctx context.Context, event *domain.TicketEvent) error {
+	query := `
+		INSERT INTO ticket_events (id, ticket_id, business_id, service_id, type, actor_type, actor_id, metadata, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9)
+		RETURNING seq
+	`
+
+	metadata := event.Metadata
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+
+	err := r.db.DB(ctx).QueryRow(ctx, query,
+		event.ID, event.TicketID, event.BusinessID, event.ServiceID, event.Type, event.ActorType, event.ActorID,
+		metadata, event.OccurredAt,
+	).Scan(&event.Seq)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to create ticket event", err)
+	}
+
+	return nil
+}
+
+// ListByTicketID lists a business's events of a ticket in the order they
+// were written
+func (r *PostgresTicketEventRepository) ListByTicketID(ctx context.Context, ticketID, businessID string) ([]domain.TicketEvent, error) {
+	query := `
+		SELECT ` + ticketEventColumns + ` FROM ticket_events
+		WHERE ticket_id = $1 AND business_id = $2
+		ORDER BY seq
+	`
+	return r.list(ctx, query, ticketID, businessID)
+}
+
+// List lists events matching filter in the order they were written
+func (r *PostgresTicketEventRepository) List(ctx context.Context, filter domain.TicketEventFilter) ([]domain.TicketEvent, error) {
+	query := `
+		SELECT ` + ticketEventColumns + ` FROM ticket_events
+		WHERE business_id = $1
+		  AND ($2 = '' OR service_id = $2)
+		  AND ($3 = '' OR type = $3)
+		  AND ($4 = 0 OR occurred_at >= $4)
+		  AND ($5 = 0 OR occurred_at <= $5)
+		  AND seq > $6
+		ORDER BY seq
+		LIMIT $7
+	`
+	return r.list(ctx, query,
+		filter.BusinessID, filter.ServiceID, filter.Type, filter.From, filter.To, filter.AfterSeq, filter.Limit,
+	)
+}
+
+func (r *PostgresTicketEventRepository) list(ctx context.Context, query string, args ...any) ([]domain.TicketEvent, error) {
+	rows, err := r.db.DB(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperror.NewDatabaseError("failed to list ticket events", err)
+	}
+	defer rows.Close()
+
+	events := []domain.TicketEvent{}
+	for rows.Next() {
+		var event domain.TicketEvent
+		if err := scanTicketEvent(rows, &event); err != nil {
+			return nil, apperror.NewDatabaseError("failed to scan ticket event", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDatabaseError("failed to iterate ticket events", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/database"
+	"CLOAKBE/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ticketReissueColumns is the select list matching scanTicketReissue
+const ticketReissueColumns = `id, ticket_id, revoked_digest, identity_method, COALESCE(identity_note, ''), reissued_by, created_at`
+
+func scanTicketReissue(row rowScanner, r *domain.TicketReissue) error {
+	return row.Scan(&r.ID, &r.TicketID, &r.RevokedDigest, &r.IdentityMethod, &r.IdentityNote, &r.ReissuedBy, &r.CreatedAt)
+}
+
+// PostgresTicketReissueRepository implements TicketReissueRepository for PostgreSQL
+type PostgresTicketReissueRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresTicketReissueRepository creates a new ticket reissue repository
+func NewPostgresTicketReissueRepository(db *database.Pool) *PostgresTicketReissueRepository {
+	return &PostgresTicketReissueRepository{db: db}
+}
+
+// Create records a reissue and its revoked digest
+func (r *PostgresTicketReissueRepository) Create(ctx context.Context, reissue *domain.TicketReissue) error {
+	query := `
+		INSERT INTO ticket_reissues (id, ticket_id, revoked_digest, identity_method, identity_note, reissued_by, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+	`
+
+	_, err := r.db.DB(ctx).Exec(ctx, query,
+		reissue.ID, reissue.TicketID, reissue.RevokedDigest, reissue.IdentityMethod,
+		reissue.IdentityNote, reissue.ReissuedBy, reissue.CreatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return apperror.NewConflict("ticket was already reissued")
+		}
+		return apperror.NewDatabaseError("failed to create ticket reissue", err)
+	}
+
+	return nil
+}
+
+// FindByRevokedDigest finds the reissue that revoked a signature digest
+func (r *PostgresTicketReissueRepository) FindByRevokedDigest(ctx context.Context, digest string) (*domain.TicketReissue, error) {
+	query := `SELECT ` + ticketReissueColumns + ` FROM ticket_reissues WHERE revoked_digest = $1`
+
+	reissue := &domain.TicketReissue{}
+	if err := scanTicketReissue(r.db.DB(ctx).QueryRow(ctx, query, digest), reissue); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("ticket reissue")
+		}
+		return nil, apperror.NewDatabaseError("failed to find ticket reissue", err)
+	}
+
+	return reissue, nil
+}
+
+// ListByTicketID lists the reissues of a ticket, oldest first
+func (r *PostgresTicketReissueRepository) ListByTicketID(ctx context.Context, ticketID string) ([]domain.TicketReissue, error) {
+	query := `SELECT ` + ticketReissueColumns + ` FROM ticket_reissues WHERE ticket_id = $1 ORDER BY created_at ASC`
+
+	rows, err := r.db.DB(ctx).Query(ctx, query, ticketID)
+	if err != nil {
+		return nil, apperror.NewDatabaseError("failed to list ticket reissues", err)
+	}
+	defer rows.Close()
+
+	reissues := []domain.TicketReissue{}
+	for rows.Next() {
+		var reissue domain.TicketReissue
+		if err := scanTicketReissue(rows, &reissue); err != nil {
+			return nil, apperror.NewDatabaseError("failed to scan ticket reissue", err)
+		}
+		reissues = append(reissues, reissue)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDatabaseError("failed to iterate ticket reissues", err)
+	}
+
+	return reissues, nil
+}
@@ -2,11 +2,35 @@ package repository
 
 import (
 	"context"
+	"errors"
 
+	"CLOAKBE/internal/apperror"
 	"CLOAKBE/internal/database"
 	"CLOAKBE/internal/domain"
+
+	"github.com/jackc/pgx/v5"
 )
 
+// ticketColumns is the select list matching scanTicket
+const ticketColumns = `t.id, t.service_id, COALESCE(t.slot_id, ''), t.slot_number, COALESCE(t.customer_id, ''), t.status,
+		COALESCE(t.hmac_digest, ''), t.issued_at, COALESCE(t.released_at, 0), COALESCE(t.expired_at, 0),
+		COALESCE(t.expiry_reason, ''), t.qr_generation, COALESCE(t.issued_by, ''), COALESCE(t.scanned_by, ''),
+		COALESCE(t.scanned_at, 0), COALESCE(t.released_by, ''), t.created_at, t.updated_at`
+
+// rowScanner is implemented by pgx.Row and pgx.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTicket(row rowScanner, t *domain.Ticket) error {
+	return row.Scan(
+		&t.ID, &t.ServiceID, &t.SlotID, &t.SlotNumber, &t.CustomerID, &t.Status,
+		&t.HMACDigest, &t.IssuedAt, &t.ReleasedAt, &t.ExpiredAt,
+		&t.ExpiryReason, &t.QRGeneration, &t.IssuedBy, &t.ScannedBy,
+		&t.ScannedAt, &t.ReleasedBy, &t.CreatedAt, &t.UpdatedAt,
+	)
+}
+
 // PostgresTicketRepository implements TicketRepository for PostgreSQL
 type PostgresTicketRepository struct {
 	db *database.Pool
@@ -20,10 +44,10 @@ func NewPostgresTicketRepository(db *database.Pool) *PostgresTicketRepository {
 // Create inserts a new ticket
 func (r *PostgresTicketRepository) Create(ctx context.Context, ticket *domain.Ticket) error {
 	query := `
-		INSERT INTO tickets (id, service_id, slot_id, slot_number, customer_id, status, hmac_digest, issued_at, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		INSERT INTO tickets (id, service_id, slot_id, slot_number, customer_id, status, hmac_digest, issued_at, issued_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, NULLIF($9, ''), $10, $11)
 	`
-	_, err := r.db.Exec(ctx, query,
+	_, err := r.db.DB(ctx).Exec(ctx, query,
 		ticket.ID,
 		ticket.ServiceID,
 		ticket.SlotID,
@@ -32,100 +56,257 @@ func (r *PostgresTicketRepository) Create(ctx context.Context, ticket *domain.Ti
 		ticket.Status,
 		ticket.HMACDigest,
 		ticket.IssuedAt,
+		ticket.IssuedBy,
+		ticket.CreatedAt,
+		ticket.UpdatedAt,
 	)
-	return err
+	if err != nil {
+		return apperror.NewDatabaseError("failed to create ticket", err)
+	}
+
+	return nil
 }
 
 // FindByID retrieves a ticket by ID
 func (r *PostgresTicketRepository) FindByID(ctx context.Context, id string) (*domain.Ticket, error) {
-	query := `
-		SELECT id, service_id, slot_id, slot_number, customer_id, status, hmac_digest, issued_at, released_at, created_at, updated_at
-		FROM tickets WHERE id = $1
-	`
+	query := `SELECT ` + ticketColumns + ` FROM tickets t WHERE t.id = $1`
+
 	t := &domain.Ticket{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&t.ID, &t.ServiceID, &t.SlotID, &t.SlotNumber, &t.CustomerID, &t.Status, &t.HMACDigest, &t.IssuedAt, &t.ReleasedAt, &t.CreatedAt, &t.UpdatedAt,
-	)
-	if err != nil {
-		return nil, err
+	if err := scanTicket(r.db.DB(ctx).QueryRow(ctx, query, id), t); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("ticket")
+		}
+		return nil, apperror.NewDatabaseError("failed to find ticket", err)
 	}
 	return t, nil
 }
 
 // FindByHMAC finds a ticket by HMAC digest
 func (r *PostgresTicketRepository) FindByHMAC(ctx context.Context, hmacDigest string) (*domain.Ticket, error) {
-	query := `
-		SELECT id, service_id, slot_id, slot_number, customer_id, status, hmac_digest, issued_at, released_at, created_at, updated_at
-		FROM tickets WHERE hmac_digest = $1
-	`
+	query := `SELECT ` + ticketColumns + ` FROM tickets t WHERE t.hmac_digest = $1`
+
 	t := &domain.Ticket{}
-	err := r.db.QueryRow(ctx, query, hmacDigest).Scan(
-		&t.ID, &t.ServiceID, &t.SlotID, &t.SlotNumber, &t.CustomerID, &t.Status, &t.HMACDigest, &t.IssuedAt, &t.ReleasedAt, &t.CreatedAt, &t.UpdatedAt,
-	)
-	if err != nil {
-		return nil, err
+	if err := scanTicket(r.db.DB(ctx).QueryRow(ctx, query, hmacDigest), t); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("ticket")
+		}
+		return nil, apperror.NewDatabaseError("failed to find ticket by hmac", err)
 	}
 	return t, nil
 }
 
-// ListByCustomerID lists tickets for a customer
-func (r *PostgresTicketRepository) ListByCustomerID(ctx context.Context, customerID string) ([]domain.Ticket, error) {
+// ListByCustomerID lists tickets for a customer, optionally restricted to
+// the services of one business
+func (r *PostgresTicketRepository) ListByCustomerID(ctx context.Context, customerID, businessID string) ([]domain.Ticket, error) {
+	query := `
+		SELECT ` + ticketColumns + `
+		FROM tickets t
+		JOIN services s ON s.id = t.service_id
+		WHERE t.customer_id = $1 AND ($2 = '' OR s.business_id = $2)
+		ORDER BY t.issued_at DESC
+	`
+	return r.list(ctx, query, customerID, businessID)
+}
+
+// ListActiveByServiceID lists active tickets for a service
+func (r *PostgresTicketRepository) ListActiveByServiceID(ctx context.Context, serviceID string) ([]domain.Ticket, error) {
+	query := `SELECT ` + ticketColumns + ` FROM tickets t WHERE t.service_id = $1 AND t.status = 'active' ORDER BY t.slot_number ASC`
+	return r.list(ctx, query, serviceID)
+}
+
+// CountByServiceID counts the tickets a service ever issued, whatever
+// their status
+func (r *PostgresTicketRepository) CountByServiceID(ctx context.Context, serviceID string) (int, error) {
+	var count int
+	if err := r.db.DB(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM tickets WHERE service_id = $1`, serviceID).Scan(&count); err != nil {
+		return 0, apperror.NewDatabaseError("failed to count tickets", err)
+	}
+
+	return count, nil
+}
+
+// ListExpirable lists active tickets past their service's TTL or closing time,
+// optionally restricted to one business
+func (r *PostgresTicketRepository) ListExpirable(ctx context.Context, businessID string, now int64, limit int) ([]domain.Ticket, error) {
+	query := `
+		SELECT ` + ticketColumns + `
+		FROM tickets t
+		JOIN services s ON s.id = t.service_id
+		WHERE t.status = 'active'
+		  AND ($1 = '' OR s.business_id = $1)
+		  AND ((s.ticket_ttl_seconds > 0 AND t.issued_at + s.ticket_ttl_seconds <= $2)
+		    OR (s.closes_at > 0 AND s.closes_at <= $2))
+		ORDER BY t.issued_at ASC
+		LIMIT $3
+	`
+	return r.list(ctx, query, businessID, now, limit)
+}
+
+// Search lists a business's tickets matching a lost-ticket lookup, newest first
+func (r *PostgresTicketRepository) Search(ctx context.Context, search domain.TicketSearch) ([]domain.Ticket, error) {
 	query := `
-		SELECT id, service_id, slot_id, slot_number, customer_id, status, hmac_digest, issued_at, released_at, created_at, updated_at
-		FROM tickets WHERE customer_id = $1 ORDER BY issued_at DESC
+		SELECT ` + ticketColumns + `
+		FROM tickets t
+		JOIN services s ON s.id = t.service_id
+		LEFT JOIN customers c ON c.id = t.customer_id
+		WHERE s.business_id = $1
+		  AND ($2 = '' OR t.service_id = $2)
+		  AND ($3 = '' OR LOWER(c.email) = LOWER($3))
+		  AND ($4 = '' OR c.phone = $4)
+		  AND ($5 = 0 OR t.slot_number = $5)
+		  AND ($6 = 0 OR t.issued_at >= $6)
+		  AND ($7 = 0 OR t.issued_at <= $7)
+		  AND ($8 = '' OR t.status = $8)
+		ORDER BY t.issued_at DESC
+		LIMIT $9
 	`
-	rows, err := r.db.Query(ctx, query, customerID)
+	return r.list(ctx, query,
+		search.BusinessID, search.ServiceID, search.CustomerEmail, search.CustomerPhone,
+		search.SlotNumber, search.IssuedFrom, search.IssuedTo, search.Status, search.Limit,
+	)
+}
+
+func (r *PostgresTicketRepository) list(ctx context.Context, query string, args ...any) ([]domain.Ticket, error) {
+	rows, err := r.db.DB(ctx).Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, apperror.NewDatabaseError("failed to list tickets", err)
 	}
 	defer rows.Close()
 
 	tickets := make([]domain.Ticket, 0)
 	for rows.Next() {
 		var t domain.Ticket
-		if err := rows.Scan(
-			&t.ID, &t.ServiceID, &t.SlotID, &t.SlotNumber, &t.CustomerID, &t.Status, &t.HMACDigest, &t.IssuedAt, &t.ReleasedAt, &t.CreatedAt, &t.UpdatedAt,
-		); err != nil {
-			return nil, err
+		if err := scanTicket(rows, &t); err != nil {
+			return nil, apperror.NewDatabaseError("failed to scan ticket", err)
 		}
 		tickets = append(tickets, t)
 	}
-	return tickets, rows.Err()
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDatabaseError("failed to iterate tickets", err)
+	}
+
+	return tickets, nil
 }
 
-// ListActiveByServiceID lists active tickets for a service
-func (r *PostgresTicketRepository) ListActiveByServiceID(ctx context.Context, serviceID string) ([]domain.Ticket, error) {
+// UpdateStatus changes the status of an active ticket. It returns a
+// conflict when the ticket is no longer active.
+func (r *PostgresTicketRepository) UpdateStatus(ctx context.Context, id string, status string) error {
 	query := `
-		SELECT id, service_id, slot_id, slot_number, customer_id, status, hmac_digest, issued_at, released_at, created_at, updated_at
-		FROM tickets WHERE service_id = $1 AND status = 'active'
+		UPDATE tickets
+		SET status = $2, released_at = CASE WHEN $2 = 'released' THEN $3 ELSE released_at END, updated_at = $3
+		WHERE id = $1 AND status = $4
 	`
-	rows, err := r.db.Query(ctx, query, serviceID)
+	result, err := r.db.DB(ctx).Exec(ctx, query, id, status, domain.NowTimestamp(), domain.TicketStatusActive)
 	if err != nil {
-		return nil, err
+		return apperror.NewDatabaseError("failed to update ticket", err)
 	}
-	defer rows.Close()
 
-	var tickets []domain.Ticket
-	for rows.Next() {
-		var t domain.Ticket
-		if err := rows.Scan(
-			&t.ID, &t.ServiceID, &t.SlotID, &t.SlotNumber, &t.CustomerID, &t.Status, &t.HMACDigest, &t.IssuedAt, &t.ReleasedAt, &t.CreatedAt, &t.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		tickets = append(tickets, t)
+	if result.RowsAffected() == 0 {
+		return apperror.NewConflict("ticket is no longer active")
 	}
-	return tickets, rows.Err()
+
+	return nil
 }
 
-// UpdateStatus updates ticket status
-func (r *PostgresTicketRepository) UpdateStatus(ctx context.Context, id string, status string) error {
+// MarkScanned records when and by which staff member ("" for the business
+// account) a ticket was last scanned
+func (r *PostgresTicketRepository) MarkScanned(ctx context.Context, id, staffID string) error {
 	query := `
 		UPDATE tickets
-		SET status = $2, released_at = CASE WHEN $2 = 'released' THEN NOW() ELSE released_at END, updated_at = NOW()
+		SET scanned_by = NULLIF($2, ''), scanned_at = $3
 		WHERE id = $1
-		RETURNING id, service_id, slot_id, slot_number, customer_id, status, hmac_digest, issued_at, released_at, created_at, updated_at
 	`
-	_, err := r.db.Exec(ctx, query, id, status)
-	return err
+	result, err := r.db.DB(ctx).Exec(ctx, query, id, staffID, domain.NowTimestamp())
+	if err != nil {
+		return apperror.NewDatabaseError("failed to record ticket scan", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewNotFound("ticket")
+	}
+
+	return nil
+}
+
+// MarkReleased releases an active ticket, recording the staff member (""
+// for the business account) who handed the items back. It returns a
+// conflict when the ticket was released or expired concurrently.
+func (r *PostgresTicketRepository) MarkReleased(ctx context.Context, id, staffID string) error {
+	query := `
+		UPDATE tickets
+		SET status = $2, released_at = $3, released_by = NULLIF($4, ''), updated_at = $3
+		WHERE id = $1 AND status = $5
+	`
+	result, err := r.db.DB(ctx).Exec(ctx, query, id, domain.TicketStatusReleased, domain.NowTimestamp(), staffID, domain.TicketStatusActive)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to update ticket", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewConflict("ticket is no longer active")
+	}
+
+	return nil
+}
+
+// UpdateDigest replaces the signature digest of an active ticket when its QR
+// code is replaced. It returns a conflict when the ticket is no longer
+// active or its code was replaced concurrently.
+func (r *PostgresTicketRepository) UpdateDigest(ctx context.Context, id, fromDigest, toDigest string, generation int) error {
+	query := `
+		UPDATE tickets
+		SET hmac_digest = $2, qr_generation = $3, updated_at = $4
+		WHERE id = $1 AND status = $5 AND hmac_digest = $6
+	`
+	result, err := r.db.DB(ctx).Exec(ctx, query, id, toDigest, generation, domain.NowTimestamp(), domain.TicketStatusActive, fromDigest)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to update ticket digest", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewConflict("ticket is no longer active or was already reissued")
+	}
+
+	return nil
+}
+
+// UpdateCustomer moves an active ticket from one customer to another. It
+// returns a conflict when the ticket is no longer active or changed owner.
+func (r *PostgresTicketRepository) UpdateCustomer(ctx context.Context, id, fromCustomerID, toCustomerID string) error {
+	query := `
+		UPDATE tickets
+		SET customer_id = $2, updated_at = $3
+		WHERE id = $1 AND status = $4 AND customer_id = $5
+	`
+	result, err := r.db.DB(ctx).Exec(ctx, query, id, toCustomerID, domain.NowTimestamp(), domain.TicketStatusActive, fromCustomerID)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to update ticket customer", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewConflict("ticket is no longer active or changed owner")
+	}
+
+	return nil
+}
+
+// Expire moves an active ticket to expired. It returns a conflict when the
+// ticket is no longer active, e.g. because another instance expired it first.
+func (r *PostgresTicketRepository) Expire(ctx context.Context, id, reason string) error {
+	query := `
+		UPDATE tickets
+		SET status = $2, expired_at = $3, expiry_reason = $4, updated_at = $3
+		WHERE id = $1 AND status = $5
+	`
+	result, err := r.db.DB(ctx).Exec(ctx, query, id, domain.TicketStatusExpired, domain.NowTimestamp(), reason, domain.TicketStatusActive)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to expire ticket", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewConflict("ticket is no longer active")
+	}
+
+	return nil
 }
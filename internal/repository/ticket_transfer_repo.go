@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/database"
+	"CLOAKBE/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ticketTransferColumns is the select list matching scanTicketTransfer
+const ticketTransferColumns = `id, ticket_id, from_customer_id, COALESCE(to_email, ''), COALESCE(to_phone, ''),
+		COALESCE(to_customer_id, ''), status, COALESCE(revoked_digest, ''), created_at, updated_at`
+
+func scanTicketTransfer(row rowScanner, t *domain.TicketTransfer) error {
+	return row.Scan(
+		&t.ID, &t.TicketID, &t.FromCustomerID, &t.ToEmail, &t.ToPhone,
+		&t.ToCustomerID, &t.Status, &t.RevokedDigest, &t.CreatedAt, &t.UpdatedAt,
+	)
+}
+
+// PostgresTicketTransferRepository implements TicketTransferRepository for PostgreSQL
+type PostgresTicketTransferRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresTicketTransferRepository creates a new ticket transfer repository
+func NewPostgresTicketTransferRepository(db *database.Pool) *PostgresTicketTransferRepository {
+	return &PostgresTicketTransferRepository{db: db}
+}
+
+// Create inserts a new pending transfer
+func (r *PostgresTicketTransferRepository) Create(ctx context.Context, transfer *domain.TicketTransfer) error {
+	query := `
+		INSERT INTO ticket_transfers (id, ticket_id, from_customer_id, to_email, to_phone, status, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8)
+	`
+
+	_, err := r.db.DB(ctx).Exec(ctx, query,
+		transfer.ID, transfer.TicketID, transfer.FromCustomerID, transfer.ToEmail, transfer.ToPhone,
+		transfer.Status, transfer.CreatedAt, transfer.UpdatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return apperror.NewConflict("ticket already has a pending transfer")
+		}
+		return apperror.NewDatabaseError("failed to create ticket transfer", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a transfer by ID
+func (r *PostgresTicketTransferRepository) FindByID(ctx context.Context, id string) (*domain.TicketTransfer, error) {
+	return r.find(ctx, `SELECT `+ticketTransferColumns+` FROM ticket_transfers WHERE id = $1`, id)
+}
+
+// FindByRevokedDigest finds the accepted transfer that revoked a signature digest
+func (r *PostgresTicketTransferRepository) FindByRevokedDigest(ctx context.Context, digest string) (*domain.TicketTransfer, error) {
+	return r.find(ctx, `SELECT `+ticketTransferColumns+` FROM ticket_transfers WHERE revoked_digest = $1`, digest)
+}
+
+// ListByTicketID lists the transfers of a ticket, oldest first
+func (r *PostgresTicketTransferRepository) ListByTicketID(ctx context.Context, ticketID string) ([]domain.TicketTransfer, error) {
+	query := `SELECT ` + ticketTransferColumns + ` FROM ticket_transfers WHERE ticket_id = $1 ORDER BY created_at ASC`
+
+	return r.list(ctx, query, ticketID)
+}
+
+// ListPendingForRecipient lists pending transfers addressed to an email
+// (case-insensitive) or phone number, oldest first
+func (r *PostgresTicketTransferRepository) ListPendingForRecipient(ctx context.Context, email, phone string) ([]domain.TicketTransfer, error) {
+	query := `
+		SELECT ` + ticketTransferColumns + `
+		FROM ticket_transfers
+		WHERE status = $1
+			AND (($2 <> '' AND LOWER(to_email) = LOWER($2)) OR ($3 <> '' AND to_phone = $3))
+		ORDER BY created_at ASC
+	`
+
+	return r.list(ctx, query, domain.TransferStatusPending, email, phone)
+}
+
+func (r *PostgresTicketTransferRepository) find(ctx context.Context, query string, args ...any) (*domain.TicketTransfer, error) {
+	t := &domain.TicketTransfer{}
+	if err := scanTicketTransfer(r.db.DB(ctx).QueryRow(ctx, query, args...), t); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("ticket transfer")
+		}
+		return nil, apperror.NewDatabaseError("failed to find ticket transfer", err)
+	}
+	return t, nil
+}
+
+func (r *PostgresTicketTransferRepository) list(ctx context.Context, query string, args ...any) ([]domain.TicketTransfer, error) {
+	rows, err := r.db.DB(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperror.NewDatabaseError("failed to list ticket transfers", err)
+	}
+	defer rows.Close()
+
+	transfers := []domain.TicketTransfer{}
+	for rows.Next() {
+		var transfer domain.TicketTransfer
+		if err := scanTicketTransfer(rows, &transfer); err != nil {
+			return nil, apperror.NewDatabaseError("failed to scan ticket transfer", err)
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDatabaseError("failed to iterate ticket transfers", err)
+	}
+
+	return transfers, nil
+}
+
+// Update saves a transfer's state if it still has fromStatus, returning a
+// conflict when another request moved it first
+func (r *PostgresTicketTransferRepository) Update(ctx context.Context, transfer *domain.TicketTransfer, fromStatus string) error {
+	query := `
+		UPDATE ticket_transfers
+		SET status = $1, to_customer_id = NULLIF($2, ''), revoked_digest = NULLIF($3, ''), updated_at = $4
+		WHERE id = $5 AND status = $6
+	`
+
+	result, err := r.db.DB(ctx).Exec(ctx, query,
+		transfer.Status, transfer.ToCustomerID, transfer.RevokedDigest, transfer.UpdatedAt, transfer.ID, fromStatus,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return apperror.NewConflict("ticket was already transferred")
+		}
+		return apperror.NewDatabaseError("failed to update ticket transfer", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewConflict("ticket transfer is no longer " + fromStatus)
+	}
+
+	return nil
+}
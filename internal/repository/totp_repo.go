@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/database"
+	"CLOAKBE/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresTOTPFactorRepository implements TOTPFactorRepository for PostgreSQL
+type PostgresTOTPFactorRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresTOTPFactorRepository creates a new TOTP factor repository
+func NewPostgresTOTPFactorRepository(db *database.Pool) *PostgresTOTPFactorRepository {
+	return &PostgresTOTPFactorRepository{db: db}
+}
+
+// Save creates a subject's factor or replaces a pending one
+func (r *PostgresTOTPFactorRepository) Save(ctx context.Context, f *domain.TOTPFactor) error {
+	query := `
+		INSERT INTO totp_factors (subject_id, secret, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (subject_id) DO UPDATE
+		SET secret = EXCLUDED.secret, confirmed_at = NULL, last_used_step = 0,
+			created_at = EXCLUDED.created_at, updated_at = EXCLUDED.updated_at
+		WHERE totp_factors.confirmed_at IS NULL
+	`
+
+	result, err := r.db.DB(ctx).Exec(ctx, query, f.SubjectID, f.Secret, f.CreatedAt, f.UpdatedAt)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to save TOTP factor", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewConflict("two-factor authentication is already enabled")
+	}
+
+	return nil
+}
+
+// FindBySubject retrieves the factor of a business or staff member
+func (r *PostgresTOTPFactorRepository) FindBySubject(ctx context.Context, subjectID string) (*domain.TOTPFactor, error) {
+	query := `
+		SELECT subject_id, secret, COALESCE(confirmed_at, 0), last_used_step, created_at, updated_at
+		FROM totp_factors WHERE subject_id = $1
+	`
+
+	f := &domain.TOTPFactor{}
+	err := r.db.DB(ctx).QueryRow(ctx, query, subjectID).Scan(
+		&f.SubjectID, &f.Secret, &f.ConfirmedAt, &f.LastUsedStep, &f.CreatedAt, &f.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("TOTP factor")
+		}
+		return nil, apperror.NewDatabaseError("failed to find TOTP factor", err)
+	}
+
+	return f, nil
+}
+
+// Confirm turns a pending factor on
+func (r *PostgresTOTPFactorRepository) Confirm(ctx context.Context, subjectID string, confirmedAt int64) error {
+	query := `
+		UPDATE totp_factors SET confirmed_at = $2, updated_at = $2
+		WHERE subject_id = $1 AND confirmed_at IS NULL
+	`
+
+	result, err := r.db.DB(ctx).Exec(ctx, query, subjectID, confirmedAt)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to confirm TOTP factor", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewConflict("two-factor authentication is already enabled")
+	}
+
+	return nil
+}
+
+// UseStep records an accepted time step, failing with a conflict if that
+// step or a later one was already used
+func (r *PostgresTOTPFactorRepository) UseStep(ctx context.Context, subjectID string, step int64) error {
+	query := `
+		UPDATE totp_factors SET last_used_step = $2
+		WHERE subject_id = $1 AND last_used_step < $2
+	`
+
+	result, err := r.db.DB(ctx).Exec(ctx, query, subjectID, step)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to record TOTP code", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewConflict("code was already used")
+	}
+
+	return nil
+}
+
+// Delete removes a subject's factor and, by cascade, its recovery codes
+func (r *PostgresTOTPFactorRepository) Delete(ctx context.Context, subjectID string) error {
+	result, err := r.db.DB(ctx).Exec(ctx, `DELETE FROM totp_factors WHERE subject_id = $1`, subjectID)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to delete TOTP factor", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewNotFound("TOTP factor")
+	}
+
+	return nil
+}
+
+// PostgresRecoveryCodeRepository implements RecoveryCodeRepository for PostgreSQL
+type PostgresRecoveryCodeRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresRecoveryCodeRepository creates a new recovery code repository
+func NewPostgresRecoveryCodeRepository(db *database.Pool) *PostgresRecoveryCodeRepository {
+	return &PostgresRecoveryCodeRepository{db: db}
+}
+
+// Replace swaps a subject's recovery codes for a new set
+func (r *PostgresRecoveryCodeRepository) Replace(ctx context.Context, subjectID string, codes []domain.RecoveryCode) error {
+	if _, err := r.db.DB(ctx).Exec(ctx, `DELETE FROM recovery_codes WHERE subject_id = $1`, subjectID); err != nil {
+		return apperror.NewDatabaseError("failed to delete recovery codes", err)
+	}
+
+	batch := &pgx.Batch{}
+	query := `INSERT INTO recovery_codes (id, subject_id, code_hash, created_at) VALUES ($1, $2, $3, $4)`
+	for _, c := range codes {
+		batch.Queue(query, c.ID, c.SubjectID, c.CodeHash, c.CreatedAt)
+	}
+
+	results := r.db.DB(ctx).SendBatch(ctx, batch)
+	defer results.Close()
+
+	for range codes {
+		if _, err := results.Exec(); err != nil {
+			return apperror.NewDatabaseError("failed to create recovery code in batch", err)
+		}
+	}
+
+	return nil
+}
+
+// Use marks an unused code of the subject used
+func (r *PostgresRecoveryCodeRepository) Use(ctx context.Context, subjectID, codeHash string, usedAt int64) error {
+	query := `
+		UPDATE recovery_codes SET used_at = $3
+		WHERE id = (
+			SELECT id FROM recovery_codes
+			WHERE subject_id = $1 AND code_hash = $2 AND used_at IS NULL
+			LIMIT 1
+		)
+	`
+
+	result, err := r.db.DB(ctx).Exec(ctx, query, subjectID, codeHash, usedAt)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to use recovery code", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewNotFound("recovery code")
+	}
+
+	return nil
+}
+
+// CountUnused counts the recovery codes a subject has left
+func (r *PostgresRecoveryCodeRepository) CountUnused(ctx context.Context, subjectID string) (int, error) {
+	query := `SELECT COUNT(*) FROM recovery_codes WHERE subject_id = $1 AND used_at IS NULL`
+
+	var count int
+	if err := r.db.DB(ctx).QueryRow(ctx, query, subjectID).Scan(&count); err != nil {
+		return 0, apperror.NewDatabaseError("failed to count recovery codes", err)
+	}
+
+	return count, nil
+}
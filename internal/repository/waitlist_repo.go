@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/database"
+	"CLOAKBE/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// waitlistColumns is the select list matching scanWaitlistEntry
+const waitlistColumns = `id, service_id, COALESCE(zone_id, ''), COALESCE(customer_id, ''), COALESCE(name, ''), COALESCE(phone, ''),
+		status, seq, COALESCE(slot_id, ''), COALESCE(slot_number, 0), COALESCE(offered_at, 0), COALESCE(offer_expires_at, 0),
+		COALESCE(ticket_id, ''), created_at, updated_at`
+
+func scanWaitlistEntry(row rowScanner, e *domain.WaitlistEntry) error {
+	return row.Scan(
+		&e.ID, &e.ServiceID, &e.ZoneID, &e.CustomerID, &e.Name, &e.Phone,
+		&e.Status, &e.Seq, &e.SlotID, &e.SlotNumber, &e.OfferedAt, &e.OfferExpiresAt,
+		&e.TicketID, &e.CreatedAt, &e.UpdatedAt,
+	)
+}
+
+// PostgresWaitlistRepository implements WaitlistRepository for PostgreSQL
+type PostgresWaitlistRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresWaitlistRepository creates a new waitlist repository
+func NewPostgresWaitlistRepository(db *database.Pool) *PostgresWaitlistRepository {
+	return &PostgresWaitlistRepository{db: db}
+}
+
+// Create queues a new entry and sets its Seq
+func (r *PostgresWaitlistRepository) Create(ctx context.Context, entry *domain.WaitlistEntry) error {
+	query := `
+		INSERT INTO waitlist_entries (id, service_id, zone_id, customer_id, name, phone, status, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), $7, $8, $9)
+		RETURNING seq
+	`
+
+	err := r.db.DB(ctx).QueryRow(ctx, query,
+		entry.ID, entry.ServiceID, entry.ZoneID, entry.CustomerID, entry.Name, entry.Phone,
+		entry.Status, entry.CreatedAt, entry.UpdatedAt,
+	).Scan(&entry.Seq)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return apperror.NewConflict("customer is already on the waitlist")
+		}
+		return apperror.NewDatabaseError("failed to create waitlist entry", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a waitlist entry by ID
+func (r *PostgresWaitlistRepository) FindByID(ctx context.Context, id string) (*domain.WaitlistEntry, error) {
+	query := `SELECT ` + waitlistColumns + ` FROM waitlist_entries WHERE id = $1`
+	return r.find(ctx, query, id)
+}
+
+// FindOpenByCustomer finds a customer's waiting or offered entry for a service
+func (r *PostgresWaitlistRepository) FindOpenByCustomer(ctx context.Context, serviceID, customerID string) (*domain.WaitlistEntry, error) {
+	query := `
+		SELECT ` + waitlistColumns + `
+		FROM waitlist_entries
+		WHERE service_id = $1 AND customer_id = $2 AND status IN ($3, $4)
+	`
+	return r.find(ctx, query, serviceID, customerID, domain.WaitlistStatusWaiting, domain.WaitlistStatusOffered)
+}
+
+// ListOpenByServiceID lists the waiting and offered entries of a service in queue order
+func (r *PostgresWaitlistRepository) ListOpenByServiceID(ctx context.Context, serviceID string) ([]domain.WaitlistEntry, error) {
+	query := `
+		SELECT ` + waitlistColumns + `
+		FROM waitlist_entries
+		WHERE service_id = $1 AND status IN ($2, $3)
+		ORDER BY seq ASC
+	`
+	return r.list(ctx, query, serviceID, domain.WaitlistStatusWaiting, domain.WaitlistStatusOffered)
+}
+
+// LockNextWaiting locks the first waiting entry that accepts a slot in zoneID.
+// Entries locked by a concurrent release are skipped so two freed slots are
+// never offered to the same entry.
+func (r *PostgresWaitlistRepository) LockNextWaiting(ctx context.Context, serviceID, zoneID string) (*domain.WaitlistEntry, error) {
+	query := `
+		SELECT ` + waitlistColumns + `
+		FROM waitlist_entries
+		WHERE service_id = $1 AND status = $2 AND (zone_id IS NULL OR zone_id = NULLIF($3, ''))
+		ORDER BY seq ASC
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`
+	return r.find(ctx, query, serviceID, domain.WaitlistStatusWaiting, zoneID)
+}
+
+// ListExpiredOffers lists offered entries whose reservation window has passed
+func (r *PostgresWaitlistRepository) ListExpiredOffers(ctx context.Context, now int64, limit int) ([]domain.WaitlistEntry, error) {
+	query := `
+		SELECT ` + waitlistColumns + `
+		FROM waitlist_entries
+		WHERE status = $1 AND offer_expires_at <= $2
+		ORDER BY offer_expires_at ASC
+		LIMIT $3
+	`
+	return r.list(ctx, query, domain.WaitlistStatusOffered, now, limit)
+}
+
+// Update saves an entry's state if it still has fromStatus, returning a
+// conflict when another request moved it first
+func (r *PostgresWaitlistRepository) Update(ctx context.Context, entry *domain.WaitlistEntry, fromStatus string) error {
+	query := `
+		UPDATE waitlist_entries
+		SET status = $1, slot_id = NULLIF($2, ''), slot_number = NULLIF($3, 0), offered_at = NULLIF($4, 0),
+		    offer_expires_at = NULLIF($5, 0), ticket_id = NULLIF($6, ''), updated_at = $7
+		WHERE id = $8 AND status = $9
+	`
+
+	result, err := r.db.DB(ctx).Exec(ctx, query,
+		entry.Status, entry.SlotID, entry.SlotNumber, entry.OfferedAt,
+		entry.OfferExpiresAt, entry.TicketID, entry.UpdatedAt,
+		entry.ID, fromStatus,
+	)
+	if err != nil {
+		return apperror.NewDatabaseError("failed to update waitlist entry", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewConflict("waitlist entry is no longer " + fromStatus)
+	}
+
+	return nil
+}
+
+func (r *PostgresWaitlistRepository) find(ctx context.Context, query string, args ...any) (*domain.WaitlistEntry, error) {
+	e := &domain.WaitlistEntry{}
+	if err := scanWaitlistEntry(r.db.DB(ctx).QueryRow(ctx, query, args...), e); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("waitlist entry")
+		}
+		return nil, apperror.NewDatabaseError("failed to find waitlist entry", err)
+	}
+	return e, nil
+}
+
+func (r *PostgresWaitlistRepository) list(ctx context.Context, query string, args ...any) ([]domain.WaitlistEntry, error) {
+	rows, err := r.db.DB(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperror.NewDatabaseError("failed to list waitlist entries", err)
+	}
+	defer rows.Close()
+
+	entries := []domain.WaitlistEntry{}
+	for rows.Next() {
+		var e domain.WaitlistEntry
+		if err := scanWaitlistEntry(rows, &e); err != nil {
+			return nil, apperror.NewDatabaseError("failed to scan waitlist entry", err)
+		}
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDatabaseError("failed to iterate waitlist entries", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/database"
+	"CLOAKBE/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgresZoneRepository implements ZoneRepository for PostgreSQL
+type PostgresZoneRepository struct {
+	db *database.Pool
+}
+
+// NewPostgresZoneRepository creates a new zone repository
+func NewPostgresZoneRepository(db *database.Pool) *PostgresZoneRepository {
+	return &PostgresZoneRepository{db: db}
+}
+
+// Create inserts a new zone
+func (r *PostgresZoneRepository) Create(ctx context.Context, zone *domain.Zone) error {
+	query := `
+		INSERT INTO zones (id, service_id, name, capacity, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.DB(ctx).Exec(ctx, query,
+		zone.ID, zone.ServiceID, zone.Name, zone.Capacity, zone.CreatedAt, zone.UpdatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return apperror.NewConflict("zone name already exists for this service")
+		}
+		return apperror.NewDatabaseError("failed to create zone", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a zone by ID
+func (r *PostgresZoneRepository) FindByID(ctx context.Context, id string) (*domain.Zone, error) {
+	query := `
+		SELECT id, service_id, name, capacity, created_at, updated_at
+		FROM zones WHERE id = $1
+	`
+
+	z := &domain.Zone{}
+	err := r.db.DB(ctx).QueryRow(ctx, query, id).Scan(
+		&z.ID, &z.ServiceID, &z.Name, &z.Capacity, &z.CreatedAt, &z.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.NewNotFound("zone")
+		}
+		return nil, apperror.NewDatabaseError("failed to find zone", err)
+	}
+
+	return z, nil
+}
+
+// ListByServiceID lists the zones of a service in creation order
+func (r *PostgresZoneRepository) ListByServiceID(ctx context.Context, serviceID string) ([]domain.Zone, error) {
+	query := `
+		SELECT id, service_id, name, capacity, created_at, updated_at
+		FROM zones WHERE service_id = $1
+		ORDER BY created_at ASC, name ASC
+	`
+
+	rows, err := r.db.DB(ctx).Query(ctx, query, serviceID)
+	if err != nil {
+		return nil, apperror.NewDatabaseError("failed to list zones", err)
+	}
+	defer rows.Close()
+
+	zones := []domain.Zone{}
+	for rows.Next() {
+		z := domain.Zone{}
+		if err := rows.Scan(&z.ID, &z.ServiceID, &z.Name, &z.Capacity, &z.CreatedAt, &z.UpdatedAt); err != nil {
+			return nil, apperror.NewDatabaseError("failed to scan zone", err)
+		}
+		zones = append(zones, z)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.NewDatabaseError("failed to iterate zones", err)
+	}
+
+	return zones, nil
+}
+
+// Update changes a zone's name and capacity
+func (r *PostgresZoneRepository) Update(ctx context.Context, zone *domain.Zone) error {
+	query := `
+		UPDATE zones
+		SET name = $1, capacity = $2, updated_at = $3
+		WHERE id = $4
+	`
+
+	result, err := r.db.DB(ctx).Exec(ctx, query, zone.Name, zone.Capacity, zone.UpdatedAt, zone.ID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return apperror.NewConflict("zone name already exists for this service")
+		}
+		return apperror.NewDatabaseError("failed to update zone", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.NewNotFound("zone")
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 key of RFC 6238 Appendix B, "12345678901234567890",
+// in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeMatchesRFC6238Vectors(t *testing.T) {
+	// Appendix B lists 8-digit codes; six-digit codes are their last six digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code at %d: %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("Code at %d: got %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestCodeAcceptsLowercaseSecret(t *testing.T) {
+	got, err := Code("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", Step(time.Unix(59, 0)))
+	if err != nil {
+		t.Fatalf("Code: %v", err)
+	}
+	if got != "287082" {
+		t.Fatalf("Code: got %s, want 287082", got)
+	}
+}
+
+func TestCodeRejectsInvalidSecret(t *testing.T) {
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Fatal("Code: want an error for a secret that is not base32")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	// 1111111111 is step 37037037; 1111111109 is the step before
+	now := time.Unix(1111111111, 0)
+
+	tests := []struct {
+		name     string
+		code     string
+		skew     int
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", "050471", 0, 37037037, true},
+		{"surrounding spaces", " 050471 ", 0, 37037037, true},
+		{"previous step within skew", "081804", 1, 37037036, true},
+		{"previous step without skew", "081804", 0, 0, false},
+		{"wrong code", "123456", 1, 0, false},
+		{"too short", "50471", 1, 0, false},
+		{"eight digits", "14050471", 1, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now, tt.skew)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Fatalf("Validate: got (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsInvalidSecret(t *testing.T) {
+	if _, ok := Validate("not base32!", "123456", time.Now(), 1); ok {
+		t.Fatal("Validate: want no match for a secret that is not base32")
+	}
+}
+
+func TestGeneratedSecretRoundTrips(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+
+	now := time.Now()
+	code, err := Code(secret, Step(now))
+	if err != nil {
+		t.Fatalf("Code: %v", err)
+	}
+	if _, ok := Validate(secret, code, now, 0); !ok {
+		t.Fatal("Validate: a fresh code does not validate")
+	}
+}
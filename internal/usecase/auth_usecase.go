@@ -15,20 +15,55 @@ import (
 // AuthUsecase handles authentication logic
 type AuthUsecase struct {
 	businessRepo domain.BusinessRepository
-	customerRepo domain.CustomerRepository
+	keyRepo      domain.BusinessKeyRepository
+	sessionRepo  domain.AuthSessionRepository
+	refreshRepo  domain.RefreshTokenRepository
+	factorRepo   domain.TOTPFactorRepository
+	mfaRepo      domain.MFAChallengeRepository
+	limiter      *RateLimitUsecase
+	txManager    domain.TxManager
 	jwtSecret    string
+	accessTTL    time.Duration
+	refreshTTL   time.Duration
+	sessions     *sessionCache
+	// requireVerifiedEmail refuses business sessions until the email is verified
+	requireVerifiedEmail bool
 }
 
-// NewAuthUsecase creates a new auth usecase
+// NewAuthUsecase creates a new auth usecase. Access tokens expire after
+// accessTTL and refresh tokens after refreshTTL unless rotated; revocation
+// checks are cached for sessionCacheTTL. With requireVerifiedEmail, businesses
+// cannot log in, or refresh, before verifying their email address.
 func NewAuthUsecase(
 	businessRepo domain.BusinessRepository,
-	customerRepo domain.CustomerRepository,
+	keyRepo domain.BusinessKeyRepository,
+	sessionRepo domain.AuthSessionRepository,
+	refreshRepo domain.RefreshTokenRepository,
+	factorRepo domain.TOTPFactorRepository,
+	mfaRepo domain.MFAChallengeRepository,
+	limiter *RateLimitUsecase,
+	txManager domain.TxManager,
 	jwtSecret string,
+	accessTTL time.Duration,
+	refreshTTL time.Duration,
+	sessionCacheTTL time.Duration,
+	requireVerifiedEmail bool,
 ) *AuthUsecase {
 	return &AuthUsecase{
 		businessRepo: businessRepo,
-		customerRepo: customerRepo,
+		keyRepo:      keyRepo,
+		sessionRepo:  sessionRepo,
+		refreshRepo:  refreshRepo,
+		factorRepo:   factorRepo,
+		mfaRepo:      mfaRepo,
+		limiter:      limiter,
+		txManager:    txManager,
 		jwtSecret:    jwtSecret,
+		accessTTL:    accessTTL,
+		refreshTTL:   refreshTTL,
+		sessions:     newSessionCache(sessionCacheTTL, accessTTL),
+
+		requireVerifiedEmail: requireVerifiedEmail,
 	}
 }
 
@@ -44,22 +79,44 @@ type BusinessLoginRequest struct {
 	Password string `json:"password"`
 }
 
-type CustomerLoginRequest struct {
-	Email string `json:"email"`
-	Phone string `json:"phone"`
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type LogoutRequest struct {
+	All       bool   `json:"all,omitempty"` // Revoke every session of the caller, not just this one
+	SessionID string `json:"-"`
 }
 
+// AuthResponse carries the tokens of a new session or, when the account has
+// two-factor authentication on, only a challenge to complete at /auth/mfa/verify
 type AuthResponse struct {
-	Token  string `json:"token"`
-	UserID string `json:"user_id"`
-	Role   string `json:"role"`
+	Token              string `json:"token,omitempty"`         // Short-lived access token
+	RefreshToken       string `json:"refresh_token,omitempty"` // Single use; exchange it at /auth/refresh
+	ExpiresIn          int64  `json:"expires_in,omitempty"`    // Access token lifetime in seconds
+	UserID             string `json:"user_id,omitempty"`
+	Role               string `json:"role,omitempty"`
+	BusinessID         string `json:"business_id,omitempty"`
+	StaffID            string `json:"staff_id,omitempty"`
+	StaffRole          string `json:"staff_role,omitempty"`
+	MFARequired        bool   `json:"mfa_required,omitempty"`
+	ChallengeToken     string `json:"challenge_token,omitempty"`
+	ChallengeExpiresAt int64  `json:"challenge_expires_at,omitempty"`
+	// Set on registration when no session is started before the email is verified
+	EmailVerificationRequired bool `json:"email_verification_required,omitempty"`
 }
 
-// JWT Claims
+// JWT Claims. Staff tokens carry role "business" with the business ID as
+// UserID, so they reach the business routes, plus the staff member's ID and
+// role. SessionID names the login session the token was issued under.
 type CustomClaims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID     string `json:"user_id"`
+	Email      string `json:"email"`
+	Role       string `json:"role"`
+	BusinessID string `json:"business_id,omitempty"`
+	StaffID    string `json:"staff_id,omitempty"`
+	StaffRole  string `json:"staff_role,omitempty"`
+	SessionID  string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
@@ -84,32 +141,49 @@ func (u *AuthUsecase) BusinessRegister(ctx context.Context, req BusinessRegister
 		return nil, apperror.NewInternalServer("password hashing failed", err)
 	}
 
+	businessID := uuid.New().String()
+
+	// The first HMAC key shares the business ID as its kid
+	hmacKey, err := newBusinessKey(businessID, businessID, domain.KeyAlgorithmHMAC)
+	if err != nil {
+		return nil, err
+	}
+
+	ed25519Key, err := newBusinessKey(businessID, uuid.New().String(), domain.KeyAlgorithmEd25519)
+	if err != nil {
+		return nil, err
+	}
+
 	business := &domain.Business{
-		ID:        uuid.New().String(),
+		ID:        businessID,
 		Name:      req.Name,
 		Email:     req.Email,
 		Password:  string(hashedPassword),
 		Role:      "business",
-		HMACKey:   uuid.New().String(), // Secret key for QR signing
+		HMACKey:   hmacKey.Secret, // Secret key for QR signing
 		CreatedAt: domain.NowTimestamp(),
 		UpdatedAt: domain.NowTimestamp(),
 	}
 
-	if err := u.businessRepo.Create(ctx, business); err != nil {
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.businessRepo.Create(ctx, business); err != nil {
+			return err
+		}
+		if err := u.keyRepo.Create(ctx, hmacKey); err != nil {
+			return err
+		}
+		return u.keyRepo.Create(ctx, ed25519Key)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// Generate JWT
-	token, err := u.generateToken(business.ID, business.Email, "business")
-	if err != nil {
-		return nil, err
+	// Tokens would let an unverified business in without ever logging in
+	if u.requireVerifiedEmail {
+		return &AuthResponse{UserID: business.ID, Role: "business", EmailVerificationRequired: true}, nil
 	}
 
-	return &AuthResponse{
-		Token:  token,
-		UserID: business.ID,
-		Role:   "business",
-	}, nil
+	return u.startSession(ctx, newClaims(business.ID, business.Email, "business"), business.ID)
 }
 
 // BusinessLogin handles business login
@@ -118,69 +192,373 @@ func (u *AuthUsecase) BusinessLogin(ctx context.Context, req BusinessLoginReques
 		return nil, apperror.NewValidationError("email and password are required", map[string]string{})
 	}
 
+	lockoutKey := "business:" + normalizeEmail(req.Email)
+	if err := u.limiter.CheckLockout(ctx, lockoutKey); err != nil {
+		return nil, err
+	}
+
 	// Find business by email
 	business, err := u.businessRepo.FindByEmail(ctx, req.Email)
 	if err != nil {
 		if apperror.IsNotFound(err) {
-			return nil, apperror.NewUnauthorized("invalid credentials")
+			return nil, u.failLogin(ctx, lockoutKey)
 		}
 		return nil, err
 	}
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(business.Password), []byte(req.Password)); err != nil {
-		return nil, apperror.NewUnauthorized("invalid credentials")
+		return nil, u.failLogin(ctx, lockoutKey)
+	}
+
+	if err := u.limiter.RecordLoginSuccess(ctx, lockoutKey); err != nil {
+		return nil, err
+	}
+
+	if u.requireVerifiedEmail && business.EmailVerifiedAt == 0 {
+		return nil, errEmailNotVerified()
+	}
+
+	return u.login(ctx, newClaims(business.ID, business.Email, "business"), business.ID)
+}
+
+// Refresh exchanges a refresh token for a new access token and the next
+// refresh token of its family. Presenting a token that was already exchanged
+// means it leaked, so the whole session is revoked.
+func (u *AuthUsecase) Refresh(ctx context.Context, req RefreshRequest) (*AuthResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, apperror.NewValidationError("refresh_token is required", map[string]string{})
+	}
+
+	token, err := u.refreshRepo.FindByTokenHash(ctx, hashOpaqueToken(req.RefreshToken))
+	if apperror.IsNotFound(err) {
+		return nil, apperror.NewUnauthorized("invalid refresh token")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := u.sessionRepo.FindByID(ctx, token.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.IsRevoked() {
+		return nil, apperror.NewUnauthorized("session has been revoked")
+	}
+
+	if token.RotatedAt != 0 {
+		return nil, u.revokeReusedSession(ctx, session.ID)
+	}
+
+	now := domain.NowTimestamp()
+	if token.ExpiresAt <= now {
+		return nil, apperror.NewUnauthorized("refresh token has expired")
+	}
+
+	// Sessions started before verification was required must not outlive it
+	if u.requireVerifiedEmail && session.Role == "business" && session.StaffID == "" {
+		business, err := u.businessRepo.FindByID(ctx, session.Subject)
+		if err != nil {
+			return nil, err
+		}
+		if business.EmailVerifiedAt == 0 {
+			return nil, errEmailNotVerified()
+		}
+	}
+
+	next, refreshToken, err := u.newRefreshToken(session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.refreshRepo.MarkRotated(ctx, token.ID, now); err != nil {
+			return err
+		}
+		return u.refreshRepo.Create(ctx, next)
+	})
+	if apperror.IsConflict(err) {
+		// A concurrent refresh with the same token won the race
+		return nil, u.revokeReusedSession(ctx, session.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return u.authResponse(sessionClaims(session), session.Subject, refreshToken)
+}
+
+// Logout revokes the caller's session, or every session of the caller when
+// All is set. Access tokens of revoked sessions stop working at once.
+func (u *AuthUsecase) Logout(ctx context.Context, req LogoutRequest) error {
+	session, err := u.sessionRepo.FindByID(ctx, req.SessionID)
+	if err != nil {
+		return err
+	}
+
+	if !req.All {
+		return u.revokeSession(ctx, session.ID)
+	}
+
+	return u.revokeSubject(ctx, session.Subject)
+}
+
+// CheckSession rejects access tokens whose session was revoked. Lookups are
+// cached, so revocations made on another instance apply within the cache TTL.
+func (u *AuthUsecase) CheckSession(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return apperror.NewUnauthorized("Invalid or expired token")
+	}
+
+	revoked, ok := u.sessions.get(sessionID)
+	if !ok {
+		session, err := u.sessionRepo.FindByID(ctx, sessionID)
+		if apperror.IsNotFound(err) {
+			return apperror.NewUnauthorized("Invalid or expired token")
+		}
+		if err != nil {
+			return err
+		}
+
+		revoked = session.IsRevoked()
+		u.sessions.set(sessionID, revoked)
+	}
+
+	if revoked {
+		return apperror.NewUnauthorized("session has been revoked")
+	}
+
+	return nil
+}
+
+// revokeSession revokes one session
+func (u *AuthUsecase) revokeSession(ctx context.Context, sessionID string) error {
+	if err := u.sessionRepo.Revoke(ctx, sessionID, domain.NowTimestamp()); err != nil {
+		return err
+	}
+
+	u.sessions.set(sessionID, true)
+	return nil
+}
+
+// revokeSubject revokes every session of a business, customer or staff member
+func (u *AuthUsecase) revokeSubject(ctx context.Context, subject string) error {
+	ids, err := u.sessionRepo.RevokeBySubject(ctx, subject, domain.NowTimestamp())
+	if err != nil {
+		return err
+	}
+
+	u.markRevoked(ids)
+	return nil
+}
+
+// markRevoked caches sessions as revoked. Inside a unit of work, call it
+// only after commit so a rollback cannot leave live sessions cached as revoked.
+func (u *AuthUsecase) markRevoked(sessionIDs []string) {
+	for _, id := range sessionIDs {
+		u.sessions.set(id, true)
+	}
+}
+
+// revokeReusedSession revokes a session whose rotated refresh token was
+// presented again and returns the error to report
+func (u *AuthUsecase) revokeReusedSession(ctx context.Context, sessionID string) error {
+	if err := u.revokeSession(ctx, sessionID); err != nil {
+		return err
+	}
+
+	return apperror.NewUnauthorized("refresh token was already used; session revoked")
+}
+
+// errEmailNotVerified rejects businesses that must verify their email first
+func errEmailNotVerified() error {
+	return apperror.NewForbidden("email address is not verified; follow the link we sent or request a new one")
+}
+
+// failLogin counts a bad password against the account it was entered for
+// and rejects the login
+func (u *AuthUsecase) failLogin(ctx context.Context, lockoutKey string) error {
+	if err := u.limiter.RecordLoginFailure(ctx, lockoutKey); err != nil {
+		return err
+	}
+	return apperror.NewUnauthorized("invalid credentials")
+}
+
+// login finishes a password login: it starts a session, or returns a
+// challenge when the subject has two-factor authentication on
+func (u *AuthUsecase) login(ctx context.Context, claims CustomClaims, subject string) (*AuthResponse, error) {
+	factor, err := u.factorRepo.FindBySubject(ctx, subject)
+	if apperror.IsNotFound(err) || (err == nil && !factor.IsEnabled()) {
+		return u.startSession(ctx, claims, subject)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	// Generate JWT
-	token, err := u.generateToken(business.ID, business.Email, "business")
+	token, tokenHash, err := newOpaqueToken()
 	if err != nil {
 		return nil, err
 	}
 
+	now := domain.NowTimestamp()
+	challenge := &domain.MFAChallenge{
+		ID:         uuid.New().String(),
+		Subject:    subject,
+		UserID:     claims.UserID,
+		Email:      claims.Email,
+		Role:       claims.Role,
+		BusinessID: claims.BusinessID,
+		StaffID:    claims.StaffID,
+		StaffRole:  claims.StaffRole,
+		TokenHash:  tokenHash,
+		ExpiresAt:  now + mfaChallengeTTL,
+		CreatedAt:  now,
+	}
+
+	if err := u.mfaRepo.Create(ctx, challenge); err != nil {
+		return nil, err
+	}
+
 	return &AuthResponse{
-		Token:  token,
-		UserID: business.ID,
-		Role:   "business",
+		MFARequired:        true,
+		ChallengeToken:     token,
+		ChallengeExpiresAt: challenge.ExpiresAt,
 	}, nil
 }
 
-// CustomerLogin handles customer login (upsert pattern)
-func (u *AuthUsecase) CustomerLogin(ctx context.Context, req CustomerLoginRequest) (*AuthResponse, error) {
-	if req.Email == "" {
-		return nil, apperror.NewValidationError("email is required", map[string]string{})
+// startSession opens a login session for claims and issues its first access
+// and refresh tokens
+func (u *AuthUsecase) startSession(ctx context.Context, claims CustomClaims, subject string) (*AuthResponse, error) {
+	now := domain.NowTimestamp()
+	session := &domain.AuthSession{
+		ID:         uuid.New().String(),
+		Subject:    subject,
+		UserID:     claims.UserID,
+		Email:      claims.Email,
+		Role:       claims.Role,
+		BusinessID: claims.BusinessID,
+		StaffID:    claims.StaffID,
+		StaffRole:  claims.StaffRole,
+		CreatedAt:  now,
+		UpdatedAt:  now,
 	}
 
-	// Find or create customer
-	customer, err := u.customerRepo.FindOrCreate(ctx, req.Email, req.Phone)
+	token, refreshToken, err := u.newRefreshToken(session.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Generate JWT
-	token, err := u.generateToken(customer.ID, customer.Email, "customer")
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.sessionRepo.Create(ctx, session); err != nil {
+			return err
+		}
+		return u.refreshRepo.Create(ctx, token)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims.SessionID = session.ID
+	return u.authResponse(claims, subject, refreshToken)
+}
+
+// newRefreshToken creates the next refresh token of a session and returns it
+// with the token to hand out
+func (u *AuthUsecase) newRefreshToken(sessionID string) (*domain.RefreshToken, string, error) {
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := domain.NowTimestamp()
+	return &domain.RefreshToken{
+		ID:        uuid.New().String(),
+		SessionID: sessionID,
+		TokenHash: tokenHash,
+		ExpiresAt: now + int64(u.refreshTTL/time.Second),
+		CreatedAt: now,
+	}, token, nil
+}
+
+// authResponse signs an access token for claims and pairs it with a refresh
+// token
+func (u *AuthUsecase) authResponse(claims CustomClaims, subject, refreshToken string) (*AuthResponse, error) {
+	token, err := u.signToken(claims, subject)
 	if err != nil {
 		return nil, err
 	}
 
 	return &AuthResponse{
-		Token:  token,
-		UserID: customer.ID,
-		Role:   "customer",
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(u.accessTTL / time.Second),
+		UserID:       claims.UserID,
+		Role:         claims.Role,
+		BusinessID:   claims.BusinessID,
+		StaffID:      claims.StaffID,
+		StaffRole:    claims.StaffRole,
 	}, nil
 }
 
-// generateToken creates a JWT token
-func (u *AuthUsecase) generateToken(userID, email, role string) (string, error) {
+// newClaims builds the claims of a business or customer
+func newClaims(userID, email, role string) CustomClaims {
 	claims := CustomClaims{
 		UserID: userID,
 		Email:  email,
 		Role:   role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Subject:   userID,
-		},
+	}
+	if role == "business" {
+		claims.BusinessID = userID
+	}
+
+	return claims
+}
+
+// newStaffClaims builds the claims of a staff member acting for their
+// business
+func newStaffClaims(staff *domain.Staff) CustomClaims {
+	return CustomClaims{
+		UserID:     staff.BusinessID,
+		Email:      staff.Email,
+		Role:       "business",
+		BusinessID: staff.BusinessID,
+		StaffID:    staff.ID,
+		StaffRole:  staff.Role,
+	}
+}
+
+// challengeClaims restores the claims a login challenge was issued for
+func challengeClaims(challenge *domain.MFAChallenge) CustomClaims {
+	return CustomClaims{
+		UserID:     challenge.UserID,
+		Email:      challenge.Email,
+		Role:       challenge.Role,
+		BusinessID: challenge.BusinessID,
+		StaffID:    challenge.StaffID,
+		StaffRole:  challenge.StaffRole,
+	}
+}
+
+// sessionClaims restores the claims a session was opened with
+func sessionClaims(session *domain.AuthSession) CustomClaims {
+	return CustomClaims{
+		UserID:     session.UserID,
+		Email:      session.Email,
+		Role:       session.Role,
+		BusinessID: session.BusinessID,
+		StaffID:    session.StaffID,
+		StaffRole:  session.StaffRole,
+		SessionID:  session.ID,
+	}
+}
+
+// signToken signs claims for subject with the access token expiry
+func (u *AuthUsecase) signToken(claims CustomClaims, subject string) (string, error) {
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(u.accessTTL)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		Subject:   subject,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
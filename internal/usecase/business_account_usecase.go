@@ -0,0 +1,338 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+	"CLOAKBE/internal/notify"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// businessMailInterval is how many seconds must pass before another email
+// of the same kind goes to a business
+const businessMailInterval = 60
+
+// BusinessAccountUsecase verifies business email addresses and resets
+// forgotten passwords with single-use tokens sent by email
+type BusinessAccountUsecase struct {
+	businessRepo domain.BusinessRepository
+	tokenRepo    domain.BusinessTokenRepository
+	auth         *AuthUsecase
+	sender       notify.Sender
+	txManager    domain.TxManager
+	verifyTTL    time.Duration
+	resetTTL     time.Duration
+	verifyURL    string
+	resetURL     string
+}
+
+// NewBusinessAccountUsecase creates a new business account usecase.
+// Verification tokens expire after verifyTTL and reset tokens after
+// resetTTL. Emails link to verifyURL and resetURL with the token in the
+// "token" query parameter, or carry the bare token when the URL is empty.
+func NewBusinessAccountUsecase(
+	businessRepo domain.BusinessRepository,
+	tokenRepo domain.BusinessTokenRepository,
+	auth *AuthUsecase,
+	sender notify.Sender,
+	txManager domain.TxManager,
+	verifyTTL time.Duration,
+	resetTTL time.Duration,
+	verifyURL string,
+	resetURL string,
+) *BusinessAccountUsecase {
+	return &BusinessAccountUsecase{
+		businessRepo: businessRepo,
+		tokenRepo:    tokenRepo,
+		auth:         auth,
+		sender:       sender,
+		txManager:    txManager,
+		verifyTTL:    verifyTTL,
+		resetTTL:     resetTTL,
+		verifyURL:    verifyURL,
+		resetURL:     resetURL,
+	}
+}
+
+// Request/Response types
+type BusinessEmailRequest struct {
+	Email string `json:"email"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// SendVerification emails a verification link to a business that has not
+// verified its email address yet
+func (u *BusinessAccountUsecase) SendVerification(ctx context.Context, businessID string) error {
+	business, err := u.businessRepo.FindByID(ctx, businessID)
+	if err != nil {
+		return err
+	}
+
+	return u.sendVerification(ctx, business)
+}
+
+// ResendVerification emails a new verification link. Unknown and already
+// verified addresses are ignored, so the response does not reveal which
+// emails are registered.
+func (u *BusinessAccountUsecase) ResendVerification(ctx context.Context, req BusinessEmailRequest) error {
+	business, err := u.businessByEmail(ctx, req.Email)
+	if err != nil || business == nil {
+		return err
+	}
+
+	return u.sendVerification(ctx, business)
+}
+
+// VerifyEmail marks the email address of the business a verification token
+// was sent to as verified
+func (u *BusinessAccountUsecase) VerifyEmail(ctx context.Context, req VerifyEmailRequest) error {
+	token, err := u.validToken(ctx, req.Token, domain.BusinessTokenVerifyEmail)
+	if err != nil {
+		return err
+	}
+
+	business, err := u.businessRepo.FindByID(ctx, token.BusinessID)
+	if err != nil {
+		return err
+	}
+
+	return u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		now := domain.NowTimestamp()
+		if err := u.useToken(ctx, token, now); err != nil {
+			return err
+		}
+
+		if business.EmailVerifiedAt != 0 {
+			return nil
+		}
+		business.EmailVerifiedAt = now
+		return u.businessRepo.Update(ctx, business)
+	})
+}
+
+// RequestPasswordReset emails a password reset link. Unknown addresses are
+// ignored, so the response does not reveal which emails are registered.
+func (u *BusinessAccountUsecase) RequestPasswordReset(ctx context.Context, req BusinessEmailRequest) error {
+	business, err := u.businessByEmail(ctx, req.Email)
+	if err != nil || business == nil {
+		return err
+	}
+
+	token, err := u.issueToken(ctx, business.ID, domain.BusinessTokenResetPassword, u.resetTTL)
+	if err != nil || token == "" {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"Someone asked to reset the password of your CLOAK account. Choose a new password here: %s. "+
+			"The link expires in %s. If you did not ask for this, you can ignore this email.",
+		tokenLink(u.resetURL, token), describeTTL(u.resetTTL),
+	)
+
+	return u.send(ctx, business.Email, "Reset your CLOAK password", body)
+}
+
+// ResetPassword sets a new password with a reset token and signs the
+// business out everywhere. Following the emailed link also proves the
+// business owns its email address.
+func (u *BusinessAccountUsecase) ResetPassword(ctx context.Context, req ResetPasswordRequest) error {
+	if req.Token == "" || req.Password == "" {
+		return apperror.NewValidationError("token and password are required", map[string]string{})
+	}
+
+	token, err := u.validToken(ctx, req.Token, domain.BusinessTokenResetPassword)
+	if err != nil {
+		return err
+	}
+
+	business, err := u.businessRepo.FindByID(ctx, token.BusinessID)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return apperror.NewInternalServer("password hashing failed", err)
+	}
+
+	var revoked []string
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		now := domain.NowTimestamp()
+		if err := u.useToken(ctx, token, now); err != nil {
+			return err
+		}
+		if err := u.tokenRepo.RetireByBusiness(ctx, business.ID, domain.BusinessTokenResetPassword, now); err != nil {
+			return err
+		}
+
+		business.Password = string(hashedPassword)
+		if business.EmailVerifiedAt == 0 {
+			business.EmailVerifiedAt = now
+		}
+		if err := u.businessRepo.Update(ctx, business); err != nil {
+			return err
+		}
+
+		ids, err := u.auth.sessionRepo.RevokeBySubject(ctx, business.ID, now)
+		revoked = ids
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// Cached only once the revocation is committed
+	u.auth.markRevoked(revoked)
+	return nil
+}
+
+// sendVerification emails a verification link unless the business is
+// already verified
+func (u *BusinessAccountUsecase) sendVerification(ctx context.Context, business *domain.Business) error {
+	if business.EmailVerifiedAt != 0 {
+		return nil
+	}
+
+	token, err := u.issueToken(ctx, business.ID, domain.BusinessTokenVerifyEmail, u.verifyTTL)
+	if err != nil || token == "" {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"Confirm that %s is the email address of your CLOAK account: %s. The link expires in %s.",
+		business.Email, tokenLink(u.verifyURL, token), describeTTL(u.verifyTTL),
+	)
+
+	return u.send(ctx, business.Email, "Verify your CLOAK email address", body)
+}
+
+// issueToken replaces a business's unused tokens of a purpose with a new
+// one. It returns "" without issuing a token if the last one is too recent.
+func (u *BusinessAccountUsecase) issueToken(ctx context.Context, businessID, purpose string, ttl time.Duration) (string, error) {
+	now := domain.NowTimestamp()
+
+	latest, err := u.tokenRepo.FindLatest(ctx, businessID, purpose)
+	if err != nil && !apperror.IsNotFound(err) {
+		return "", err
+	}
+	if err == nil && now-latest.CreatedAt < businessMailInterval {
+		return "", nil
+	}
+
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.tokenRepo.RetireByBusiness(ctx, businessID, purpose, now); err != nil {
+			return err
+		}
+		return u.tokenRepo.Create(ctx, &domain.BusinessToken{
+			ID:         uuid.New().String(),
+			BusinessID: businessID,
+			Purpose:    purpose,
+			TokenHash:  tokenHash,
+			ExpiresAt:  now + int64(ttl/time.Second),
+			CreatedAt:  now,
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// validToken loads an unused, unexpired token of a purpose
+func (u *BusinessAccountUsecase) validToken(ctx context.Context, raw, purpose string) (*domain.BusinessToken, error) {
+	if raw == "" {
+		return nil, apperror.NewValidationError("token is required", map[string]string{})
+	}
+
+	token, err := u.tokenRepo.FindByTokenHash(ctx, hashOpaqueToken(raw))
+	if apperror.IsNotFound(err) {
+		return nil, apperror.NewUnauthorized("invalid or expired token")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Purpose != purpose || token.UsedAt != 0 || token.ExpiresAt <= domain.NowTimestamp() {
+		return nil, apperror.NewUnauthorized("invalid or expired token")
+	}
+
+	return token, nil
+}
+
+// useToken marks a token used; a token used concurrently is rejected
+func (u *BusinessAccountUsecase) useToken(ctx context.Context, token *domain.BusinessToken, now int64) error {
+	err := u.tokenRepo.Use(ctx, token.ID, now)
+	if apperror.IsConflict(err) {
+		return apperror.NewUnauthorized("invalid or expired token")
+	}
+	return err
+}
+
+// businessByEmail returns the business registered under an email, or nil
+// if there is none
+func (u *BusinessAccountUsecase) businessByEmail(ctx context.Context, email string) (*domain.Business, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return nil, apperror.NewValidationError("email is required", map[string]string{})
+	}
+
+	business, err := u.businessRepo.FindByEmail(ctx, email)
+	if apperror.IsNotFound(err) {
+		return nil, nil
+	}
+
+	return business, err
+}
+
+func (u *BusinessAccountUsecase) send(ctx context.Context, to, subject, body string) error {
+	msg := notify.Message{Channel: notify.ChannelEmail, To: to, Subject: subject, Body: body}
+	if err := u.sender.Send(ctx, msg); err != nil {
+		return apperror.NewInternalServer("failed to send email", err)
+	}
+	return nil
+}
+
+// tokenLink appends a token to a link, or returns the bare token when there
+// is no link to point to
+func tokenLink(baseURL, token string) string {
+	if baseURL == "" {
+		return token
+	}
+
+	separator := "?"
+	if strings.Contains(baseURL, "?") {
+		separator = "&"
+	}
+	return baseURL + separator + "token=" + token
+}
+
+// describeTTL renders a token lifetime for an email, e.g. "48 hours"
+func describeTTL(ttl time.Duration) string {
+	if ttl >= time.Hour && ttl%time.Hour == 0 {
+		if hours := int(ttl / time.Hour); hours != 1 {
+			return fmt.Sprintf("%d hours", hours)
+		}
+		return "1 hour"
+	}
+	return fmt.Sprintf("%d minutes", int(ttl/time.Minute))
+}
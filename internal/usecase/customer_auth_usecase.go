@@ -0,0 +1,358 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+	"CLOAKBE/internal/notify"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// loginCodeDigits is the length of customer login codes
+	loginCodeDigits = 6
+	// maxLoginCodeAttempts is how many codes may be tried against a login
+	maxLoginCodeAttempts = 5
+	// maxLoginCodesPerHour caps the codes sent to one email or phone
+	maxLoginCodesPerHour = 5
+)
+
+// CustomerAuthUsecase signs customers in without passwords: a one-time code
+// or magic link is sent to their email or phone and exchanged for tokens in
+// a separate step
+type CustomerAuthUsecase struct {
+	codeRepo       domain.LoginCodeRepository
+	customerRepo   domain.CustomerRepository
+	auth           *AuthUsecase
+	sender         notify.Sender
+	txManager      domain.TxManager
+	codeTTL        time.Duration
+	resendInterval time.Duration
+	linkURL        string
+}
+
+// NewCustomerAuthUsecase creates a new customer auth usecase. Codes expire
+// after codeTTL and a new one can be requested every resendInterval. When
+// linkURL is set, messages also carry a magic link to it with the token in
+// the "token" query parameter.
+func NewCustomerAuthUsecase(
+	codeRepo domain.LoginCodeRepository,
+	customerRepo domain.CustomerRepository,
+	auth *AuthUsecase,
+	sender notify.Sender,
+	txManager domain.TxManager,
+	codeTTL time.Duration,
+	resendInterval time.Duration,
+	linkURL string,
+) *CustomerAuthUsecase {
+	return &CustomerAuthUsecase{
+		codeRepo:       codeRepo,
+		customerRepo:   customerRepo,
+		auth:           auth,
+		sender:         sender,
+		txManager:      txManager,
+		codeTTL:        codeTTL,
+		resendInterval: resendInterval,
+		linkURL:        linkURL,
+	}
+}
+
+// Request/Response types
+type CustomerLoginRequest struct {
+	Email string `json:"email"`
+	Phone string `json:"phone"` // Alone, the code is sent by SMS
+}
+
+type CustomerLoginResponse struct {
+	LoginID     string `json:"login_id"`
+	Channel     string `json:"channel"` // "email" or "sms"
+	ExpiresAt   int64  `json:"expires_at"`
+	ResendAfter int64  `json:"resend_after"` // Earliest time a new code can be requested
+}
+
+type VerifyLoginRequest struct {
+	LoginID string `json:"login_id,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Token   string `json:"token,omitempty"` // Magic link token, instead of login_id and code
+}
+
+// RequestCode sends a login code to the customer's email, or to their phone
+// when no email is given. Requesting again retires the previous code.
+func (u *CustomerAuthUsecase) RequestCode(ctx context.Context, req CustomerLoginRequest) (*CustomerLoginResponse, error) {
+	email := normalizeEmail(req.Email)
+	phone := normalizePhone(req.Phone)
+	if req.Phone != "" && phone == "" {
+		return nil, apperror.NewValidationError("phone must be a phone number like +15551234567", map[string]string{})
+	}
+	if email == "" && phone == "" {
+		return nil, apperror.NewValidationError("email or phone is required", map[string]string{})
+	}
+
+	channel, destination := notify.ChannelEmail, email
+	if email == "" {
+		channel, destination = notify.ChannelSMS, phone
+	}
+
+	now := domain.NowTimestamp()
+	code, err := newLoginCode()
+	if err != nil {
+		return nil, err
+	}
+
+	linkToken, linkHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	loginCode := &domain.LoginCode{
+		ID:          uuid.New().String(),
+		Channel:     channel,
+		Destination: destination,
+		LinkHash:    linkHash,
+		ExpiresAt:   now + int64(u.codeTTL/time.Second),
+		CreatedAt:   now,
+	}
+	loginCode.CodeHash = hashLoginCode(loginCode.ID, code)
+	if channel == notify.ChannelEmail {
+		loginCode.Phone = phone
+	}
+
+	// Requests for one destination take turns, so concurrent ones cannot all
+	// pass the throttle
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.codeRepo.LockDestination(ctx, destination); err != nil {
+			return err
+		}
+		if err := u.checkThrottle(ctx, destination, now); err != nil {
+			return err
+		}
+		if err := u.codeRepo.ConsumeByDestination(ctx, destination, now); err != nil {
+			return err
+		}
+		return u.codeRepo.Create(ctx, loginCode)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.sender.Send(ctx, u.loginMessage(loginCode, code, linkToken)); err != nil {
+		return nil, apperror.NewInternalServer("failed to send login code", err)
+	}
+
+	return &CustomerLoginResponse{
+		LoginID:     loginCode.ID,
+		Channel:     channel,
+		ExpiresAt:   loginCode.ExpiresAt,
+		ResendAfter: now + int64(u.resendInterval/time.Second),
+	}, nil
+}
+
+// VerifyCode exchanges a login code, or the token of a magic link, for
+// tokens. The customer account is created on first sign-in.
+func (u *CustomerAuthUsecase) VerifyCode(ctx context.Context, req VerifyLoginRequest) (*AuthResponse, error) {
+	loginCode, err := u.pendingCode(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var response *AuthResponse
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		now := domain.NowTimestamp()
+		if err := u.codeRepo.Consume(ctx, loginCode.ID, now, maxLoginCodeAttempts); err != nil {
+			if apperror.IsConflict(err) {
+				return apperror.NewUnauthorized("invalid or expired code")
+			}
+			return err
+		}
+
+		customer, err := u.verifiedCustomer(ctx, loginCode, now)
+		if err != nil {
+			return err
+		}
+
+		response, err = u.auth.startSession(ctx, newClaims(customer.ID, customer.Email, "customer"), customer.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// checkThrottle rejects a new code while the last one is recent, or when
+// too many were sent within the hour
+func (u *CustomerAuthUsecase) checkThrottle(ctx context.Context, destination string, now int64) error {
+	recent, err := u.codeRepo.ListSince(ctx, destination, now-3600)
+	if err != nil {
+		return err
+	}
+
+	if len(recent) > 0 {
+		if wait := recent[0].CreatedAt + int64(u.resendInterval/time.Second) - now; wait > 0 {
+			return apperror.NewTooManyRequests("a login code was sent recently; try again later", wait)
+		}
+	}
+
+	if len(recent) >= maxLoginCodesPerHour {
+		wait := recent[maxLoginCodesPerHour-1].CreatedAt + 3600 - now
+		return apperror.NewTooManyRequests("too many login codes requested; try again later", wait)
+	}
+
+	return nil
+}
+
+// pendingCode loads the unused, unexpired code a verification refers to.
+// Every code tried counts against the login before it is compared, so
+// parallel guesses cannot get past the attempt limit.
+func (u *CustomerAuthUsecase) pendingCode(ctx context.Context, req VerifyLoginRequest) (*domain.LoginCode, error) {
+	now := domain.NowTimestamp()
+
+	if req.Token != "" {
+		loginCode, err := u.codeRepo.FindByLinkHash(ctx, hashOpaqueToken(req.Token))
+		if apperror.IsNotFound(err) {
+			return nil, apperror.NewUnauthorized("invalid or expired login link")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if loginCode.ConsumedAt != 0 || loginCode.ExpiresAt <= now {
+			return nil, apperror.NewUnauthorized("invalid or expired login link")
+		}
+		return loginCode, nil
+	}
+
+	if req.LoginID == "" || req.Code == "" {
+		return nil, apperror.NewValidationError("login_id and code, or token, are required", map[string]string{})
+	}
+
+	loginCode, err := u.codeRepo.FindByID(ctx, req.LoginID)
+	if apperror.IsNotFound(err) {
+		return nil, apperror.NewUnauthorized("invalid or expired code")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if loginCode.ConsumedAt != 0 || loginCode.ExpiresAt <= now {
+		return nil, apperror.NewUnauthorized("invalid or expired code")
+	}
+
+	if loginCode.Attempts >= maxLoginCodeAttempts {
+		return nil, apperror.NewUnauthorized("too many wrong codes; request a new one")
+	}
+
+	attempts, err := u.codeRepo.RecordAttempt(ctx, loginCode.ID, maxLoginCodeAttempts)
+	if apperror.IsConflict(err) {
+		return nil, apperror.NewUnauthorized("too many wrong codes; request a new one")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	given := hashLoginCode(loginCode.ID, strings.TrimSpace(req.Code))
+	if subtle.ConstantTimeCompare([]byte(given), []byte(loginCode.CodeHash)) != 1 {
+		if attempts >= maxLoginCodeAttempts {
+			return nil, apperror.NewUnauthorized("too many wrong codes; request a new one")
+		}
+		appErr := apperror.NewUnauthorized("invalid code")
+		appErr.Details["attempts_left"] = maxLoginCodeAttempts - attempts
+		return nil, appErr
+	}
+
+	return loginCode, nil
+}
+
+// verifiedCustomer finds or creates the customer who proved ownership of a
+// code's email or phone
+func (u *CustomerAuthUsecase) verifiedCustomer(ctx context.Context, loginCode *domain.LoginCode, now int64) (*domain.Customer, error) {
+	if loginCode.Channel == notify.ChannelEmail {
+		return u.customerRepo.FindOrCreate(ctx, loginCode.Destination, loginCode.Phone)
+	}
+
+	customer, err := u.customerRepo.FindByVerifiedPhone(ctx, loginCode.Destination)
+	if !apperror.IsNotFound(err) {
+		return customer, err
+	}
+
+	customer = &domain.Customer{
+		ID:              uuid.New().String(),
+		Phone:           loginCode.Destination,
+		PhoneVerifiedAt: now,
+		CreatedAt:       now,
+	}
+	if err := u.customerRepo.Create(ctx, customer); err != nil {
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+// loginMessage builds the message carrying a login code and magic link
+func (u *CustomerAuthUsecase) loginMessage(loginCode *domain.LoginCode, code, linkToken string) notify.Message {
+	body := fmt.Sprintf("Your CLOAK login code is %s. It expires in %s.", code, describeTTL(u.codeTTL))
+
+	if u.linkURL != "" {
+		body += " Or sign in with this link: " + tokenLink(u.linkURL, linkToken)
+	}
+
+	return notify.Message{
+		Channel: loginCode.Channel,
+		To:      loginCode.Destination,
+		Subject: "Your CLOAK login code",
+		Body:    body,
+	}
+}
+
+// newLoginCode returns a random numeric code
+func newLoginCode() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < loginCodeDigits; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", apperror.NewInternalServer("code generation failed", err)
+	}
+
+	return fmt.Sprintf("%0*d", loginCodeDigits, n), nil
+}
+
+// hashLoginCode hashes a code with its login ID, so equal codes of different
+// logins do not share a hash
+func hashLoginCode(loginID, code string) string {
+	return hashOpaqueToken(loginID + ":" + code)
+}
+
+// normalizePhone strips formatting from a phone number and returns "" if
+// what is left is not a plausible number
+func normalizePhone(phone string) string {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(phone) {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+		default:
+			return ""
+		}
+	}
+
+	digits := strings.TrimPrefix(b.String(), "+")
+	if len(digits) < 6 || len(digits) > 15 {
+		return ""
+	}
+
+	return b.String()
+}
@@ -0,0 +1,344 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// DeviceTokenPrefix marks scanner device tokens so they can be told apart
+// from JWTs without parsing them
+const DeviceTokenPrefix = "cdt_"
+
+// deviceSeenInterval is how many seconds a device's last-seen time may lag
+// before an authenticated request refreshes it
+const deviceSeenInterval = 60
+
+// DeviceUsecase registers scanner devices under a business and
+// authenticates their long-lived tokens
+type DeviceUsecase struct {
+	deviceRepo  domain.DeviceRepository
+	serviceRepo domain.ServiceRepository
+}
+
+// NewDeviceUsecase creates a new device usecase
+func NewDeviceUsecase(
+	deviceRepo domain.DeviceRepository,
+	serviceRepo domain.ServiceRepository,
+) *DeviceUsecase {
+	return &DeviceUsecase{
+		deviceRepo:  deviceRepo,
+		serviceRepo: serviceRepo,
+	}
+}
+
+// Request/Response types
+type RegisterDeviceRequest struct {
+	Name       string   `json:"name"`
+	ServiceIDs []string `json:"service_ids,omitempty"` // Empty = every service of the business
+	Actions    []string `json:"actions"`               // checkin, scan, release and/or sync
+	BusinessID string   `json:"-"`
+}
+
+type UpdateDeviceRequest struct {
+	Name       string   `json:"name,omitempty"`
+	ServiceIDs []string `json:"service_ids,omitempty"` // Omitted = unchanged, [] = every service
+	Actions    []string `json:"actions,omitempty"`     // Omitted = unchanged
+	DeviceID   string   `json:"-"`
+	BusinessID string   `json:"-"`
+}
+
+type DeviceResponse struct {
+	ID         string   `json:"id"`
+	BusinessID string   `json:"business_id"`
+	Name       string   `json:"name"`
+	ServiceIDs []string `json:"service_ids"`
+	Actions    []string `json:"actions"`
+	LastSeenAt int64    `json:"last_seen_at,omitempty"`
+	RevokedAt  int64    `json:"revoked_at,omitempty"`
+	CreatedAt  int64    `json:"created_at"`
+	UpdatedAt  int64    `json:"updated_at"`
+}
+
+// DeviceRegistrationResponse carries the device token. It is shown once;
+// only its hash is stored.
+type DeviceRegistrationResponse struct {
+	DeviceResponse
+	Token string `json:"token"`
+}
+
+// DeviceScope limits a request made with a device token to the device's
+// services and actions. A nil scope means the caller is not a device.
+type DeviceScope struct {
+	DeviceID   string
+	ServiceIDs []string // Empty = every service of the business
+	Actions    []string
+}
+
+// Allows reports whether the device may perform action
+func (s *DeviceScope) Allows(action string) bool {
+	if s == nil {
+		return true
+	}
+	for _, allowed := range s.Actions {
+		if allowed == action {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsService reports whether the device may act on a service
+func (s *DeviceScope) AllowsService(serviceID string) bool {
+	if s == nil || len(s.ServiceIDs) == 0 {
+		return true
+	}
+	for _, allowed := range s.ServiceIDs {
+		if allowed == serviceID {
+			return true
+		}
+	}
+	return false
+}
+
+// checkService rejects services outside the device's scope
+func (s *DeviceScope) checkService(serviceID string) error {
+	if !s.AllowsService(serviceID) {
+		return apperror.NewForbidden("device is not allowed to access this service")
+	}
+	return nil
+}
+
+// RegisterDevice registers a scanner device and returns its token
+func (u *DeviceUsecase) RegisterDevice(ctx context.Context, req RegisterDeviceRequest) (*DeviceRegistrationResponse, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, apperror.NewValidationError("name is required", map[string]string{})
+	}
+
+	serviceIDs, actions, err := u.validateScope(ctx, req.BusinessID, req.ServiceIDs, req.Actions)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, _, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	token := DeviceTokenPrefix + raw
+
+	now := domain.NowTimestamp()
+	device := &domain.Device{
+		ID:         uuid.New().String(),
+		BusinessID: req.BusinessID,
+		Name:       req.Name,
+		TokenHash:  hashOpaqueToken(token),
+		ServiceIDs: serviceIDs,
+		Actions:    actions,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := u.deviceRepo.Create(ctx, device); err != nil {
+		return nil, err
+	}
+
+	return &DeviceRegistrationResponse{
+		DeviceResponse: toDeviceResponse(device),
+		Token:          token,
+	}, nil
+}
+
+// ListDevices lists the devices of a business, revoked ones included
+func (u *DeviceUsecase) ListDevices(ctx context.Context, businessID string) ([]DeviceResponse, error) {
+	devices, err := u.deviceRepo.ListByBusinessID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]DeviceResponse, len(devices))
+	for i, device := range devices {
+		responses[i] = toDeviceResponse(&device)
+	}
+
+	return responses, nil
+}
+
+// GetDevice returns a device of the business
+func (u *DeviceUsecase) GetDevice(ctx context.Context, deviceID, businessID string) (*DeviceResponse, error) {
+	device, err := u.ownedDevice(ctx, deviceID, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toDeviceResponse(device)
+	return &response, nil
+}
+
+// UpdateDevice renames a device or changes its scope. The new scope applies
+// to the device's next request.
+func (u *DeviceUsecase) UpdateDevice(ctx context.Context, req UpdateDeviceRequest) (*DeviceResponse, error) {
+	device, err := u.ownedDevice(ctx, req.DeviceID, req.BusinessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if device.IsRevoked() {
+		return nil, apperror.NewConflict("device has been revoked")
+	}
+
+	if req.ServiceIDs == nil {
+		req.ServiceIDs = device.ServiceIDs
+	}
+	if req.Actions == nil {
+		req.Actions = device.Actions
+	}
+
+	serviceIDs, actions, err := u.validateScope(ctx, req.BusinessID, req.ServiceIDs, req.Actions)
+	if err != nil {
+		return nil, err
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" {
+		device.Name = name
+	}
+	device.ServiceIDs = serviceIDs
+	device.Actions = actions
+	device.UpdatedAt = domain.NowTimestamp()
+
+	if err := u.deviceRepo.Update(ctx, device); err != nil {
+		return nil, err
+	}
+
+	response := toDeviceResponse(device)
+	return &response, nil
+}
+
+// RevokeDevice revokes a device's token for good. Revoking twice is a no-op.
+func (u *DeviceUsecase) RevokeDevice(ctx context.Context, deviceID, businessID string) error {
+	device, err := u.ownedDevice(ctx, deviceID, businessID)
+	if err != nil {
+		return err
+	}
+
+	if device.IsRevoked() {
+		return nil
+	}
+
+	now := domain.NowTimestamp()
+	device.RevokedAt = now
+	device.UpdatedAt = now
+
+	return u.deviceRepo.Update(ctx, device)
+}
+
+// Authenticate resolves a device token to its device, rejecting unknown and
+// revoked tokens, and records that the device was seen
+func (u *DeviceUsecase) Authenticate(ctx context.Context, token string) (*domain.Device, error) {
+	device, err := u.deviceRepo.FindByTokenHash(ctx, hashOpaqueToken(token))
+	if apperror.IsNotFound(err) {
+		return nil, apperror.NewUnauthorized("invalid device token")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if device.IsRevoked() {
+		return nil, apperror.NewUnauthorized("device has been revoked")
+	}
+
+	// Only write when the last-seen time is stale, not on every request
+	now := domain.NowTimestamp()
+	if now-device.LastSeenAt >= deviceSeenInterval {
+		if err := u.deviceRepo.UpdateLastSeen(ctx, device.ID, now); err != nil {
+			return nil, err
+		}
+		device.LastSeenAt = now
+	}
+
+	return device, nil
+}
+
+// validateScope checks that actions are known and that services belong to
+// the business, dropping duplicates
+func (u *DeviceUsecase) validateScope(ctx context.Context, businessID string, serviceIDs, actions []string) ([]string, []string, error) {
+	if len(actions) == 0 {
+		return nil, nil, apperror.NewValidationError("at least one action is required", map[string]string{})
+	}
+
+	uniqueActions := []string{}
+	for _, action := range actions {
+		if !isDeviceAction(action) {
+			return nil, nil, apperror.NewValidationError("actions must be checkin, scan, release or sync", map[string]string{})
+		}
+		if !contains(uniqueActions, action) {
+			uniqueActions = append(uniqueActions, action)
+		}
+	}
+
+	uniqueServices := []string{}
+	for _, serviceID := range serviceIDs {
+		if contains(uniqueServices, serviceID) {
+			continue
+		}
+		service, err := u.serviceRepo.FindByID(ctx, serviceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if service.BusinessID != businessID {
+			return nil, nil, apperror.NewForbidden("service does not belong to this business")
+		}
+		uniqueServices = append(uniqueServices, serviceID)
+	}
+
+	return uniqueServices, uniqueActions, nil
+}
+
+// ownedDevice loads a device of the business
+func (u *DeviceUsecase) ownedDevice(ctx context.Context, deviceID, businessID string) (*domain.Device, error) {
+	device, err := u.deviceRepo.FindByID(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if device.BusinessID != businessID {
+		return nil, apperror.NewNotFound("device")
+	}
+
+	return device, nil
+}
+
+func isDeviceAction(action string) bool {
+	switch action {
+	case domain.DeviceActionCheckIn, domain.DeviceActionScan, domain.DeviceActionRelease, domain.DeviceActionSync:
+		return true
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func toDeviceResponse(device *domain.Device) DeviceResponse {
+	return DeviceResponse{
+		ID:         device.ID,
+		BusinessID: device.BusinessID,
+		Name:       device.Name,
+		ServiceIDs: device.ServiceIDs,
+		Actions:    device.Actions,
+		LastSeenAt: device.LastSeenAt,
+		RevokedAt:  device.RevokedAt,
+		CreatedAt:  device.CreatedAt,
+		UpdatedAt:  device.UpdatedAt,
+	}
+}
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// expirySweepBatchSize is how many expirable tickets are loaded at a time
+const expirySweepBatchSize = 100
+
+// ExpiryUsecase expires tickets past their service's TTL or closing time
+// and frees their slots
+type ExpiryUsecase struct {
+	ticketRepo  domain.TicketRepository
+	slotRepo    domain.SlotRepository
+	serviceRepo domain.ServiceRepository
+	waitlist    *WaitlistUsecase
+	holds       *HoldUsecase
+	events      *TicketEventUsecase
+	txManager   domain.TxManager
+}
+
+// NewExpiryUsecase creates a new expiry usecase
+func NewExpiryUsecase(
+	ticketRepo domain.TicketRepository,
+	slotRepo domain.SlotRepository,
+	serviceRepo domain.ServiceRepository,
+	waitlist *WaitlistUsecase,
+	holds *HoldUsecase,
+	events *TicketEventUsecase,
+	txManager domain.TxManager,
+) *ExpiryUsecase {
+	return &ExpiryUsecase{
+		ticketRepo:  ticketRepo,
+		slotRepo:    slotRepo,
+		serviceRepo: serviceRepo,
+		waitlist:    waitlist,
+		holds:       holds,
+		events:      events,
+		txManager:   txManager,
+	}
+}
+
+type ExpiredTicket struct {
+	TicketID   string `json:"ticket_id"`
+	ServiceID  string `json:"service_id"`
+	SlotNumber int    `json:"slot_number"`
+	Reason     string `json:"reason"`
+}
+
+type SweepResponse struct {
+	RanAt   int64           `json:"ran_at"`
+	Expired []ExpiredTicket `json:"expired"`
+	Skipped int             `json:"skipped"` // Tickets released or expired concurrently
+}
+
+type ReservationSweepResponse struct {
+	ExpiredHolds int // Slot holds not confirmed in time
+	LapsedOffers int // Waitlist reservations not taken up in time
+}
+
+// Sweep expires every overdue ticket, restricted to one business unless
+// businessID is empty; staffID names the staff member running it. Each ticket is expired in its own transaction with a
+// conditional update, so several API instances can sweep at the same time.
+func (u *ExpiryUsecase) Sweep(ctx context.Context, businessID, staffID string) (*SweepResponse, error) {
+	now := domain.NowTimestamp()
+	response := &SweepResponse{RanAt: now, Expired: []ExpiredTicket{}}
+	services := make(map[string]*domain.Service)
+
+	// A business or staff member running the sweep is the actor; otherwise
+	// the background job is
+	actor := systemActor
+	if businessID != "" {
+		actor = businessActor(businessID, staffID, nil)
+	}
+
+	for {
+		tickets, err := u.ticketRepo.ListExpirable(ctx, businessID, now, expirySweepBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		progressed := false
+		for _, ticket := range tickets {
+			service, ok := services[ticket.ServiceID]
+			if !ok {
+				service, err = u.serviceRepo.FindByID(ctx, ticket.ServiceID)
+				if err != nil {
+					return nil, err
+				}
+				services[ticket.ServiceID] = service
+			}
+
+			reason := service.ExpiryReason(ticket.IssuedAt, now)
+			if reason == "" {
+				continue
+			}
+
+			err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+				if err := u.ticketRepo.Expire(ctx, ticket.ID, reason); err != nil {
+					return err
+				}
+				if ticket.SlotID != "" {
+					if err := u.waitlist.releaseSlot(ctx, ticket.ServiceID, ticket.SlotID); err != nil {
+						return err
+					}
+				}
+				return u.events.record(ctx, &ticket, service.BusinessID, domain.TicketEventExpired, actor, map[string]interface{}{
+					"reason": reason,
+				})
+			})
+			if apperror.IsConflict(err) {
+				response.Skipped++
+				progressed = true
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			progressed = true
+			response.Expired = append(response.Expired, ExpiredTicket{
+				TicketID:   ticket.ID,
+				ServiceID:  ticket.ServiceID,
+				SlotNumber: ticket.SlotNumber,
+				Reason:     reason,
+			})
+		}
+
+		if len(tickets) < expirySweepBatchSize || !progressed {
+			break
+		}
+	}
+
+	return response, nil
+}
+
+// SweepReservations expires slot holds and lapses waitlist offers that ran
+// out, for every business. Holds go first so their slots can be offered to
+// the waitlist in the same sweep. It runs on its own schedule, independent
+// of ticket expiry.
+func (u *ExpiryUsecase) SweepReservations(ctx context.Context) (*ReservationSweepResponse, error) {
+	expired, err := u.holds.ExpireHolds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lapsed, err := u.waitlist.LapseOffers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReservationSweepResponse{ExpiredHolds: expired, LapsedOffers: lapsed}, nil
+}
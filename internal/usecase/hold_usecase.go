@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// holdExpiryBatchSize is how many expired holds are loaded at a time
+const holdExpiryBatchSize = 100
+
+// HoldUsecase lets customers hold a slot for a short time, e.g. while they
+// walk to the counter, until an attendant confirms the hold into a ticket
+type HoldUsecase struct {
+	holdRepo     domain.SlotHoldRepository
+	serviceRepo  domain.ServiceRepository
+	zoneRepo     domain.ZoneRepository
+	slotRepo     domain.SlotRepository
+	waitlist     *WaitlistUsecase
+	txManager    domain.TxManager
+	holdDuration time.Duration
+}
+
+// NewHoldUsecase creates a new hold usecase. Holds that are not confirmed
+// within holdDuration expire and their slots are released.
+func NewHoldUsecase(
+	holdRepo domain.SlotHoldRepository,
+	serviceRepo domain.ServiceRepository,
+	zoneRepo domain.ZoneRepository,
+	slotRepo domain.SlotRepository,
+	waitlist *WaitlistUsecase,
+	txManager domain.TxManager,
+	holdDuration time.Duration,
+) *HoldUsecase {
+	return &HoldUsecase{
+		holdRepo:     holdRepo,
+		serviceRepo:  serviceRepo,
+		zoneRepo:     zoneRepo,
+		slotRepo:     slotRepo,
+		waitlist:     waitlist,
+		txManager:    txManager,
+		holdDuration: holdDuration,
+	}
+}
+
+// Request/Response types
+type CreateHoldRequest struct {
+	ServiceID  string `json:"service_id"`
+	ZoneID     string `json:"zone_id,omitempty"` // "" = any zone
+	CustomerID string `json:"-"`
+}
+
+type HoldResponse struct {
+	ID         string `json:"id"`
+	ServiceID  string `json:"service_id"`
+	SlotNumber int    `json:"slot_number"`
+	ZoneID     string `json:"zone_id,omitempty"`
+	CustomerID string `json:"customer_id"`
+	Status     string `json:"status"`
+	ExpiresAt  int64  `json:"expires_at"`
+	TicketID   string `json:"ticket_id,omitempty"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+// CreateHold holds the next free slot of a service for a customer
+func (u *HoldUsecase) CreateHold(ctx context.Context, req CreateHoldRequest) (*HoldResponse, error) {
+	if req.ServiceID == "" {
+		return nil, apperror.NewValidationError("service_id is required", map[string]string{})
+	}
+
+	service, err := u.serviceRepo.FindByID(ctx, req.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if service.IsArchived() {
+		return nil, apperror.NewConflict("service is archived")
+	}
+	if service.IsClosed(domain.NowTimestamp()) {
+		return nil, apperror.NewConflict("service is closed")
+	}
+
+	if req.ZoneID != "" {
+		zone, err := u.zoneRepo.FindByID(ctx, req.ZoneID)
+		if err != nil {
+			return nil, err
+		}
+		if zone.ServiceID != service.ID {
+			return nil, apperror.NewNotFound("zone")
+		}
+	}
+
+	var hold *domain.SlotHold
+
+	// Claim the slot and record the hold atomically so a customer who
+	// already holds a slot does not tie up a second one
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		slot, err := u.slotRepo.ClaimNextFreeSlot(ctx, service.ID, req.ZoneID)
+		if err != nil {
+			return err
+		}
+
+		if err := u.slotRepo.UpdateStatus(ctx, slot.ID, domain.SlotStatusHeld); err != nil {
+			return err
+		}
+
+		now := domain.NowTimestamp()
+		hold = &domain.SlotHold{
+			ID:         uuid.New().String(),
+			ServiceID:  service.ID,
+			SlotID:     slot.ID,
+			SlotNumber: slot.SlotNumber,
+			ZoneID:     slot.ZoneID,
+			CustomerID: req.CustomerID,
+			Status:     domain.HoldStatusActive,
+			ExpiresAt:  now + int64(u.holdDuration/time.Second),
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+
+		return u.holdRepo.Create(ctx, hold)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toHoldResponse(hold)
+	return &response, nil
+}
+
+// GetHold returns a hold the actor may see
+func (u *HoldUsecase) GetHold(ctx context.Context, holdID string, actor Actor) (*HoldResponse, error) {
+	hold, err := u.authorizedHold(ctx, holdID, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toHoldResponse(hold)
+	return &response, nil
+}
+
+// CancelHold gives up an active hold and releases its slot
+func (u *HoldUsecase) CancelHold(ctx context.Context, holdID string, actor Actor) error {
+	hold, err := u.authorizedHold(ctx, holdID, actor)
+	if err != nil {
+		return err
+	}
+
+	if hold.Status != domain.HoldStatusActive {
+		return apperror.NewConflict("slot hold is no longer active")
+	}
+
+	return u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return u.close(ctx, hold, domain.HoldStatusCancelled)
+	})
+}
+
+// ExpireHolds closes holds that were not confirmed in time and releases
+// their slots. It returns how many holds expired.
+func (u *HoldUsecase) ExpireHolds(ctx context.Context) (int, error) {
+	expired := 0
+
+	for {
+		holds, err := u.holdRepo.ListExpired(ctx, domain.NowTimestamp(), holdExpiryBatchSize)
+		if err != nil {
+			return expired, err
+		}
+
+		progressed := false
+		for _, hold := range holds {
+			err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+				return u.close(ctx, &hold, domain.HoldStatusExpired)
+			})
+			if apperror.IsConflict(err) {
+				// Confirmed or cancelled concurrently
+				progressed = true
+				continue
+			}
+			if err != nil {
+				return expired, err
+			}
+
+			progressed = true
+			expired++
+		}
+
+		if len(holds) < holdExpiryBatchSize || !progressed {
+			return expired, nil
+		}
+	}
+}
+
+// claimHold turns a held slot into an occupied slot for ticketID. It must
+// run inside the caller's unit of work.
+func (u *HoldUsecase) claimHold(ctx context.Context, holdID, serviceID, ticketID string) (*domain.Slot, error) {
+	hold, err := u.holdRepo.FindByID(ctx, holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	if hold.ServiceID != serviceID {
+		return nil, apperror.NewNotFound("slot hold")
+	}
+
+	if hold.Status != domain.HoldStatusActive {
+		return nil, apperror.NewConflict("slot hold is no longer active")
+	}
+
+	now := domain.NowTimestamp()
+	if hold.ExpiresAt <= now {
+		return nil, apperror.NewConflict("slot hold has expired")
+	}
+
+	hold.Status = domain.HoldStatusConfirmed
+	hold.TicketID = ticketID
+	hold.UpdatedAt = now
+
+	if err := u.holdRepo.Update(ctx, hold, domain.HoldStatusActive); err != nil {
+		return nil, err
+	}
+
+	if err := u.slotRepo.UpdateStatus(ctx, hold.SlotID, domain.SlotStatusOccupied); err != nil {
+		return nil, err
+	}
+
+	return u.slotRepo.FindByID(ctx, hold.SlotID)
+}
+
+// cancelService cancels the active holds of a service that is closing for
+// good and frees their slots without offering them to the waitlist. It must
+// run inside the caller's unit of work.
+func (u *HoldUsecase) cancelService(ctx context.Context, serviceID string) error {
+	holds, err := u.holdRepo.ListActiveByServiceID(ctx, serviceID)
+	if err != nil {
+		return err
+	}
+
+	now := domain.NowTimestamp()
+	for _, hold := range holds {
+		hold.Status = domain.HoldStatusCancelled
+		hold.UpdatedAt = now
+
+		err := u.holdRepo.Update(ctx, &hold, domain.HoldStatusActive)
+		if apperror.IsConflict(err) {
+			// Expired concurrently
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := u.slotRepo.UpdateStatus(ctx, hold.SlotID, domain.SlotStatusFree); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// close moves an active hold to status and releases its slot, offering it
+// to the waitlist first
+func (u *HoldUsecase) close(ctx context.Context, hold *domain.SlotHold, status string) error {
+	hold.Status = status
+	hold.UpdatedAt = domain.NowTimestamp()
+
+	if err := u.holdRepo.Update(ctx, hold, domain.HoldStatusActive); err != nil {
+		return err
+	}
+
+	return u.waitlist.releaseSlot(ctx, hold.ServiceID, hold.SlotID)
+}
+
+// authorizedHold loads a hold the actor may see: customers their own,
+// businesses those of their services
+func (u *HoldUsecase) authorizedHold(ctx context.Context, holdID string, actor Actor) (*domain.SlotHold, error) {
+	hold, err := u.holdRepo.FindByID(ctx, holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	if actor.Role == "customer" {
+		if hold.CustomerID != actor.UserID {
+			return nil, apperror.NewForbidden("slot hold does not belong to this customer")
+		}
+		return hold, nil
+	}
+
+	service, err := u.serviceRepo.FindByID(ctx, hold.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if service.BusinessID != actor.UserID {
+		return nil, apperror.NewForbidden("slot hold does not belong to this business")
+	}
+
+	return hold, nil
+}
+
+func toHoldResponse(hold *domain.SlotHold) HoldResponse {
+	return HoldResponse{
+		ID:         hold.ID,
+		ServiceID:  hold.ServiceID,
+		SlotNumber: hold.SlotNumber,
+		ZoneID:     hold.ZoneID,
+		CustomerID: hold.CustomerID,
+		Status:     hold.Status,
+		ExpiresAt:  hold.ExpiresAt,
+		TicketID:   hold.TicketID,
+		CreatedAt:  hold.CreatedAt,
+	}
+}
@@ -0,0 +1,333 @@
+package usecase
+
+import (
+	"context"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// ItemUsecase manages the items handed in under a ticket and the item types
+// a service accepts
+type ItemUsecase struct {
+	itemTypeRepo   domain.ItemTypeRepository
+	ticketItemRepo domain.TicketItemRepository
+	ticketRepo     domain.TicketRepository
+	serviceRepo    domain.ServiceRepository
+	zoneRepo       domain.ZoneRepository
+	slotRepo       domain.SlotRepository
+}
+
+// NewItemUsecase creates a new item usecase
+func NewItemUsecase(
+	itemTypeRepo domain.ItemTypeRepository,
+	ticketItemRepo domain.TicketItemRepository,
+	ticketRepo domain.TicketRepository,
+	serviceRepo domain.ServiceRepository,
+	zoneRepo domain.ZoneRepository,
+	slotRepo domain.SlotRepository,
+) *ItemUsecase {
+	return &ItemUsecase{
+		itemTypeRepo:   itemTypeRepo,
+		ticketItemRepo: ticketItemRepo,
+		ticketRepo:     ticketRepo,
+		serviceRepo:    serviceRepo,
+		zoneRepo:       zoneRepo,
+		slotRepo:       slotRepo,
+	}
+}
+
+// Request/Response types
+type CreateItemTypeRequest struct {
+	Name       string `json:"name"`
+	ZoneID     string `json:"zone_id,omitempty"` // Items of this type are stored in this zone
+	ServiceID  string `json:"-"`
+	BusinessID string `json:"-"`
+}
+
+type ItemTypeResponse struct {
+	ID        string `json:"id"`
+	ServiceID string `json:"service_id"`
+	Name      string `json:"name"`
+	ZoneID    string `json:"zone_id,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type TicketItemRequest struct {
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	Count       int    `json:"count,omitempty"` // 0 = not counted
+}
+
+type AddTicketItemRequest struct {
+	TicketItemRequest
+	TicketID   string `json:"-"`
+	BusinessID string `json:"-"`
+}
+
+type TicketItemResponse struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	Count       int    `json:"count,omitempty"`
+}
+
+// ListItemTypes lists the item types of a service
+func (u *ItemUsecase) ListItemTypes(ctx context.Context, serviceID, businessID string) ([]ItemTypeResponse, error) {
+	if _, err := u.ownedService(ctx, serviceID, businessID); err != nil {
+		return nil, err
+	}
+
+	itemTypes, err := u.itemTypeRepo.ListByServiceID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]ItemTypeResponse, len(itemTypes))
+	for i, itemType := range itemTypes {
+		responses[i] = toItemTypeResponse(&itemType)
+	}
+
+	return responses, nil
+}
+
+// CreateItemType adds an item type to a service, optionally tying it to one
+// of the service's zones
+func (u *ItemUsecase) CreateItemType(ctx context.Context, req CreateItemTypeRequest) (*ItemTypeResponse, error) {
+	if req.Name == "" {
+		return nil, apperror.NewValidationError("name is required", map[string]string{})
+	}
+
+	service, err := u.ownedService(ctx, req.ServiceID, req.BusinessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ZoneID != "" {
+		zone, err := u.zoneRepo.FindByID(ctx, req.ZoneID)
+		if err != nil {
+			return nil, err
+		}
+		if zone.ServiceID != service.ID {
+			return nil, apperror.NewNotFound("zone")
+		}
+	}
+
+	now := domain.NowTimestamp()
+	itemType := &domain.ItemType{
+		ID:        uuid.New().String(),
+		ServiceID: service.ID,
+		Name:      req.Name,
+		ZoneID:    req.ZoneID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := u.itemTypeRepo.Create(ctx, itemType); err != nil {
+		return nil, err
+	}
+
+	response := toItemTypeResponse(itemType)
+	return &response, nil
+}
+
+// DeleteItemType removes an item type. Items already handed in under it
+// keep their type name.
+func (u *ItemUsecase) DeleteItemType(ctx context.Context, serviceID, typeID, businessID string) error {
+	if _, err := u.ownedService(ctx, serviceID, businessID); err != nil {
+		return err
+	}
+
+	itemType, err := u.itemTypeRepo.FindByID(ctx, typeID)
+	if err != nil {
+		return err
+	}
+
+	if itemType.ServiceID != serviceID {
+		return apperror.NewNotFound("item type")
+	}
+
+	return u.itemTypeRepo.Delete(ctx, typeID)
+}
+
+// AddItem adds an item to an active ticket. Items whose type is stored in a
+// zone can only be added to tickets whose slot is in that zone.
+func (u *ItemUsecase) AddItem(ctx context.Context, req AddTicketItemRequest) (*TicketItemResponse, error) {
+	ticket, err := u.activeTicket(ctx, req.TicketID, req.BusinessID)
+	if err != nil {
+		return nil, err
+	}
+
+	zoneID, err := u.itemsZone(ctx, ticket.ServiceID, []TicketItemRequest{req.TicketItemRequest})
+	if err != nil {
+		return nil, err
+	}
+
+	if zoneID != "" && ticket.SlotID != "" {
+		slot, err := u.slotRepo.FindByID(ctx, ticket.SlotID)
+		if err != nil {
+			return nil, err
+		}
+		if slot.ZoneID != zoneID {
+			return nil, apperror.NewConflict("item type is stored in another zone; check it in on a separate ticket")
+		}
+	}
+
+	items, err := u.createItems(ctx, ticket.ID, []TicketItemRequest{req.TicketItemRequest})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toTicketItemResponse(&items[0])
+	return &response, nil
+}
+
+// RemoveItem removes an item from an active ticket
+func (u *ItemUsecase) RemoveItem(ctx context.Context, ticketID, itemID, businessID string) error {
+	ticket, err := u.activeTicket(ctx, ticketID, businessID)
+	if err != nil {
+		return err
+	}
+
+	item, err := u.ticketItemRepo.FindByID(ctx, itemID)
+	if err != nil {
+		return err
+	}
+
+	if item.TicketID != ticket.ID {
+		return apperror.NewNotFound("ticket item")
+	}
+
+	return u.ticketItemRepo.Delete(ctx, itemID)
+}
+
+// itemsZone validates items and returns the zone their types are stored
+// in, or "" if none of them is tied to a zone
+func (u *ItemUsecase) itemsZone(ctx context.Context, serviceID string, items []TicketItemRequest) (string, error) {
+	zoneID := ""
+
+	for _, item := range items {
+		if item.Type == "" {
+			return "", apperror.NewValidationError("item type is required", map[string]string{})
+		}
+		if item.Count < 0 {
+			return "", apperror.NewValidationError("item count cannot be negative", map[string]string{})
+		}
+
+		itemType, err := u.itemTypeRepo.FindByName(ctx, serviceID, item.Type)
+		if apperror.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if itemType.ZoneID == "" {
+			continue
+		}
+		if zoneID != "" && zoneID != itemType.ZoneID {
+			return "", apperror.NewValidationError("items are stored in different zones; check them in on separate tickets", map[string]string{})
+		}
+		zoneID = itemType.ZoneID
+	}
+
+	return zoneID, nil
+}
+
+// createItems stores items under a ticket in the given order
+func (u *ItemUsecase) createItems(ctx context.Context, ticketID string, reqs []TicketItemRequest) ([]domain.TicketItem, error) {
+	now := domain.NowTimestamp()
+	items := make([]domain.TicketItem, len(reqs))
+	for i, req := range reqs {
+		items[i] = domain.TicketItem{
+			ID:          uuid.New().String(),
+			TicketID:    ticketID,
+			Type:        req.Type,
+			Description: req.Description,
+			Count:       req.Count,
+			CreatedAt:   now,
+		}
+	}
+
+	if err := u.ticketItemRepo.CreateBatch(ctx, items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// listItems returns a ticket's items in the order they were added
+func (u *ItemUsecase) listItems(ctx context.Context, ticketID string) ([]TicketItemResponse, error) {
+	items, err := u.ticketItemRepo.ListByTicketID(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toTicketItemResponses(items), nil
+}
+
+// activeTicket loads an active ticket of one of the business's services
+func (u *ItemUsecase) activeTicket(ctx context.Context, ticketID, businessID string) (*domain.Ticket, error) {
+	ticket, err := u.ticketRepo.FindByID(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := u.serviceRepo.FindByID(ctx, ticket.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if service.BusinessID != businessID {
+		return nil, apperror.NewForbidden("ticket does not belong to this business")
+	}
+
+	if ticket.Status != domain.TicketStatusActive {
+		return nil, apperror.NewConflict("ticket is no longer active")
+	}
+
+	return ticket, nil
+}
+
+// ownedService loads a service of the business
+func (u *ItemUsecase) ownedService(ctx context.Context, serviceID, businessID string) (*domain.Service, error) {
+	service, err := u.serviceRepo.FindByID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if service.BusinessID != businessID {
+		return nil, apperror.NewForbidden("service does not belong to this business")
+	}
+
+	return service, nil
+}
+
+func toItemTypeResponse(itemType *domain.ItemType) ItemTypeResponse {
+	return ItemTypeResponse{
+		ID:        itemType.ID,
+		ServiceID: itemType.ServiceID,
+		Name:      itemType.Name,
+		ZoneID:    itemType.ZoneID,
+		CreatedAt: itemType.CreatedAt,
+	}
+}
+
+func toTicketItemResponse(item *domain.TicketItem) TicketItemResponse {
+	return TicketItemResponse{
+		ID:          item.ID,
+		Type:        item.Type,
+		Description: item.Description,
+		Count:       item.Count,
+	}
+}
+
+func toTicketItemResponses(items []domain.TicketItem) []TicketItemResponse {
+	responses := make([]TicketItemResponse, len(items))
+	for i, item := range items {
+		responses[i] = toTicketItemResponse(&item)
+	}
+	return responses
+}
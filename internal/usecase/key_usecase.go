@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+	"CLOAKBE/internal/qr"
+
+	"github.com/google/uuid"
+)
+
+// KeyUsecase manages the QR signing keyring of a business
+type KeyUsecase struct {
+	keyRepo     domain.BusinessKeyRepository
+	txManager   domain.TxManager
+	gracePeriod time.Duration
+}
+
+// NewKeyUsecase creates a new key usecase
+func NewKeyUsecase(
+	keyRepo domain.BusinessKeyRepository,
+	txManager domain.TxManager,
+	gracePeriod time.Duration,
+) *KeyUsecase {
+	return &KeyUsecase{
+		keyRepo:     keyRepo,
+		txManager:   txManager,
+		gracePeriod: gracePeriod,
+	}
+}
+
+// Request/Response types
+type RotateKeyRequest struct {
+	// Algorithm selects the key to rotate; empty rotates every algorithm
+	Algorithm string `json:"algorithm"`
+	// GracePeriodSeconds overrides the configured grace period; 0 revokes
+	// the retired key immediately (e.g. after a leak)
+	GracePeriodSeconds *int64 `json:"grace_period_seconds"`
+	BusinessID         string `json:"-"`
+}
+
+type KeyResponse struct {
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key,omitempty"`
+	Status    string `json:"status"`
+	CreatedAt int64  `json:"created_at"`
+	RetiredAt int64  `json:"retired_at,omitempty"`
+	ExpiresAt int64  `json:"expires_at,omitempty"`
+}
+
+// ListKeys returns the keyring of a business without secrets
+func (u *KeyUsecase) ListKeys(ctx context.Context, businessID string) ([]KeyResponse, error) {
+	keys, err := u.keyRepo.ListByBusinessID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]KeyResponse, len(keys))
+	for i, key := range keys {
+		responses[i] = toKeyResponse(&key)
+	}
+
+	return responses, nil
+}
+
+// PublicKeyResponse describes an Ed25519 verification key as a JWK
+type PublicKeyResponse struct {
+	KeyID     string `json:"kid"`
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	Algorithm string `json:"alg"`
+	X         string `json:"x"`
+	Status    string `json:"status"`
+	ExpiresAt int64  `json:"expires_at,omitempty"`
+}
+
+// ListPublicKeys returns the Ed25519 keys scanner devices need to verify
+// v2 QR payloads offline, including retired keys still in their grace period
+func (u *KeyUsecase) ListPublicKeys(ctx context.Context, businessID string) ([]PublicKeyResponse, error) {
+	keys, err := u.keyRepo.ListByBusinessID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := domain.NowTimestamp()
+	responses := make([]PublicKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		if key.Algorithm != domain.KeyAlgorithmEd25519 || !key.CanVerify(now) {
+			continue
+		}
+		responses = append(responses, PublicKeyResponse{
+			KeyID:     key.ID,
+			KeyType:   "OKP",
+			Curve:     "Ed25519",
+			Algorithm: "EdDSA",
+			X:         key.PublicKey,
+			Status:    key.Status,
+			ExpiresAt: key.ExpiresAt,
+		})
+	}
+
+	return responses, nil
+}
+
+// RotateKey retires the active signing keys and creates new ones. Tickets
+// signed with a retired key keep verifying until the grace period ends.
+func (u *KeyUsecase) RotateKey(ctx context.Context, req RotateKeyRequest) ([]KeyResponse, error) {
+	algorithms := []string{domain.KeyAlgorithmHMAC, domain.KeyAlgorithmEd25519}
+	switch req.Algorithm {
+	case "":
+	case domain.KeyAlgorithmHMAC, domain.KeyAlgorithmEd25519:
+		algorithms = []string{req.Algorithm}
+	default:
+		return nil, apperror.NewValidationError("algorithm must be hmac-sha256 or ed25519", map[string]string{})
+	}
+
+	grace := u.gracePeriod
+	if req.GracePeriodSeconds != nil {
+		if *req.GracePeriodSeconds < 0 {
+			return nil, apperror.NewValidationError("grace_period_seconds cannot be negative", map[string]string{})
+		}
+		grace = time.Duration(*req.GracePeriodSeconds) * time.Second
+	}
+
+	responses := make([]KeyResponse, 0, len(algorithms))
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		now := domain.NowTimestamp()
+
+		for _, algorithm := range algorithms {
+			current, err := u.keyRepo.FindActiveByBusinessID(ctx, req.BusinessID, algorithm)
+			if err != nil && !apperror.IsNotFound(err) {
+				return err
+			}
+			if current != nil {
+				if err := u.keyRepo.Retire(ctx, current.ID, now, now+int64(grace.Seconds())); err != nil {
+					return err
+				}
+			}
+
+			key, err := newBusinessKey(req.BusinessID, uuid.New().String(), algorithm)
+			if err != nil {
+				return err
+			}
+
+			if err := u.keyRepo.Create(ctx, key); err != nil {
+				return err
+			}
+			responses = append(responses, toKeyResponse(key))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return responses, nil
+}
+
+// newBusinessKey creates an active signing key with fresh key material
+func newBusinessKey(businessID, keyID, algorithm string) (*domain.BusinessKey, error) {
+	key := &domain.BusinessKey{
+		ID:         keyID,
+		BusinessID: businessID,
+		Algorithm:  algorithm,
+		Status:     domain.KeyStatusActive,
+		CreatedAt:  domain.NowTimestamp(),
+	}
+
+	switch algorithm {
+	case domain.KeyAlgorithmEd25519:
+		secret, publicKey, err := qr.GenerateEd25519Key()
+		if err != nil {
+			return nil, apperror.NewInternalServer("key generation failed", err)
+		}
+		key.Secret = secret
+		key.PublicKey = publicKey
+	default:
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, apperror.NewInternalServer("key generation failed", err)
+		}
+		key.Secret = hex.EncodeToString(secret)
+	}
+
+	return key, nil
+}
+
+// activeSigningKey returns the business's active key for algorithm, creating
+// one for businesses registered before the algorithm was introduced
+func activeSigningKey(ctx context.Context, keyRepo domain.BusinessKeyRepository, businessID, algorithm string) (*domain.BusinessKey, error) {
+	key, err := keyRepo.FindActiveByBusinessID(ctx, businessID, algorithm)
+	if err == nil || !apperror.IsNotFound(err) {
+		return key, err
+	}
+
+	key, err = newBusinessKey(businessID, uuid.New().String(), algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := keyRepo.Create(ctx, key); err != nil {
+		// Another request created it concurrently
+		if apperror.IsConflict(err) {
+			return keyRepo.FindActiveByBusinessID(ctx, businessID, algorithm)
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func toKeyResponse(key *domain.BusinessKey) KeyResponse {
+	return KeyResponse{
+		KeyID:     key.ID,
+		Algorithm: key.Algorithm,
+		PublicKey: key.PublicKey,
+		Status:    key.Status,
+		CreatedAt: key.CreatedAt,
+		RetiredAt: key.RetiredAt,
+		ExpiresAt: key.ExpiresAt,
+	}
+}
@@ -0,0 +1,363 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+	"CLOAKBE/internal/totp"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// totpIssuer names the account in authenticator apps
+	totpIssuer = "CLOAK"
+	// totpSkew is how many 30-second steps of clock drift codes may have
+	totpSkew = 1
+	// mfaChallengeTTL is how many seconds a login waits for its second factor
+	mfaChallengeTTL = 300
+	// maxChallengeAttempts is how many wrong codes void a login challenge
+	maxChallengeAttempts = 5
+	// recoveryCodeCount is how many recovery codes are issued at a time
+	recoveryCodeCount = 10
+)
+
+// MFAUsecase enrolls businesses and staff in TOTP two-factor
+// authentication and completes logins that wait for the second factor
+type MFAUsecase struct {
+	factorRepo   domain.TOTPFactorRepository
+	recoveryRepo domain.RecoveryCodeRepository
+	mfaRepo      domain.MFAChallengeRepository
+	auth         *AuthUsecase
+	txManager    domain.TxManager
+}
+
+// NewMFAUsecase creates a new MFA usecase
+func NewMFAUsecase(
+	factorRepo domain.TOTPFactorRepository,
+	recoveryRepo domain.RecoveryCodeRepository,
+	mfaRepo domain.MFAChallengeRepository,
+	auth *AuthUsecase,
+	txManager domain.TxManager,
+) *MFAUsecase {
+	return &MFAUsecase{
+		factorRepo:   factorRepo,
+		recoveryRepo: recoveryRepo,
+		mfaRepo:      mfaRepo,
+		auth:         auth,
+		txManager:    txManager,
+	}
+}
+
+// Request/Response types
+type MFACodeRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code,omitempty"` // Instead of code, where accepted
+	SubjectID    string `json:"-"`                       // Business or staff ID
+}
+
+type VerifyMFARequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code,omitempty"`
+	RecoveryCode   string `json:"recovery_code,omitempty"` // When the authenticator app is lost
+}
+
+type MFAStatusResponse struct {
+	Enabled           bool `json:"enabled"`
+	Pending           bool `json:"pending"` // Set up but not confirmed yet
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+type MFASetupResponse struct {
+	Secret          string `json:"secret"`           // Base32, for manual entry
+	ProvisioningURI string `json:"provisioning_uri"` // otpauth:// URI, usually shown as a QR code
+}
+
+// RecoveryCodesResponse carries recovery codes. They are shown once; only
+// their hashes are stored.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// Status reports whether a business or staff member has 2FA on
+func (u *MFAUsecase) Status(ctx context.Context, subjectID string) (*MFAStatusResponse, error) {
+	factor, err := u.factorRepo.FindBySubject(ctx, subjectID)
+	if apperror.IsNotFound(err) {
+		return &MFAStatusResponse{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	left, err := u.recoveryRepo.CountUnused(ctx, subjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MFAStatusResponse{
+		Enabled:           factor.IsEnabled(),
+		Pending:           !factor.IsEnabled(),
+		RecoveryCodesLeft: left,
+	}, nil
+}
+
+// Setup generates a TOTP secret for the subject. 2FA stays off until the
+// first code from the authenticator app is confirmed; setting up again
+// before that replaces the secret.
+func (u *MFAUsecase) Setup(ctx context.Context, subjectID, account string) (*MFASetupResponse, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, apperror.NewInternalServer("secret generation failed", err)
+	}
+
+	now := domain.NowTimestamp()
+	factor := &domain.TOTPFactor{
+		SubjectID: subjectID,
+		Secret:    secret,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := u.factorRepo.Save(ctx, factor); err != nil {
+		return nil, err
+	}
+
+	return &MFASetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(secret, totpIssuer, account),
+	}, nil
+}
+
+// Confirm turns 2FA on with a code from the newly set up authenticator app
+// and returns the first recovery codes
+func (u *MFAUsecase) Confirm(ctx context.Context, req MFACodeRequest) (*RecoveryCodesResponse, error) {
+	factor, err := u.factorRepo.FindBySubject(ctx, req.SubjectID)
+	if apperror.IsNotFound(err) {
+		return nil, apperror.NewConflict("set up two-factor authentication first")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if factor.IsEnabled() {
+		return nil, apperror.NewConflict("two-factor authentication is already enabled")
+	}
+
+	step, ok := totp.Validate(factor.Secret, req.Code, time.Now(), totpSkew)
+	if !ok {
+		return nil, apperror.NewUnauthorized("invalid code")
+	}
+
+	var codes []string
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.factorRepo.Confirm(ctx, req.SubjectID, domain.NowTimestamp()); err != nil {
+			return err
+		}
+		if err := u.factorRepo.UseStep(ctx, req.SubjectID, step); err != nil {
+			return err
+		}
+		codes, err = u.replaceRecoveryCodes(ctx, req.SubjectID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// RegenerateRecoveryCodes replaces the subject's recovery codes, used or
+// not, after checking a TOTP code
+func (u *MFAUsecase) RegenerateRecoveryCodes(ctx context.Context, req MFACodeRequest) (*RecoveryCodesResponse, error) {
+	factor, err := u.enabledFactor(ctx, req.SubjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.checkSecondFactor(ctx, factor, req.Code, ""); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		codes, err = u.replaceRecoveryCodes(ctx, req.SubjectID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable turns 2FA off after checking a TOTP or recovery code. Recovery
+// codes are removed with the secret.
+func (u *MFAUsecase) Disable(ctx context.Context, req MFACodeRequest) error {
+	factor, err := u.enabledFactor(ctx, req.SubjectID)
+	if err != nil {
+		return err
+	}
+
+	if err := u.checkSecondFactor(ctx, factor, req.Code, req.RecoveryCode); err != nil {
+		return err
+	}
+
+	return u.factorRepo.Delete(ctx, req.SubjectID)
+}
+
+// VerifyChallenge completes a login that passed the password check with a
+// TOTP or recovery code and starts the session
+func (u *MFAUsecase) VerifyChallenge(ctx context.Context, req VerifyMFARequest) (*AuthResponse, error) {
+	if req.ChallengeToken == "" {
+		return nil, apperror.NewValidationError("challenge_token is required", map[string]string{})
+	}
+
+	challenge, err := u.mfaRepo.FindByTokenHash(ctx, hashOpaqueToken(req.ChallengeToken))
+	if apperror.IsNotFound(err) {
+		return nil, apperror.NewUnauthorized("invalid or expired challenge; log in again")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if challenge.UsedAt != 0 || challenge.ExpiresAt <= domain.NowTimestamp() {
+		return nil, apperror.NewUnauthorized("invalid or expired challenge; log in again")
+	}
+
+	if challenge.Attempts >= maxChallengeAttempts {
+		return nil, apperror.NewUnauthorized("too many wrong codes; log in again")
+	}
+
+	factor, err := u.enabledFactor(ctx, challenge.Subject)
+	if apperror.IsConflict(err) {
+		return nil, apperror.NewUnauthorized("invalid or expired challenge; log in again")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Count the attempt before checking the code, so parallel guesses on one
+	// challenge cannot all pass the limit
+	attempts, err := u.mfaRepo.RecordAttempt(ctx, challenge.ID, maxChallengeAttempts)
+	if apperror.IsConflict(err) {
+		return nil, apperror.NewUnauthorized("too many wrong codes; log in again")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.checkSecondFactor(ctx, factor, req.Code, req.RecoveryCode); err != nil {
+		if !apperror.IsUnauthorized(err) {
+			return nil, err
+		}
+		if attempts >= maxChallengeAttempts {
+			return nil, apperror.NewUnauthorized("too many wrong codes; log in again")
+		}
+		appErr := apperror.From(err)
+		appErr.Details["attempts_left"] = maxChallengeAttempts - attempts
+		return nil, appErr
+	}
+
+	var response *AuthResponse
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.mfaRepo.Use(ctx, challenge.ID, domain.NowTimestamp()); err != nil {
+			if apperror.IsConflict(err) {
+				return apperror.NewUnauthorized("invalid or expired challenge; log in again")
+			}
+			return err
+		}
+
+		response, err = u.auth.startSession(ctx, challengeClaims(challenge), challenge.Subject)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// enabledFactor loads the subject's factor, which must be turned on
+func (u *MFAUsecase) enabledFactor(ctx context.Context, subjectID string) (*domain.TOTPFactor, error) {
+	factor, err := u.factorRepo.FindBySubject(ctx, subjectID)
+	if apperror.IsNotFound(err) {
+		return nil, apperror.NewConflict("two-factor authentication is not enabled")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !factor.IsEnabled() {
+		return nil, apperror.NewConflict("two-factor authentication is not enabled")
+	}
+
+	return factor, nil
+}
+
+// checkSecondFactor accepts a TOTP code, each time step once, or an unused
+// recovery code, which is then spent. Wrong codes fail as unauthorized.
+func (u *MFAUsecase) checkSecondFactor(ctx context.Context, factor *domain.TOTPFactor, code, recoveryCode string) error {
+	if recoveryCode != "" {
+		err := u.recoveryRepo.Use(ctx, factor.SubjectID, hashOpaqueToken(normalizeRecoveryCode(recoveryCode)), domain.NowTimestamp())
+		if apperror.IsNotFound(err) {
+			return apperror.NewUnauthorized("invalid recovery code")
+		}
+		return err
+	}
+
+	if code == "" {
+		return apperror.NewValidationError("code is required", map[string]string{})
+	}
+
+	step, ok := totp.Validate(factor.Secret, code, time.Now(), totpSkew)
+	if !ok {
+		return apperror.NewUnauthorized("invalid code")
+	}
+
+	err := u.factorRepo.UseStep(ctx, factor.SubjectID, step)
+	if apperror.IsConflict(err) {
+		return apperror.NewUnauthorized("code was already used; wait for the next one")
+	}
+	return err
+}
+
+// replaceRecoveryCodes issues a new set of recovery codes and returns them
+func (u *MFAUsecase) replaceRecoveryCodes(ctx context.Context, subjectID string) ([]string, error) {
+	now := domain.NowTimestamp()
+	codes := make([]string, recoveryCodeCount)
+	records := make([]domain.RecoveryCode, recoveryCodeCount)
+
+	for i := range codes {
+		raw := make([]byte, 6)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, apperror.NewInternalServer("recovery code generation failed", err)
+		}
+		code := hex.EncodeToString(raw)
+		codes[i] = code[:4] + "-" + code[4:8] + "-" + code[8:]
+
+		records[i] = domain.RecoveryCode{
+			ID:        uuid.New().String(),
+			SubjectID: subjectID,
+			CodeHash:  hashOpaqueToken(code),
+			CreatedAt: now,
+		}
+	}
+
+	if err := u.recoveryRepo.Replace(ctx, subjectID, records); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// normalizeRecoveryCode drops the dashes and spaces people type recovery
+// codes with
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+// lockoutMemory is how many seconds bad passwords are remembered after the
+// last one
+const lockoutMemory = 24 * 60 * 60
+
+// RateLimitPolicy caps the requests one IP, account or business may make
+// in a fixed window
+type RateLimitPolicy struct {
+	Name   string // Namespaces the counters, e.g. "login-ip"
+	Limit  int
+	Window time.Duration
+}
+
+// RateLimitResult reports a request counted under a policy
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Reset     int64 // Seconds until the window ends
+}
+
+// LockoutPolicy locks an account out after repeated bad passwords. The
+// first lockout lasts BaseDelay and each further bad password doubles it,
+// up to MaxDelay. A zero Threshold turns lockouts off.
+type LockoutPolicy struct {
+	Threshold int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// RateLimitUsecase throttles requests per policy and locks accounts out
+// after repeated bad passwords. Counters live in the repositories, so API
+// instances sharing PostgreSQL share limits.
+type RateLimitUsecase struct {
+	limitRepo   domain.RateLimitRepository
+	lockoutRepo domain.LoginLockoutRepository
+	lockout     LockoutPolicy
+}
+
+// NewRateLimitUsecase creates a new rate limit usecase
+func NewRateLimitUsecase(
+	limitRepo domain.RateLimitRepository,
+	lockoutRepo domain.LoginLockoutRepository,
+	lockout LockoutPolicy,
+) *RateLimitUsecase {
+	return &RateLimitUsecase{
+		limitRepo:   limitRepo,
+		lockoutRepo: lockoutRepo,
+		lockout:     lockout,
+	}
+}
+
+// Allow counts a request under a policy and key and reports whether it is
+// within the limit
+func (u *RateLimitUsecase) Allow(ctx context.Context, policy RateLimitPolicy, key string) (*RateLimitResult, error) {
+	now := domain.NowTimestamp()
+
+	counter, err := u.limitRepo.Hit(ctx, policy.Name+":"+key, int64(policy.Window/time.Second), now)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := policy.Limit - counter.Count
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return &RateLimitResult{
+		Allowed:   counter.Count <= policy.Limit,
+		Limit:     policy.Limit,
+		Remaining: remaining,
+		Reset:     counter.ResetAt - now,
+	}, nil
+}
+
+// CheckLockout refuses a login while the account is locked out
+func (u *RateLimitUsecase) CheckLockout(ctx context.Context, key string) error {
+	if u.lockout.Threshold == 0 {
+		return nil
+	}
+
+	lockout, err := u.lockoutRepo.FindByKey(ctx, key)
+	if apperror.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	now := domain.NowTimestamp()
+	if lockout.LockedUntil > now {
+		return apperror.NewTooManyRequests("too many failed logins; try again later", lockout.LockedUntil-now)
+	}
+
+	return nil
+}
+
+// RecordLoginFailure counts a bad password and locks the account out once
+// there have been too many
+func (u *RateLimitUsecase) RecordLoginFailure(ctx context.Context, key string) error {
+	if u.lockout.Threshold == 0 {
+		return nil
+	}
+
+	now := domain.NowTimestamp()
+	lockout, err := u.lockoutRepo.RecordFailure(ctx, key, now, now-lockoutMemory)
+	if err != nil {
+		return err
+	}
+
+	if lockout.Failures < u.lockout.Threshold {
+		return nil
+	}
+
+	return u.lockoutRepo.Lock(ctx, key, now+int64(u.lockoutDelay(lockout.Failures)/time.Second))
+}
+
+// RecordLoginSuccess forgets an account's bad passwords
+func (u *RateLimitUsecase) RecordLoginSuccess(ctx context.Context, key string) error {
+	if u.lockout.Threshold == 0 {
+		return nil
+	}
+
+	return u.lockoutRepo.Delete(ctx, key)
+}
+
+// Sweep deletes finished rate limit windows and forgotten lockouts
+func (u *RateLimitUsecase) Sweep(ctx context.Context) error {
+	now := domain.NowTimestamp()
+
+	if _, err := u.limitRepo.DeleteExpired(ctx, now); err != nil {
+		return err
+	}
+
+	_, err := u.lockoutRepo.DeleteStale(ctx, now-lockoutMemory, now)
+	return err
+}
+
+// lockoutDelay doubles the base delay for each bad password past the
+// threshold
+func (u *RateLimitUsecase) lockoutDelay(failures int) time.Duration {
+	delay := u.lockout.BaseDelay
+	for i := u.lockout.Threshold; i < failures && delay < u.lockout.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > u.lockout.MaxDelay {
+		delay = u.lockout.MaxDelay
+	}
+
+	return delay
+}
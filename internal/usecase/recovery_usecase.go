@@ -0,0 +1,335 @@
+package usecase
+
+import (
+	"context"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+	"CLOAKBE/internal/qr"
+
+	"github.com/google/uuid"
+)
+
+// maxLookupResults caps how many tickets a lost-ticket lookup returns
+const maxLookupResults = 50
+
+// RecoveryUsecase helps staff find lost tickets and reissue them with a new
+// QR code, revoking the old one
+type RecoveryUsecase struct {
+	ticketRepo   domain.TicketRepository
+	reissueRepo  domain.TicketReissueRepository
+	serviceRepo  domain.ServiceRepository
+	customerRepo domain.CustomerRepository
+	slotRepo     domain.SlotRepository
+	keyRepo      domain.BusinessKeyRepository
+	items        *ItemUsecase
+	events       *TicketEventUsecase
+	txManager    domain.TxManager
+}
+
+// NewRecoveryUsecase creates a new recovery usecase
+func NewRecoveryUsecase(
+	ticketRepo domain.TicketRepository,
+	reissueRepo domain.TicketReissueRepository,
+	serviceRepo domain.ServiceRepository,
+	customerRepo domain.CustomerRepository,
+	slotRepo domain.SlotRepository,
+	keyRepo domain.BusinessKeyRepository,
+	items *ItemUsecase,
+	events *TicketEventUsecase,
+	txManager domain.TxManager,
+) *RecoveryUsecase {
+	return &RecoveryUsecase{
+		ticketRepo:   ticketRepo,
+		reissueRepo:  reissueRepo,
+		serviceRepo:  serviceRepo,
+		customerRepo: customerRepo,
+		slotRepo:     slotRepo,
+		keyRepo:      keyRepo,
+		items:        items,
+		events:       events,
+		txManager:    txManager,
+	}
+}
+
+// Request/Response types
+type LookupTicketsRequest struct {
+	Email      string
+	Phone      string
+	SlotNumber int
+	ServiceID  string
+	From       int64 // Issued at or after, Unix seconds
+	To         int64 // Issued at or before, Unix seconds
+	Status     string
+	BusinessID string
+}
+
+type LostTicketResponse struct {
+	TicketID      string               `json:"ticket_id"`
+	ServiceID     string               `json:"service_id"`
+	SlotNumber    int                  `json:"slot_number"`
+	Status        string               `json:"status"`
+	IssuedAt      int64                `json:"issued_at"`
+	CustomerEmail string               `json:"customer_email,omitempty"`
+	CustomerPhone string               `json:"customer_phone,omitempty"`
+	Items         []TicketItemResponse `json:"items"` // Lets staff ask the guest to describe them
+}
+
+type ReissueTicketRequest struct {
+	IdentityMethod string `json:"identity_method"` // id_document, account, item_description or other
+	IdentityNote   string `json:"identity_note,omitempty"`
+	TicketID       string `json:"-"`
+	BusinessID     string `json:"-"`
+	StaffID        string `json:"-"` // Staff member reissuing the ticket, "" for the business account
+}
+
+type ReissueResponse struct {
+	ID             string `json:"id"`
+	TicketID       string `json:"ticket_id"`
+	IdentityMethod string `json:"identity_method"`
+	IdentityNote   string `json:"identity_note,omitempty"`
+	ReissuedBy     string `json:"reissued_by"`
+	CreatedAt      int64  `json:"created_at"`
+}
+
+// ReissueTicketResponse carries the replacement QR code
+type ReissueTicketResponse struct {
+	CheckInResponse
+	Reissue ReissueResponse `json:"reissue"`
+}
+
+// LookupTickets finds a business's tickets by customer email or phone, slot
+// number and issue time
+func (u *RecoveryUsecase) LookupTickets(ctx context.Context, req LookupTicketsRequest) ([]LostTicketResponse, error) {
+	if req.Email == "" && req.Phone == "" && req.SlotNumber == 0 {
+		return nil, apperror.NewValidationError("email, phone or slot_number is required", map[string]string{})
+	}
+
+	if req.SlotNumber < 0 || req.From < 0 || req.To < 0 {
+		return nil, apperror.NewValidationError("slot_number, from and to cannot be negative", map[string]string{})
+	}
+
+	if req.To != 0 && req.From > req.To {
+		return nil, apperror.NewValidationError("from must not be after to", map[string]string{})
+	}
+
+	if req.ServiceID != "" {
+		service, err := u.serviceRepo.FindByID(ctx, req.ServiceID)
+		if err != nil {
+			return nil, err
+		}
+		if service.BusinessID != req.BusinessID {
+			return nil, apperror.NewForbidden("service does not belong to this business")
+		}
+	}
+
+	tickets, err := u.ticketRepo.Search(ctx, domain.TicketSearch{
+		BusinessID:    req.BusinessID,
+		ServiceID:     req.ServiceID,
+		CustomerEmail: req.Email,
+		CustomerPhone: req.Phone,
+		SlotNumber:    req.SlotNumber,
+		IssuedFrom:    req.From,
+		IssuedTo:      req.To,
+		Status:        req.Status,
+		Limit:         maxLookupResults,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	customers := make(map[string]*domain.Customer)
+	responses := make([]LostTicketResponse, len(tickets))
+	for i, ticket := range tickets {
+		items, err := u.items.listItems(ctx, ticket.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		responses[i] = LostTicketResponse{
+			TicketID:   ticket.ID,
+			ServiceID:  ticket.ServiceID,
+			SlotNumber: ticket.SlotNumber,
+			Status:     ticket.Status,
+			IssuedAt:   ticket.IssuedAt,
+			Items:      items,
+		}
+
+		if ticket.CustomerID == "" {
+			continue
+		}
+		customer, ok := customers[ticket.CustomerID]
+		if !ok {
+			customer, err = u.customerRepo.FindByID(ctx, ticket.CustomerID)
+			if err != nil {
+				return nil, err
+			}
+			customers[ticket.CustomerID] = customer
+		}
+		responses[i].CustomerEmail = customer.Email
+		responses[i].CustomerPhone = customer.Phone
+	}
+
+	return responses, nil
+}
+
+// ReissueTicket issues a new QR code for the same ticket and slot after
+// staff checked the guest's identity. The old code is revoked for good.
+func (u *RecoveryUsecase) ReissueTicket(ctx context.Context, req ReissueTicketRequest) (*ReissueTicketResponse, error) {
+	switch req.IdentityMethod {
+	case domain.IdentityCheckIDDocument, domain.IdentityCheckAccount, domain.IdentityCheckItemDescription:
+	case domain.IdentityCheckOther:
+		if req.IdentityNote == "" {
+			return nil, apperror.NewValidationError("identity_note is required when identity_method is other", map[string]string{})
+		}
+	default:
+		return nil, apperror.NewValidationError("identity_method must be id_document, account, item_description or other", map[string]string{})
+	}
+
+	ticket, err := u.ticketRepo.FindByID(ctx, req.TicketID)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := u.serviceRepo.FindByID(ctx, ticket.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if service.BusinessID != req.BusinessID {
+		return nil, apperror.NewForbidden("ticket does not belong to this business")
+	}
+
+	if ticket.Status != domain.TicketStatusActive {
+		return nil, apperror.NewConflict("only active tickets can be reissued")
+	}
+
+	key, err := activeSigningKey(ctx, u.keyRepo, req.BusinessID, domain.KeyAlgorithmEd25519)
+	if err != nil {
+		return nil, err
+	}
+
+	// The generation keeps the new signature distinct from every revoked one,
+	// even when reissued within the same second
+	payload := qr.NewV2(ticket.ID, ticket.ServiceID, req.BusinessID, ticket.SlotNumber, key.ID)
+	payload.Generation = ticket.QRGeneration + 1
+	encoded, err := signPayload(payload, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record who checked the guest's identity: the staff member, or the
+	// business when it is signed in itself
+	reissuedBy := req.StaffID
+	if reissuedBy == "" {
+		reissuedBy = req.BusinessID
+	}
+
+	reissue := &domain.TicketReissue{
+		ID:             uuid.New().String(),
+		TicketID:       ticket.ID,
+		RevokedDigest:  ticket.HMACDigest,
+		IdentityMethod: req.IdentityMethod,
+		IdentityNote:   req.IdentityNote,
+		ReissuedBy:     reissuedBy,
+		CreatedAt:      domain.NowTimestamp(),
+	}
+
+	// Swap the digest and revoke the old one together; a concurrent reissue
+	// of the same ticket fails the conditional update
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.ticketRepo.UpdateDigest(ctx, ticket.ID, ticket.HMACDigest, payload.Digest(), payload.Generation); err != nil {
+			return err
+		}
+		if err := u.reissueRepo.Create(ctx, reissue); err != nil {
+			return err
+		}
+		return u.events.record(ctx, ticket, req.BusinessID, domain.TicketEventReissued, businessActor(req.BusinessID, req.StaffID, nil), map[string]interface{}{
+			"reason":          "lost",
+			"reissue_id":      reissue.ID,
+			"identity_method": reissue.IdentityMethod,
+			"qr_generation":   payload.Generation,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := u.items.listItems(ctx, ticket.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	zoneID := ""
+	if ticket.SlotID != "" {
+		slot, err := u.slotRepo.FindByID(ctx, ticket.SlotID)
+		if err != nil {
+			return nil, err
+		}
+		zoneID = slot.ZoneID
+	}
+
+	return &ReissueTicketResponse{
+		CheckInResponse: CheckInResponse{
+			TicketID:   ticket.ID,
+			SlotNumber: ticket.SlotNumber,
+			ZoneID:     zoneID,
+			QRPayload:  encoded,
+			IssuedAt:   payload.IssuedAt,
+			Items:      items,
+		},
+		Reissue: toReissueResponse(reissue),
+	}, nil
+}
+
+// ListReissues lists the reissues of a ticket with the identity checks
+// staff recorded
+func (u *RecoveryUsecase) ListReissues(ctx context.Context, ticketID, businessID string) ([]ReissueResponse, error) {
+	ticket, err := u.ticketRepo.FindByID(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := u.serviceRepo.FindByID(ctx, ticket.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if service.BusinessID != businessID {
+		return nil, apperror.NewForbidden("ticket does not belong to this business")
+	}
+
+	reissues, err := u.reissueRepo.ListByTicketID(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]ReissueResponse, len(reissues))
+	for i, reissue := range reissues {
+		responses[i] = toReissueResponse(&reissue)
+	}
+
+	return responses, nil
+}
+
+// revocation returns the reissue that revoked a signature digest, or nil if
+// the digest was never revoked
+func (u *RecoveryUsecase) revocation(ctx context.Context, digest string) (*domain.TicketReissue, error) {
+	reissue, err := u.reissueRepo.FindByRevokedDigest(ctx, digest)
+	if apperror.IsNotFound(err) {
+		return nil, nil
+	}
+
+	return reissue, err
+}
+
+func toReissueResponse(reissue *domain.TicketReissue) ReissueResponse {
+	return ReissueResponse{
+		ID:             reissue.ID,
+		TicketID:       reissue.TicketID,
+		IdentityMethod: reissue.IdentityMethod,
+		IdentityNote:   reissue.IdentityNote,
+		ReissuedBy:     reissue.ReissuedBy,
+		CreatedAt:      reissue.CreatedAt,
+	}
+}
@@ -0,0 +1,353 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Scan attempt and alert page sizes
+const (
+	defaultScanPageSize = 100
+	maxScanPageSize     = 500
+)
+
+// ScanAlertPolicy tunes the rules that flag suspicious scan attempts
+type ScanAlertPolicy struct {
+	// Bad signatures from one device or staff member within
+	// BadSignatureWindow that raise an alert; 0 turns the rule off
+	BadSignatureThreshold int
+	BadSignatureWindow    time.Duration
+	// How soon a valid scan of the same ticket on another scanner counts as
+	// a duplicate; 0 turns the rule off
+	DuplicateWindow time.Duration
+}
+
+// ScanAuditUsecase records every QR code presented to a scanner, flags
+// suspicious attempts and serves both to the business
+type ScanAuditUsecase struct {
+	attemptRepo domain.ScanAttemptRepository
+	alertRepo   domain.ScanAlertRepository
+	txManager   domain.TxManager
+	policy      ScanAlertPolicy
+}
+
+// NewScanAuditUsecase creates a new scan audit usecase
+func NewScanAuditUsecase(
+	attemptRepo domain.ScanAttemptRepository,
+	alertRepo domain.ScanAlertRepository,
+	txManager domain.TxManager,
+	policy ScanAlertPolicy,
+) *ScanAuditUsecase {
+	return &ScanAuditUsecase{
+		attemptRepo: attemptRepo,
+		alertRepo:   alertRepo,
+		txManager:   txManager,
+		policy:      policy,
+	}
+}
+
+// Request/Response types
+type ListScanAttemptsRequest struct {
+	Outcome    string
+	TicketID   string
+	DeviceID   string
+	StaffID    string
+	From       int64 // Attempted at or after, Unix seconds
+	To         int64 // Attempted at or before, Unix seconds
+	After      int64 // Seq of the last attempt already seen
+	Limit      int
+	BusinessID string
+}
+
+type ScanAttemptResponse struct {
+	ID          string `json:"id"`
+	Seq         int64  `json:"seq"`
+	ServiceID   string `json:"service_id,omitempty"`
+	TicketID    string `json:"ticket_id,omitempty"`
+	Outcome     string `json:"outcome"`
+	QRDigest    string `json:"qr_digest,omitempty"`
+	DeviceID    string `json:"device_id,omitempty"`
+	StaffID     string `json:"staff_id,omitempty"`
+	AttemptedAt int64  `json:"attempted_at"`
+}
+
+// ScanAttemptFeedResponse is a page of a business's scan attempts. NextAfter
+// is set when more attempts may follow; pass it as after to get them. Like
+// the ticket event feed, attempts committed late can appear behind the cursor.
+type ScanAttemptFeedResponse struct {
+	Attempts  []ScanAttemptResponse `json:"attempts"`
+	NextAfter int64                 `json:"next_after,omitempty"`
+}
+
+type ListScanAlertsRequest struct {
+	Rule       string
+	Status     string // "open" or "acknowledged", "" for both
+	From       int64  // Created at or after, Unix seconds
+	To         int64  // Created at or before, Unix seconds
+	Limit      int
+	BusinessID string
+}
+
+type ScanAlertResponse struct {
+	ID             string                 `json:"id"`
+	Rule           string                 `json:"rule"`
+	Status         string                 `json:"status"`
+	AttemptID      string                 `json:"attempt_id"`
+	TicketID       string                 `json:"ticket_id,omitempty"`
+	DeviceID       string                 `json:"device_id,omitempty"`
+	StaffID        string                 `json:"staff_id,omitempty"`
+	Details        map[string]interface{} `json:"details"`
+	CreatedAt      int64                  `json:"created_at"`
+	AcknowledgedAt int64                  `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy string                 `json:"acknowledged_by,omitempty"`
+}
+
+// ListAttempts pages through a business's scan attempts, oldest first
+func (u *ScanAuditUsecase) ListAttempts(ctx context.Context, req ListScanAttemptsRequest) (*ScanAttemptFeedResponse, error) {
+	switch req.Outcome {
+	case "", domain.ScanOutcomeValid, domain.ScanOutcomeAlreadyReleased, domain.ScanOutcomeExpired,
+		domain.ScanOutcomeBadSignature, domain.ScanOutcomeWrongBusiness, domain.ScanOutcomeWrongService,
+		domain.ScanOutcomeUnknownTicket, domain.ScanOutcomeRevoked, domain.ScanOutcomeMalformed:
+	default:
+		return nil, apperror.NewValidationError("outcome must be valid, already_released, expired, bad_signature, wrong_business, wrong_service, unknown_ticket, revoked or malformed", map[string]string{})
+	}
+
+	if req.From < 0 || req.To < 0 || req.After < 0 || req.Limit < 0 {
+		return nil, apperror.NewValidationError("from, to, after and limit cannot be negative", map[string]string{})
+	}
+
+	if req.To != 0 && req.From > req.To {
+		return nil, apperror.NewValidationError("from must not be after to", map[string]string{})
+	}
+
+	limit := scanPageSize(req.Limit)
+
+	attempts, err := u.attemptRepo.List(ctx, domain.ScanAttemptFilter{
+		BusinessID: req.BusinessID,
+		Outcome:    req.Outcome,
+		TicketID:   req.TicketID,
+		DeviceID:   req.DeviceID,
+		StaffID:    req.StaffID,
+		From:       req.From,
+		To:         req.To,
+		AfterSeq:   req.After,
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ScanAttemptFeedResponse{Attempts: make([]ScanAttemptResponse, len(attempts))}
+	for i := range attempts {
+		response.Attempts[i] = toScanAttemptResponse(&attempts[i])
+	}
+	if len(attempts) == limit {
+		response.NextAfter = attempts[len(attempts)-1].Seq
+	}
+
+	return response, nil
+}
+
+// ListAlerts lists a business's scan alerts, newest first
+func (u *ScanAuditUsecase) ListAlerts(ctx context.Context, req ListScanAlertsRequest) ([]ScanAlertResponse, error) {
+	switch req.Rule {
+	case "", domain.ScanAlertReleasedRescanned, domain.ScanAlertRevokedCode,
+		domain.ScanAlertDuplicateScan, domain.ScanAlertBadSignatureBurst:
+	default:
+		return nil, apperror.NewValidationError("rule must be released_ticket_rescanned, revoked_code_scanned, duplicate_scan or bad_signature_burst", map[string]string{})
+	}
+
+	switch req.Status {
+	case "", domain.ScanAlertStatusOpen, domain.ScanAlertStatusAcknowledged:
+	default:
+		return nil, apperror.NewValidationError("status must be open or acknowledged", map[string]string{})
+	}
+
+	if req.From < 0 || req.To < 0 || req.Limit < 0 {
+		return nil, apperror.NewValidationError("from, to and limit cannot be negative", map[string]string{})
+	}
+
+	if req.To != 0 && req.From > req.To {
+		return nil, apperror.NewValidationError("from must not be after to", map[string]string{})
+	}
+
+	alerts, err := u.alertRepo.List(ctx, domain.ScanAlertFilter{
+		BusinessID: req.BusinessID,
+		Rule:       req.Rule,
+		Status:     req.Status,
+		From:       req.From,
+		To:         req.To,
+		Limit:      scanPageSize(req.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]ScanAlertResponse, len(alerts))
+	for i := range alerts {
+		responses[i] = toScanAlertResponse(&alerts[i])
+	}
+
+	return responses, nil
+}
+
+// AcknowledgeAlert marks one of a business's alerts as reviewed.
+// acknowledgedBy is the staff member, or the business account itself.
+func (u *ScanAuditUsecase) AcknowledgeAlert(ctx context.Context, alertID, businessID, acknowledgedBy string) (*ScanAlertResponse, error) {
+	alert, err := u.alertRepo.FindByID(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+
+	if alert.BusinessID != businessID {
+		return nil, apperror.NewForbidden("scan alert does not belong to this business")
+	}
+
+	now := domain.NowTimestamp()
+	if err := u.alertRepo.Acknowledge(ctx, alert.ID, acknowledgedBy, now); err != nil {
+		return nil, err
+	}
+
+	alert.AcknowledgedAt = now
+	alert.AcknowledgedBy = acknowledgedBy
+
+	response := toScanAlertResponse(alert)
+	return &response, nil
+}
+
+// record stores a scan attempt and raises the alerts its rules call for.
+// ticket is the matched ticket, or nil. Call it inside the unit of work of
+// an accepted scan so the attempt is written with it.
+func (u *ScanAuditUsecase) record(ctx context.Context, attempt *domain.ScanAttempt, ticket *domain.Ticket) error {
+	return u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.attemptRepo.Create(ctx, attempt); err != nil {
+			return err
+		}
+
+		rule, details, err := u.evaluate(ctx, attempt, ticket)
+		if err != nil || rule == "" {
+			return err
+		}
+
+		return u.alertRepo.Create(ctx, &domain.ScanAlert{
+			ID:         uuid.New().String(),
+			BusinessID: attempt.BusinessID,
+			Rule:       rule,
+			AttemptID:  attempt.ID,
+			TicketID:   attempt.TicketID,
+			DeviceID:   attempt.DeviceID,
+			StaffID:    attempt.StaffID,
+			Details:    details,
+			CreatedAt:  domain.NowTimestamp(),
+		})
+	})
+}
+
+// evaluate runs the alert rules against a stored attempt and returns the
+// rule it breaks, or ""
+func (u *ScanAuditUsecase) evaluate(ctx context.Context, attempt *domain.ScanAttempt, ticket *domain.Ticket) (string, map[string]interface{}, error) {
+	switch attempt.Outcome {
+	case domain.ScanOutcomeAlreadyReleased:
+		details := map[string]interface{}{"released_at": ticket.ReleasedAt}
+		if ticket.ReleasedBy != "" {
+			details["released_by"] = ticket.ReleasedBy
+		}
+		return domain.ScanAlertReleasedRescanned, details, nil
+
+	case domain.ScanOutcomeRevoked:
+		return domain.ScanAlertRevokedCode, map[string]interface{}{
+			"qr_digest": attempt.QRDigest,
+		}, nil
+
+	case domain.ScanOutcomeBadSignature:
+		if u.policy.BadSignatureThreshold == 0 {
+			return "", nil, nil
+		}
+
+		window := int64(u.policy.BadSignatureWindow / time.Second)
+		count, err := u.attemptRepo.CountByScanner(ctx, attempt.BusinessID, attempt.DeviceID, attempt.StaffID,
+			domain.ScanOutcomeBadSignature, attempt.AttemptedAt-window)
+		if err != nil {
+			return "", nil, err
+		}
+
+		// Alert once when the threshold is reached, not on every attempt past it
+		if count != u.policy.BadSignatureThreshold {
+			return "", nil, nil
+		}
+		return domain.ScanAlertBadSignatureBurst, map[string]interface{}{
+			"count":          count,
+			"window_seconds": window,
+		}, nil
+
+	case domain.ScanOutcomeValid:
+		if u.policy.DuplicateWindow == 0 {
+			return "", nil, nil
+		}
+
+		window := int64(u.policy.DuplicateWindow / time.Second)
+		// The latest valid scan of the ticket on a different scanner
+		other, err := u.attemptRepo.FindLatestByOtherScanner(ctx, attempt.BusinessID, attempt.TicketID,
+			attempt.DeviceID, attempt.StaffID, domain.ScanOutcomeValid, attempt.AttemptedAt-window)
+		if err != nil {
+			if apperror.IsNotFound(err) {
+				return "", nil, nil
+			}
+			return "", nil, err
+		}
+		return domain.ScanAlertDuplicateScan, map[string]interface{}{
+			"previous_attempt_id": other.ID,
+			"previous_device_id":  other.DeviceID,
+			"previous_staff_id":   other.StaffID,
+			"seconds_apart":       attempt.AttemptedAt - other.AttemptedAt,
+		}, nil
+	}
+
+	return "", nil, nil
+}
+
+// scanPageSize applies the default and maximum page size to a requested limit
+func scanPageSize(limit int) int {
+	if limit == 0 {
+		return defaultScanPageSize
+	}
+	if limit > maxScanPageSize {
+		return maxScanPageSize
+	}
+	return limit
+}
+
+func toScanAttemptResponse(attempt *domain.ScanAttempt) ScanAttemptResponse {
+	return ScanAttemptResponse{
+		ID:          attempt.ID,
+		Seq:         attempt.Seq,
+		ServiceID:   attempt.ServiceID,
+		TicketID:    attempt.TicketID,
+		Outcome:     attempt.Outcome,
+		QRDigest:    attempt.QRDigest,
+		DeviceID:    attempt.DeviceID,
+		StaffID:     attempt.StaffID,
+		AttemptedAt: attempt.AttemptedAt,
+	}
+}
+
+func toScanAlertResponse(alert *domain.ScanAlert) ScanAlertResponse {
+	return ScanAlertResponse{
+		ID:             alert.ID,
+		Rule:           alert.Rule,
+		Status:         alert.Status(),
+		AttemptID:      alert.AttemptID,
+		TicketID:       alert.TicketID,
+		DeviceID:       alert.DeviceID,
+		StaffID:        alert.StaffID,
+		Details:        alert.Details,
+		CreatedAt:      alert.CreatedAt,
+		AcknowledgedAt: alert.AcknowledgedAt,
+		AcknowledgedBy: alert.AcknowledgedBy,
+	}
+}
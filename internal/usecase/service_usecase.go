@@ -14,6 +14,12 @@ type ServiceUsecase struct {
 	serviceRepo  domain.ServiceRepository
 	slotRepo     domain.SlotRepository
 	businessRepo domain.BusinessRepository
+	ticketRepo   domain.TicketRepository
+	zoneRepo     domain.ZoneRepository
+	waitlist     *WaitlistUsecase
+	holds        *HoldUsecase
+	events       *TicketEventUsecase
+	txManager    domain.TxManager
 }
 
 // NewServiceUsecase creates a new service usecase
@@ -21,44 +27,103 @@ func NewServiceUsecase(
 	serviceRepo domain.ServiceRepository,
 	slotRepo domain.SlotRepository,
 	businessRepo domain.BusinessRepository,
+	ticketRepo domain.TicketRepository,
+	zoneRepo domain.ZoneRepository,
+	waitlist *WaitlistUsecase,
+	holds *HoldUsecase,
+	events *TicketEventUsecase,
+	txManager domain.TxManager,
 ) *ServiceUsecase {
 	return &ServiceUsecase{
 		serviceRepo:  serviceRepo,
 		slotRepo:     slotRepo,
 		businessRepo: businessRepo,
+		ticketRepo:   ticketRepo,
+		zoneRepo:     zoneRepo,
+		waitlist:     waitlist,
+		holds:        holds,
+		events:       events,
+		txManager:    txManager,
 	}
 }
 
 // Request/Response types
 type CreateServiceRequest struct {
-	Name       string `json:"name"`
-	TotalSlots int    `json:"total_slots"`
-	BusinessID string `json:"-"`
+	Name             string              `json:"name"`
+	TotalSlots       int                 `json:"total_slots"`        // Derived from zones when they are given
+	TicketTTLSeconds int64               `json:"ticket_ttl_seconds"` // 0 = tickets never expire
+	ClosesAt         int64               `json:"closes_at"`          // Unix timestamp, 0 = no closing time
+	Zones            []CreateZoneRequest `json:"zones"`
+	BusinessID       string              `json:"-"`
+}
+
+// UpdateServiceRequest changes only the fields that are present
+type UpdateServiceRequest struct {
+	Name             *string `json:"name"`
+	TotalSlots       *int    `json:"total_slots"`
+	TicketTTLSeconds *int64  `json:"ticket_ttl_seconds"`
+	ClosesAt         *int64  `json:"closes_at"`
+	ServiceID        string  `json:"-"`
+	BusinessID       string  `json:"-"`
 }
 
 type ServiceResponse struct {
-	ID         string `json:"id"`
-	BusinessID string `json:"business_id"`
-	Name       string `json:"name"`
-	TotalSlots int    `json:"total_slots"`
-	CreatedAt  int64  `json:"created_at"`
-	UpdatedAt  int64  `json:"updated_at"`
+	ID               string `json:"id"`
+	BusinessID       string `json:"business_id"`
+	Name             string `json:"name"`
+	TotalSlots       int    `json:"total_slots"`
+	TicketTTLSeconds int64  `json:"ticket_ttl_seconds"`
+	ClosesAt         int64  `json:"closes_at"`
+	ArchivedAt       int64  `json:"archived_at"` // 0 = not archived
+	CreatedAt        int64  `json:"created_at"`
+	UpdatedAt        int64  `json:"updated_at"`
 }
 
 type ServiceStatsResponse struct {
-	ServiceID  string `json:"service_id"`
-	Name       string `json:"name"`
-	TotalSlots int    `json:"total_slots"`
-	Occupied   int    `json:"occupied"`
-	Free       int    `json:"free"`
+	ServiceID  string      `json:"service_id"`
+	Name       string      `json:"name"`
+	TotalSlots int         `json:"total_slots"`
+	Occupied   int         `json:"occupied"`
+	Held       int         `json:"held"`     // Held for a customer awaiting confirmation
+	Reserved   int         `json:"reserved"` // Offered to the head of the waitlist
+	Free       int         `json:"free"`
+	Zones      []ZoneStats `json:"zones,omitempty"`
+}
+
+type ZoneStats struct {
+	ZoneID   string `json:"zone_id"`
+	Name     string `json:"name"`
+	Capacity int    `json:"capacity"`
+	Occupied int    `json:"occupied"`
+	Held     int    `json:"held"`
+	Reserved int    `json:"reserved"`
+	Free     int    `json:"free"`
 }
 
 // CreateService creates a new service and generates slots
 func (u *ServiceUsecase) CreateService(ctx context.Context, req CreateServiceRequest) (*ServiceResponse, error) {
+	if len(req.Zones) > 0 {
+		capacity := 0
+		for _, zone := range req.Zones {
+			if zone.Name == "" || zone.Capacity <= 0 {
+				return nil, apperror.NewValidationError("every zone needs a name and a positive capacity", map[string]string{})
+			}
+			capacity += zone.Capacity
+		}
+		if req.TotalSlots != 0 && req.TotalSlots != capacity {
+			return nil, apperror.NewValidationError("total_slots must equal the sum of zone capacities", map[string]string{})
+		}
+		req.TotalSlots = capacity
+	}
+
 	if req.Name == "" || req.TotalSlots <= 0 {
 		return nil, apperror.NewValidationError("name and totalSlots are required", map[string]string{})
 	}
 
+	if req.TicketTTLSeconds < 0 || req.ClosesAt < 0 {
+		return nil, apperror.NewValidationError("ticket_ttl_seconds and closes_at cannot be negative", map[string]string{})
+	}
+
 	// Verify business exists
 	_, err := u.businessRepo.FindByID(ctx, req.BusinessID)
 	if err != nil {
@@ -73,39 +138,139 @@ func (u *ServiceUsecase) CreateService(ctx context.Context, req CreateServiceReq
 		BusinessID: req.BusinessID,
 		Name:       req.Name,
 		TotalSlots: req.TotalSlots,
+		TicketTTL:  req.TicketTTLSeconds,
+		ClosesAt:   req.ClosesAt,
 		CreatedAt:  now,
 		UpdatedAt:  now,
 	}
 
-	if err := u.serviceRepo.Create(ctx, service); err != nil {
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.serviceRepo.Create(ctx, service); err != nil {
+			return err
+		}
+
+		if len(req.Zones) == 0 {
+			// Generate slots
+			return u.slotRepo.CreateBatch(ctx, newSlots(service.ID, "", 1, req.TotalSlots, now))
+		}
+
+		// Generate each zone's slots, numbered on from the previous zone
+		next := 1
+		for _, zoneReq := range req.Zones {
+			zone := newZone(service.ID, zoneReq.Name, zoneReq.Capacity, now)
+			if err := u.zoneRepo.Create(ctx, zone); err != nil {
+				return err
+			}
+			if err := u.slotRepo.CreateBatch(ctx, newSlots(service.ID, zone.ID, next, next+zone.Capacity-1, now)); err != nil {
+				return err
+			}
+			next += zone.Capacity
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// Generate slots
-	slots := make([]domain.Slot, req.TotalSlots)
-	for i := 1; i <= req.TotalSlots; i++ {
-		slots[i-1] = domain.Slot{
-			ID:         uuid.New().String(),
-			ServiceID:  service.ID,
-			SlotNumber: i,
-			Status:     domain.SlotStatusFree,
-			CreatedAt:  now,
-			UpdatedAt:  now,
-		}
+	return toServiceResponse(service), nil
+}
+
+// UpdateService renames a service, changes its expiry settings and resizes
+// it. Growing appends free slots; shrinking removes trailing slots and fails
+// with a conflict if any of them is in use. The service row is locked for the
+// duration so concurrent updates and check-ins see either the old or the new
+// slot set, never a mix.
+func (u *ServiceUsecase) UpdateService(ctx context.Context, req UpdateServiceRequest) (*ServiceResponse, error) {
+	if req.Name != nil && *req.Name == "" {
+		return nil, apperror.NewValidationError("name cannot be empty", map[string]string{})
+	}
+
+	if req.TotalSlots != nil && *req.TotalSlots <= 0 {
+		return nil, apperror.NewValidationError("total_slots must be positive", map[string]string{})
+	}
+
+	if (req.TicketTTLSeconds != nil && *req.TicketTTLSeconds < 0) || (req.ClosesAt != nil && *req.ClosesAt < 0) {
+		return nil, apperror.NewValidationError("ticket_ttl_seconds and closes_at cannot be negative", map[string]string{})
 	}
 
-	if err := u.slotRepo.CreateBatch(ctx, slots); err != nil {
+	var service *domain.Service
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		service, err = u.serviceRepo.LockByID(ctx, req.ServiceID)
+		if err != nil {
+			return err
+		}
+
+		if service.BusinessID != req.BusinessID {
+			return apperror.NewForbidden("service does not belong to this business")
+		}
+
+		now := domain.NowTimestamp()
+
+		if req.TotalSlots != nil && *req.TotalSlots != service.TotalSlots {
+			zones, err := u.zoneRepo.ListByServiceID(ctx, service.ID)
+			if err != nil {
+				return err
+			}
+			if len(zones) > 0 {
+				return apperror.NewConflict("service has zones; resize its zones instead")
+			}
+
+			if err := u.resizeSlots(ctx, service, *req.TotalSlots, now); err != nil {
+				return err
+			}
+			service.TotalSlots = *req.TotalSlots
+		}
+
+		if req.Name != nil {
+			service.Name = *req.Name
+		}
+		if req.TicketTTLSeconds != nil {
+			service.TicketTTL = *req.TicketTTLSeconds
+		}
+		if req.ClosesAt != nil {
+			service.ClosesAt = *req.ClosesAt
+		}
+		service.UpdatedAt = now
+
+		return u.serviceRepo.Update(ctx, service)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &ServiceResponse{
-		ID:         service.ID,
-		BusinessID: service.BusinessID,
-		Name:       service.Name,
-		TotalSlots: service.TotalSlots,
-		CreatedAt:  service.CreatedAt,
-		UpdatedAt:  service.UpdatedAt,
-	}, nil
+	return toServiceResponse(service), nil
+}
+
+// resizeSlots grows or shrinks the slot set of a locked service to total
+func (u *ServiceUsecase) resizeSlots(ctx context.Context, service *domain.Service, total int, now int64) error {
+	highest, err := highestSlotNumber(ctx, u.slotRepo, service.ID)
+	if err != nil {
+		return err
+	}
+
+	if total < highest {
+		return u.slotRepo.DeleteTrailing(ctx, service.ID, total)
+	}
+
+	return u.slotRepo.CreateBatch(ctx, newSlots(service.ID, "", highest+1, total, now))
+}
+
+// highestSlotNumber returns the largest slot number in use by a service, or 0
+func highestSlotNumber(ctx context.Context, slotRepo domain.SlotRepository, serviceID string) (int, error) {
+	slots, err := slotRepo.ListByServiceID(ctx, serviceID)
+	if err != nil {
+		return 0, err
+	}
+
+	highest := 0
+	for _, slot := range slots {
+		if slot.SlotNumber > highest {
+			highest = slot.SlotNumber
+		}
+	}
+
+	return highest, nil
 }
 
 // GetService retrieves a service by ID
@@ -119,38 +284,159 @@ func (u *ServiceUsecase) GetService(ctx context.Context, serviceID, businessID s
 		return nil, apperror.NewForbidden("service does not belong to this business")
 	}
 
-	return &ServiceResponse{
-		ID:         service.ID,
-		BusinessID: service.BusinessID,
-		Name:       service.Name,
-		TotalSlots: service.TotalSlots,
-		CreatedAt:  service.CreatedAt,
-		UpdatedAt:  service.UpdatedAt,
-	}, nil
+	return toServiceResponse(service), nil
 }
 
-// ListServices lists all services for a business
-func (u *ServiceUsecase) ListServices(ctx context.Context, businessID string) ([]ServiceResponse, error) {
+// ListServices lists the services of a business; archived services are
+// left out unless includeArchived is set
+func (u *ServiceUsecase) ListServices(ctx context.Context, businessID string, includeArchived bool) ([]ServiceResponse, error) {
 	services, err := u.serviceRepo.ListByBusinessID(ctx, businessID)
 	if err != nil {
 		return nil, err
 	}
 
-	responses := make([]ServiceResponse, len(services))
-	for i, service := range services {
-		responses[i] = ServiceResponse{
-			ID:         service.ID,
-			BusinessID: service.BusinessID,
-			Name:       service.Name,
-			TotalSlots: service.TotalSlots,
-			CreatedAt:  service.CreatedAt,
-			UpdatedAt:  service.UpdatedAt,
+	responses := make([]ServiceResponse, 0, len(services))
+	for _, service := range services {
+		if service.IsArchived() && !includeArchived {
+			continue
 		}
+		responses = append(responses, *toServiceResponse(&service))
 	}
 
 	return responses, nil
 }
 
+// ArchiveService closes a service to new check-ins and hides it from
+// listings. Existing tickets stay valid and can still be scanned and
+// released; the waitlist is cancelled, since nobody can check in from it.
+func (u *ServiceUsecase) ArchiveService(ctx context.Context, serviceID, businessID string) (*ServiceResponse, error) {
+	return u.setArchived(ctx, serviceID, businessID, true)
+}
+
+// UnarchiveService reopens an archived service
+func (u *ServiceUsecase) UnarchiveService(ctx context.Context, serviceID, businessID string) (*ServiceResponse, error) {
+	return u.setArchived(ctx, serviceID, businessID, false)
+}
+
+func (u *ServiceUsecase) setArchived(ctx context.Context, serviceID, businessID string, archived bool) (*ServiceResponse, error) {
+	var service *domain.Service
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		service, err = u.serviceRepo.LockByID(ctx, serviceID)
+		if err != nil {
+			return err
+		}
+
+		if service.BusinessID != businessID {
+			return apperror.NewForbidden("service does not belong to this business")
+		}
+
+		if service.IsArchived() == archived {
+			if archived {
+				return apperror.NewConflict("service is already archived")
+			}
+			return apperror.NewConflict("service is not archived")
+		}
+
+		now := domain.NowTimestamp()
+		service.ArchivedAt = 0
+		if archived {
+			service.ArchivedAt = now
+			if err := u.waitlist.cancelService(ctx, serviceID); err != nil {
+				return err
+			}
+		}
+		service.UpdatedAt = now
+
+		return u.serviceRepo.Update(ctx, service)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toServiceResponse(service), nil
+}
+
+// DeleteService removes a service with its slots. It refuses while tickets
+// are active unless force is set, in which case those tickets are released
+// and voided first. Active slot holds are cancelled. A service that ever issued tickets is archived instead
+// of deleted, so its tickets stay for reporting; the archived service is
+// returned. Otherwise the response is nil.
+func (u *ServiceUsecase) DeleteService(ctx context.Context, serviceID, businessID, staffID string, force bool) (*ServiceResponse, error) {
+	var archived *domain.Service
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		service, err := u.serviceRepo.LockByID(ctx, serviceID)
+		if err != nil {
+			return err
+		}
+
+		if service.BusinessID != businessID {
+			return apperror.NewForbidden("service does not belong to this business")
+		}
+
+		tickets, err := u.ticketRepo.ListActiveByServiceID(ctx, serviceID)
+		if err != nil {
+			return err
+		}
+
+		if len(tickets) > 0 && !force {
+			appErr := apperror.NewConflict("service has active tickets")
+			appErr.Details["active_tickets"] = len(tickets)
+			return appErr
+		}
+
+		actor := businessActor(businessID, staffID, nil)
+		for _, ticket := range tickets {
+			if err := u.ticketRepo.MarkReleased(ctx, ticket.ID, staffID); err != nil {
+				return err
+			}
+			if err := u.events.record(ctx, &ticket, businessID, domain.TicketEventVoided, actor, map[string]interface{}{
+				"reason": "service_deleted",
+			}); err != nil {
+				return err
+			}
+			if ticket.SlotID != "" {
+				if err := u.slotRepo.UpdateStatus(ctx, ticket.SlotID, domain.SlotStatusFree); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := u.holds.cancelService(ctx, serviceID); err != nil {
+			return err
+		}
+
+		issued, err := u.ticketRepo.CountByServiceID(ctx, serviceID)
+		if err != nil {
+			return err
+		}
+
+		if issued == 0 {
+			return u.serviceRepo.Delete(ctx, serviceID)
+		}
+
+		// Deleting would cascade to the tickets
+		if !service.IsArchived() {
+			if err := u.waitlist.cancelService(ctx, serviceID); err != nil {
+				return err
+			}
+			now := domain.NowTimestamp()
+			service.ArchivedAt = now
+			service.UpdatedAt = now
+			if err := u.serviceRepo.Update(ctx, service); err != nil {
+				return err
+			}
+		}
+		archived = service
+		return nil
+	})
+	if err != nil || archived == nil {
+		return nil, err
+	}
+
+	return toServiceResponse(archived), nil
+}
+
 // GetServiceStats returns occupancy statistics for a service
 func (u *ServiceUsecase) GetServiceStats(ctx context.Context, serviceID, businessID string) (*ServiceStatsResponse, error) {
 	service, err := u.serviceRepo.FindByID(ctx, serviceID)
@@ -162,16 +448,79 @@ func (u *ServiceUsecase) GetServiceStats(ctx context.Context, serviceID, busines
 		return nil, apperror.NewForbidden("service does not belong to this business")
 	}
 
-	total, occupied, err := u.slotRepo.CountSlotsByStatus(ctx, serviceID)
+	counts, err := u.slotRepo.CountSlotsByStatus(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	zones, err := u.zoneRepo.ListByServiceID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	zoneCounts, err := u.slotRepo.CountSlotsByZone(ctx, serviceID)
 	if err != nil {
 		return nil, err
 	}
 
+	byZone := make(map[string]domain.SlotCounts, len(zoneCounts))
+	for _, count := range zoneCounts {
+		byZone[count.ZoneID] = count.Counts
+	}
+
+	zoneStats := make([]ZoneStats, len(zones))
+	for i, zone := range zones {
+		count := byZone[zone.ID]
+		zoneStats[i] = ZoneStats{
+			ZoneID:   zone.ID,
+			Name:     zone.Name,
+			Capacity: zone.Capacity,
+			Occupied: count[domain.SlotStatusOccupied],
+			Held:     count[domain.SlotStatusHeld],
+			Reserved: count[domain.SlotStatusReserved],
+			Free:     count[domain.SlotStatusFree],
+		}
+	}
+
 	return &ServiceStatsResponse{
 		ServiceID:  service.ID,
 		Name:       service.Name,
 		TotalSlots: service.TotalSlots,
-		Occupied:   occupied,
-		Free:       total - occupied,
+		Occupied:   counts[domain.SlotStatusOccupied],
+		Held:       counts[domain.SlotStatusHeld],
+		Reserved:   counts[domain.SlotStatusReserved],
+		Free:       counts[domain.SlotStatusFree],
+		Zones:      zoneStats,
 	}, nil
 }
+
+// newSlots builds free slots numbered from first to last inclusive
+func newSlots(serviceID, zoneID string, first, last int, now int64) []domain.Slot {
+	slots := make([]domain.Slot, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		slots = append(slots, domain.Slot{
+			ID:         uuid.New().String(),
+			ServiceID:  serviceID,
+			ZoneID:     zoneID,
+			SlotNumber: i,
+			Status:     domain.SlotStatusFree,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+	}
+	return slots
+}
+
+func toServiceResponse(service *domain.Service) *ServiceResponse {
+	return &ServiceResponse{
+		ID:               service.ID,
+		BusinessID:       service.BusinessID,
+		Name:             service.Name,
+		TotalSlots:       service.TotalSlots,
+		TicketTTLSeconds: service.TicketTTL,
+		ClosesAt:         service.ClosesAt,
+		ArchivedAt:       service.ArchivedAt,
+		CreatedAt:        service.CreatedAt,
+		UpdatedAt:        service.UpdatedAt,
+	}
+}
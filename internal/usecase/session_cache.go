@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+)
+
+// maxCachedSessions is the cache size at which expired entries are swept
+const maxCachedSessions = 10000
+
+// sessionCache remembers whether sessions are revoked so the auth check does
+// not hit the database on every request. Live sessions are looked up again
+// after liveTTL. Revocations are final and are kept for revokedTTL, the
+// access token lifetime, after which no token of the session is valid.
+type sessionCache struct {
+	mu         sync.Mutex
+	entries    map[string]sessionCacheEntry
+	liveTTL    time.Duration
+	revokedTTL time.Duration
+}
+
+type sessionCacheEntry struct {
+	revoked   bool
+	expiresAt time.Time
+}
+
+func newSessionCache(liveTTL, revokedTTL time.Duration) *sessionCache {
+	return &sessionCache{
+		entries:    make(map[string]sessionCacheEntry),
+		liveTTL:    liveTTL,
+		revokedTTL: revokedTTL,
+	}
+}
+
+// get returns whether a session is revoked, and false if it is not cached
+func (c *sessionCache) get(sessionID string) (bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[sessionID]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return false, false
+	}
+
+	return entry.revoked, true
+}
+
+// set caches whether a session is revoked
+func (c *sessionCache) set(sessionID string, revoked bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= maxCachedSessions {
+		for id, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+	}
+
+	ttl := c.liveTTL
+	if revoked {
+		ttl = c.revokedTTL
+	}
+	c.entries[sessionID] = sessionCacheEntry{revoked: revoked, expiresAt: now.Add(ttl)}
+}
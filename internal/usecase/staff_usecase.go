@@ -0,0 +1,387 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// StaffUsecase manages the staff accounts of a business: invites, logins,
+// roles and disabling access
+type StaffUsecase struct {
+	staffRepo domain.StaffRepository
+	auth      *AuthUsecase
+	inviteTTL time.Duration
+}
+
+// NewStaffUsecase creates a new staff usecase. Invites that are not
+// accepted within inviteTTL lapse.
+func NewStaffUsecase(
+	staffRepo domain.StaffRepository,
+	auth *AuthUsecase,
+	inviteTTL time.Duration,
+) *StaffUsecase {
+	return &StaffUsecase{
+		staffRepo: staffRepo,
+		auth:      auth,
+		inviteTTL: inviteTTL,
+	}
+}
+
+// Request/Response types
+type InviteStaffRequest struct {
+	Email      string `json:"email"`
+	Name       string `json:"name,omitempty"`
+	Role       string `json:"role"` // owner, manager or attendant
+	BusinessID string `json:"-"`
+}
+
+type AcceptInviteRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+	Name     string `json:"name,omitempty"`
+}
+
+type StaffLoginRequest struct {
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	BusinessID string `json:"business_id,omitempty"` // Needed when the email works for several businesses
+}
+
+type UpdateStaffRequest struct {
+	Role       string `json:"role"`
+	StaffID    string `json:"-"`
+	BusinessID string `json:"-"`
+	ActorID    string `json:"-"` // Staff member making the change, "" for the business account
+}
+
+type StaffResponse struct {
+	ID         string `json:"id"`
+	BusinessID string `json:"business_id"`
+	Email      string `json:"email"`
+	Name       string `json:"name,omitempty"`
+	Role       string `json:"role"`
+	Status     string `json:"status"`
+	CreatedAt  int64  `json:"created_at"`
+	UpdatedAt  int64  `json:"updated_at"`
+}
+
+// StaffInviteResponse carries the invite token to hand to the new staff
+// member. It is shown once; only its hash is stored.
+type StaffInviteResponse struct {
+	StaffResponse
+	InviteToken     string `json:"invite_token"`
+	InviteExpiresAt int64  `json:"invite_expires_at"`
+}
+
+// InviteStaff invites a person by email to work for the business. Inviting
+// an email whose invite is still pending sends a fresh token.
+func (u *StaffUsecase) InviteStaff(ctx context.Context, req InviteStaffRequest) (*StaffInviteResponse, error) {
+	req.Email = normalizeEmail(req.Email)
+	if req.Email == "" {
+		return nil, apperror.NewValidationError("email is required", map[string]string{})
+	}
+
+	if !isStaffRole(req.Role) {
+		return nil, apperror.NewValidationError("role must be owner, manager or attendant", map[string]string{})
+	}
+
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := domain.NowTimestamp()
+	expiresAt := now + int64(u.inviteTTL/time.Second)
+
+	staff, err := u.staffRepo.FindByEmail(ctx, req.BusinessID, req.Email)
+	switch {
+	case err == nil:
+		if staff.Status != domain.StaffStatusInvited {
+			return nil, apperror.NewConflict("staff member already exists")
+		}
+		if req.Name != "" {
+			staff.Name = req.Name
+		}
+		staff.Role = req.Role
+		staff.InviteTokenHash = tokenHash
+		staff.InviteExpiresAt = expiresAt
+		staff.UpdatedAt = now
+		err = u.staffRepo.Update(ctx, staff)
+	case apperror.IsNotFound(err):
+		staff = &domain.Staff{
+			ID:              uuid.New().String(),
+			BusinessID:      req.BusinessID,
+			Email:           req.Email,
+			Name:            req.Name,
+			Role:            req.Role,
+			Status:          domain.StaffStatusInvited,
+			InviteTokenHash: tokenHash,
+			InviteExpiresAt: expiresAt,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+		err = u.staffRepo.Create(ctx, staff)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &StaffInviteResponse{
+		StaffResponse:   toStaffResponse(staff),
+		InviteToken:     token,
+		InviteExpiresAt: expiresAt,
+	}, nil
+}
+
+// AcceptInvite sets the invited staff member's password and logs them in.
+// The invite token cannot be used again.
+func (u *StaffUsecase) AcceptInvite(ctx context.Context, req AcceptInviteRequest) (*AuthResponse, error) {
+	if req.Token == "" || req.Password == "" {
+		return nil, apperror.NewValidationError("token and password are required", map[string]string{})
+	}
+
+	tokenHash := hashOpaqueToken(req.Token)
+	staff, err := u.staffRepo.FindByInviteToken(ctx, tokenHash)
+	if apperror.IsNotFound(err) {
+		return nil, apperror.NewUnauthorized("invalid or expired invite")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := domain.NowTimestamp()
+	if staff.Status != domain.StaffStatusInvited || staff.InviteExpiresAt <= now {
+		return nil, apperror.NewUnauthorized("invalid or expired invite")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, apperror.NewInternalServer("password hashing failed", err)
+	}
+
+	if req.Name != "" {
+		staff.Name = req.Name
+	}
+	staff.Password = string(hashedPassword)
+	staff.Status = domain.StaffStatusActive
+	staff.InviteTokenHash = ""
+	staff.InviteExpiresAt = 0
+	staff.UpdatedAt = now
+
+	// Only one of several concurrent accepts of an invite gets through
+	if err := u.staffRepo.AcceptInvite(ctx, staff, tokenHash); err != nil {
+		if apperror.IsConflict(err) {
+			return nil, apperror.NewUnauthorized("invalid or expired invite")
+		}
+		return nil, err
+	}
+
+	return u.staffAuthResponse(ctx, staff)
+}
+
+// StaffLogin logs a staff member in with their own email and password
+func (u *StaffUsecase) StaffLogin(ctx context.Context, req StaffLoginRequest) (*AuthResponse, error) {
+	email := normalizeEmail(req.Email)
+	if email == "" || req.Password == "" {
+		return nil, apperror.NewValidationError("email and password are required", map[string]string{})
+	}
+
+	lockoutKey := "staff:" + email
+	if err := u.auth.limiter.CheckLockout(ctx, lockoutKey); err != nil {
+		return nil, err
+	}
+
+	accounts, err := u.staffRepo.ListByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []domain.Staff
+	for _, staff := range accounts {
+		if staff.Status != domain.StaffStatusActive {
+			continue
+		}
+		if req.BusinessID != "" && staff.BusinessID != req.BusinessID {
+			continue
+		}
+		if bcrypt.CompareHashAndPassword([]byte(staff.Password), []byte(req.Password)) == nil {
+			matched = append(matched, staff)
+		}
+	}
+
+	if len(matched) == 0 {
+		return nil, u.auth.failLogin(ctx, lockoutKey)
+	}
+	if err := u.auth.limiter.RecordLoginSuccess(ctx, lockoutKey); err != nil {
+		return nil, err
+	}
+
+	switch len(matched) {
+	case 1:
+		return u.staffAuthResponse(ctx, &matched[0])
+	default:
+		return nil, apperror.NewValidationError("business_id is required for staff of several businesses", map[string]string{})
+	}
+}
+
+// ListStaff lists the staff of a business
+func (u *StaffUsecase) ListStaff(ctx context.Context, businessID string) ([]StaffResponse, error) {
+	staff, err := u.staffRepo.ListByBusinessID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]StaffResponse, len(staff))
+	for i, s := range staff {
+		responses[i] = toStaffResponse(&s)
+	}
+
+	return responses, nil
+}
+
+// UpdateStaff changes a staff member's role. Staff cannot change their own
+// role.
+func (u *StaffUsecase) UpdateStaff(ctx context.Context, req UpdateStaffRequest) (*StaffResponse, error) {
+	if !isStaffRole(req.Role) {
+		return nil, apperror.NewValidationError("role must be owner, manager or attendant", map[string]string{})
+	}
+
+	staff, err := u.ownedStaff(ctx, req.StaffID, req.BusinessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if staff.ID == req.ActorID {
+		return nil, apperror.NewForbidden("staff cannot change their own role")
+	}
+
+	staff.Role = req.Role
+	staff.UpdatedAt = domain.NowTimestamp()
+
+	if err := u.staffRepo.Update(ctx, staff); err != nil {
+		return nil, err
+	}
+
+	response := toStaffResponse(staff)
+	return &response, nil
+}
+
+// DisableStaff takes away a staff member's access. Their tokens stop
+// working on the next request.
+func (u *StaffUsecase) DisableStaff(ctx context.Context, staffID, businessID, actorID string) error {
+	staff, err := u.ownedStaff(ctx, staffID, businessID)
+	if err != nil {
+		return err
+	}
+
+	if staff.ID == actorID {
+		return apperror.NewForbidden("staff cannot disable themselves")
+	}
+
+	if staff.Status == domain.StaffStatusDisabled {
+		return nil
+	}
+
+	staff.Status = domain.StaffStatusDisabled
+	staff.InviteTokenHash = ""
+	staff.InviteExpiresAt = 0
+	staff.UpdatedAt = domain.NowTimestamp()
+
+	if err := u.staffRepo.Update(ctx, staff); err != nil {
+		return err
+	}
+
+	// Their refresh tokens must not mint new access tokens either
+	return u.auth.revokeSubject(ctx, staff.ID)
+}
+
+// ActiveStaffRole returns the current role of an active staff member of the
+// business, so disabling staff or changing their role takes effect before
+// their token expires
+func (u *StaffUsecase) ActiveStaffRole(ctx context.Context, staffID, businessID string) (string, error) {
+	staff, err := u.staffRepo.FindByID(ctx, staffID)
+	if apperror.IsNotFound(err) {
+		return "", apperror.NewUnauthorized("staff account not found")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if staff.BusinessID != businessID || staff.Status != domain.StaffStatusActive {
+		return "", apperror.NewUnauthorized("staff account is disabled")
+	}
+
+	return staff.Role, nil
+}
+
+func (u *StaffUsecase) staffAuthResponse(ctx context.Context, staff *domain.Staff) (*AuthResponse, error) {
+	return u.auth.login(ctx, newStaffClaims(staff), staff.ID)
+}
+
+// ownedStaff loads a staff member of the business
+func (u *StaffUsecase) ownedStaff(ctx context.Context, staffID, businessID string) (*domain.Staff, error) {
+	staff, err := u.staffRepo.FindByID(ctx, staffID)
+	if err != nil {
+		return nil, err
+	}
+
+	if staff.BusinessID != businessID {
+		return nil, apperror.NewNotFound("staff member")
+	}
+
+	return staff, nil
+}
+
+func isStaffRole(role string) bool {
+	switch role {
+	case domain.StaffRoleOwner, domain.StaffRoleManager, domain.StaffRoleAttendant:
+		return true
+	}
+	return false
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// newOpaqueToken returns a random URL-safe token and the SHA-256 hash that
+// is stored in its place
+func newOpaqueToken() (string, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", apperror.NewInternalServer("token generation failed", err)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	return token, hashOpaqueToken(token), nil
+}
+
+// hashOpaqueToken hashes a token for lookup
+func hashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func toStaffResponse(staff *domain.Staff) StaffResponse {
+	return StaffResponse{
+		ID:         staff.ID,
+		BusinessID: staff.BusinessID,
+		Email:      staff.Email,
+		Name:       staff.Name,
+		Role:       staff.Role,
+		Status:     staff.Status,
+		CreatedAt:  staff.CreatedAt,
+		UpdatedAt:  staff.UpdatedAt,
+	}
+}
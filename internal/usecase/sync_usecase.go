@@ -0,0 +1,352 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+)
+
+const (
+	// maxSyncBatchSize caps the number of events a device may upload at once
+	maxSyncBatchSize = 500
+	// maxSyncEventIDLength is the width of the sync_events.id column
+	maxSyncEventIDLength = 36
+	// maxSyncTicketIDLength is the width of the sync_events.ticket_id column
+	maxSyncTicketIDLength = 36
+)
+
+// SyncUsecase serves offline scanner devices: it builds ticket manifests and
+// replays the scans and releases devices recorded while disconnected
+type SyncUsecase struct {
+	ticketUsecase *TicketUsecase
+	ticketRepo    domain.TicketRepository
+	serviceRepo   domain.ServiceRepository
+	syncRepo      domain.SyncEventRepository
+	txManager     domain.TxManager
+}
+
+// NewSyncUsecase creates a new sync usecase
+func NewSyncUsecase(
+	ticketUsecase *TicketUsecase,
+	ticketRepo domain.TicketRepository,
+	serviceRepo domain.ServiceRepository,
+	syncRepo domain.SyncEventRepository,
+	txManager domain.TxManager,
+) *SyncUsecase {
+	return &SyncUsecase{
+		ticketUsecase: ticketUsecase,
+		ticketRepo:    ticketRepo,
+		serviceRepo:   serviceRepo,
+		syncRepo:      syncRepo,
+		txManager:     txManager,
+	}
+}
+
+// Request/Response types
+type ManifestRequest struct {
+	ServiceIDs []string
+	BusinessID string
+	Device     *DeviceScope // Limits the manifest to the device's services
+}
+
+type ManifestResponse struct {
+	GeneratedAt int64             `json:"generated_at"`
+	Services    []ServiceManifest `json:"services"`
+}
+
+type ServiceManifest struct {
+	ServiceID string           `json:"service_id"`
+	Name      string           `json:"name"`
+	Tickets   []ManifestTicket `json:"tickets"`
+}
+
+type ManifestTicket struct {
+	TicketID   string `json:"ticket_id"`
+	SlotNumber int    `json:"slot_number"`
+	Digest     string `json:"digest"` // QR signature, lets devices match scanned codes offline
+	IssuedAt   int64  `json:"issued_at"`
+}
+
+type SyncUploadRequest struct {
+	DeviceID   string           `json:"device_id"` // Defaults to the registered device uploading
+	Events     []SyncEventInput `json:"events"`
+	BusinessID string           `json:"-"`
+	StaffID    string           `json:"-"` // Staff member signed in on the device
+	Device     *DeviceScope     `json:"-"` // Set when a registered device uploads with its own token
+}
+
+type SyncEventInput struct {
+	EventID    string `json:"event_id"`
+	Type       string `json:"type"`       // "scan" or "release"
+	TicketID   string `json:"ticket_id"`  // required for releases
+	QRPayload  string `json:"qr_payload"` // required for scans
+	OccurredAt int64  `json:"occurred_at"`
+}
+
+type SyncEventResult struct {
+	EventID      string `json:"event_id"`
+	Result       string `json:"result"` // "applied", "conflict", "rejected" or "duplicate"
+	Message      string `json:"message,omitempty"`
+	TicketID     string `json:"ticket_id,omitempty"`
+	TicketStatus string `json:"ticket_status,omitempty"`
+}
+
+type SyncUploadResponse struct {
+	Results []SyncEventResult `json:"results"`
+}
+
+// Manifest lists the active tickets of the requested services, or of every
+// service of the business (or of the device's scope) when none are given
+func (u *SyncUsecase) Manifest(ctx context.Context, req ManifestRequest) (*ManifestResponse, error) {
+	var services []domain.Service
+	if len(req.ServiceIDs) == 0 {
+		all, err := u.serviceRepo.ListByBusinessID(ctx, req.BusinessID)
+		if err != nil {
+			return nil, err
+		}
+		for _, service := range all {
+			if req.Device.AllowsService(service.ID) {
+				services = append(services, service)
+			}
+		}
+	} else {
+		for _, serviceID := range req.ServiceIDs {
+			service, err := u.serviceRepo.FindByID(ctx, serviceID)
+			if err != nil {
+				return nil, err
+			}
+			if service.BusinessID != req.BusinessID {
+				return nil, apperror.NewForbidden("service does not belong to this business")
+			}
+			if err := req.Device.checkService(service.ID); err != nil {
+				return nil, err
+			}
+			services = append(services, *service)
+		}
+	}
+
+	response := &ManifestResponse{
+		GeneratedAt: domain.NowTimestamp(),
+		Services:    make([]ServiceManifest, 0, len(services)),
+	}
+
+	for _, service := range services {
+		tickets, err := u.ticketRepo.ListActiveByServiceID(ctx, service.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		manifest := ServiceManifest{
+			ServiceID: service.ID,
+			Name:      service.Name,
+			Tickets:   make([]ManifestTicket, 0, len(tickets)),
+		}
+		for _, t := range tickets {
+			manifest.Tickets = append(manifest.Tickets, ManifestTicket{
+				TicketID:   t.ID,
+				SlotNumber: t.SlotNumber,
+				Digest:     t.HMACDigest,
+				IssuedAt:   t.IssuedAt,
+			})
+		}
+
+		response.Services = append(response.Services, manifest)
+	}
+
+	return response, nil
+}
+
+// Upload replays a batch of device events in the order they occurred and
+// returns one result per event. Events already replayed are reported as
+// duplicates, so devices can safely retry an upload.
+func (u *SyncUsecase) Upload(ctx context.Context, req SyncUploadRequest) (*SyncUploadResponse, error) {
+	if req.Device != nil {
+		if req.DeviceID == "" {
+			req.DeviceID = req.Device.DeviceID
+		}
+		if req.DeviceID != req.Device.DeviceID {
+			return nil, apperror.NewValidationError("device_id does not match the device token", map[string]string{})
+		}
+	}
+	if req.DeviceID == "" {
+		return nil, apperror.NewValidationError("device_id is required", map[string]string{})
+	}
+	if len(req.Events) > maxSyncBatchSize {
+		return nil, apperror.NewValidationError(fmt.Sprintf("at most %d events can be uploaded at once", maxSyncBatchSize), map[string]string{})
+	}
+
+	// Replay in the order events happened on the device
+	order := make([]int, len(req.Events))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return req.Events[order[a]].OccurredAt < req.Events[order[b]].OccurredAt
+	})
+
+	// Results are returned in upload order
+	response := &SyncUploadResponse{Results: make([]SyncEventResult, len(req.Events))}
+	for _, i := range order {
+		result, err := u.replay(ctx, req.BusinessID, req.DeviceID, req.StaffID, req.Device, req.Events[i])
+		if err != nil {
+			return nil, err
+		}
+		response.Results[i] = result
+	}
+
+	return response, nil
+}
+
+// replay applies a single event and records its outcome in one unit of
+// work, so an event is never applied without being recorded. Only
+// server-side failures are returned as errors; invalid events become
+// rejected results.
+func (u *SyncUsecase) replay(ctx context.Context, businessID, deviceID, staffID string, device *DeviceScope, ev SyncEventInput) (SyncEventResult, error) {
+	result := SyncEventResult{EventID: ev.EventID, TicketID: ev.TicketID}
+
+	if ev.EventID == "" {
+		result.Result = domain.SyncResultRejected
+		result.Message = "event_id is required"
+		return result, nil
+	}
+	if len(ev.EventID) > maxSyncEventIDLength {
+		result.Result = domain.SyncResultRejected
+		result.Message = fmt.Sprintf("event_id must be at most %d characters", maxSyncEventIDLength)
+		return result, nil
+	}
+	if len(ev.TicketID) > maxSyncTicketIDLength {
+		result.Result = domain.SyncResultRejected
+		result.Message = fmt.Sprintf("ticket_id must be at most %d characters", maxSyncTicketIDLength)
+		return result, nil
+	}
+	if ev.Type != domain.SyncEventTypeScan && ev.Type != domain.SyncEventTypeRelease {
+		result.Result = domain.SyncResultRejected
+		result.Message = "type must be scan or release"
+		return result, nil
+	}
+
+	// Sync event types match the device actions they need
+	if !device.Allows(ev.Type) {
+		result.Result = domain.SyncResultRejected
+		result.Message = fmt.Sprintf("device is not allowed to %s tickets", ev.Type)
+		return result, nil
+	}
+
+	prior, err := u.syncRepo.FindByID(ctx, businessID, ev.EventID)
+	if err == nil {
+		result.Result = domain.SyncResultDuplicate
+		result.Message = prior.Message
+		result.TicketID = prior.TicketID
+		return result, nil
+	}
+	if !apperror.IsNotFound(err) {
+		return result, err
+	}
+
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if ev.Type == domain.SyncEventTypeScan {
+			err = u.replayScan(ctx, businessID, staffID, device, ev, &result)
+		} else {
+			err = u.replayRelease(ctx, businessID, deviceID, staffID, device, ev, &result)
+		}
+		if err != nil {
+			return err
+		}
+
+		return u.syncRepo.Create(ctx, &domain.SyncEvent{
+			ID:         ev.EventID,
+			BusinessID: businessID,
+			DeviceID:   deviceID,
+			Type:       ev.Type,
+			TicketID:   result.TicketID,
+			OccurredAt: ev.OccurredAt,
+			ReceivedAt: domain.NowTimestamp(),
+			Result:     result.Result,
+			Message:    result.Message,
+		})
+	})
+	// A concurrent upload of the same event won the race; what this replay
+	// did was rolled back with the failed insert
+	if apperror.IsConflict(err) {
+		return SyncEventResult{EventID: ev.EventID, TicketID: ev.TicketID, Result: domain.SyncResultDuplicate}, nil
+	}
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// replayScan verifies the scanned QR and flags scans of tickets that were
+// already released when the device scanned them
+func (u *SyncUsecase) replayScan(ctx context.Context, businessID, staffID string, device *DeviceScope, ev SyncEventInput, result *SyncEventResult) error {
+	scan, err := u.ticketUsecase.Scan(ctx, ScanRequest{QRPayload: ev.QRPayload, BusinessID: businessID, StaffID: staffID, Device: device, ScannedAt: ev.OccurredAt})
+	if err != nil {
+		return rejectClientError(err, result)
+	}
+
+	result.TicketID = scan.TicketID
+	result.TicketStatus = scan.Status
+
+	if scan.ReleasedAt != nil && *scan.ReleasedAt <= ev.OccurredAt {
+		result.Result = domain.SyncResultConflict
+		result.Message = "ticket was already released when it was scanned"
+		return nil
+	}
+
+	result.Result = domain.SyncResultApplied
+	return nil
+}
+
+// replayRelease releases the ticket, reporting double releases and tickets
+// released on another device as conflicts
+func (u *SyncUsecase) replayRelease(ctx context.Context, businessID, deviceID, staffID string, device *DeviceScope, ev SyncEventInput, result *SyncEventResult) error {
+	if ev.TicketID == "" {
+		result.Result = domain.SyncResultRejected
+		result.Message = "ticket_id is required"
+		return nil
+	}
+
+	releaseErr := u.ticketUsecase.Release(ctx, ev.TicketID, businessID, staffID, device)
+	if releaseErr != nil && !apperror.IsConflict(releaseErr) {
+		return rejectClientError(releaseErr, result)
+	}
+
+	if releaseErr == nil {
+		result.Result = domain.SyncResultApplied
+		result.TicketStatus = domain.TicketStatusReleased
+		return nil
+	}
+
+	result.Result = domain.SyncResultConflict
+	prior, err := u.syncRepo.FindAppliedReleaseByTicketID(ctx, ev.TicketID)
+	switch {
+	case err == nil && prior.DeviceID != deviceID:
+		result.Message = fmt.Sprintf("ticket already released on device %s", prior.DeviceID)
+	case err == nil:
+		result.Message = "ticket already released by this device"
+	case apperror.IsNotFound(err):
+		// Released online or expired
+		result.Message = apperror.From(releaseErr).Message
+	default:
+		return err
+	}
+
+	return nil
+}
+
+// rejectClientError turns request errors into a rejected result and passes
+// server errors through
+func rejectClientError(err error, result *SyncEventResult) error {
+	appErr := apperror.From(err)
+	if appErr.StatusCode >= 500 {
+		return err
+	}
+
+	result.Result = domain.SyncResultRejected
+	result.Message = appErr.Message
+	return nil
+}
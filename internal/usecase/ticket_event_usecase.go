@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"context"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Ticket event feed page sizes
+const (
+	defaultEventPageSize = 100
+	maxEventPageSize     = 500
+)
+
+// EventActor identifies who caused a ticket event
+type EventActor struct {
+	Type string // business, staff, device, customer or system
+	ID   string // "" for the system
+}
+
+// systemActor is the actor of background jobs
+var systemActor = EventActor{Type: domain.ActorTypeSystem}
+
+// businessActor names who acts for a business: a scanner device, a staff
+// member or the business account itself
+func businessActor(businessID, staffID string, device *DeviceScope) EventActor {
+	switch {
+	case device != nil:
+		return EventActor{Type: domain.ActorTypeDevice, ID: device.DeviceID}
+	case staffID != "":
+		return EventActor{Type: domain.ActorTypeStaff, ID: staffID}
+	default:
+		return EventActor{Type: domain.ActorTypeBusiness, ID: businessID}
+	}
+}
+
+// TicketEventUsecase records the lifecycle of tickets and serves their
+// history. Other usecases record events inside the unit of work that makes
+// the change, so the history never disagrees with the tickets.
+type TicketEventUsecase struct {
+	eventRepo   domain.TicketEventRepository
+	ticketRepo  domain.TicketRepository
+	serviceRepo domain.ServiceRepository
+}
+
+// NewTicketEventUsecase creates a new ticket event usecase
+func NewTicketEventUsecase(
+	eventRepo domain.TicketEventRepository,
+	ticketRepo domain.TicketRepository,
+	serviceRepo domain.ServiceRepository,
+) *TicketEventUsecase {
+	return &TicketEventUsecase{
+		eventRepo:   eventRepo,
+		ticketRepo:  ticketRepo,
+		serviceRepo: serviceRepo,
+	}
+}
+
+// Request/Response types
+type ListEventsRequest struct {
+	ServiceID  string
+	Type       string
+	From       int64 // Occurred at or after, Unix seconds
+	To         int64 // Occurred at or before, Unix seconds
+	After      int64 // Seq of the last event already seen
+	Limit      int
+	BusinessID string
+}
+
+type TicketEventResponse struct {
+	ID         string                 `json:"id"`
+	Seq        int64                  `json:"seq"`
+	TicketID   string                 `json:"ticket_id"`
+	ServiceID  string                 `json:"service_id"`
+	Type       string                 `json:"type"`
+	ActorType  string                 `json:"actor_type"`
+	ActorID    string                 `json:"actor_id,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata"`
+	OccurredAt int64                  `json:"occurred_at"`
+}
+
+// EventFeedResponse is a page of a business's ticket events. NextAfter is
+// set when more events may follow; pass it as after to get them.
+//
+// Seq is assigned when an event is written, not when its transaction
+// commits, so an event can become visible after a later seq was already
+// returned. Consumers that must see every event should re-read a margin
+// behind their cursor and skip IDs they have seen.
+type EventFeedResponse struct {
+	Events    []TicketEventResponse `json:"events"`
+	NextAfter int64                 `json:"next_after,omitempty"`
+}
+
+// ListTicketEvents lists the history of one of a business's tickets, oldest
+// first. The history of tickets deleted with their service stays readable.
+func (u *TicketEventUsecase) ListTicketEvents(ctx context.Context, ticketID, businessID string) ([]TicketEventResponse, error) {
+	events, err := u.eventRepo.ListByTicketID(ctx, ticketID, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	// No events of this business: tell a missing ticket from a foreign one
+	if len(events) == 0 {
+		ticket, err := u.ticketRepo.FindByID(ctx, ticketID)
+		if err != nil {
+			return nil, err
+		}
+		service, err := u.serviceRepo.FindByID(ctx, ticket.ServiceID)
+		if err != nil {
+			return nil, err
+		}
+		if service.BusinessID != businessID {
+			return nil, apperror.NewForbidden("ticket does not belong to this business")
+		}
+	}
+
+	return toTicketEventResponses(events), nil
+}
+
+// ListEvents pages through a business's ticket events, oldest first,
+// optionally narrowed to a service, an event type and a time range
+func (u *TicketEventUsecase) ListEvents(ctx context.Context, req ListEventsRequest) (*EventFeedResponse, error) {
+	switch req.Type {
+	case "", domain.TicketEventIssued, domain.TicketEventScanned, domain.TicketEventReleased,
+		domain.TicketEventExpired, domain.TicketEventReissued, domain.TicketEventVoided:
+	default:
+		return nil, apperror.NewValidationError("type must be issued, scanned, released, expired, reissued or voided", map[string]string{})
+	}
+
+	if req.From < 0 || req.To < 0 || req.After < 0 || req.Limit < 0 {
+		return nil, apperror.NewValidationError("from, to, after and limit cannot be negative", map[string]string{})
+	}
+
+	if req.To != 0 && req.From > req.To {
+		return nil, apperror.NewValidationError("from must not be after to", map[string]string{})
+	}
+
+	// Services may be gone while their events remain
+	if req.ServiceID != "" {
+		service, err := u.serviceRepo.FindByID(ctx, req.ServiceID)
+		if err != nil && !apperror.IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil && service.BusinessID != req.BusinessID {
+			return nil, apperror.NewForbidden("service does not belong to this business")
+		}
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultEventPageSize
+	}
+	if limit > maxEventPageSize {
+		limit = maxEventPageSize
+	}
+
+	events, err := u.eventRepo.List(ctx, domain.TicketEventFilter{
+		BusinessID: req.BusinessID,
+		ServiceID:  req.ServiceID,
+		Type:       req.Type,
+		From:       req.From,
+		To:         req.To,
+		AfterSeq:   req.After,
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &EventFeedResponse{Events: toTicketEventResponses(events)}
+	if len(events) == limit {
+		response.NextAfter = events[len(events)-1].Seq
+	}
+
+	return response, nil
+}
+
+// record appends an event for a ticket of a business. Call it inside the
+// unit of work that makes the change.
+func (u *TicketEventUsecase) record(ctx context.Context, ticket *domain.Ticket, businessID, eventType string, actor EventActor, metadata map[string]interface{}) error {
+	return u.eventRepo.Create(ctx, &domain.TicketEvent{
+		ID:         uuid.New().String(),
+		TicketID:   ticket.ID,
+		BusinessID: businessID,
+		ServiceID:  ticket.ServiceID,
+		Type:       eventType,
+		ActorType:  actor.Type,
+		ActorID:    actor.ID,
+		Metadata:   metadata,
+		OccurredAt: domain.NowTimestamp(),
+	})
+}
+
+func toTicketEventResponses(events []domain.TicketEvent) []TicketEventResponse {
+	responses := make([]TicketEventResponse, len(events))
+	for i, event := range events {
+		responses[i] = TicketEventResponse{
+			ID:         event.ID,
+			Seq:        event.Seq,
+			TicketID:   event.TicketID,
+			ServiceID:  event.ServiceID,
+			Type:       event.Type,
+			ActorType:  event.ActorType,
+			ActorID:    event.ActorID,
+			Metadata:   event.Metadata,
+			OccurredAt: event.OccurredAt,
+		}
+	}
+	return responses
+}
@@ -12,10 +12,19 @@ import (
 
 // TicketUsecase handles ticket operations
 type TicketUsecase struct {
-	ticketRepo   domain.TicketRepository
-	slotRepo     domain.SlotRepository
-	serviceRepo  domain.ServiceRepository
-	businessRepo domain.BusinessRepository
+	ticketRepo  domain.TicketRepository
+	slotRepo    domain.SlotRepository
+	serviceRepo domain.ServiceRepository
+	zoneRepo    domain.ZoneRepository
+	keyRepo     domain.BusinessKeyRepository
+	waitlist    *WaitlistUsecase
+	holds       *HoldUsecase
+	items       *ItemUsecase
+	recovery    *RecoveryUsecase
+	transfers   *TransferUsecase
+	events      *TicketEventUsecase
+	scans       *ScanAuditUsecase
+	txManager   domain.TxManager
 }
 
 // NewTicketUsecase creates a new ticket usecase
@@ -23,43 +32,104 @@ func NewTicketUsecase(
 	ticketRepo domain.TicketRepository,
 	slotRepo domain.SlotRepository,
 	serviceRepo domain.ServiceRepository,
-	businessRepo domain.BusinessRepository,
+	zoneRepo domain.ZoneRepository,
+	keyRepo domain.BusinessKeyRepository,
+	waitlist *WaitlistUsecase,
+	holds *HoldUsecase,
+	items *ItemUsecase,
+	recovery *RecoveryUsecase,
+	transfers *TransferUsecase,
+	events *TicketEventUsecase,
+	scans *ScanAuditUsecase,
+	txManager domain.TxManager,
 ) *TicketUsecase {
 	return &TicketUsecase{
-		ticketRepo:   ticketRepo,
-		slotRepo:     slotRepo,
-		serviceRepo:  serviceRepo,
-		businessRepo: businessRepo,
+		ticketRepo:  ticketRepo,
+		slotRepo:    slotRepo,
+		serviceRepo: serviceRepo,
+		zoneRepo:    zoneRepo,
+		keyRepo:     keyRepo,
+		waitlist:    waitlist,
+		holds:       holds,
+		items:       items,
+		recovery:    recovery,
+		transfers:   transfers,
+		events:      events,
+		scans:       scans,
+		txManager:   txManager,
 	}
 }
 
 // Request/Response types
 type CheckInRequest struct {
 	ServiceID  string `json:"service_id"`
-	BusinessID string `json:"-"`
+	SlotNumber int    `json:"slot_number,omitempty"` // 0 = next free slot
+	ZoneID     string `json:"zone_id,omitempty"`     // "" = any zone
+	// Checks in on the slot reserved for this waitlist entry
+	WaitlistEntryID string `json:"waitlist_entry_id,omitempty"`
+	// Confirms this slot hold into a ticket
+	HoldID     string              `json:"hold_id,omitempty"`
+	Items      []TicketItemRequest `json:"items,omitempty"` // What is handed in; may decide the zone
+	BusinessID string              `json:"-"`
+	StaffID    string              `json:"-"` // Staff member issuing the ticket, "" for the business account
+	CustomerID string              `json:"-"` // Set for customer check-ins and confirmed holds
+	Device     *DeviceScope        `json:"-"` // Set when a scanner device issues the ticket
+	Actor      EventActor          `json:"-"` // Who issues the ticket; defaults to the device, staff member or business
 }
 
+type CustomerCheckInRequest struct {
+	ServiceID    string              `json:"service_id"`
+	ZoneID       string              `json:"zone_id,omitempty"`
+	JoinWaitlist bool                `json:"join_waitlist,omitempty"` // Queue instead of failing when the service is full
+	Items        []TicketItemRequest `json:"items,omitempty"`
+	CustomerID   string              `json:"-"`
+}
+
+// CheckInResponse describes the issued ticket, or only Waitlist when the
+// customer was queued instead
 type CheckInResponse struct {
-	TicketID   string `json:"ticket_id"`
-	SlotNumber int    `json:"slot_number"`
-	QRPayload  string `json:"qr_payload"` // base64 encoded
-	IssuedAt   int64  `json:"issued_at"`
+	TicketID   string               `json:"ticket_id,omitempty"`
+	SlotNumber int                  `json:"slot_number,omitempty"`
+	ZoneID     string               `json:"zone_id,omitempty"`
+	QRPayload  string               `json:"qr_payload,omitempty"` // base64 encoded
+	IssuedAt   int64                `json:"issued_at,omitempty"`
+	Items      []TicketItemResponse `json:"items,omitempty"`
+	Waitlist   *WaitlistResponse    `json:"waitlist,omitempty"`
 }
 
 type ScanRequest struct {
-	QRPayload  string `json:"qr_payload"` // base64 encoded payload
-	BusinessID string `json:"-"`
+	QRPayload  string       `json:"qr_payload"` // base64 encoded payload
+	BusinessID string       `json:"-"`
+	StaffID    string       `json:"-"`
+	Device     *DeviceScope `json:"-"`
+	ScannedAt  int64        `json:"-"` // When an offline scan happened, 0 = now
 }
 
 type ScanResponse struct {
-	SlotNumber int    `json:"slot_number"`
-	ServiceID  string `json:"service_id"`
-	Status     string `json:"status"`
-	ReleasedAt *int64 `json:"released_at"`
+	TicketID   string               `json:"ticket_id"`
+	SlotNumber int                  `json:"slot_number"`
+	ServiceID  string               `json:"service_id"`
+	Status     string               `json:"status"`
+	ReleasedAt *int64               `json:"released_at"`
+	IssuedBy   string               `json:"issued_by,omitempty"`   // Staff member who checked the ticket in
+	ReleasedBy string               `json:"released_by,omitempty"` // Staff member who released the ticket
+	Items      []TicketItemResponse `json:"items"`                 // What the attendant should retrieve
 }
 
 // CheckIn claims a slot and creates a QR code ticket
 func (u *TicketUsecase) CheckIn(ctx context.Context, req CheckInRequest) (*CheckInResponse, error) {
+	if req.SlotNumber < 0 {
+		return nil, apperror.NewValidationError("slot_number must be positive", map[string]string{})
+	}
+
+	if req.WaitlistEntryID != "" && (req.SlotNumber > 0 || req.ZoneID != "") {
+		return nil, apperror.NewValidationError("waitlist_entry_id cannot be combined with slot_number or zone_id", map[string]string{})
+	}
+
+	if req.HoldID != "" && (req.SlotNumber > 0 || req.ZoneID != "" || req.WaitlistEntryID != "") {
+		return nil, apperror.NewValidationError("hold_id cannot be combined with slot_number, zone_id or waitlist_entry_id", map[string]string{})
+	}
+
 	// Verify service ownership
 	service, err := u.serviceRepo.FindByID(ctx, req.ServiceID)
 	if err != nil {
@@ -70,117 +140,436 @@ func (u *TicketUsecase) CheckIn(ctx context.Context, req CheckInRequest) (*Check
 		return nil, apperror.NewForbidden("service does not belong to this business")
 	}
 
-	// Get business to retrieve HMAC key
-	business, err := u.businessRepo.FindByID(ctx, req.BusinessID)
-	if err != nil {
+	if err := req.Device.checkService(service.ID); err != nil {
 		return nil, err
 	}
 
-	// Claim next free slot (with row locking to prevent race conditions)
-	slot, err := u.slotRepo.ClaimNextFreeSlot(ctx, req.ServiceID)
-	if err != nil {
-		return nil, err
+	if service.IsArchived() {
+		return nil, apperror.NewConflict("service is archived")
+	}
+	if service.IsClosed(domain.NowTimestamp()) {
+		return nil, apperror.NewConflict("service is closed")
 	}
 
-	// Create QR payload
-	payload := qr.New(uuid.New().String(), req.ServiceID, req.BusinessID, slot.SlotNumber)
+	if req.ZoneID != "" {
+		zone, err := u.zoneRepo.FindByID(ctx, req.ZoneID)
+		if err != nil {
+			return nil, err
+		}
+		if zone.ServiceID != service.ID {
+			return nil, apperror.NewNotFound("zone")
+		}
+	}
 
-	// Sign payload with business HMAC key
-	if err := payload.Sign(business.HMACKey); err != nil {
-		return nil, apperror.NewInternalServer("QR signing failed", err)
+	// Items whose type is stored in a zone decide the zone of the slot
+	zoneID := req.ZoneID
+	itemsZone, err := u.items.itemsZone(ctx, service.ID, req.Items)
+	if err != nil {
+		return nil, err
+	}
+	if itemsZone != "" {
+		if zoneID != "" && zoneID != itemsZone {
+			return nil, apperror.NewValidationError("items are stored in a different zone than zone_id", map[string]string{})
+		}
+		zoneID = itemsZone
 	}
 
-	// Encode payload to base64
-	encoded, err := payload.Encode()
+	// Get the business's active Ed25519 signing key
+	key, err := activeSigningKey(ctx, u.keyRepo, req.BusinessID, domain.KeyAlgorithmEd25519)
 	if err != nil {
-		return nil, apperror.NewInternalServer("QR encoding failed", err)
+		return nil, err
 	}
 
-	// Create ticket record
-	ticket := &domain.Ticket{
-		ID:         payload.TicketID,
-		ServiceID:  req.ServiceID,
-		SlotID:     slot.ID,
-		SlotNumber: slot.SlotNumber,
-		Status:     domain.TicketStatusActive,
-		HMACDigest: payload.HMAC,
-		IssuedAt:   payload.IssuedAt,
-		CreatedAt:  domain.NowTimestamp(),
-		UpdatedAt:  domain.NowTimestamp(),
+	var (
+		slot    *domain.Slot
+		payload *qr.Payload
+		encoded string
+		items   []domain.TicketItem
+	)
+	ticketID := uuid.New().String()
+
+	actor := req.Actor
+	if actor.Type == "" {
+		actor = businessActor(req.BusinessID, req.StaffID, req.Device)
 	}
 
-	if err := u.ticketRepo.Create(ctx, ticket); err != nil {
+	// Claim the slot and create the ticket atomically so a failed insert
+	// never leaves an occupied slot without a ticket
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Hold the service row so it cannot be archived or deleted before
+		// the ticket is committed
+		locked, err := u.serviceRepo.ShareLockByID(ctx, service.ID)
+		if err != nil {
+			return err
+		}
+		if locked.IsArchived() {
+			return apperror.NewConflict("service is archived")
+		}
+		if locked.IsClosed(domain.NowTimestamp()) {
+			return apperror.NewConflict("service is closed")
+		}
+
+		// Claim the held, reserved, requested or next free slot (with row locking to prevent race conditions)
+		switch {
+		case req.HoldID != "":
+			slot, err = u.holds.claimHold(ctx, req.HoldID, req.ServiceID, ticketID)
+		case req.WaitlistEntryID != "":
+			slot, err = u.waitlist.claimOffer(ctx, req.WaitlistEntryID, req.ServiceID, ticketID)
+		case req.SlotNumber > 0:
+			slot, err = u.slotRepo.ClaimSlot(ctx, req.ServiceID, req.SlotNumber)
+		default:
+			slot, err = u.slotRepo.ClaimNextFreeSlot(ctx, req.ServiceID, zoneID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if zoneID != "" && slot.ZoneID != zoneID {
+			return apperror.NewConflict("slot is not in the requested zone")
+		}
+
+		// Create QR payload, signed with the active key
+		payload = qr.NewV2(ticketID, req.ServiceID, req.BusinessID, slot.SlotNumber, key.ID)
+		encoded, err = signPayload(payload, key)
+		if err != nil {
+			return err
+		}
+
+		// Create ticket record
+		ticket := &domain.Ticket{
+			ID:         payload.TicketID,
+			ServiceID:  req.ServiceID,
+			SlotID:     slot.ID,
+			SlotNumber: slot.SlotNumber,
+			CustomerID: req.CustomerID,
+			Status:     domain.TicketStatusActive,
+			HMACDigest: payload.Digest(),
+			IssuedAt:   payload.IssuedAt,
+			IssuedBy:   req.StaffID,
+			CreatedAt:  domain.NowTimestamp(),
+			UpdatedAt:  domain.NowTimestamp(),
+		}
+
+		if err := u.ticketRepo.Create(ctx, ticket); err != nil {
+			return err
+		}
+
+		items, err = u.items.createItems(ctx, ticket.ID, req.Items)
+		if err != nil {
+			return err
+		}
+
+		return u.events.record(ctx, ticket, req.BusinessID, domain.TicketEventIssued, actor, issuedMetadata(req, slot, len(items)))
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return &CheckInResponse{
-		TicketID:   ticket.ID,
+		TicketID:   payload.TicketID,
 		SlotNumber: slot.SlotNumber,
+		ZoneID:     slot.ZoneID,
 		QRPayload:  encoded,
 		IssuedAt:   payload.IssuedAt,
+		Items:      toTicketItemResponses(items),
 	}, nil
 }
 
+// ConfirmHold turns a customer's slot hold into a ticket on the held slot
+func (u *TicketUsecase) ConfirmHold(ctx context.Context, holdID, businessID, staffID string) (*CheckInResponse, error) {
+	hold, err := u.holds.authorizedHold(ctx, holdID, Actor{UserID: businessID, Role: "business"})
+	if err != nil {
+		return nil, err
+	}
+
+	return u.CheckIn(ctx, CheckInRequest{
+		ServiceID:  hold.ServiceID,
+		HoldID:     hold.ID,
+		BusinessID: businessID,
+		StaffID:    staffID,
+		CustomerID: hold.CustomerID,
+	})
+}
+
 // CustomerCheckIn allows a customer to check in to a service
-func (u *TicketUsecase) CustomerCheckIn(ctx context.Context, serviceID string) (*CheckInResponse, error) {
+func (u *TicketUsecase) CustomerCheckIn(ctx context.Context, req CustomerCheckInRequest) (*CheckInResponse, error) {
 	// Fetch service to get business ID
-	service, err := u.serviceRepo.FindByID(ctx, serviceID)
+	service, err := u.serviceRepo.FindByID(ctx, req.ServiceID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Create check-in request with business ID from service
-	req := CheckInRequest{
-		ServiceID:  serviceID,
+	checkIn := CheckInRequest{
+		ServiceID:  req.ServiceID,
+		ZoneID:     req.ZoneID,
+		Items:      req.Items,
 		BusinessID: service.BusinessID,
+		CustomerID: req.CustomerID,
+		Actor:      EventActor{Type: domain.ActorTypeCustomer, ID: req.CustomerID},
 	}
 
-	return u.CheckIn(ctx, req)
+	// A customer already in the queue checks in on their reserved slot
+	entry, err := u.waitlist.openEntry(ctx, req.ServiceID, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if entry != nil {
+		if entry.Status == domain.WaitlistStatusWaiting {
+			position, err := u.waitlist.toResponse(ctx, entry)
+			if err != nil {
+				return nil, err
+			}
+			return &CheckInResponse{Waitlist: position}, nil
+		}
+		checkIn.ZoneID = ""
+		checkIn.WaitlistEntryID = entry.ID
+	}
+
+	result, err := u.CheckIn(ctx, checkIn)
+	if err == nil || !req.JoinWaitlist || entry != nil || !apperror.IsConflict(err) {
+		return result, err
+	}
+
+	// Service is full: queue the customer instead
+	queued, err := u.waitlist.Join(ctx, JoinWaitlistRequest{
+		ServiceID:  req.ServiceID,
+		ZoneID:     req.ZoneID,
+		CustomerID: req.CustomerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &CheckInResponse{Waitlist: queued}, nil
 }
 
-// Scan verifies a QR code and returns ticket status
+// Scan verifies a QR code and returns ticket status. Every attempt is
+// recorded with its outcome, whether the code is accepted or not.
 func (u *TicketUsecase) Scan(ctx context.Context, req ScanRequest) (*ScanResponse, error) {
+	attempt := &domain.ScanAttempt{
+		ID:          uuid.New().String(),
+		BusinessID:  req.BusinessID,
+		StaffID:     req.StaffID,
+		AttemptedAt: req.ScannedAt,
+	}
+	if attempt.AttemptedAt == 0 {
+		attempt.AttemptedAt = domain.NowTimestamp()
+	}
+	if req.Device != nil {
+		attempt.DeviceID = req.Device.DeviceID
+	}
+
+	response, err := u.scan(ctx, req, attempt)
+
+	// Accepted codes were recorded along with the scan; rejected ones are
+	// recorded here. Failures without an outcome are not about the code.
+	if err != nil && attempt.Outcome != "" {
+		if recordErr := u.scans.record(ctx, attempt, nil); recordErr != nil {
+			return nil, recordErr
+		}
+	}
+
+	return response, err
+}
+
+// scan does the work of Scan, setting the outcome of attempt whenever the
+// code is rejected
+func (u *TicketUsecase) scan(ctx context.Context, req ScanRequest, attempt *domain.ScanAttempt) (*ScanResponse, error) {
+	reject := func(outcome string, err error) (*ScanResponse, error) {
+		attempt.Outcome = outcome
+		return nil, err
+	}
+
 	// Decode QR payload
 	payload, err := qr.Decode(req.QRPayload)
 	if err != nil {
-		return nil, apperror.NewBadRequest("invalid QR payload")
+		return reject(domain.ScanOutcomeMalformed, apperror.NewBadRequest("invalid QR payload"))
 	}
+	attempt.QRDigest = payload.Digest()
 
 	// Verify business ownership
 	if payload.BusinessID != req.BusinessID {
-		return nil, apperror.NewForbidden("QR code does not belong to this business")
+		return reject(domain.ScanOutcomeWrongBusiness, apperror.NewForbidden("QR code does not belong to this business"))
+	}
+
+	if err := req.Device.checkService(payload.ServiceID); err != nil {
+		return reject(domain.ScanOutcomeWrongService, err)
 	}
 
-	// Get business HMAC key
-	business, err := u.businessRepo.FindByID(ctx, req.BusinessID)
+	// Pick the signing key by kid
+	key, err := u.verificationKey(ctx, req.BusinessID, payload)
+	if err != nil {
+		if apperror.IsBadRequest(err) {
+			return reject(domain.ScanOutcomeBadSignature, err)
+		}
+		return nil, err
+	}
+
+	// Verify the signature (HMAC for v1, Ed25519 for v2)
+	if err := verifySignature(payload, key); err != nil {
+		return reject(domain.ScanOutcomeBadSignature, apperror.NewBadRequest("invalid QR signature"))
+	}
+
+	// The signature is genuine, so the payload can be trusted from here on
+	attempt.ServiceID = payload.ServiceID
+
+	// Reject codes replaced by a reissue or transfer, even though their
+	// signature is valid
+	reissue, err := u.recovery.revocation(ctx, payload.Digest())
 	if err != nil {
 		return nil, err
 	}
+	if reissue != nil {
+		attempt.TicketID = reissue.TicketID
+		appErr := apperror.NewBadRequest("QR code has been revoked")
+		appErr.Details["ticket_id"] = reissue.TicketID
+		appErr.Details["reason"] = "reissued"
+		appErr.Details["revoked_at"] = reissue.CreatedAt
+		return reject(domain.ScanOutcomeRevoked, appErr)
+	}
 
-	// Verify HMAC signature
-	if err := payload.Verify(business.HMACKey); err != nil {
-		return nil, apperror.NewBadRequest("invalid QR signature")
+	transfer, err := u.transfers.revocation(ctx, payload.Digest())
+	if err != nil {
+		return nil, err
+	}
+	if transfer != nil {
+		attempt.TicketID = transfer.TicketID
+		appErr := apperror.NewBadRequest("QR code has been revoked")
+		appErr.Details["ticket_id"] = transfer.TicketID
+		appErr.Details["reason"] = "transferred"
+		appErr.Details["revoked_at"] = transfer.UpdatedAt
+		return reject(domain.ScanOutcomeRevoked, appErr)
 	}
 
-	// Find ticket by HMAC for audit trail
-	ticket, err := u.ticketRepo.FindByHMAC(ctx, payload.HMAC)
+	// Find ticket by signature digest for audit trail
+	ticket, err := u.ticketRepo.FindByHMAC(ctx, payload.Digest())
 	if err != nil {
 		if apperror.IsNotFound(err) {
-			return nil, apperror.NewBadRequest("ticket not found")
+			return reject(domain.ScanOutcomeUnknownTicket, apperror.NewBadRequest("ticket not found"))
+		}
+		return nil, err
+	}
+
+	attempt.TicketID = ticket.ID
+	attempt.Outcome = scanOutcome(ticket, attempt.AttemptedAt)
+
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.ticketRepo.MarkScanned(ctx, ticket.ID, req.StaffID); err != nil {
+			return err
+		}
+		if err := u.scans.record(ctx, attempt, ticket); err != nil {
+			return err
 		}
+		return u.events.record(ctx, ticket, req.BusinessID, domain.TicketEventScanned, businessActor(req.BusinessID, req.StaffID, req.Device), map[string]interface{}{
+			"status":        ticket.Status,
+			"qr_generation": payload.Generation,
+			"attempt_id":    attempt.ID,
+		})
+	})
+	if err != nil {
+		// Already reported; the attempt was rolled back with the scan
+		attempt.Outcome = ""
 		return nil, err
 	}
 
-	return &ScanResponse{
+	items, err := u.items.listItems(ctx, ticket.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ScanResponse{
+		TicketID:   ticket.ID,
 		SlotNumber: ticket.SlotNumber,
 		ServiceID:  ticket.ServiceID,
 		Status:     ticket.Status,
-		ReleasedAt: (*int64)(nil), // Will populate if released
-	}, nil
+		IssuedBy:   ticket.IssuedBy,
+		ReleasedBy: ticket.ReleasedBy,
+		Items:      items,
+	}
+	if ticket.ReleasedAt != 0 {
+		response.ReleasedAt = &ticket.ReleasedAt
+	}
+
+	return response, nil
+}
+
+// scanOutcome classifies a scan of a genuine ticket. A ticket released or
+// expired only after the scan, as with offline scans replayed later, was
+// still valid when scanned.
+func scanOutcome(ticket *domain.Ticket, scannedAt int64) string {
+	switch {
+	case ticket.Status == domain.TicketStatusReleased && ticket.ReleasedAt <= scannedAt:
+		return domain.ScanOutcomeAlreadyReleased
+	case ticket.Status == domain.TicketStatusExpired && ticket.ExpiredAt <= scannedAt:
+		return domain.ScanOutcomeExpired
+	default:
+		return domain.ScanOutcomeValid
+	}
 }
 
-// Release frees a slot and marks ticket as released
-func (u *TicketUsecase) Release(ctx context.Context, ticketID, businessID string) error {
+// signPayload signs a v2 payload with an Ed25519 key and returns it base64 encoded
+func signPayload(payload *qr.Payload, key *domain.BusinessKey) (string, error) {
+	if err := payload.SignEd25519(key.Secret); err != nil {
+		return "", apperror.NewInternalServer("QR signing failed", err)
+	}
+
+	encoded, err := payload.Encode()
+	if err != nil {
+		return "", apperror.NewInternalServer("QR encoding failed", err)
+	}
+
+	return encoded, nil
+}
+
+// verificationKey returns the keyring entry a QR payload was signed with,
+// rejecting unknown keys and retired keys past their grace period
+func (u *TicketUsecase) verificationKey(ctx context.Context, businessID string, payload *qr.Payload) (*domain.BusinessKey, error) {
+	// Tickets issued before key rotation carry no kid and were signed with
+	// the original key, which is stored under the business ID
+	kid := payload.KeyID
+	if kid == "" {
+		kid = businessID
+	}
+
+	key, err := u.keyRepo.FindByID(ctx, kid)
+	if err != nil {
+		if apperror.IsNotFound(err) {
+			return nil, apperror.NewBadRequest("unknown QR signing key")
+		}
+		return nil, err
+	}
+
+	if key.BusinessID != businessID || key.Algorithm != payloadAlgorithm(payload) {
+		return nil, apperror.NewBadRequest("unknown QR signing key")
+	}
+
+	if !key.CanVerify(domain.NowTimestamp()) {
+		return nil, apperror.NewBadRequest("QR signing key has been retired")
+	}
+
+	return key, nil
+}
+
+// payloadAlgorithm returns the key algorithm a payload version is signed with
+func payloadAlgorithm(payload *qr.Payload) string {
+	if payload.Version == qr.VersionEd25519 {
+		return domain.KeyAlgorithmEd25519
+	}
+	return domain.KeyAlgorithmHMAC
+}
+
+// verifySignature checks a payload against the key it claims to be signed with
+func verifySignature(payload *qr.Payload, key *domain.BusinessKey) error {
+	if key.Algorithm == domain.KeyAlgorithmEd25519 {
+		return payload.VerifyEd25519(key.PublicKey)
+	}
+	return payload.Verify(key.Secret)
+}
+
+// Release frees a slot and marks ticket as released. device is nil unless a
+// scanner device releases the ticket.
+func (u *TicketUsecase) Release(ctx context.Context, ticketID, businessID, staffID string, device *DeviceScope) error {
 	// Find ticket
 	ticket, err := u.ticketRepo.FindByID(ctx, ticketID)
 	if err != nil {
@@ -197,30 +586,90 @@ func (u *TicketUsecase) Release(ctx context.Context, ticketID, businessID string
 		return apperror.NewForbidden("ticket does not belong to this business")
 	}
 
-	// Mark ticket as released
-	now := domain.NowTimestamp()
-	if err := u.ticketRepo.UpdateStatus(ctx, ticketID, domain.TicketStatusReleased); err != nil {
+	if err := device.checkService(service.ID); err != nil {
 		return err
 	}
 
-	// Free the slot
-	if ticket.SlotID != "" {
-		if err := u.slotRepo.UpdateStatus(ctx, ticket.SlotID, domain.SlotStatusFree); err != nil {
+	// Release the ticket and free its slot as one unit of work. The
+	// conditional update lets only one of concurrent releases, or a release
+	// racing the expiry sweep, free the slot.
+	return u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.ticketRepo.MarkReleased(ctx, ticketID, staffID); err != nil {
+			if apperror.IsConflict(err) {
+				return u.releaseConflict(ctx, ticketID)
+			}
 			return err
 		}
+
+		// Free the slot, or reserve it for the head of the waitlist
+		if ticket.SlotID != "" {
+			if err := u.waitlist.releaseSlot(ctx, ticket.ServiceID, ticket.SlotID); err != nil {
+				return err
+			}
+		}
+
+		return u.events.record(ctx, ticket, businessID, domain.TicketEventReleased, businessActor(businessID, staffID, device), map[string]interface{}{
+			"slot_number": ticket.SlotNumber,
+		})
+	})
+}
+
+// releaseConflict explains why a ticket is no longer active
+func (u *TicketUsecase) releaseConflict(ctx context.Context, ticketID string) error {
+	ticket, err := u.ticketRepo.FindByID(ctx, ticketID)
+	if err != nil {
+		return err
 	}
 
-	_ = now
-	return nil
+	if ticket.Status == domain.TicketStatusExpired {
+		return apperror.NewConflict("ticket has expired")
+	}
+	return apperror.NewConflict("ticket already released")
 }
 
-// GetCustomerTickets retrieves all tickets for a customer
-func (u *TicketUsecase) GetCustomerTickets(ctx context.Context, customerID string) ([]Ticket, error) {
+// issuedMetadata describes where a new ticket came from
+func issuedMetadata(req CheckInRequest, slot *domain.Slot, itemCount int) map[string]interface{} {
+	metadata := map[string]interface{}{
+		"slot_number": slot.SlotNumber,
+	}
+	if slot.ZoneID != "" {
+		metadata["zone_id"] = slot.ZoneID
+	}
+	if req.CustomerID != "" {
+		metadata["customer_id"] = req.CustomerID
+	}
+	if req.HoldID != "" {
+		metadata["hold_id"] = req.HoldID
+	}
+	if req.WaitlistEntryID != "" {
+		metadata["waitlist_entry_id"] = req.WaitlistEntryID
+	}
+	if itemCount > 0 {
+		metadata["items"] = itemCount
+	}
+	return metadata
+}
+
+// GetCustomerTickets retrieves a customer's tickets. Customers can only list
+// their own; businesses only see the tickets issued for their services.
+func (u *TicketUsecase) GetCustomerTickets(ctx context.Context, customerID string, actor Actor) ([]Ticket, error) {
 	if customerID == "" {
 		return nil, apperror.NewBadRequest("customer_id cannot be empty")
 	}
 
-	tickets, err := u.ticketRepo.ListByCustomerID(ctx, customerID)
+	businessID := ""
+	switch actor.Role {
+	case "customer":
+		if customerID != actor.UserID {
+			return nil, apperror.NewForbidden("customers can only list their own tickets")
+		}
+	case "business":
+		businessID = actor.UserID
+	default:
+		return nil, apperror.NewForbidden("insufficient permissions")
+	}
+
+	tickets, err := u.ticketRepo.ListByCustomerID(ctx, customerID, businessID)
 	if err != nil {
 		return nil, err
 	}
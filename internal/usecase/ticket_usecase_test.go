@@ -0,0 +1,317 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+	"CLOAKBE/internal/repository/memory"
+
+	"github.com/google/uuid"
+)
+
+// errInjected is the failure the stubs below inject between two steps of a
+// unit of work
+var errInjected = errors.New("injected failure")
+
+// failingTicketRepo fails ticket writes on demand
+type failingTicketRepo struct {
+	domain.TicketRepository
+	failCreate bool
+}
+
+func (r *failingTicketRepo) Create(ctx context.Context, ticket *domain.Ticket) error {
+	if r.failCreate {
+		return errInjected
+	}
+	return r.TicketRepository.Create(ctx, ticket)
+}
+
+// failingSlotRepo fails slot status changes on demand
+type failingSlotRepo struct {
+	domain.SlotRepository
+	failUpdateStatus bool
+}
+
+func (r *failingSlotRepo) UpdateStatus(ctx context.Context, id string, status string) error {
+	if r.failUpdateStatus {
+		return errInjected
+	}
+	return r.SlotRepository.UpdateStatus(ctx, id, status)
+}
+
+// failingEventRepo fails event writes on demand
+type failingEventRepo struct {
+	domain.TicketEventRepository
+	failCreate bool
+}
+
+func (r *failingEventRepo) Create(ctx context.Context, event *domain.TicketEvent) error {
+	if r.failCreate {
+		return errInjected
+	}
+	return r.TicketEventRepository.Create(ctx, event)
+}
+
+// ticketFixture wires a TicketUsecase to the memory store, with stubs that
+// can fail in the middle of check-in and release
+type ticketFixture struct {
+	tickets    *TicketUsecase
+	ticketRepo *failingTicketRepo
+	slotRepo   *failingSlotRepo
+	eventRepo  *failingEventRepo
+	businessID string
+	serviceID  string
+}
+
+func newTicketFixture(t *testing.T, totalSlots int) *ticketFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	store := memory.NewStore()
+	txManager := memory.NewTxManager(store)
+	businessRepo := memory.NewBusinessRepository(store)
+	keyRepo := memory.NewBusinessKeyRepository(store)
+	customerRepo := memory.NewCustomerRepository(store)
+	serviceRepo := memory.NewServiceRepository(store)
+	zoneRepo := memory.NewZoneRepository(store)
+	ticketRepo := &failingTicketRepo{TicketRepository: memory.NewTicketRepository(store)}
+	slotRepo := &failingSlotRepo{SlotRepository: memory.NewSlotRepository(store)}
+	eventRepo := &failingEventRepo{TicketEventRepository: memory.NewTicketEventRepository(store)}
+
+	waitlist := NewWaitlistUsecase(memory.NewWaitlistRepository(store), serviceRepo, zoneRepo, slotRepo, txManager, time.Minute)
+	holds := NewHoldUsecase(memory.NewSlotHoldRepository(store), serviceRepo, zoneRepo, slotRepo, waitlist, txManager, time.Minute)
+	items := NewItemUsecase(memory.NewItemTypeRepository(store), memory.NewTicketItemRepository(store), ticketRepo, serviceRepo, zoneRepo, slotRepo)
+	events := NewTicketEventUsecase(eventRepo, ticketRepo, serviceRepo)
+	scans := NewScanAuditUsecase(memory.NewScanAttemptRepository(store), memory.NewScanAlertRepository(store), txManager, ScanAlertPolicy{})
+	recovery := NewRecoveryUsecase(ticketRepo, memory.NewTicketReissueRepository(store), serviceRepo, customerRepo, slotRepo, keyRepo, items, events, txManager)
+	transfers := NewTransferUsecase(memory.NewTicketTransferRepository(store), ticketRepo, serviceRepo, customerRepo, slotRepo, keyRepo, items, events, txManager)
+	tickets := NewTicketUsecase(ticketRepo, slotRepo, serviceRepo, zoneRepo, keyRepo, waitlist, holds, items, recovery, transfers, events, scans, txManager)
+	services := NewServiceUsecase(serviceRepo, slotRepo, businessRepo, ticketRepo, zoneRepo, waitlist, holds, events, txManager)
+
+	now := domain.NowTimestamp()
+	business := &domain.Business{
+		ID:        uuid.New().String(),
+		Name:      "Cloakroom",
+		Email:     "owner@example.com",
+		Role:      "business",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := businessRepo.Create(ctx, business); err != nil {
+		t.Fatalf("create business: %v", err)
+	}
+
+	service, err := services.CreateService(ctx, CreateServiceRequest{
+		Name:       "Coats",
+		TotalSlots: totalSlots,
+		BusinessID: business.ID,
+	})
+	if err != nil {
+		t.Fatalf("create service: %v", err)
+	}
+
+	return &ticketFixture{
+		tickets:    tickets,
+		ticketRepo: ticketRepo,
+		slotRepo:   slotRepo,
+		eventRepo:  eventRepo,
+		businessID: business.ID,
+		serviceID:  service.ID,
+	}
+}
+
+func (f *ticketFixture) checkIn(t *testing.T) *CheckInResponse {
+	t.Helper()
+
+	ticket, err := f.tickets.CheckIn(context.Background(), CheckInRequest{ServiceID: f.serviceID, BusinessID: f.businessID})
+	if err != nil {
+		t.Fatalf("check in: %v", err)
+	}
+	return ticket
+}
+
+// assertSlots checks how many of the service's slots are in each status
+func (f *ticketFixture) assertSlots(t *testing.T, free, occupied int) {
+	t.Helper()
+
+	counts, err := f.slotRepo.CountSlotsByStatus(context.Background(), f.serviceID)
+	if err != nil {
+		t.Fatalf("count slots: %v", err)
+	}
+	if counts[domain.SlotStatusFree] != free || counts[domain.SlotStatusOccupied] != occupied {
+		t.Fatalf("slots: got %d free and %d occupied, want %d free and %d occupied",
+			counts[domain.SlotStatusFree], counts[domain.SlotStatusOccupied], free, occupied)
+	}
+}
+
+// assertActiveTickets checks how many of the service's tickets are active
+func (f *ticketFixture) assertActiveTickets(t *testing.T, want int) {
+	t.Helper()
+
+	tickets, err := f.ticketRepo.ListActiveByServiceID(context.Background(), f.serviceID)
+	if err != nil {
+		t.Fatalf("list tickets: %v", err)
+	}
+	if len(tickets) != want {
+		t.Fatalf("active tickets: got %d, want %d", len(tickets), want)
+	}
+}
+
+func (f *ticketFixture) events(t *testing.T, ticketID string) []domain.TicketEvent {
+	t.Helper()
+
+	events, err := f.eventRepo.ListByTicketID(context.Background(), ticketID, f.businessID)
+	if err != nil {
+		t.Fatalf("list events: %v", err)
+	}
+	return events
+}
+
+func TestCheckInRollsBackSlotClaimWhenTicketInsertFails(t *testing.T) {
+	f := newTicketFixture(t, 2)
+	f.ticketRepo.failCreate = true
+
+	_, err := f.tickets.CheckIn(context.Background(), CheckInRequest{ServiceID: f.serviceID, BusinessID: f.businessID})
+	if !errors.Is(err, errInjected) {
+		t.Fatalf("check in: got %v, want the injected failure", err)
+	}
+
+	f.assertSlots(t, 2, 0)
+	f.assertActiveTickets(t, 0)
+}
+
+func TestCheckInRollsBackWhenEventRecordFails(t *testing.T) {
+	f := newTicketFixture(t, 2)
+	f.eventRepo.failCreate = true
+
+	_, err := f.tickets.CheckIn(context.Background(), CheckInRequest{ServiceID: f.serviceID, BusinessID: f.businessID})
+	if !errors.Is(err, errInjected) {
+		t.Fatalf("check in: got %v, want the injected failure", err)
+	}
+
+	f.assertSlots(t, 2, 0)
+	f.assertActiveTickets(t, 0)
+}
+
+func TestCheckInAfterRollbackReusesSlot(t *testing.T) {
+	f := newTicketFixture(t, 1)
+	f.ticketRepo.failCreate = true
+
+	if _, err := f.tickets.CheckIn(context.Background(), CheckInRequest{ServiceID: f.serviceID, BusinessID: f.businessID}); err == nil {
+		t.Fatal("check in: want the injected failure")
+	}
+
+	f.ticketRepo.failCreate = false
+	ticket := f.checkIn(t)
+	if ticket.SlotNumber != 1 {
+		t.Fatalf("slot number: got %d, want 1", ticket.SlotNumber)
+	}
+
+	f.assertSlots(t, 0, 1)
+	f.assertActiveTickets(t, 1)
+}
+
+func TestReleaseRollsBackTicketWhenSlotFreeFails(t *testing.T) {
+	f := newTicketFixture(t, 2)
+	ticket := f.checkIn(t)
+	f.slotRepo.failUpdateStatus = true
+
+	err := f.tickets.Release(context.Background(), ticket.TicketID, f.businessID, "", nil)
+	if !errors.Is(err, errInjected) {
+		t.Fatalf("release: got %v, want the injected failure", err)
+	}
+
+	f.assertSlots(t, 1, 1)
+	f.assertActiveTickets(t, 1)
+}
+
+func TestReleaseRollsBackWhenEventRecordFails(t *testing.T) {
+	f := newTicketFixture(t, 2)
+	ticket := f.checkIn(t)
+	f.eventRepo.failCreate = true
+
+	err := f.tickets.Release(context.Background(), ticket.TicketID, f.businessID, "", nil)
+	if !errors.Is(err, errInjected) {
+		t.Fatalf("release: got %v, want the injected failure", err)
+	}
+
+	f.assertSlots(t, 1, 1)
+	f.assertActiveTickets(t, 1)
+
+	// The ticket can still be released once the failure clears
+	f.eventRepo.failCreate = false
+	if err := f.tickets.Release(context.Background(), ticket.TicketID, f.businessID, "", nil); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	f.assertSlots(t, 2, 0)
+	f.assertActiveTickets(t, 0)
+}
+
+func TestReleaseTwiceConflicts(t *testing.T) {
+	f := newTicketFixture(t, 1)
+	ticket := f.checkIn(t)
+
+	if err := f.tickets.Release(context.Background(), ticket.TicketID, f.businessID, "", nil); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+
+	// A new check-in takes the freed slot; a second release must not free it
+	f.checkIn(t)
+
+	err := f.tickets.Release(context.Background(), ticket.TicketID, f.businessID, "", nil)
+	if !apperror.IsConflict(err) {
+		t.Fatalf("second release: got %v, want a conflict", err)
+	}
+
+	f.assertSlots(t, 0, 1)
+}
+
+func TestConcurrentReleasesFreeSlotOnce(t *testing.T) {
+	f := newTicketFixture(t, 1)
+	ticket := f.checkIn(t)
+
+	const releases = 8
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+	)
+	for i := 0; i < releases; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := f.tickets.Release(context.Background(), ticket.TicketID, f.businessID, "", nil)
+			if err != nil && !apperror.IsConflict(err) {
+				t.Errorf("release: %v", err)
+				return
+			}
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 1 {
+		t.Fatalf("successful releases: got %d, want 1", succeeded)
+	}
+
+	released := 0
+	for _, event := range f.events(t, ticket.TicketID) {
+		if event.Type == domain.TicketEventReleased {
+			released++
+		}
+	}
+	if released != 1 {
+		t.Fatalf("released events: got %d, want 1", released)
+	}
+
+	f.assertSlots(t, 1, 0)
+}
@@ -0,0 +1,364 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+	"CLOAKBE/internal/qr"
+
+	"github.com/google/uuid"
+)
+
+// TransferUsecase lets a customer hand a ticket to someone else. The
+// recipient gets a fresh QR code and the sender's code stops scanning.
+type TransferUsecase struct {
+	transferRepo domain.TicketTransferRepository
+	ticketRepo   domain.TicketRepository
+	serviceRepo  domain.ServiceRepository
+	customerRepo domain.CustomerRepository
+	slotRepo     domain.SlotRepository
+	keyRepo      domain.BusinessKeyRepository
+	items        *ItemUsecase
+	events       *TicketEventUsecase
+	txManager    domain.TxManager
+}
+
+// NewTransferUsecase creates a new ticket transfer usecase
+func NewTransferUsecase(
+	transferRepo domain.TicketTransferRepository,
+	ticketRepo domain.TicketRepository,
+	serviceRepo domain.ServiceRepository,
+	customerRepo domain.CustomerRepository,
+	slotRepo domain.SlotRepository,
+	keyRepo domain.BusinessKeyRepository,
+	items *ItemUsecase,
+	events *TicketEventUsecase,
+	txManager domain.TxManager,
+) *TransferUsecase {
+	return &TransferUsecase{
+		transferRepo: transferRepo,
+		ticketRepo:   ticketRepo,
+		serviceRepo:  serviceRepo,
+		customerRepo: customerRepo,
+		slotRepo:     slotRepo,
+		keyRepo:      keyRepo,
+		items:        items,
+		events:       events,
+		txManager:    txManager,
+	}
+}
+
+// Request/Response types
+type StartTransferRequest struct {
+	ToEmail    string `json:"to_email,omitempty"`
+	ToPhone    string `json:"to_phone,omitempty"`
+	TicketID   string `json:"-"`
+	CustomerID string `json:"-"`
+}
+
+type TransferResponse struct {
+	ID             string `json:"id"`
+	TicketID       string `json:"ticket_id"`
+	FromCustomerID string `json:"from_customer_id"`
+	ToEmail        string `json:"to_email,omitempty"`
+	ToPhone        string `json:"to_phone,omitempty"`
+	ToCustomerID   string `json:"to_customer_id,omitempty"`
+	Status         string `json:"status"`
+	CreatedAt      int64  `json:"created_at"`
+	UpdatedAt      int64  `json:"updated_at"`
+}
+
+// AcceptTransferResponse carries the recipient's new QR code
+type AcceptTransferResponse struct {
+	CheckInResponse
+	Transfer TransferResponse `json:"transfer"`
+}
+
+// StartTransfer offers the customer's active ticket to another email or
+// phone number. A ticket has at most one pending transfer.
+func (u *TransferUsecase) StartTransfer(ctx context.Context, req StartTransferRequest) (*TransferResponse, error) {
+	req.ToEmail = strings.TrimSpace(req.ToEmail)
+	req.ToPhone = strings.TrimSpace(req.ToPhone)
+	if req.ToEmail == "" && req.ToPhone == "" {
+		return nil, apperror.NewValidationError("to_email or to_phone is required", map[string]string{})
+	}
+
+	// Stored the way login codes store phones, so the recipient matches
+	if req.ToPhone != "" {
+		phone := normalizePhone(req.ToPhone)
+		if phone == "" {
+			return nil, apperror.NewValidationError("to_phone must be a phone number like +15551234567", map[string]string{})
+		}
+		req.ToPhone = phone
+	}
+
+	ticket, err := u.ticketRepo.FindByID(ctx, req.TicketID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ticket.CustomerID != req.CustomerID {
+		return nil, apperror.NewForbidden("ticket does not belong to this customer")
+	}
+
+	if ticket.Status != domain.TicketStatusActive {
+		return nil, apperror.NewConflict("only active tickets can be transferred")
+	}
+
+	sender, err := u.customerRepo.FindByID(ctx, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if isRecipient(sender, req.ToEmail, req.ToPhone) {
+		return nil, apperror.NewValidationError("cannot transfer a ticket to yourself", map[string]string{})
+	}
+
+	now := domain.NowTimestamp()
+	transfer := &domain.TicketTransfer{
+		ID:             uuid.New().String(),
+		TicketID:       ticket.ID,
+		FromCustomerID: req.CustomerID,
+		ToEmail:        req.ToEmail,
+		ToPhone:        req.ToPhone,
+		Status:         domain.TransferStatusPending,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if err := u.transferRepo.Create(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	response := toTransferResponse(transfer)
+	return &response, nil
+}
+
+// ListTransfers lists a ticket's transfer history to its current owner or
+// the business that issued it
+func (u *TransferUsecase) ListTransfers(ctx context.Context, ticketID string, actor Actor) ([]TransferResponse, error) {
+	ticket, err := u.ticketRepo.FindByID(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	if actor.Role == "customer" {
+		if ticket.CustomerID != actor.UserID {
+			return nil, apperror.NewForbidden("ticket does not belong to this customer")
+		}
+	} else {
+		service, err := u.serviceRepo.FindByID(ctx, ticket.ServiceID)
+		if err != nil {
+			return nil, err
+		}
+		if service.BusinessID != actor.UserID {
+			return nil, apperror.NewForbidden("ticket does not belong to this business")
+		}
+	}
+
+	transfers, err := u.transferRepo.ListByTicketID(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toTransferResponses(transfers), nil
+}
+
+// ListIncoming lists the pending transfers addressed to a customer's email
+// or phone number
+func (u *TransferUsecase) ListIncoming(ctx context.Context, customerID string) ([]TransferResponse, error) {
+	customer, err := u.customerRepo.FindByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	transfers, err := u.transferRepo.ListPendingForRecipient(ctx, customer.Email, verifiedPhone(customer))
+	if err != nil {
+		return nil, err
+	}
+
+	return toTransferResponses(transfers), nil
+}
+
+// AcceptTransfer moves the ticket to the recipient and issues a new QR code
+// for it. The sender's code is revoked in the same unit of work.
+func (u *TransferUsecase) AcceptTransfer(ctx context.Context, transferID, customerID string) (*AcceptTransferResponse, error) {
+	transfer, err := u.transferRepo.FindByID(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	recipient, err := u.customerRepo.FindByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isRecipient(recipient, transfer.ToEmail, transfer.ToPhone) {
+		return nil, apperror.NewForbidden("ticket transfer is not addressed to this customer")
+	}
+
+	if transfer.Status != domain.TransferStatusPending {
+		return nil, apperror.NewConflict("ticket transfer is no longer pending")
+	}
+
+	ticket, err := u.ticketRepo.FindByID(ctx, transfer.TicketID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ticket.Status != domain.TicketStatusActive || ticket.CustomerID != transfer.FromCustomerID {
+		return nil, apperror.NewConflict("ticket is no longer active or changed owner")
+	}
+
+	service, err := u.serviceRepo.FindByID(ctx, ticket.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := activeSigningKey(ctx, u.keyRepo, service.BusinessID, domain.KeyAlgorithmEd25519)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := qr.NewV2(ticket.ID, ticket.ServiceID, service.BusinessID, ticket.SlotNumber, key.ID)
+	payload.Generation = ticket.QRGeneration + 1
+	encoded, err := signPayload(payload, key)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer.Status = domain.TransferStatusAccepted
+	transfer.ToCustomerID = recipient.ID
+	transfer.RevokedDigest = ticket.HMACDigest
+	transfer.UpdatedAt = domain.NowTimestamp()
+
+	// Accepting, changing owner and swapping the digest happen together; a
+	// concurrent reissue or release fails the conditional ticket updates
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.transferRepo.Update(ctx, transfer, domain.TransferStatusPending); err != nil {
+			return err
+		}
+		if err := u.ticketRepo.UpdateCustomer(ctx, ticket.ID, transfer.FromCustomerID, recipient.ID); err != nil {
+			return err
+		}
+		if err := u.ticketRepo.UpdateDigest(ctx, ticket.ID, ticket.HMACDigest, payload.Digest(), payload.Generation); err != nil {
+			return err
+		}
+		return u.events.record(ctx, ticket, service.BusinessID, domain.TicketEventReissued, EventActor{Type: domain.ActorTypeCustomer, ID: recipient.ID}, map[string]interface{}{
+			"reason":           "transferred",
+			"transfer_id":      transfer.ID,
+			"from_customer_id": transfer.FromCustomerID,
+			"to_customer_id":   recipient.ID,
+			"qr_generation":    payload.Generation,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := u.items.listItems(ctx, ticket.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	zoneID := ""
+	if ticket.SlotID != "" {
+		slot, err := u.slotRepo.FindByID(ctx, ticket.SlotID)
+		if err != nil {
+			return nil, err
+		}
+		zoneID = slot.ZoneID
+	}
+
+	return &AcceptTransferResponse{
+		CheckInResponse: CheckInResponse{
+			TicketID:   ticket.ID,
+			SlotNumber: ticket.SlotNumber,
+			ZoneID:     zoneID,
+			QRPayload:  encoded,
+			IssuedAt:   payload.IssuedAt,
+			Items:      items,
+		},
+		Transfer: toTransferResponse(transfer),
+	}, nil
+}
+
+// CancelTransfer withdraws a pending transfer when called by the sender and
+// declines it when called by the recipient
+func (u *TransferUsecase) CancelTransfer(ctx context.Context, transferID, customerID string) error {
+	transfer, err := u.transferRepo.FindByID(ctx, transferID)
+	if err != nil {
+		return err
+	}
+
+	status := domain.TransferStatusCancelled
+	if transfer.FromCustomerID != customerID {
+		customer, err := u.customerRepo.FindByID(ctx, customerID)
+		if err != nil {
+			return err
+		}
+		if !isRecipient(customer, transfer.ToEmail, transfer.ToPhone) {
+			return apperror.NewForbidden("ticket transfer does not involve this customer")
+		}
+		status = domain.TransferStatusDeclined
+	}
+
+	transfer.Status = status
+	transfer.UpdatedAt = domain.NowTimestamp()
+
+	return u.transferRepo.Update(ctx, transfer, domain.TransferStatusPending)
+}
+
+// revocation returns the accepted transfer that revoked a signature digest,
+// or nil if no transfer revoked it
+func (u *TransferUsecase) revocation(ctx context.Context, digest string) (*domain.TicketTransfer, error) {
+	transfer, err := u.transferRepo.FindByRevokedDigest(ctx, digest)
+	if apperror.IsNotFound(err) {
+		return nil, nil
+	}
+
+	return transfer, err
+}
+
+// isRecipient reports whether a transfer addressed to email or phone is meant
+// for the customer. Emails compare case-insensitively.
+func isRecipient(customer *domain.Customer, email, phone string) bool {
+	if email != "" && strings.EqualFold(customer.Email, email) {
+		return true
+	}
+	return phone != "" && verifiedPhone(customer) == phone
+}
+
+// verifiedPhone returns the customer's phone number if a login code proved
+// it. A phone given alongside an email at sign-in is never checked, so it
+// must not unlock transfers addressed to that number.
+func verifiedPhone(customer *domain.Customer) string {
+	if customer.PhoneVerifiedAt == 0 {
+		return ""
+	}
+	return customer.Phone
+}
+
+func toTransferResponse(transfer *domain.TicketTransfer) TransferResponse {
+	return TransferResponse{
+		ID:             transfer.ID,
+		TicketID:       transfer.TicketID,
+		FromCustomerID: transfer.FromCustomerID,
+		ToEmail:        transfer.ToEmail,
+		ToPhone:        transfer.ToPhone,
+		ToCustomerID:   transfer.ToCustomerID,
+		Status:         transfer.Status,
+		CreatedAt:      transfer.CreatedAt,
+		UpdatedAt:      transfer.UpdatedAt,
+	}
+}
+
+func toTransferResponses(transfers []domain.TicketTransfer) []TransferResponse {
+	responses := make([]TransferResponse, len(transfers))
+	for i, transfer := range transfers {
+		responses[i] = toTransferResponse(&transfer)
+	}
+	return responses
+}
@@ -0,0 +1,422 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// waitlistLapseBatchSize is how many expired offers are loaded at a time
+const waitlistLapseBatchSize = 100
+
+// WaitlistUsecase queues customers for full services and hands released
+// slots to the head of the queue
+type WaitlistUsecase struct {
+	waitlistRepo domain.WaitlistRepository
+	serviceRepo  domain.ServiceRepository
+	zoneRepo     domain.ZoneRepository
+	slotRepo     domain.SlotRepository
+	txManager    domain.TxManager
+	offerWindow  time.Duration
+}
+
+// NewWaitlistUsecase creates a new waitlist usecase. A released slot stays
+// reserved for the head of the queue for offerWindow.
+func NewWaitlistUsecase(
+	waitlistRepo domain.WaitlistRepository,
+	serviceRepo domain.ServiceRepository,
+	zoneRepo domain.ZoneRepository,
+	slotRepo domain.SlotRepository,
+	txManager domain.TxManager,
+	offerWindow time.Duration,
+) *WaitlistUsecase {
+	return &WaitlistUsecase{
+		waitlistRepo: waitlistRepo,
+		serviceRepo:  serviceRepo,
+		zoneRepo:     zoneRepo,
+		slotRepo:     slotRepo,
+		txManager:    txManager,
+		offerWindow:  offerWindow,
+	}
+}
+
+// Request/Response types
+type JoinWaitlistRequest struct {
+	ServiceID  string `json:"service_id"`
+	ZoneID     string `json:"zone_id,omitempty"`     // "" = any zone
+	Name       string `json:"name,omitempty"`        // Walk-ins added by staff
+	Phone      string `json:"phone,omitempty"`       // Walk-ins added by staff
+	CustomerID string `json:"customer_id,omitempty"` // Set from the token for customers
+	BusinessID string `json:"-"`                     // Set when staff add the entry
+}
+
+type WaitlistResponse struct {
+	ID             string `json:"id"`
+	ServiceID      string `json:"service_id"`
+	ZoneID         string `json:"zone_id,omitempty"`
+	CustomerID     string `json:"customer_id,omitempty"`
+	Name           string `json:"name,omitempty"`
+	Phone          string `json:"phone,omitempty"`
+	Status         string `json:"status"`
+	Position       int    `json:"position,omitempty"` // 1 = next in line; only while waiting
+	SlotNumber     int    `json:"slot_number,omitempty"`
+	OfferExpiresAt int64  `json:"offer_expires_at,omitempty"`
+	TicketID       string `json:"ticket_id,omitempty"`
+	CreatedAt      int64  `json:"created_at"`
+}
+
+// Actor identifies who is reading or changing a customer-owned resource
+// such as a waitlist entry or slot hold
+type Actor struct {
+	UserID string
+	Role   string // "business" or "customer"
+}
+
+// Join adds an entry to a service's queue. Customers join for themselves;
+// businesses can add walk-ins by name and phone to their own services.
+func (u *WaitlistUsecase) Join(ctx context.Context, req JoinWaitlistRequest) (*WaitlistResponse, error) {
+	if req.ServiceID == "" {
+		return nil, apperror.NewValidationError("service_id is required", map[string]string{})
+	}
+
+	if req.CustomerID == "" && req.Name == "" {
+		return nil, apperror.NewValidationError("name is required for walk-in entries", map[string]string{})
+	}
+
+	service, err := u.serviceRepo.FindByID(ctx, req.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.BusinessID != "" && service.BusinessID != req.BusinessID {
+		return nil, apperror.NewForbidden("service does not belong to this business")
+	}
+
+	if service.IsArchived() {
+		return nil, apperror.NewConflict("service is archived")
+	}
+	if service.IsClosed(domain.NowTimestamp()) {
+		return nil, apperror.NewConflict("service is closed")
+	}
+
+	if req.ZoneID != "" {
+		zone, err := u.zoneRepo.FindByID(ctx, req.ZoneID)
+		if err != nil {
+			return nil, err
+		}
+		if zone.ServiceID != service.ID {
+			return nil, apperror.NewNotFound("zone")
+		}
+	}
+
+	now := domain.NowTimestamp()
+	entry := &domain.WaitlistEntry{
+		ID:         uuid.New().String(),
+		ServiceID:  service.ID,
+		ZoneID:     req.ZoneID,
+		CustomerID: req.CustomerID,
+		Name:       req.Name,
+		Phone:      req.Phone,
+		Status:     domain.WaitlistStatusWaiting,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := u.waitlistRepo.Create(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return u.toResponse(ctx, entry)
+}
+
+// GetEntry returns an entry with its current queue position
+func (u *WaitlistUsecase) GetEntry(ctx context.Context, entryID string, actor Actor) (*WaitlistResponse, error) {
+	entry, err := u.authorizedEntry(ctx, entryID, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.toResponse(ctx, entry)
+}
+
+// ListWaitlist lists the open entries of a service in queue order
+func (u *WaitlistUsecase) ListWaitlist(ctx context.Context, serviceID, businessID string) ([]WaitlistResponse, error) {
+	service, err := u.serviceRepo.FindByID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if service.BusinessID != businessID {
+		return nil, apperror.NewForbidden("service does not belong to this business")
+	}
+
+	entries, err := u.waitlistRepo.ListOpenByServiceID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]WaitlistResponse, len(entries))
+	position := 0
+	for i, entry := range entries {
+		responses[i] = toWaitlistResponse(&entry)
+		if entry.Status == domain.WaitlistStatusWaiting {
+			position++
+			responses[i].Position = position
+		}
+	}
+
+	return responses, nil
+}
+
+// Leave removes an entry from the queue. A slot already reserved for it is
+// passed on to the next entry.
+func (u *WaitlistUsecase) Leave(ctx context.Context, entryID string, actor Actor) error {
+	entry, err := u.authorizedEntry(ctx, entryID, actor)
+	if err != nil {
+		return err
+	}
+
+	if !entry.IsOpen() {
+		return apperror.NewConflict("waitlist entry is already closed")
+	}
+
+	return u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return u.close(ctx, entry, domain.WaitlistStatusCancelled)
+	})
+}
+
+// LapseOffers closes offers that were not taken up in time and passes their
+// slots on. It returns how many offers lapsed.
+func (u *WaitlistUsecase) LapseOffers(ctx context.Context) (int, error) {
+	lapsed := 0
+
+	for {
+		entries, err := u.waitlistRepo.ListExpiredOffers(ctx, domain.NowTimestamp(), waitlistLapseBatchSize)
+		if err != nil {
+			return lapsed, err
+		}
+
+		progressed := false
+		for _, entry := range entries {
+			err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+				return u.close(ctx, &entry, domain.WaitlistStatusLapsed)
+			})
+			if apperror.IsConflict(err) {
+				// Checked in or cancelled concurrently
+				progressed = true
+				continue
+			}
+			if err != nil {
+				return lapsed, err
+			}
+
+			progressed = true
+			lapsed++
+		}
+
+		if len(entries) < waitlistLapseBatchSize || !progressed {
+			return lapsed, nil
+		}
+	}
+}
+
+// openEntry returns a customer's waiting or offered entry for a service, or
+// nil if they are not queued
+func (u *WaitlistUsecase) openEntry(ctx context.Context, serviceID, customerID string) (*domain.WaitlistEntry, error) {
+	if customerID == "" {
+		return nil, nil
+	}
+
+	entry, err := u.waitlistRepo.FindOpenByCustomer(ctx, serviceID, customerID)
+	if apperror.IsNotFound(err) {
+		return nil, nil
+	}
+
+	return entry, err
+}
+
+// releaseSlot frees a slot, or reserves it for the first waiting entry that
+// accepts its zone. It must run inside the caller's unit of work.
+func (u *WaitlistUsecase) releaseSlot(ctx context.Context, serviceID, slotID string) error {
+	slot, err := u.slotRepo.FindByID(ctx, slotID)
+	if err != nil {
+		return err
+	}
+
+	entry, err := u.waitlistRepo.LockNextWaiting(ctx, serviceID, slot.ZoneID)
+	if apperror.IsNotFound(err) {
+		return u.slotRepo.UpdateStatus(ctx, slot.ID, domain.SlotStatusFree)
+	}
+	if err != nil {
+		return err
+	}
+
+	now := domain.NowTimestamp()
+	entry.Status = domain.WaitlistStatusOffered
+	entry.SlotID = slot.ID
+	entry.SlotNumber = slot.SlotNumber
+	entry.OfferedAt = now
+	entry.OfferExpiresAt = now + int64(u.offerWindow/time.Second)
+	entry.UpdatedAt = now
+
+	if err := u.waitlistRepo.Update(ctx, entry, domain.WaitlistStatusWaiting); err != nil {
+		return err
+	}
+
+	return u.slotRepo.UpdateStatus(ctx, slot.ID, domain.SlotStatusReserved)
+}
+
+// claimOffer turns the slot reserved for an entry into an occupied slot for
+// ticketID. It must run inside the caller's unit of work.
+func (u *WaitlistUsecase) claimOffer(ctx context.Context, entryID, serviceID, ticketID string) (*domain.Slot, error) {
+	entry, err := u.waitlistRepo.FindByID(ctx, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.ServiceID != serviceID {
+		return nil, apperror.NewNotFound("waitlist entry")
+	}
+
+	if entry.Status != domain.WaitlistStatusOffered {
+		return nil, apperror.NewConflict("waitlist entry has no slot reserved")
+	}
+
+	now := domain.NowTimestamp()
+	if entry.OfferExpiresAt <= now {
+		return nil, apperror.NewConflict("slot reservation has expired")
+	}
+
+	entry.Status = domain.WaitlistStatusFulfilled
+	entry.TicketID = ticketID
+	entry.UpdatedAt = now
+
+	if err := u.waitlistRepo.Update(ctx, entry, domain.WaitlistStatusOffered); err != nil {
+		return nil, err
+	}
+
+	if err := u.slotRepo.UpdateStatus(ctx, entry.SlotID, domain.SlotStatusOccupied); err != nil {
+		return nil, err
+	}
+
+	return u.slotRepo.FindByID(ctx, entry.SlotID)
+}
+
+// cancelService cancels the open entries of a service that is closing for
+// good and frees the slots reserved for them instead of passing them on. It
+// must run inside the caller's unit of work.
+func (u *WaitlistUsecase) cancelService(ctx context.Context, serviceID string) error {
+	entries, err := u.waitlistRepo.ListOpenByServiceID(ctx, serviceID)
+	if err != nil {
+		return err
+	}
+
+	now := domain.NowTimestamp()
+	for _, entry := range entries {
+		fromStatus := entry.Status
+		entry.Status = domain.WaitlistStatusCancelled
+		entry.UpdatedAt = now
+
+		err := u.waitlistRepo.Update(ctx, &entry, fromStatus)
+		if apperror.IsConflict(err) {
+			// Lapsed concurrently
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if fromStatus == domain.WaitlistStatusOffered && entry.SlotID != "" {
+			if err := u.slotRepo.UpdateStatus(ctx, entry.SlotID, domain.SlotStatusFree); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// close moves an open entry to status and passes on any slot reserved for it
+func (u *WaitlistUsecase) close(ctx context.Context, entry *domain.WaitlistEntry, status string) error {
+	fromStatus := entry.Status
+	slotID := entry.SlotID
+
+	entry.Status = status
+	entry.UpdatedAt = domain.NowTimestamp()
+
+	if err := u.waitlistRepo.Update(ctx, entry, fromStatus); err != nil {
+		return err
+	}
+
+	if fromStatus == domain.WaitlistStatusOffered && slotID != "" {
+		return u.releaseSlot(ctx, entry.ServiceID, slotID)
+	}
+
+	return nil
+}
+
+// authorizedEntry loads an entry the actor may see: customers their own,
+// businesses those of their services
+func (u *WaitlistUsecase) authorizedEntry(ctx context.Context, entryID string, actor Actor) (*domain.WaitlistEntry, error) {
+	entry, err := u.waitlistRepo.FindByID(ctx, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if actor.Role == "customer" {
+		if entry.CustomerID != actor.UserID {
+			return nil, apperror.NewForbidden("waitlist entry does not belong to this customer")
+		}
+		return entry, nil
+	}
+
+	service, err := u.serviceRepo.FindByID(ctx, entry.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if service.BusinessID != actor.UserID {
+		return nil, apperror.NewForbidden("waitlist entry does not belong to this business")
+	}
+
+	return entry, nil
+}
+
+// toResponse converts an entry, computing its position among waiting entries
+func (u *WaitlistUsecase) toResponse(ctx context.Context, entry *domain.WaitlistEntry) (*WaitlistResponse, error) {
+	response := toWaitlistResponse(entry)
+
+	if entry.Status == domain.WaitlistStatusWaiting {
+		entries, err := u.waitlistRepo.ListOpenByServiceID(ctx, entry.ServiceID)
+		if err != nil {
+			return nil, err
+		}
+		for _, other := range entries {
+			if other.Status == domain.WaitlistStatusWaiting && other.Seq <= entry.Seq {
+				response.Position++
+			}
+		}
+	}
+
+	return &response, nil
+}
+
+func toWaitlistResponse(entry *domain.WaitlistEntry) WaitlistResponse {
+	return WaitlistResponse{
+		ID:             entry.ID,
+		ServiceID:      entry.ServiceID,
+		ZoneID:         entry.ZoneID,
+		CustomerID:     entry.CustomerID,
+		Name:           entry.Name,
+		Phone:          entry.Phone,
+		Status:         entry.Status,
+		SlotNumber:     entry.SlotNumber,
+		OfferExpiresAt: entry.OfferExpiresAt,
+		TicketID:       entry.TicketID,
+		CreatedAt:      entry.CreatedAt,
+	}
+}
@@ -0,0 +1,229 @@
+package usecase
+
+import (
+	"context"
+
+	"CLOAKBE/internal/apperror"
+	"CLOAKBE/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// ZoneUsecase manages the zones of a service and keeps their slots in step
+// with their capacity
+type ZoneUsecase struct {
+	zoneRepo    domain.ZoneRepository
+	serviceRepo domain.ServiceRepository
+	slotRepo    domain.SlotRepository
+	txManager   domain.TxManager
+}
+
+// NewZoneUsecase creates a new zone usecase
+func NewZoneUsecase(
+	zoneRepo domain.ZoneRepository,
+	serviceRepo domain.ServiceRepository,
+	slotRepo domain.SlotRepository,
+	txManager domain.TxManager,
+) *ZoneUsecase {
+	return &ZoneUsecase{
+		zoneRepo:    zoneRepo,
+		serviceRepo: serviceRepo,
+		slotRepo:    slotRepo,
+		txManager:   txManager,
+	}
+}
+
+// Request/Response types
+type CreateZoneRequest struct {
+	Name       string `json:"name"`
+	Capacity   int    `json:"capacity"`
+	ServiceID  string `json:"-"`
+	BusinessID string `json:"-"`
+}
+
+// UpdateZoneRequest changes only the fields that are present
+type UpdateZoneRequest struct {
+	Name       *string `json:"name"`
+	Capacity   *int    `json:"capacity"`
+	ZoneID     string  `json:"-"`
+	ServiceID  string  `json:"-"`
+	BusinessID string  `json:"-"`
+}
+
+type ZoneResponse struct {
+	ID        string `json:"id"`
+	ServiceID string `json:"service_id"`
+	Name      string `json:"name"`
+	Capacity  int    `json:"capacity"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+// ListZones lists the zones of a service
+func (u *ZoneUsecase) ListZones(ctx context.Context, serviceID, businessID string) ([]ZoneResponse, error) {
+	service, err := u.serviceRepo.FindByID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if service.BusinessID != businessID {
+		return nil, apperror.NewForbidden("service does not belong to this business")
+	}
+
+	zones, err := u.zoneRepo.ListByServiceID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]ZoneResponse, len(zones))
+	for i, zone := range zones {
+		responses[i] = *toZoneResponse(&zone)
+	}
+
+	return responses, nil
+}
+
+// CreateZone adds a zone to a service and generates its slots, numbered
+// after the service's current highest slot
+func (u *ZoneUsecase) CreateZone(ctx context.Context, req CreateZoneRequest) (*ZoneResponse, error) {
+	if req.Name == "" || req.Capacity <= 0 {
+		return nil, apperror.NewValidationError("name and a positive capacity are required", map[string]string{})
+	}
+
+	var zone *domain.Zone
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		service, err := u.lockService(ctx, req.ServiceID, req.BusinessID)
+		if err != nil {
+			return err
+		}
+
+		highest, err := highestSlotNumber(ctx, u.slotRepo, service.ID)
+		if err != nil {
+			return err
+		}
+
+		now := domain.NowTimestamp()
+		zone = newZone(service.ID, req.Name, req.Capacity, now)
+
+		if err := u.zoneRepo.Create(ctx, zone); err != nil {
+			return err
+		}
+
+		if err := u.slotRepo.CreateBatch(ctx, newSlots(service.ID, zone.ID, highest+1, highest+zone.Capacity, now)); err != nil {
+			return err
+		}
+
+		service.TotalSlots += zone.Capacity
+		service.UpdatedAt = now
+		return u.serviceRepo.Update(ctx, service)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toZoneResponse(zone), nil
+}
+
+// UpdateZone renames and resizes a zone. Growing appends slots after the
+// service's highest slot number; shrinking removes the zone's highest
+// numbered slots and fails with a conflict if any of them is in use.
+func (u *ZoneUsecase) UpdateZone(ctx context.Context, req UpdateZoneRequest) (*ZoneResponse, error) {
+	if req.Name != nil && *req.Name == "" {
+		return nil, apperror.NewValidationError("name cannot be empty", map[string]string{})
+	}
+
+	if req.Capacity != nil && *req.Capacity <= 0 {
+		return nil, apperror.NewValidationError("capacity must be positive", map[string]string{})
+	}
+
+	var zone *domain.Zone
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		service, err := u.lockService(ctx, req.ServiceID, req.BusinessID)
+		if err != nil {
+			return err
+		}
+
+		zone, err = u.zoneRepo.FindByID(ctx, req.ZoneID)
+		if err != nil {
+			return err
+		}
+
+		if zone.ServiceID != service.ID {
+			return apperror.NewNotFound("zone")
+		}
+
+		now := domain.NowTimestamp()
+
+		if req.Capacity != nil && *req.Capacity != zone.Capacity {
+			if *req.Capacity < zone.Capacity {
+				if err := u.slotRepo.DeleteZoneSlots(ctx, zone.ID, *req.Capacity); err != nil {
+					return err
+				}
+			} else {
+				highest, err := highestSlotNumber(ctx, u.slotRepo, service.ID)
+				if err != nil {
+					return err
+				}
+				added := *req.Capacity - zone.Capacity
+				if err := u.slotRepo.CreateBatch(ctx, newSlots(service.ID, zone.ID, highest+1, highest+added, now)); err != nil {
+					return err
+				}
+			}
+
+			service.TotalSlots += *req.Capacity - zone.Capacity
+			service.UpdatedAt = now
+			if err := u.serviceRepo.Update(ctx, service); err != nil {
+				return err
+			}
+			zone.Capacity = *req.Capacity
+		}
+
+		if req.Name != nil {
+			zone.Name = *req.Name
+		}
+		zone.UpdatedAt = now
+
+		return u.zoneRepo.Update(ctx, zone)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toZoneResponse(zone), nil
+}
+
+// lockService locks a service owned by businessID for the rest of the unit of work
+func (u *ZoneUsecase) lockService(ctx context.Context, serviceID, businessID string) (*domain.Service, error) {
+	service, err := u.serviceRepo.LockByID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if service.BusinessID != businessID {
+		return nil, apperror.NewForbidden("service does not belong to this business")
+	}
+
+	return service, nil
+}
+
+func newZone(serviceID, name string, capacity int, now int64) *domain.Zone {
+	return &domain.Zone{
+		ID:        uuid.New().String(),
+		ServiceID: serviceID,
+		Name:      name,
+		Capacity:  capacity,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func toZoneResponse(zone *domain.Zone) *ZoneResponse {
+	return &ZoneResponse{
+		ID:        zone.ID,
+		ServiceID: zone.ServiceID,
+		Name:      zone.Name,
+		Capacity:  zone.Capacity,
+		CreatedAt: zone.CreatedAt,
+		UpdatedAt: zone.UpdatedAt,
+	}
+}
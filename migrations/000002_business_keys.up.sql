@@ -0,0 +1,21 @@
+-- Create business_keys table (QR signing keyring)
+CREATE TABLE IF NOT EXISTS business_keys (
+    id VARCHAR(36) PRIMARY KEY,
+    business_id VARCHAR(36) NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    secret VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL CHECK (status IN ('active', 'retired')),
+    created_at BIGINT NOT NULL,
+    retired_at BIGINT,
+    expires_at BIGINT,
+    created_at_ts TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_business_keys_business_id ON business_keys(business_id);
+
+-- At most one active signing key per business
+CREATE UNIQUE INDEX IF NOT EXISTS idx_business_keys_one_active ON business_keys(business_id) WHERE status = 'active';
+
+-- Existing tickets carry no kid; their original key is stored under the business ID
+INSERT INTO business_keys (id, business_id, secret, status, created_at)
+SELECT id, id, hmac_key, 'active', created_at FROM businesses
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,8 @@
+DELETE FROM business_keys WHERE algorithm = 'ed25519';
+
+DROP INDEX IF EXISTS idx_business_keys_one_active;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_business_keys_one_active ON business_keys(business_id) WHERE status = 'active';
+
+ALTER TABLE business_keys
+    DROP COLUMN IF EXISTS public_key,
+    DROP COLUMN IF EXISTS algorithm;
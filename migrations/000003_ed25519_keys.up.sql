@@ -0,0 +1,8 @@
+-- Business keys can now be HMAC secrets (v1 QR) or Ed25519 key pairs (v2 QR)
+ALTER TABLE business_keys
+    ADD COLUMN IF NOT EXISTS algorithm VARCHAR(50) NOT NULL DEFAULT 'hmac-sha256' CHECK (algorithm IN ('hmac-sha256', 'ed25519')),
+    ADD COLUMN IF NOT EXISTS public_key VARCHAR(255);
+
+-- At most one active signing key per business and algorithm
+DROP INDEX IF EXISTS idx_business_keys_one_active;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_business_keys_one_active ON business_keys(business_id, algorithm) WHERE status = 'active';
@@ -0,0 +1,16 @@
+-- Create sync_events table (scanner device events replayed by the server)
+CREATE TABLE IF NOT EXISTS sync_events (
+    id VARCHAR(36) PRIMARY KEY,
+    business_id VARCHAR(36) NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    device_id VARCHAR(255) NOT NULL,
+    type VARCHAR(50) NOT NULL CHECK (type IN ('scan', 'release')),
+    ticket_id VARCHAR(36), -- not a foreign key: rejected events may name unknown tickets
+    occurred_at BIGINT NOT NULL,
+    received_at BIGINT NOT NULL,
+    result VARCHAR(50) NOT NULL CHECK (result IN ('applied', 'conflict', 'rejected')),
+    message TEXT,
+    created_at_ts TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_events_business_id ON sync_events(business_id);
+CREATE INDEX IF NOT EXISTS idx_sync_events_ticket_id ON sync_events(ticket_id);
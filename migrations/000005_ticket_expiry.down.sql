@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_tickets_status_issued_at;
+
+UPDATE tickets SET status = 'released', released_at = expired_at WHERE status = 'expired';
+
+ALTER TABLE tickets
+    DROP COLUMN IF EXISTS expiry_reason,
+    DROP COLUMN IF EXISTS expired_at;
+
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_status_check;
+ALTER TABLE tickets ADD CONSTRAINT tickets_status_check CHECK (status IN ('active', 'released'));
+
+ALTER TABLE services
+    DROP COLUMN IF EXISTS closes_at,
+    DROP COLUMN IF EXISTS ticket_ttl_seconds;
@@ -0,0 +1,14 @@
+-- Per-service ticket TTL and closing time (0 = disabled)
+ALTER TABLE services
+    ADD COLUMN IF NOT EXISTS ticket_ttl_seconds BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS closes_at BIGINT NOT NULL DEFAULT 0;
+
+-- Tickets can now expire
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_status_check;
+ALTER TABLE tickets ADD CONSTRAINT tickets_status_check CHECK (status IN ('active', 'released', 'expired'));
+
+ALTER TABLE tickets
+    ADD COLUMN IF NOT EXISTS expired_at BIGINT,
+    ADD COLUMN IF NOT EXISTS expiry_reason VARCHAR(50);
+
+CREATE INDEX IF NOT EXISTS idx_tickets_status_issued_at ON tickets(status, issued_at);
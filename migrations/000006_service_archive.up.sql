@@ -0,0 +1,3 @@
+-- Archived services are hidden from listings and closed to new check-ins (0 = not archived)
+ALTER TABLE services
+    ADD COLUMN IF NOT EXISTS archived_at BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_slots_zone_status;
+
+ALTER TABLE slots DROP COLUMN IF EXISTS zone_id;
+
+DROP TABLE IF EXISTS zones;
@@ -0,0 +1,19 @@
+-- Zones split a service into areas with their own capacity
+CREATE TABLE IF NOT EXISTS zones (
+    id VARCHAR(36) PRIMARY KEY,
+    service_id VARCHAR(36) NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    capacity INT NOT NULL CHECK (capacity > 0),
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    created_at_ts TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(service_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_zones_service_id ON zones(service_id);
+
+-- Slots of services without zones keep a NULL zone
+ALTER TABLE slots
+    ADD COLUMN IF NOT EXISTS zone_id VARCHAR(36) REFERENCES zones(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_slots_zone_status ON slots(zone_id, status);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS waitlist_entries;
+
+UPDATE slots SET status = 'free' WHERE status = 'reserved';
+
+ALTER TABLE slots DROP CONSTRAINT IF EXISTS slots_status_check;
+ALTER TABLE slots ADD CONSTRAINT slots_status_check CHECK (status IN ('free', 'occupied'));
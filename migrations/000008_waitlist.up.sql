@@ -0,0 +1,31 @@
+-- Slots can be reserved for the head of a service's waitlist
+ALTER TABLE slots DROP CONSTRAINT IF EXISTS slots_status_check;
+ALTER TABLE slots ADD CONSTRAINT slots_status_check CHECK (status IN ('free', 'occupied', 'reserved'));
+
+-- Create waitlist_entries table (per-service queue for full services)
+CREATE TABLE IF NOT EXISTS waitlist_entries (
+    id VARCHAR(36) PRIMARY KEY,
+    seq BIGSERIAL UNIQUE,
+    service_id VARCHAR(36) NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    zone_id VARCHAR(36) REFERENCES zones(id) ON DELETE CASCADE,
+    customer_id VARCHAR(36) REFERENCES customers(id) ON DELETE CASCADE,
+    name VARCHAR(255),
+    phone VARCHAR(20),
+    status VARCHAR(50) NOT NULL CHECK (status IN ('waiting', 'offered', 'fulfilled', 'cancelled', 'lapsed')),
+    slot_id VARCHAR(36) REFERENCES slots(id) ON DELETE SET NULL,
+    slot_number INT,
+    offered_at BIGINT,
+    offer_expires_at BIGINT,
+    ticket_id VARCHAR(36), -- not a foreign key: set before the ticket row exists in the same transaction
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    created_at_ts TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_waitlist_service_status_seq ON waitlist_entries(service_id, status, seq);
+CREATE INDEX IF NOT EXISTS idx_waitlist_status_offer_expires ON waitlist_entries(status, offer_expires_at);
+
+-- A customer holds at most one open place per service
+CREATE UNIQUE INDEX IF NOT EXISTS idx_waitlist_open_customer
+    ON waitlist_entries(service_id, customer_id)
+    WHERE status IN ('waiting', 'offered');
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS slot_holds;
+
+UPDATE slots SET status = 'free' WHERE status = 'held';
+
+ALTER TABLE slots DROP CONSTRAINT IF EXISTS slots_status_check;
+ALTER TABLE slots ADD CONSTRAINT slots_status_check CHECK (status IN ('free', 'occupied', 'reserved'));
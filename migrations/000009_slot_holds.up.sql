@@ -0,0 +1,26 @@
+-- Slots can be held for a customer until staff confirm the hold into a ticket
+ALTER TABLE slots DROP CONSTRAINT IF EXISTS slots_status_check;
+ALTER TABLE slots ADD CONSTRAINT slots_status_check CHECK (status IN ('free', 'occupied', 'reserved', 'held'));
+
+-- Create slot_holds table (short-lived holds placed from the customer app)
+CREATE TABLE IF NOT EXISTS slot_holds (
+    id VARCHAR(36) PRIMARY KEY,
+    service_id VARCHAR(36) NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    slot_id VARCHAR(36) NOT NULL REFERENCES slots(id) ON DELETE CASCADE,
+    slot_number INT NOT NULL,
+    zone_id VARCHAR(36) REFERENCES zones(id) ON DELETE CASCADE,
+    customer_id VARCHAR(36) NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    status VARCHAR(50) NOT NULL CHECK (status IN ('active', 'confirmed', 'cancelled', 'expired')),
+    expires_at BIGINT NOT NULL,
+    ticket_id VARCHAR(36), -- not a foreign key: set before the ticket row exists in the same transaction
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    created_at_ts TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_slot_holds_status_expires ON slot_holds(status, expires_at);
+
+-- A customer holds at most one slot per service at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_slot_holds_active_customer
+    ON slot_holds(service_id, customer_id)
+    WHERE status = 'active';
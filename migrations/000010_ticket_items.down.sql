@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS ticket_items;
+DROP TABLE IF EXISTS item_types;
@@ -0,0 +1,25 @@
+-- Create item_types table (kinds of items a service accepts, optionally tied to a zone)
+CREATE TABLE IF NOT EXISTS item_types (
+    id VARCHAR(36) PRIMARY KEY,
+    service_id VARCHAR(36) NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    zone_id VARCHAR(36) REFERENCES zones(id) ON DELETE CASCADE,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    created_at_ts TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (service_id, name)
+);
+
+-- Create ticket_items table (what was handed in under a ticket)
+CREATE TABLE IF NOT EXISTS ticket_items (
+    id VARCHAR(36) PRIMARY KEY,
+    seq BIGSERIAL UNIQUE,
+    ticket_id VARCHAR(36) NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+    type VARCHAR(100) NOT NULL,
+    description TEXT,
+    count INT CHECK (count > 0),
+    created_at BIGINT NOT NULL,
+    created_at_ts TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_ticket_items_ticket_seq ON ticket_items(ticket_id, seq);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_tickets_service_slot;
+DROP INDEX IF EXISTS idx_customers_phone;
+DROP TABLE IF EXISTS ticket_reissues;
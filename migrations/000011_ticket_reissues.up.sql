@@ -0,0 +1,19 @@
+-- Create ticket_reissues table (lost tickets reissued with a new QR code).
+-- revoked_digest is the signature of the replaced QR code; scans of it are
+-- rejected for good.
+CREATE TABLE IF NOT EXISTS ticket_reissues (
+    id VARCHAR(36) PRIMARY KEY,
+    ticket_id VARCHAR(36) NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+    revoked_digest VARCHAR(255) NOT NULL UNIQUE,
+    identity_method VARCHAR(50) NOT NULL CHECK (identity_method IN ('id_document', 'account', 'item_description', 'other')),
+    identity_note TEXT,
+    reissued_by VARCHAR(36) NOT NULL,
+    created_at BIGINT NOT NULL,
+    created_at_ts TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_ticket_reissues_ticket_id ON ticket_reissues(ticket_id, created_at);
+
+-- Lost-ticket lookups by customer phone and by slot number
+CREATE INDEX IF NOT EXISTS idx_customers_phone ON customers(phone);
+CREATE INDEX IF NOT EXISTS idx_tickets_service_slot ON tickets(service_id, slot_number);
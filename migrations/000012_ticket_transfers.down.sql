@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS ticket_transfers;
+
+ALTER TABLE tickets DROP COLUMN IF EXISTS qr_generation;
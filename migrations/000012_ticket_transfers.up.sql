@@ -0,0 +1,31 @@
+-- Count QR code replacements per ticket; the count is signed into new codes
+-- so a replacement never reproduces a revoked signature
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS qr_generation INT NOT NULL DEFAULT 0;
+
+UPDATE tickets t
+SET qr_generation = (SELECT COUNT(*) FROM ticket_reissues r WHERE r.ticket_id = t.id);
+
+-- Create ticket_transfers table (customer-to-customer hand-overs)
+CREATE TABLE IF NOT EXISTS ticket_transfers (
+    id VARCHAR(36) PRIMARY KEY,
+    ticket_id VARCHAR(36) NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+    from_customer_id VARCHAR(36) NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    to_email VARCHAR(255),
+    to_phone VARCHAR(20),
+    to_customer_id VARCHAR(36) REFERENCES customers(id) ON DELETE SET NULL,
+    status VARCHAR(50) NOT NULL CHECK (status IN ('pending', 'accepted', 'declined', 'cancelled')),
+    revoked_digest VARCHAR(255) UNIQUE,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    created_at_ts TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    CHECK (to_email IS NOT NULL OR to_phone IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ticket_transfers_ticket_id ON ticket_transfers(ticket_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_ticket_transfers_pending_email ON ticket_transfers(LOWER(to_email)) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_ticket_transfers_pending_phone ON ticket_transfers(to_phone) WHERE status = 'pending';
+
+-- A ticket has at most one pending transfer
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ticket_transfers_one_pending
+    ON ticket_transfers(ticket_id)
+    WHERE status = 'pending';
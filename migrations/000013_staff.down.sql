@@ -0,0 +1,6 @@
+ALTER TABLE tickets DROP COLUMN IF EXISTS released_by;
+ALTER TABLE tickets DROP COLUMN IF EXISTS scanned_at;
+ALTER TABLE tickets DROP COLUMN IF EXISTS scanned_by;
+ALTER TABLE tickets DROP COLUMN IF EXISTS issued_by;
+
+DROP TABLE IF EXISTS staff;
@@ -0,0 +1,24 @@
+-- Create staff table (individual logins under a business)
+CREATE TABLE IF NOT EXISTS staff (
+    id VARCHAR(36) PRIMARY KEY,
+    business_id VARCHAR(36) NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    name VARCHAR(255),
+    role VARCHAR(50) NOT NULL CHECK (role IN ('owner', 'manager', 'attendant')),
+    status VARCHAR(50) NOT NULL CHECK (status IN ('invited', 'active', 'disabled')),
+    password VARCHAR(255),
+    invite_token_hash VARCHAR(64) UNIQUE,
+    invite_expires_at BIGINT,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    created_at_ts TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (business_id, email)
+);
+
+CREATE INDEX IF NOT EXISTS idx_staff_email ON staff(email);
+
+-- Record which staff member checked in, scanned and released each ticket
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS issued_by VARCHAR(36) REFERENCES staff(id) ON DELETE SET NULL;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS scanned_by VARCHAR(36) REFERENCES staff(id) ON DELETE SET NULL;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS scanned_at BIGINT;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS released_by VARCHAR(36) REFERENCES staff(id) ON DELETE SET NULL;
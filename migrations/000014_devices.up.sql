@@ -0,0 +1,16 @@
+-- Create devices table (scanner tablets with long-lived, scoped tokens)
+CREATE TABLE IF NOT EXISTS devices (
+    id VARCHAR(36) PRIMARY KEY,
+    business_id VARCHAR(36) NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    service_ids TEXT[] NOT NULL DEFAULT '{}', -- empty = all services of the business
+    actions TEXT[] NOT NULL,
+    last_seen_at BIGINT,
+    revoked_at BIGINT,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    created_at_ts TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_devices_business_id ON devices(business_id);
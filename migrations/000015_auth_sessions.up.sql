@@ -0,0 +1,29 @@
+-- Create auth_sessions table (one per login; revoking it kills its tokens)
+CREATE TABLE IF NOT EXISTS auth_sessions (
+    id VARCHAR(36) PRIMARY KEY,
+    subject VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    email VARCHAR(255),
+    role VARCHAR(50) NOT NULL,
+    business_id VARCHAR(36) REFERENCES businesses(id) ON DELETE CASCADE,
+    staff_id VARCHAR(36) REFERENCES staff(id) ON DELETE CASCADE,
+    staff_role VARCHAR(50),
+    revoked_at BIGINT,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    created_at_ts TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_sessions_subject ON auth_sessions(subject) WHERE revoked_at IS NULL;
+
+-- Create refresh_tokens table (the rotating token family of a session)
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id VARCHAR(36) PRIMARY KEY,
+    session_id VARCHAR(36) NOT NULL REFERENCES auth_sessions(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at BIGINT NOT NULL,
+    rotated_at BIGINT,
+    created_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens(session_id);
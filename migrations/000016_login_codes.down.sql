@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS login_codes;
+
+DROP INDEX IF EXISTS idx_customers_verified_phone;
+ALTER TABLE customers DROP COLUMN IF EXISTS phone_verified_at;
+DELETE FROM customers WHERE email IS NULL;
+ALTER TABLE customers ALTER COLUMN email SET NOT NULL;
@@ -0,0 +1,21 @@
+-- Customers may sign in by phone alone once a login code proved the phone
+ALTER TABLE customers ALTER COLUMN email DROP NOT NULL;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS phone_verified_at BIGINT;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_verified_phone ON customers(phone) WHERE phone_verified_at IS NOT NULL;
+
+-- Create login_codes table (one-time codes and magic links for customer login)
+CREATE TABLE IF NOT EXISTS login_codes (
+    id VARCHAR(36) PRIMARY KEY,
+    channel VARCHAR(10) NOT NULL CHECK (channel IN ('email', 'sms')),
+    destination VARCHAR(255) NOT NULL,
+    phone VARCHAR(20),
+    code_hash VARCHAR(64) NOT NULL,
+    link_hash VARCHAR(64) UNIQUE NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at BIGINT NOT NULL,
+    consumed_at BIGINT,
+    created_at BIGINT NOT NULL,
+    created_at_ts TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_codes_destination ON login_codes(destination, created_at);
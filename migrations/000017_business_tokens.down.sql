@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS business_tokens;
+
+ALTER TABLE businesses DROP COLUMN IF EXISTS email_verified_at;
@@ -0,0 +1,16 @@
+-- Businesses verify their email address by following a link sent to it
+ALTER TABLE businesses ADD COLUMN IF NOT EXISTS email_verified_at BIGINT;
+
+-- Create business_tokens table (single-use email verification and password reset tokens)
+CREATE TABLE IF NOT EXISTS business_tokens (
+    id VARCHAR(36) PRIMARY KEY,
+    business_id VARCHAR(36) NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    purpose VARCHAR(20) NOT NULL CHECK (purpose IN ('verify_email', 'reset_password')),
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at BIGINT NOT NULL,
+    used_at BIGINT,
+    created_at BIGINT NOT NULL,
+    created_at_ts TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_business_tokens_business ON business_tokens(business_id, purpose, created_at);
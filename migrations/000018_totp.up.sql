@@ -0,0 +1,40 @@
+-- Create totp_factors table (authenticator apps enrolled by businesses and staff)
+CREATE TABLE IF NOT EXISTS totp_factors (
+    subject_id VARCHAR(36) PRIMARY KEY,
+    secret VARCHAR(64) NOT NULL,
+    confirmed_at BIGINT,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    created_at_ts TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create recovery_codes table (one-time stand-ins for TOTP codes)
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id VARCHAR(36) PRIMARY KEY,
+    subject_id VARCHAR(36) NOT NULL REFERENCES totp_factors(subject_id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at BIGINT,
+    created_at BIGINT NOT NULL,
+    created_at_ts TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_subject ON recovery_codes(subject_id, code_hash);
+
+-- Create mfa_challenges table (logins waiting for their second factor)
+CREATE TABLE IF NOT EXISTS mfa_challenges (
+    id VARCHAR(36) PRIMARY KEY,
+    subject VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    email VARCHAR(255),
+    role VARCHAR(50) NOT NULL,
+    business_id VARCHAR(36),
+    staff_id VARCHAR(36),
+    staff_role VARCHAR(50),
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at BIGINT NOT NULL,
+    used_at BIGINT,
+    created_at BIGINT NOT NULL,
+    created_at_ts TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
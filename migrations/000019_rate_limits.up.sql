@@ -0,0 +1,18 @@
+-- Create rate_limits table (fixed-window request counters shared by every API instance)
+CREATE TABLE IF NOT EXISTS rate_limits (
+    key VARCHAR(255) PRIMARY KEY,
+    count INT NOT NULL,
+    reset_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limits_reset_at ON rate_limits(reset_at);
+
+-- Create login_lockouts table (bad password counts and lockouts per account)
+CREATE TABLE IF NOT EXISTS login_lockouts (
+    key VARCHAR(255) PRIMARY KEY,
+    failures INT NOT NULL,
+    locked_until BIGINT,
+    last_failure_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_lockouts_last_failure_at ON login_lockouts(last_failure_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS ticket_events;
+DROP FUNCTION IF EXISTS ticket_events_append_only();
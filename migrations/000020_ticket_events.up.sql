@@ -0,0 +1,35 @@
+-- Create ticket_events table (append-only ticket history). Business, service
+-- and ticket IDs are not foreign keys so the history outlives their deletion.
+CREATE TABLE IF NOT EXISTS ticket_events (
+    id VARCHAR(36) PRIMARY KEY,
+    seq BIGSERIAL UNIQUE,
+    ticket_id VARCHAR(36) NOT NULL,
+    business_id VARCHAR(36) NOT NULL,
+    service_id VARCHAR(36) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('issued', 'scanned', 'released', 'expired', 'reissued', 'voided')),
+    actor_type VARCHAR(20) NOT NULL CHECK (actor_type IN ('business', 'staff', 'device', 'customer', 'system')),
+    actor_id VARCHAR(36),
+    metadata JSONB NOT NULL DEFAULT '{}',
+    occurred_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ticket_events_ticket ON ticket_events(ticket_id, seq);
+CREATE INDEX IF NOT EXISTS idx_ticket_events_business ON ticket_events(business_id, seq);
+CREATE INDEX IF NOT EXISTS idx_ticket_events_service ON ticket_events(service_id, occurred_at);
+
+-- Events are history: refuse edits, deletes and truncation
+CREATE OR REPLACE FUNCTION ticket_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'ticket_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS ticket_events_no_update ON ticket_events;
+CREATE TRIGGER ticket_events_no_update
+    BEFORE UPDATE OR DELETE ON ticket_events
+    FOR EACH ROW EXECUTE FUNCTION ticket_events_append_only();
+
+DROP TRIGGER IF EXISTS ticket_events_no_truncate ON ticket_events;
+CREATE TRIGGER ticket_events_no_truncate
+    BEFORE TRUNCATE ON ticket_events
+    FOR EACH STATEMENT EXECUTE FUNCTION ticket_events_append_only();
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS scan_alerts;
+DROP TABLE IF EXISTS scan_attempts;
@@ -0,0 +1,37 @@
+-- Create scan_attempts table (every QR code presented to a scanner). Ticket
+-- and service IDs are not foreign keys: rejected codes may name tickets that
+-- never existed.
+CREATE TABLE IF NOT EXISTS scan_attempts (
+    id VARCHAR(36) PRIMARY KEY,
+    seq BIGSERIAL UNIQUE,
+    business_id VARCHAR(36) NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    service_id VARCHAR(36),
+    ticket_id VARCHAR(36),
+    outcome VARCHAR(20) NOT NULL CHECK (outcome IN ('valid', 'already_released', 'expired', 'bad_signature', 'wrong_business', 'wrong_service', 'unknown_ticket', 'revoked', 'malformed')),
+    qr_digest VARCHAR(255),
+    device_id VARCHAR(36),
+    staff_id VARCHAR(36),
+    attempted_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_scan_attempts_business ON scan_attempts(business_id, seq);
+CREATE INDEX IF NOT EXISTS idx_scan_attempts_ticket ON scan_attempts(ticket_id, attempted_at);
+CREATE INDEX IF NOT EXISTS idx_scan_attempts_scanner ON scan_attempts(business_id, outcome, device_id, staff_id, attempted_at);
+
+-- Create scan_alerts table (suspicious attempts flagged for review)
+CREATE TABLE IF NOT EXISTS scan_alerts (
+    id VARCHAR(36) PRIMARY KEY,
+    business_id VARCHAR(36) NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    rule VARCHAR(40) NOT NULL CHECK (rule IN ('released_ticket_rescanned', 'revoked_code_scanned', 'duplicate_scan', 'bad_signature_burst')),
+    attempt_id VARCHAR(36) NOT NULL REFERENCES scan_attempts(id) ON DELETE CASCADE,
+    ticket_id VARCHAR(36),
+    device_id VARCHAR(36),
+    staff_id VARCHAR(36),
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at BIGINT NOT NULL,
+    acknowledged_at BIGINT,
+    acknowledged_by VARCHAR(36)
+);
+
+CREATE INDEX IF NOT EXISTS idx_scan_alerts_business ON scan_alerts(business_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_scan_alerts_open ON scan_alerts(business_id) WHERE acknowledged_at IS NULL;
@@ -0,0 +1,5 @@
+-- Fails if two businesses have recorded the same event ID since the up
+-- migration
+CREATE INDEX IF NOT EXISTS idx_sync_events_business_id ON sync_events(business_id);
+ALTER TABLE sync_events DROP CONSTRAINT sync_events_pkey;
+ALTER TABLE sync_events ADD PRIMARY KEY (id);
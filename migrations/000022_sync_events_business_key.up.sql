@@ -0,0 +1,5 @@
+-- Device-generated event IDs are only unique within a business, so key
+-- sync_events by both; the key also serves lookups by business
+ALTER TABLE sync_events DROP CONSTRAINT sync_events_pkey;
+ALTER TABLE sync_events ADD PRIMARY KEY (business_id, id);
+DROP INDEX IF EXISTS idx_sync_events_business_id;